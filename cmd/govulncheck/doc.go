@@ -13,6 +13,9 @@ paths, not code or other properties of your program. See
 https://vuln.go.dev/privacy.html for more. Use the -db flag to specify a
 different database, which must implement the specification at
 https://go.dev/security/vuln/database.
+Govulncheck reports when it starts downloading vulnerability data from an HTTP
+database, and how much data it downloaded, so that a slow download is not
+mistaken for a hang.
 
 Govulncheck looks for vulnerabilities in Go programs using a specific build
 configuration. For analyzing source code, that configuration is the Go version
@@ -41,6 +44,34 @@ To control which files are processed, use the -tags flag to provide a
 comma-separated list of build tags, and the -test flag to indicate that test
 files should be included.
 
+Source code is analyzed at the module versions selected for the build, which
+can be higher than the versions required by go.mod when other modules require
+later versions. A finding then reports the version required by go.mod along
+with the selected version it is based on, and govulncheck notes the
+vulnerabilities of a required version that the selected version fixes, which
+are not reported.
+
+For each called vulnerability, govulncheck tells whether its call stack enters
+the vulnerable module through the exported API of the module, or through its
+internal code, such as an unexported method called through an interface or a
+function of an internal package. In the latter case, changing how the code
+calls the module is unlikely to help, and upgrading it is the remedy. JSON
+output reports this as the "entry_api" of findings.
+
+In source mode, JSON findings also have a "dependency_path": the chain of
+modules, each with its version, through which the main module depends on the
+vulnerable module, following package imports across the fewest modules. For a
+transitive dependency, the modules in between tell which direct dependency to
+upgrade, and which module's upgrade may drop the vulnerable one.
+
+Calls from C back into the Go functions that a package exports to C with
+//export directives are followed through the C functions of the package, in
+the preambles of its Go files and in its C files, that mention them. A C
+function whose source is not part of the package, such as a function of a C
+library, is assumed to call back every function the package exports. These
+calls are labeled as assumed callbacks in the traces, and in JSON output as
+frames with "callback" set.
+
 To run govulncheck on a compiled binary, pass it the path to the binary file
 with the -mode=binary flag:
 
@@ -48,6 +79,42 @@ with the -mode=binary flag:
 
 Govulncheck uses the binary's symbol information to find mentions of vulnerable
 functions. Its output omits call stacks, which require source code analysis.
+When the compiler inlined a vulnerable function into its callers, govulncheck
+reports the source position of a call to the function, which is marked as
+inlined.
+
+Govulncheck also reports the version of Go that built the binary, and in JSON
+output the build settings recorded in the binary, such as CGO_ENABLED and
+GOFLAGS. They are reported even if the binary has no vulnerabilities, which
+helps find binaries built with Go versions that are no longer supported.
+
+The binary can also be the executable of a running process, such as
+/proc/<pid>/exe on Linux, or an ELF core dump of a process. Core dumps do not
+contain a symbol table, so govulncheck reports the vulnerabilities of all the
+modules found in their build information, as for stripped binaries. The build
+information is only found if the data segment of the program was dumped, and
+only for programs built with Go 1.18 or later.
+
+Vulnerabilities that only affect some platforms are matched against the GOOS
+and GOARCH build settings of the binary in binary mode. In source mode, they
+are only matched against the GOOS and GOARCH of the go command if the
+-match-platform flag is set, as code is often built for other platforms than
+the one it is scanned on. Vulnerabilities of other platforms are reported as not
+applicable with a message instead of as findings. Where the vulnerability
+database expresses no platform, the vulnerability applies to all of them. The
+database expresses no other build conditions, such as cgo or GODEBUG settings,
+so they are not matched.
+
+Govulncheck says when a scan is incomplete, so that a scan without findings is
+not mistaken for code without vulnerabilities. A scan is incomplete if the
+analysis of some package failed, if the -depth flag excluded modules from the
+scan, or if go.sum is missing entries for some dependencies, whose packages
+then cannot be loaded. The other packages are still scanned, with a warning
+about go.sum. The reasons are printed after the findings in text output, and JSON
+output then ends with a message listing them, which is absent for complete
+scans. In source mode, JSON output also records the scope of the scan before
+that message: the number of scanned packages, of the packages they depend on
+and of their modules, and whether test packages were scanned.
 
 Govulncheck exits successfully (exit code 0) if there are no vulnerabilities,
 and exits unsuccessfully if there are. It also exits successfully if -json flag
@@ -61,25 +128,431 @@ The -C flag causes govulncheck to change its working directory to the provided
 directory before running. Any patterns or files named on the command line are
 interpreted after changing directories.
 
+The -archive flag causes govulncheck to analyze the source code in the provided
+zip or tar file, which may be compressed with gzip, or in an archive read from
+standard input if the file is "-". The archive is extracted to a temporary
+directory that is removed after the scan, and patterns are interpreted in the
+directory of the module. The module is at the root of the archive, or in its
+single top-level directory if the root has no go.mod file, as in the snapshots
+of repositories served by code hosts. The -archive-dir flag names the directory
+of the module in the archive otherwise. Combine with -relpath=module to report
+positions that do not mention the temporary directory. It is only supported for
+source analysis.
+
+The -assume-called flag causes govulncheck to treat the vulnerabilities of the
+matching modules as called even when they are only imported, for modules so
+sensitive, such as cryptography or authentication libraries, that any known
+vulnerability must be acted upon. It accepts comma-separated glob patterns of
+module path prefixes, like the -internal flag. This overrides the result of the
+analysis: such vulnerabilities cause govulncheck to fail and are reported among
+the called ones, labeled as treated as called, and their findings are marked as
+assumed_called in JSON output.
+
+The -baseline flag causes govulncheck to report only the findings that are not
+accepted in the provided baseline file, which allows adopting govulncheck on an
+existing project without fixing every vulnerability first. Together with the
+-accept flag, govulncheck instead records all current findings as accepted in
+the baseline file. Findings are matched by their identity hash, so accepted
+findings stay accepted when code moves or the vulnerable module is upgraded to
+another affected version. Govulncheck warns about accepted findings that are no
+longer found, for instance because they were fixed.
+
+The -binary flag combines source analysis with the analysis of the named binary,
+built from the scanned code: vulnerable symbols called in the source are only
+reported as called if they are also compiled into the binary, and are reported
+as imported otherwise, as the linker left them out. Call stacks are still
+computed from the source. Govulncheck warns about the vulnerable symbols that
+are compiled into the binary but not called in the source, which may be called
+through reflection, and about differences between the build of the binary and
+the scanned code, such as other module versions or another platform. The binary
+must have a symbol table. It is only supported for source analysis.
+
+The -changed flag limits the findings of called vulnerabilities to those with
+an example trace that passes through one of the provided functions, a
+comma-separated list of symbols of the form pkg.Func or pkg.T.Method, where the
+closures of a function are part of it. A vulnerability without such a trace is
+reported as imported. This focuses the scan of a pull request on the functions
+it changes, so that it fails only if the change reaches a vulnerability. It is
+only supported in source mode.
+
+The -confidence flag causes govulncheck to mark called vulnerabilities as likely
+false positives when all of their call stacks go through more than the provided
+number of functions of the standard library, counting the vulnerable function.
+Such call stacks are often not realizable in practice. Likely false positives
+do not cause govulncheck to fail. With a value of the form n,drop, they are
+instead reported as imported, but not called. A vulnerability with some call
+stack within the threshold is reported as called as usual. It is only supported
+for source analysis.
+
+The -count flag causes govulncheck to print only a single line of the form
+"called=C imported=I total=T", where C is the number of vulnerabilities whose
+vulnerable symbols are called, I is the number of vulnerabilities whose
+packages are imported but not called, and T is their total. The exit code of
+govulncheck is 0 when this flag is provided. It cannot be combined with the
+-json flag.
+
 The -db flag causes govulncheck to read from the specified database, which must
 implement the specification at https://go.dev/security/vuln/database. By
 default, govulncheck fetches vulnerability data from https://vuln.go.dev.
+A database can also be served by a Go module proxy as a module holding the
+database at its root, so that existing proxy caching and authentication
+apply: the URL is then that of the module on the proxy with the scheme
+prefixed by "goproxy+", such as goproxy+https://proxy.example.com/example.com/vulndb,
+optionally followed by @version to pin a version of the module instead of
+using the latest one.
+
+The -db-overlay flag adds the OSV entries in the provided directory, in files
+named <ID>.json, to the vulnerability database. An overlay entry replaces the
+database entry with the same ID, and govulncheck warns about such replacements,
+noting when the replaced entry is the more recent one. This allows authors of
+new or updated entries to try them on real code before publishing them.
+Govulncheck fails if an entry of the directory is malformed.
+
+The -depth flag limits source analysis to modules at most the provided number
+of dependencies away from the main module, so that -depth=1 scans only the main
+module and its direct dependencies. A module's distance is measured along
+package imports. Vulnerable symbols of the scanned modules are still reported
+when they are reached through packages of deeper modules, but vulnerabilities
+in the deeper modules themselves are not. This is intended as a quick check
+and may miss vulnerabilities that a full scan reports.
+
+The -dispatch-tables flag causes govulncheck to assume, in source mode, that
+the functions stored in maps, slices, and arrays, such as the handlers of a
+command or route registry, are called by every dynamic call of a function value
+of the same signature. This finds vulnerable functions that are only reached
+through tables that the analysis cannot follow, for example when the table is
+accessed through reflection, at the cost of reporting calls that may never
+happen. The assumed calls are labeled in the traces, and in JSON output as
+frames with "assumed" set.
+
+The -entry-functions flag causes govulncheck to write to the provided file, as
+JSON, the package, name, receiver, and position of each entry function from
+which the call graph of a source scan is built, such as the main functions and
+the exported functions of the scanned packages. The file is written whether or
+not vulnerabilities are found, and documents the code that the analysis
+considers reachable, so that a scan seeded from the wrong packages can be
+diagnosed before its findings are trusted.
+
+The -entry-symbols flag scopes a binary scan to the provided comma-separated
+list of functions of the binary, such as the main function of one subcommand of
+a multi-call binary, named as in main.serve or example.com/cmd.Server.Serve. The
+vulnerable symbols of the binary are only reported as called if they are
+reached from one of these functions through direct calls, closures, or inlined
+code, which govulncheck recovers from the machine code of amd64, 386, arm, and
+arm64 binaries; the others are reported as imported. Functions only reached
+through interfaces or function values are thus not reported as called. A
+warning is printed for each entry symbol that is not a function of the binary,
+and the flag is not supported for stripped binaries.
+
+The -exclude-stdlib flag causes govulncheck to leave the vulnerabilities of the
+Go standard library out of its report, for teams that fix them separately by
+upgrading Go. They are only counted, in a message that also tells how many of
+them are called, and they do not cause govulncheck to fail. It is not supported
+in convert and query modes.
+
+The -first-seen flag turns the -baseline file into a history of the findings:
+all findings are reported, and those recorded in the baseline file are reported
+along with the date they were first seen, which tells how long the vulnerability
+has been present. Together with the -accept flag, govulncheck records the
+findings that are not in the baseline file yet as first seen on the current
+date, and keeps the dates of the others.
+
+The -flush-bytes and -flush-messages flags cause govulncheck to buffer JSON
+output and write it in chunks, once the provided number of bytes or messages is
+buffered, instead of writing each message as soon as it is produced. Chunks
+only hold complete messages, so the output is valid even when read while the
+scan runs, and the messages buffered when a scan fails are still written.
+
+The -format flag selects the output format, one of "text" (the default), "json",
+"github", "dot", "cyclonedx", "ids", or "sarif". With "github", govulncheck prints GitHub
+Actions workflow commands, so that findings appear as annotations of pull
+requests. Called vulnerabilities are reported as warnings at the position where
+the scanned module calls toward the vulnerable symbol, and imported
+vulnerabilities as notices that are not attached to a file. File names are
+relative to the directory named by the GITHUB_WORKSPACE environment variable, if
+it is set. With "dot", govulncheck prints a Graphviz DOT graph for each called
+vulnerability, which is the union of its call stacks. Nodes are functions and
+edges are calls labeled by the position of the call site. Vulnerable functions
+are outlined in red, functions of the standard library are filled in grey, and
+calls that cannot be statically resolved, such as calls through interfaces, are
+dashed. With "cyclonedx", govulncheck prints a CycloneDX 1.5 VEX document, whose
+components are the vulnerable modules, identified by package URLs such as
+pkg:golang/golang.org/x/text@v0.3.0. Called vulnerabilities are analyzed as
+"exploitable", and vulnerabilities that are only imported as "not_affected" with
+the justification "code_not_reachable". At the module and package scan levels,
+whose findings are not analyzed for calls, vulnerabilities are "in_triage".
+Vulnerabilities also carry the details, CVSS v3 rating, and publication and
+modification dates of their OSV entries, so that the document can serve as a
+VDR (Vulnerability Disclosure Report).
+With "ids", govulncheck prints a JSON object per line for each vulnerability
+found, sorted by OSV ID, with its aliases, the CVSS v3 vector of its most severe
+finding, and whether it is called, but no traces, which makes the sets of
+vulnerabilities of periodic scans cheap to collect and compare. With "sarif",
+govulncheck prints a SARIF 2.1.0 log, which can be uploaded to GitHub code
+scanning. Each vulnerability is a rule, described by its OSV entry and ranked
+by its CVSS v3 score, and each finding a result of the rule. Called findings are
+errors located where the scanned module calls toward the vulnerable symbol,
+with their trace as code flow, and imported findings are notes located at the
+go.mod file. These formats can also be produced from JSON output in convert mode.
+
+The -go-version flag causes govulncheck to match the vulnerabilities of the
+standard library against the provided Go version, such as go1.20.3, instead of
+the version of the go command. This models a program built with an older Go
+release than the one running govulncheck. Packages are still loaded by the go
+command, so govulncheck warns when the versions differ, as the standard library
+that is analyzed may not be the one of the provided version.
+
+The -go-versions flag accepts a comma-separated list of Go versions, such as
+go1.20.12,go1.21.5, and causes govulncheck to report the vulnerabilities of the
+standard library that affect any of them, rather than only the version of the go
+command or of -go-version. Each such finding lists the affected versions, telling
+which of the supported toolchains need patching. It is only supported in source
+mode.
+
+The -internal flag marks the findings of the modules owned by the organization
+running govulncheck as internal, and the others as external, so that findings
+can be routed to the teams that own the modules. It accepts a comma-separated
+list of glob patterns of module path prefixes, with the same syntax as the
+GOPRIVATE environment variable: "example.com/corp,*.corp.example.com" matches
+example.com/corp and any module under it, say. The ownership is shown next to
+the module in text output, and is part of the findings in JSON output.
 
-The -json flag causes govulncheck to print its output as a JSON object
-corresponding to the type [golang.org/x/vuln/internal/govulncheck.Result]. The
-exit code of govulncheck is 0 when this flag is provided.
+The -json flag, which is the same as -format=json, causes govulncheck to print
+its output as a JSON object corresponding to the type
+[golang.org/x/vuln/internal/govulncheck.Result]. The exit code of govulncheck is
+0 when this flag is provided.
+
+The -max-db-age flag causes govulncheck to fail when the vulnerability database
+was last modified longer ago than the provided age, such as 7d for seven days or
+36h, or when its last modification time is unknown. This guards against
+scanning with a cached or vendored database that has silently gone stale. With
+the -db-age-warn flag, govulncheck only warns instead. Scans of an
+intentionally pinned database, such as in an air-gapped environment, can
+suppress the check by setting -max-db-age=0, which is the default. It is not
+supported in convert mode.
+
+The -max-hops flag limits the findings of called vulnerabilities to those whose
+example trace reaches the vulnerable symbol within the provided number of calls
+of an entry point, so that -max-hops=1 only keeps the vulnerable symbols called
+directly by entry points. A vulnerability without such a trace is reported as
+imported. This is intended as a noise filter for initial triage, as deep call
+stacks are less likely to be triggered in practice. By default, or if the
+number is 0, traces of any length are reported.
+
+The -merge flag causes govulncheck to merge the findings of a vulnerability
+whose call stacks go through the same functions and only differ by the
+positions of the calls, as often happens in generated code. The first of them
+is reported along with the number of findings merged into it. Without the
+flag, every distinct call stack is reported.
 
 The -mode flag causes govulncheck to run source or binary analysis. By default,
 govulnchecks runs source analysis.
 
+The -osv flag limits the scan to the vulnerabilities in the provided
+comma-separated list of OSV IDs or their aliases, such as CVE IDs. Other
+vulnerabilities are neither analyzed nor reported. This quickly answers whether
+code is affected by a specific vulnerability, for instance while responding to
+its disclosure.
+
+The -overlay flag causes govulncheck to read a JSON build overlay from the
+provided file, in the format accepted by the -overlay flag of go build. The
+contents of the replacement files are used in place of the files they replace
+when loading packages for source analysis. This allows govulncheck to run in
+build sandboxes where source files are not at their usual locations on disk.
+
+The -position-format flag selects how text output writes source positions, so
+that they can be clicked in the terminal of your editor: colon, the default,
+writes file:line:col, paren writes file(line,col), and vscode writes
+vscode://file links to absolute paths, which open the position in VS Code. For
+tools that index source files by byte offset, which cannot be derived from the
+column alone in lines with tabs or multibyte characters, offset writes
+file:line:col:#offset, with the byte offset of the position in its file, as in
+the file:#offset positions accepted by gopls. The offset is left out where it is
+unknown, as for binaries. JSON output always includes the offsets of positions.
+It is not supported in convert mode.
+
+The -reachability-snapshot and -reachability-diff flags compare the call graphs
+of two source scans, for instance before and after a dependency upgrade. The
+-reachability-snapshot flag writes to the provided file the vulnerable symbols
+reached by the code, along with the calls between functions that lead to them.
+The -reachability-diff flag compares the current scan with the snapshot in the
+provided file, and reports which vulnerabilities are no longer or newly
+reachable, which vulnerable symbols are no longer or newly reached, and which
+symbols are reached through different calls. This shows changes to reachability
+even when the findings stay the same. Both flags can name the same file, which
+is then overwritten after the comparison.
+
+The -relpath flag causes govulncheck to report source positions relative to
+the provided directory instead of as absolute paths. If the directory is
+"module", positions are relative to the root of the main module. This makes
+output portable across machines. It has no effect when run on a binary.
+
+The -repo-url, -repo-commit, and -repo-branch flags record the repository, commit,
+and branch of the scanned code in the config message of JSON output, so that
+downstream systems can attribute findings to the exact source, for instance to
+link the relative positions of traces into a code host. Govulncheck does not
+check them. With the -repo-findings flag, they are also recorded on each
+finding, for systems that store findings separately.
+
+The -representative flag selects how govulncheck picks the call stack reported
+for a called vulnerable symbol among those found. The default, "first", picks the
+first stack found, which already prefers stacks with fewer standard library
+functions, fewer calls, and fewer dynamic calls, in that order. With "shortest",
+it picks the stack with the fewest calls, with "static" the stack with the fewest
+dynamic calls, such as calls of interface methods, and with "main" the stack
+whose last function in the main module is the closest to the vulnerable symbol.
+Stacks that go through other vulnerable symbols of the same package are never
+picked, and stacks that only run in tests are only picked if there are no
+others. Other strategies than the default are recorded in the config message of
+JSON output. It is only supported in source mode.
+
+The -separate-test-deps flag, which requires the -test flag, causes govulncheck
+to report the vulnerabilities of the modules that are only dependencies of tests
+in a section of their own, as they are not part of the program. Like those of
+build-time tools, they are not counted in the summary and do not cause
+govulncheck to fail. It only affects text output.
+
+The -sequential flag causes govulncheck to run the analysis on a single
+goroutine: the call graph is built before the vulnerability database is queried,
+packages are built one at a time, and the call stacks of vulnerabilities are
+searched one after the other, in the order of their IDs. The findings are the
+same as without it, but timings, as reported by -timing or a profiler, and the
+order of the analysis are reproducible from run to run, at the cost of a slower
+scan. It is only supported in source mode.
+
+The -severity-map flag causes govulncheck to assign the severities of your
+organization, such as P0 to P3, to findings by the rules in the provided JSON
+file, of the form
+
+	{"rules": [
+		{"modules": "golang.org/x/*", "min_score": 9, "severity": "P0"},
+		{"min_score": 7, "severity": "P1"},
+		{"severity": "P3"}
+	]}
+
+A finding is given the severity of the first rule whose comma-separated glob
+patterns, as for GOPRIVATE, match its module, and whose minimum CVSS v3 base
+score its vulnerability reaches. A rule without modules matches all modules.
+JSON output reports both the CVSS v3 vector of the vulnerability and the
+mapped severity.
+
+The -show flag accepts a comma-separated list of additional information to
+include in text output. The option "traces" prints full call stacks instead of
+their summaries, along with the functions that calls through interfaces and
+function values may dispatch to, which are the candidates field of the frames
+of JSON output, "color" enables colored output, "fixes" adds a list of
+module upgrades that resolve the called vulnerabilities, where each upgrade
+names the lowest version that fixes all of a module's vulnerabilities, and
+"references" adds the links of each vulnerability, such as to the commit that
+fixes it, and the IDs of related vulnerabilities. In JSON output, references
+and related IDs are part of the OSV entries. The option "reproduce" adds, for
+source analysis, a govulncheck command that reproduces only the finding, by
+scanning the package of its entry point for its vulnerability with -osv. In
+JSON output, the command is part of each finding. The option "ranges" adds the
+affected version ranges of each vulnerable module, by their introduced and fixed
+events as listed in the vulnerability database, which the found version is
+matched against. In JSON output, the ranges are part of the OSV entries, which
+only list the modules of the findings, and the range containing the found version
+is the affected_range of each finding.
+
+The -sort flag orders the reported findings. With -sort=effort, the findings
+that are easiest to fix come first: those of direct dependencies and of the
+standard library with a fixed version, then those of indirect dependencies,
+which may require upgrading other modules, and last those without a fixed
+version. Findings with the same effort are ordered by decreasing CVSS v3 base
+score. This suits clearing a backlog of findings starting with the quick wins.
+
+The -surface flag causes govulncheck to report, for each module with
+vulnerabilities, how many of the exported functions and methods of its
+packages are referenced by the main module. Using a small part of the API of a
+module suggests that upgrading the module carries little risk. The numbers are
+printed after the vulnerabilities in text output, and are part of the module
+summaries in JSON output. It is only supported for source analysis.
+
 The -tags flag accepts a comma-separated list of build tags to control which
 files should be included in loaded packages for source analysis.
 
 The -test flag causes govulncheck to include test files in the source analysis.
+Vulnerabilities whose call stacks pass through test code are marked as only
+called from tests. With -test=nofail, such vulnerabilities are still reported,
+but they do not cause govulncheck to exit with a failure, so that
+vulnerabilities only reachable from tests do not block a deployment.
+Vulnerabilities in modules that are only dependencies of tests, such as mocking
+libraries imported from _test.go files, are marked as such, and in JSON output
+as findings with "test_dependency" set.
+
+The -test-helpers flag designates test helper packages, such as
+example.com/m/internal/testutil, by a comma-separated list of glob patterns
+of package paths, as for GOPRIVATE. The exported functions of the helper
+packages are entry points of the analysis, even when the helpers are only
+imported by the tests of the scanned packages, and vulnerabilities whose call
+stacks pass through them are marked as only called from tests, as for -test.
+This reports the vulnerabilities that matter to test infrastructure without
+mistaking them for vulnerabilities of the program.
+
+The -timeout flag sets a wall-clock budget for a source scan, such as 10m. When
+it expires, govulncheck stops the analysis and reports what it found so far,
+instead of running until the CI job running it is killed: vulnerabilities whose
+call stacks were not searched yet are reported as imported, later phases such
+as the -tools scan are skipped, and the scan is reported as incomplete. If the
+budget expires before the packages are loaded or the vulnerabilities are
+fetched, there is nothing to report and govulncheck fails.
+
+The -timing flag causes govulncheck to report, at the end of a source scan,
+the time spent loading packages, fetching vulnerabilities, building the call
+graph, matching vulnerabilities, and searching for call stacks, as well as the
+call stack search time of each vulnerable package. This shows which phase
+dominates a slow scan. Building the call graph runs concurrently with fetching
+vulnerabilities. In JSON output, the timing is a final message of its own.
+
+The -tools flag causes govulncheck to also check, in source mode, the
+build-time tools of the main modules: the packages named by the tool directives
+of their go.mod files, and the packages run with "go run" by the go:generate
+directives of their source files. Tools are analyzed at the package level, and
+their findings are reported in a separate "Build-time tools" section, as they
+are not part of the program. Tools run at an explicit version, as in
+"go run pkg@version", are not loaded from the build list and are not checked.
+
+The -trace-order flag sets the order of the frames of the traces of findings
+in JSON output. By default, a trace starts from the vulnerable symbol and ends
+at the entry point of the code that calls it; -trace-order=entry reverses it to
+follow the calls. When the flag is set, each finding records the order of its
+trace in its trace_order field, which is sink_first or entry_first. The
+-mode=convert flag accepts traces in both orders.
+
+The -try-upgrade flag proposes module upgrades as a comma-separated list, as
+in -try-upgrade=golang.org/x/text@v0.3.8, and can be repeated. After the scan,
+govulncheck applies the upgrades with "go get" to a temporary copy of the
+go.mod file of the main module, scans the source again with it, and reports
+the vulnerabilities that the upgrades would fix, introduce, or change from
+imported to called and back. The go.mod file of the module is not modified.
 
 The -v flag causes govulncheck to output more information when run on source.
 It has no effect when run on a binary.
 
+The -verify flag causes govulncheck to check the trace of each called finding
+against the call graph in a second pass that is independent of the search of
+call stacks: starting at the vulnerable symbol, the pass looks up each caller
+of the trace among the call sites of its callee and requires the trace to start
+at an entry point. Findings that cannot be corroborated are reported as
+warnings, which point to bugs in the analysis, and the number of verified
+findings is reported after the scan. It is only supported in source mode.
+
+The -without-calls flag causes govulncheck to also report which called
+vulnerabilities would no longer be called if the packages matching the provided
+comma-separated glob patterns, as for GOPRIVATE, made no calls. This helps
+planning remediations that drop a dependency instead of upgrading a module. The
+call stacks are searched again without the outgoing calls of the matching
+packages, and the findings themselves are not changed. It is only supported in
+source mode.
+
+The -worst flag causes govulncheck to report only the most severe finding of
+each module, which gives a short overview of the modules that need attention.
+Findings are ranked by the CVSS v3 base score of their vulnerability, if the
+database publishes one, then called vulnerabilities are ranked over imported
+ones, and then shorter call stacks over longer ones. Module summaries in JSON
+output still count all the findings of the module.
+
 # Limitations
 
 Govulncheck has these limitations:
@@ -89,6 +562,10 @@ Govulncheck has these limitations:
   - Calls to functions made using package reflect are not visible to static
     analysis. Vulnerable code reachable only through those calls will not be
     reported.
+  - Code guarded by a condition that is a constant false, such as "if debug"
+    where debug is a boolean constant, is known to never execute, and calls
+    made from it are not reported as called. Other dead code, such as code
+    guarded by a variable that is never set, is analyzed as if it could run.
   - Because Go binaries do not contain detailed call information, govulncheck
     cannot show the call graphs for detected vulnerabilities. It may also
     report false positives for code that is in the binary but unreachable.
@@ -98,8 +575,9 @@ Govulncheck has these limitations:
     version. For example, a standard library vulnerability that only applies for
     Go 1.18 will not be reported if the current Go version is 1.19. See
     https://go.dev/issue/54841 for updates to this limitation.
-  - For stripped binaries, govulncheck reports vulnerabilities for all modules
-    on which the binary depends due to the lack of symbol information.
+  - For stripped binaries and core dumps, govulncheck reports vulnerabilities
+    for all modules on which the binary depends due to the lack of symbol
+    information.
 
 # Feedback
 
@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"unsafe"
@@ -45,10 +46,15 @@ var fixups = []fixup{
 		// For example,/a/b/c.go becomes .../c.go .
 		// This makes it possible to compare govulncheck output across systems, because
 		// Go filenames include setup-specific paths.
-		pattern: `[^\s"]*\.go[\s":]`,
+		// Links that open positions in VS Code keep their scheme.
+		pattern: `[^\s"]*\.go[\s":(]`,
 		replaceFunc: func(b []byte) []byte {
 			s := string(b)
-			return []byte(fmt.Sprintf(`.../%s%c`, filepath.Base(s[:len(s)-1]), s[len(s)-1]))
+			scheme := ""
+			if strings.HasPrefix(s, "vscode://file") {
+				scheme = "vscode://file/"
+			}
+			return []byte(fmt.Sprintf(`%s.../%s%c`, scheme, filepath.Base(s[:len(s)-1]), s[len(s)-1]))
 		},
 	}, {
 		// There was a one-line change in container/heap/heap.go between 1.18
@@ -58,6 +64,14 @@ var fixups = []fixup{
 	}, {
 		pattern: `Scanning your code and (\d+) packages across (\d+)`,
 		replace: `Scanning your code and P packages across M`,
+	}, {
+		// The scope of JSON output counts the same packages and
+		// modules as the progress message.
+		pattern: `"dependencies": \d+`,
+		replace: `"dependencies": P`,
+	}, {
+		pattern: `"modules": \d+`,
+		replace: `"modules": M`,
 	}, {
 		pattern: `govulncheck@v([^ ]*) `,
 		replace: `govulncheck@v0.0.0-00000000000-20000101010101 `,
@@ -67,6 +81,10 @@ var fixups = []fixup{
 	}, {
 		pattern: `"scanner_version": "[^"]*"`,
 		replace: `"scanner_version": "v0.0.0-00000000000-20000101010101"`,
+	}, {
+		// The version of the scanner in CycloneDX documents.
+		pattern: `("name": "govulncheck",\s*"version": )"[^"]*"`,
+		replace: `$1"v0.0.0-00000000000-20000101010101"`,
 	}, {
 		pattern: `file:///(.*)/testdata/vulndb`,
 		replace: `testdata/vulndb`,
@@ -82,6 +100,19 @@ var fixups = []fixup{
 	}, {
 		pattern: `"go_version": "go[^\s"]*"`,
 		replace: `"go_version": "go1.18"`,
+	}, {
+		pattern: `built with go1\.[\.\d]*\d`,
+		replace: `built with go1.18`,
+	}, {
+		// Commands reproducing findings name the setup-specific
+		// directory of the scanned module.
+		pattern: `-C [^\s"]*/testdata/modules/`,
+		replace: `-C .../modules/`,
+	}, {
+		// Build settings of test binaries depend on the platform and
+		// environment of the test.
+		pattern: `"settings": \[[^\]]*\]`,
+		replace: `"settings": [...]`,
 	},
 }
 
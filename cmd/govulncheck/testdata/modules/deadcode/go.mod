@@ -0,0 +1,6 @@
+module golang.org/deadcode
+
+go 1.18
+
+// This version has a vulnerability that is only called in dead code.
+require golang.org/x/text v0.3.0
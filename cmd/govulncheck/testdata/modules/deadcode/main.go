@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/text/language"
+)
+
+// debug enables the parsing of the language tags of the arguments, which
+// is disabled at compile time.
+const debug = false
+
+func main() {
+	if debug {
+		fmt.Println(language.Parse(os.Args[1]))
+	}
+	fmt.Println(language.English)
+}
@@ -0,0 +1,6 @@
+module golang.org/dispatch
+
+go 1.18
+
+// This version has a vulnerability that is called through an interface.
+require golang.org/x/text v0.3.0
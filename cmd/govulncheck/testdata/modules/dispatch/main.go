@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/text/language"
+)
+
+// parser is implemented by a parser that calls the vulnerable
+// language.Parse, and by one that does not.
+type parser interface {
+	Parse(s string) (language.Tag, error)
+}
+
+type textParser struct{}
+
+func (textParser) Parse(s string) (language.Tag, error) {
+	return language.Parse(s)
+}
+
+type undParser struct{}
+
+func (undParser) Parse(s string) (language.Tag, error) {
+	return language.Und, nil
+}
+
+func main() {
+	var p parser = textParser{}
+	if len(os.Args) > 2 {
+		p = undParser{}
+	}
+	fmt.Println(p.Parse(os.Args[1]))
+}
@@ -0,0 +1,6 @@
+module golang.org/dispatchtable
+
+go 1.18
+
+// This version has a vulnerability that is called through a dispatch table.
+require golang.org/x/text v0.3.0
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"golang.org/x/text/language"
+)
+
+// commands is a dispatch table of the commands of the program, which are
+// looked up through reflection, so that the call graph analysis cannot
+// tell which of them are called.
+var commands = map[string]func(string){}
+
+func register() {
+	commands["parse"] = parse
+	commands["echo"] = echo
+}
+
+func parse(s string) {
+	fmt.Println(language.Parse(s))
+}
+
+func echo(s string) {
+	fmt.Println(s)
+}
+
+func main() {
+	register()
+	cmd := reflect.ValueOf(commands).MapIndex(reflect.ValueOf(os.Args[1]))
+	cmd.Interface().(func(string))(os.Args[2])
+}
@@ -0,0 +1,16 @@
+module golang.org/goroutine
+
+go 1.18
+
+require (
+	// This version has a vulnerability that is called in a deferred
+	// function.
+	github.com/tidwall/gjson v1.6.5
+	// This version has a vulnerability that is called in a goroutine.
+	golang.org/x/text v0.3.0
+)
+
+require (
+	github.com/tidwall/match v1.1.0 // indirect
+	github.com/tidwall/pretty v1.2.0 // indirect
+)
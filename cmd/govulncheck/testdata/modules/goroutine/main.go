@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tidwall/gjson"
+	"golang.org/x/text/language"
+)
+
+// The vulnerable functions are only called by go and defer statements.
+func main() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go worker(&wg)
+	wg.Wait()
+
+	defer func() {
+		fmt.Println(gjson.Result{}.Get(""))
+	}()
+}
+
+func worker(wg *sync.WaitGroup) {
+	defer wg.Done()
+	fmt.Println(language.Parse(""))
+}
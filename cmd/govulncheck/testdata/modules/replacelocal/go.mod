@@ -0,0 +1,8 @@
+module golang.org/replacelocal
+
+go 1.18
+
+// The replacement is a patched copy of golang.org/x/text.
+replace golang.org/x/text v0.3.0 => ./text
+
+require golang.org/x/text v0.3.0
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+func main() {
+	fmt.Println("hello")
+	language.Parse("")
+}
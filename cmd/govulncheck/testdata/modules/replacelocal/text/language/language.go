@@ -0,0 +1,10 @@
+// Package language is a patched copy of golang.org/x/text/language.
+package language
+
+// Tag is a language tag.
+type Tag struct{}
+
+// Parse parses s as a language tag.
+func Parse(s string) (Tag, error) {
+	return Tag{}, nil
+}
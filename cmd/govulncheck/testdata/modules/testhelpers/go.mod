@@ -0,0 +1,7 @@
+module golang.org/testhelpers
+
+go 1.18
+
+// This version has a vulnerability that is only called from a test
+// helper package.
+require golang.org/x/text v0.3.0
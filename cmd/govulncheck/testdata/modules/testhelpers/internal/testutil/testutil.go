@@ -0,0 +1,10 @@
+// Package testutil provides helpers for tests.
+package testutil
+
+import "golang.org/x/text/language"
+
+// Tag returns the canonical form of the language tag s.
+func Tag(s string) string {
+	t, _ := language.Parse(s)
+	return t.String()
+}
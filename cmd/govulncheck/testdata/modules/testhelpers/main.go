@@ -0,0 +1,11 @@
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(tag("en"))
+}
+
+func tag(s string) string {
+	return s
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/testhelpers/internal/testutil"
+)
+
+func TestTag(t *testing.T) {
+	if got, want := tag("en"), testutil.Tag("en"); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,6 @@
+module golang.org/testonly
+
+go 1.18
+
+// This version has a vulnerability that is only called from tests.
+require golang.org/x/text v0.3.0
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestTag(t *testing.T) {
+	want, _ := language.Parse("en")
+	if got := tag("en"); got != want.String() {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
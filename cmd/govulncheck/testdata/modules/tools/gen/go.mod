@@ -0,0 +1,5 @@
+module example.com/gen
+
+go 1.18
+
+require golang.org/x/text v0.3.0
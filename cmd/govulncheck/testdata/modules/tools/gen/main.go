@@ -0,0 +1,20 @@
+// Command gen generates the canonical form of language tags.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/text/language"
+)
+
+func main() {
+	for _, arg := range os.Args[1:] {
+		tag, err := language.Parse(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(tag)
+	}
+}
@@ -0,0 +1,12 @@
+module golang.org/tools
+
+go 1.18
+
+// The code generator, which imports a vulnerable version of
+// golang.org/x/text, is run by a go:generate directive.
+replace example.com/gen v0.0.0 => ./gen
+
+require (
+	example.com/gen v0.0.0
+	golang.org/x/text v0.3.0
+)
@@ -0,0 +1,10 @@
+package main
+
+import "fmt"
+
+//go:generate go run example.com/gen -o tags.go
+//go:generate go run golang.org/x/text/cmd/gotext@v0.3.0 update
+
+func main() {
+	fmt.Println(tags)
+}
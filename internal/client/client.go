@@ -36,6 +36,54 @@ type Client struct {
 
 type Options struct {
 	HTTPClient *http.Client
+
+	// Overlay, if set, is a directory of OSV entries, in files named
+	// <ID>.json, that are added to the database. An overlay entry takes
+	// precedence over the database entry with the same ID.
+	Overlay string
+
+	// OnDuplicate, if set, is called once for each entry of the Overlay
+	// that replaces a database entry with the same ID, when the modules
+	// index of the database is read, so that diverging copies of an
+	// entry can be reported. It may be called concurrently.
+	OnDuplicate func(*Duplicate)
+
+	// OnDownload, if set, is called when the download of an endpoint of
+	// an HTTP database starts and when it is done, so that slow
+	// downloads can be reported. It is not called for local databases.
+	// It may be called concurrently.
+	OnDownload func(*Download)
+}
+
+// Download describes the progress of the download of an endpoint of an
+// HTTP database.
+type Download struct {
+	// Endpoint is the endpoint being downloaded, such as "index/modules".
+	Endpoint string
+
+	// Bytes is the number of compressed bytes downloaded, which is 0
+	// when the download starts.
+	Bytes int64
+
+	// Done is set when the download is done.
+	Done bool
+}
+
+// Duplicate describes an OSV entry of an overlay that replaces the entry
+// with the same ID of the database.
+type Duplicate struct {
+	// ID is the ID of the entries.
+	ID string
+
+	// Database is the source of the database, and Overlay the directory
+	// of the overlay.
+	Database string
+	Overlay  string
+
+	// DatabaseModified and OverlayModified are the times that the
+	// entries of the database and of the overlay were last modified.
+	DatabaseModified time.Time
+	OverlayModified  time.Time
 }
 
 // NewClient returns a client that reads the vulnerability database
@@ -43,20 +91,42 @@ type Options struct {
 //
 // It supports databases following the API described
 // in https://go.dev/security/vuln/database#api.
+//
+// A database can also be served as a module by a module proxy, with a
+// "goproxy+http" or "goproxy+https" prefixed URL made of the URL of the
+// proxy and the escaped path of the module, optionally followed by "@"
+// and a version, as in "goproxy+https://proxy.example.com/example.com/vulndb@v1.2.0".
+// The module holds the database at its root, with the layout of a
+// local database. The latest version of the module is used by default.
 func NewClient(source string, opts *Options) (_ *Client, err error) {
 	source = strings.TrimRight(source, "/")
 	uri, err := url.Parse(source)
 	if err != nil {
 		return nil, err
 	}
+	var c *Client
 	switch uri.Scheme {
 	case "http", "https":
-		return newHTTPClient(uri, opts)
+		c, err = newHTTPClient(uri, opts)
 	case "file":
-		return newLocalClient(uri)
+		c, err = newLocalClient(uri)
+	case proxySchemePrefix + "http", proxySchemePrefix + "https":
+		c, err = newProxyClient(uri, opts)
 	default:
 		return nil, fmt.Errorf("source %q has unsupported scheme", uri)
 	}
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.Overlay != "" {
+		src, err := newOverlaySource(c.source, opts.Overlay)
+		if err != nil {
+			return nil, err
+		}
+		src.database, src.onDuplicate = source, opts.OnDuplicate
+		c = &Client{source: src}
+	}
+	return c, nil
 }
 
 var errUnknownSchema = errors.New("unrecognized vulndb format; see https://go.dev/security/vuln/database#api for accepted schema")
@@ -5,14 +5,18 @@
 package client
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -49,6 +53,49 @@ func newTestServer(dir string) *httptest.Server {
 	return httptest.NewServer(mux)
 }
 
+// newProxyTestServer returns a module proxy serving the database in dir
+// as version v1.0.0 of module example.com/vulndb, and the source URL of
+// the database.
+func newProxyTestServer(t *testing.T, dir string) (*httptest.Server, string) {
+	const prefix = "example.com/vulndb@v1.0.0/"
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(prefix + filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	})
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/example.com/vulndb/@latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Version":"v1.0.0","Time":"2023-04-03T15:57:51Z"}`)
+	})
+	mux.HandleFunc("/example.com/vulndb/@v/v1.0.0.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	})
+	srv := httptest.NewServer(mux)
+	return srv, "goproxy+" + srv.URL + "/example.com/vulndb"
+}
+
 func entries(ids []string) ([]*osv.Entry, error) {
 	if len(ids) == 0 {
 		return nil, nil
@@ -137,6 +184,34 @@ func TestNewClient(t *testing.T) {
 		}
 	})
 
+	t.Run("proxy/version", func(t *testing.T) {
+		srv, src := newProxyTestServer(t, testVulndb)
+		t.Cleanup(srv.Close)
+
+		c, err := NewClient(src+"@v1.0.0", &Options{HTTPClient: srv.Client()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.LastModifiedTime(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		// The database is not found at versions the proxy does not serve.
+		c, err = NewClient(src+"@v1.1.0", &Options{HTTPClient: srv.Client()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.LastModifiedTime(context.Background()); err == nil {
+			t.Error("LastModifiedTime() succeeded for a missing version, want error")
+		}
+	})
+
+	t.Run("proxy/no module", func(t *testing.T) {
+		if _, err := NewClient("goproxy+https://proxy.example.com", nil); err == nil {
+			t.Error("NewClient() succeeded without module path, want error")
+		}
+	})
+
 	t.Run("local/legacy", func(t *testing.T) {
 		src := testLegacyVulndbFileURL
 		_, err := NewClient(src, nil)
@@ -326,6 +401,18 @@ func testAllClientTypes(t *testing.T, test func(t *testing.T, c *Client)) {
 		test(t, fc)
 	})
 
+	t.Run("proxy", func(t *testing.T) {
+		srv, src := newProxyTestServer(t, testVulndb)
+		t.Cleanup(srv.Close)
+
+		pc, err := NewClient(src, &Options{HTTPClient: srv.Client()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		test(t, pc)
+	})
+
 	t.Run("in-memory", func(t *testing.T) {
 		testEntries, err := entries(testIDs)
 		if err != nil {
@@ -339,3 +426,140 @@ func testAllClientTypes(t *testing.T, test func(t *testing.T, c *Client)) {
 		test(t, mc)
 	})
 }
+
+func TestOnDownload(t *testing.T) {
+	srv := newTestServer(testVulndb)
+	t.Cleanup(srv.Close)
+
+	var downloads []Download
+	c, err := NewClient(srv.URL, &Options{
+		HTTPClient: srv.Client(),
+		OnDownload: func(d *Download) { downloads = append(downloads, *d) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LastModifiedTime(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(filepath.Join(testVulndb, dbEndpoint+".json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Download{
+		{Endpoint: dbEndpoint},
+		{Endpoint: dbEndpoint, Bytes: fi.Size(), Done: true},
+	}
+	if diff := cmp.Diff(want, downloads); diff != "" {
+		t.Errorf("downloads mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	overlay := []*osv.Entry{
+		{
+			// Replaces the entry of the database, which also
+			// affects stdlib.
+			ID:       "GO-2022-0229",
+			Modified: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+			Affected: []osv.Affected{{Module: osv.Module{Path: "golang.org/x/crypto"}}},
+		},
+		{
+			ID:       "GO-2099-0001",
+			Modified: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC),
+			Affected: []osv.Affected{{Module: osv.Module{Path: "golang.org/x/crypto"}}},
+		},
+	}
+	dir := t.TempDir()
+	for _, e := range overlay {
+		b, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, e.ID+".json"), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var dups []*Duplicate
+	c, err := NewClient(testVulndbFileURL, &Options{Overlay: dir, OnDuplicate: func(d *Duplicate) {
+		dups = append(dups, d)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	modified, err := c.LastModifiedTime(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := overlay[0].Modified; modified != want {
+		t.Errorf("LastModifiedTime = %s, want %s", modified, want)
+	}
+
+	resps, err := c.ByModules(ctx, []*ModuleRequest{{Path: "golang.org/x/crypto"}, {Path: "stdlib"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][]string
+	for _, r := range resps {
+		var ids []string
+		for _, e := range r.Entries {
+			ids = append(ids, e.ID)
+		}
+		got = append(got, ids)
+	}
+	want := [][]string{
+		{"GO-2022-0229", "GO-2099-0001"},
+		{"GO-2021-0159", "GO-2021-0240", "GO-2021-0264", "GO-2022-0273"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ByModules() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(overlay[0], resps[0].Entries[0]); diff != "" {
+		t.Errorf("overlay entry mismatch (-want +got):\n%s", diff)
+	}
+	// The replaced entry is reported once, although it is
+	// requested for both of its modules.
+	if len(dups) != 1 || dups[0].ID != "GO-2022-0229" || dups[0].Overlay != dir || !dups[0].OverlayModified.Equal(overlay[0].Modified) {
+		t.Errorf("got duplicates %+v; want GO-2022-0229 of overlay %s", dups, dir)
+	}
+}
+
+func TestOverlayErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, file, content, wantErr string
+	}{
+		{
+			name:    "malformed",
+			file:    "GO-2099-0001.json",
+			content: `{"id": "GO-2099-0001",`,
+			wantErr: "unexpected end of JSON input",
+		},
+		{
+			name:    "filename",
+			file:    "GO-2099-0002.json",
+			content: `{"id": "GO-2099-0001", "affected": [{"package": {"name": "golang.org/x/crypto"}}]}`,
+			wantErr: "OSV entries must have filename of the form <ID>.json, got GO-2099-0002.json",
+		},
+		{
+			name:    "no affected",
+			file:    "GO-2099-0001.json",
+			content: `{"id": "GO-2099-0001"}`,
+			wantErr: "no affected modules",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, tc.file)
+			if err := os.WriteFile(file, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := NewClient(testVulndbFileURL, &Options{Overlay: dir})
+			want := fmt.Sprintf("overlay entry %s: %s", file, tc.wantErr)
+			if err == nil || err.Error() != want {
+				t.Errorf("got error %v, want %q", err, want)
+			}
+		})
+	}
+}
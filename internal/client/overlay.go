@@ -0,0 +1,190 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/vuln/internal/derrors"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// newOverlaySource returns a source that reads the database of base,
+// with the OSV entries in the directory dir added to it.
+//
+// It errors if any of the JSON files of dir cannot be unmarshaled into
+// OSV, have a filename other than <ID>.json, or affect no module.
+func newOverlaySource(base source, dir string) (*overlaySource, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading overlay: %v", err)
+	}
+	ovs := &overlaySource{
+		base:       base,
+		dir:        dir,
+		entries:    make(map[string][]byte),
+		index:      newIndex(),
+		duplicates: make(map[string]bool),
+	}
+	for _, f := range files {
+		fname := f.Name()
+		if f.IsDir() || filepath.Ext(fname) != ".json" {
+			continue
+		}
+		file := filepath.Join(dir, fname)
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading overlay: %v", err)
+		}
+		var entry osv.Entry
+		if err := json.Unmarshal(b, &entry); err != nil {
+			return nil, fmt.Errorf("overlay entry %s: %v", file, err)
+		}
+		if err := validateOverlayEntry(fname, &entry); err != nil {
+			return nil, fmt.Errorf("overlay entry %s: %v", file, err)
+		}
+		ovs.entries[entry.ID] = b
+		ovs.index.add(&entry)
+	}
+	return ovs, nil
+}
+
+// validateOverlayEntry reports whether entry, read from the file named
+// fname, can be added to a database.
+func validateOverlayEntry(fname string, entry *osv.Entry) error {
+	if entry.ID == "" {
+		return fmt.Errorf("missing ID")
+	}
+	if fname != entry.ID+".json" {
+		return fmt.Errorf("OSV entries must have filename of the form <ID>.json, got %s", fname)
+	}
+	if len(entry.Affected) == 0 {
+		return fmt.Errorf("no affected modules")
+	}
+	for _, a := range entry.Affected {
+		if a.Module.Path == "" {
+			return fmt.Errorf("affected module has no path")
+		}
+	}
+	return nil
+}
+
+// overlaySource reads a vulnerability database from a base source,
+// with additional OSV entries held in memory. An overlay entry takes
+// precedence over the base entry with the same ID, including in the
+// indexes, so the modules it affects are the ones of the overlay entry.
+type overlaySource struct {
+	base    source
+	dir     string            // directory of the overlay entries
+	entries map[string][]byte // raw OSV entries keyed by ID
+	index   *index            // index of the overlay entries
+
+	// database is the source of base, and onDuplicate, if not nil, is
+	// called for the overlay entries replacing entries of base.
+	database    string
+	onDuplicate func(*Duplicate)
+
+	mu         sync.Mutex      // guards duplicates
+	duplicates map[string]bool // IDs passed to onDuplicate
+}
+
+func (ovs *overlaySource) get(ctx context.Context, endpoint string) (_ []byte, err error) {
+	derrors.Wrap(&err, "get(%s)", endpoint)
+
+	switch endpoint {
+	case dbEndpoint:
+		return ovs.db(ctx)
+	case modulesEndpoint:
+		return ovs.modules(ctx)
+	}
+	if path.Dir(endpoint) == idDir {
+		if b, ok := ovs.entries[path.Base(endpoint)]; ok {
+			return b, nil
+		}
+	}
+	return ovs.base.get(ctx, endpoint)
+}
+
+// db returns the database metadata of the base source, modified as
+// of the latest entry of the overlay if that is more recent.
+func (ovs *overlaySource) db(ctx context.Context) ([]byte, error) {
+	b, err := ovs.base.get(ctx, dbEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	var meta dbMeta
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, err
+	}
+	if ovs.index.db.Modified.After(meta.Modified) {
+		meta.Modified = ovs.index.db.Modified
+	}
+	return json.Marshal(&meta)
+}
+
+// modules returns the modules index of the base source, with the
+// vulnerabilities of the overlay replacing those with the same ID.
+func (ovs *overlaySource) modules(ctx context.Context) ([]byte, error) {
+	b, err := ovs.base.get(ctx, modulesEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	var metas []*moduleMeta
+	if err := json.Unmarshal(b, &metas); err != nil {
+		return nil, err
+	}
+	modules := make(modulesIndex)
+	for _, m := range metas {
+		var vulns []moduleVuln
+		for _, v := range m.Vulns {
+			if _, ok := ovs.entries[v.ID]; !ok {
+				vulns = append(vulns, v)
+			} else {
+				ovs.duplicate(v)
+			}
+		}
+		if len(vulns) > 0 {
+			modules[m.Path] = &moduleMeta{Path: m.Path, Vulns: vulns}
+		}
+	}
+	for path, m := range ovs.index.modules {
+		if base, ok := modules[path]; ok {
+			base.Vulns = append(base.Vulns, m.Vulns...)
+		} else {
+			modules[path] = &moduleMeta{Path: path, Vulns: m.Vulns}
+		}
+	}
+	return json.Marshal(modules)
+}
+
+// duplicate calls onDuplicate for the overlay entry replacing v, a
+// vulnerability of the modules index of the base source, unless it was
+// called for its ID already.
+func (ovs *overlaySource) duplicate(v moduleVuln) {
+	if ovs.onDuplicate == nil {
+		return
+	}
+	ovs.mu.Lock()
+	defer ovs.mu.Unlock()
+	if ovs.duplicates[v.ID] {
+		return
+	}
+	ovs.duplicates[v.ID] = true
+	d := &Duplicate{ID: v.ID, Database: ovs.database, Overlay: ovs.dir, DatabaseModified: v.Modified}
+	for _, m := range ovs.index.modules {
+		for _, ov := range m.Vulns {
+			if ov.ID == v.ID {
+				d.OverlayModified = ov.Modified
+			}
+		}
+	}
+	ovs.onDuplicate(d)
+}
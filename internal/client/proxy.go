@@ -0,0 +1,168 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/vuln/internal/derrors"
+)
+
+// proxySchemePrefix prefixes the scheme of the URLs of databases served
+// by a module proxy, as in "goproxy+https".
+const proxySchemePrefix = "goproxy+"
+
+// newProxyClient returns a client of the database served by a module
+// proxy as a version of a module. uri is the URL of the proxy followed by
+// the escaped module path, as in the URLs of the module proxy protocol,
+// and optionally by "@" and the version of the module, with a scheme
+// prefixed by proxySchemePrefix. The latest version of the module is used
+// if none is given.
+func newProxyClient(uri *url.URL, opts *Options) (*Client, error) {
+	u := *uri
+	u.Scheme = strings.TrimPrefix(u.Scheme, proxySchemePrefix)
+	var version string
+	if i := strings.LastIndex(u.Path, "@"); i >= 0 && !strings.Contains(u.Path[i:], "/") {
+		u.Path, version = u.Path[:i], u.Path[i+1:]
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf("source %q has no module path", uri)
+	}
+	ps := &proxySource{url: u.String(), version: version, c: http.DefaultClient}
+	if opts != nil {
+		if opts.HTTPClient != nil {
+			ps.c = opts.HTTPClient
+		}
+		ps.onDownload = opts.OnDownload
+	}
+	return &Client{source: ps}, nil
+}
+
+// proxySource reads a vulnerability database from the zip of a module
+// version served by a module proxy, following the module proxy protocol
+// described at https://go.dev/ref/mod#goproxy-protocol. The zip holds the
+// database at the root of the module, with the layout of a local database.
+// It is downloaded once, when the first endpoint is read.
+type proxySource struct {
+	url     string // URL of the module on the proxy
+	version string // version of the module, or "" for the latest one
+	c       *http.Client
+
+	// onDownload, if not nil, is called with the progress of downloads.
+	onDownload func(*Download)
+
+	once  sync.Once
+	files map[string][]byte // contents of the database by endpoint
+	err   error             // error downloading the database
+}
+
+func (ps *proxySource) get(ctx context.Context, endpoint string) (_ []byte, err error) {
+	derrors.Wrap(&err, "get(%s)", endpoint)
+
+	ps.once.Do(func() {
+		ps.files, ps.err = ps.download(ctx)
+	})
+	if ps.err != nil {
+		return nil, ps.err
+	}
+	b, ok := ps.files[endpoint]
+	if !ok {
+		return nil, fmt.Errorf("no data found at endpoint %q", endpoint)
+	}
+	return b, nil
+}
+
+// download downloads the zip of the module version and returns the
+// contents of the JSON files of the database, keyed by endpoint.
+func (ps *proxySource) download(ctx context.Context) (_ map[string][]byte, err error) {
+	derrors.Wrap(&err, "downloading %s", ps.url)
+
+	version := ps.version
+	if version == "" {
+		b, err := ps.fetch(ctx, "@latest")
+		if err != nil {
+			return nil, err
+		}
+		var info struct{ Version string }
+		if err := json.Unmarshal(b, &info); err != nil {
+			return nil, err
+		}
+		version = info.Version
+	}
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ps.fetch(ctx, "@v/"+escaped+".zip")
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+	// The files of a module zip are prefixed by the module path and
+	// version, as in "example.com/vulndb@v1.0.0/index/db.json".
+	prefix := "@" + version + "/"
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		i := strings.Index(f.Name, prefix)
+		if i < 0 || !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+		endpoint := strings.TrimSuffix(f.Name[i+len(prefix):], ".json")
+		if files[endpoint], err = readZipFile(f); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// fetch returns the body of the response to a GET request of the path
+// of the module proxy protocol, such as "@latest", for the module.
+func (ps *proxySource) fetch(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ps.url+"/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ps.onDownload != nil {
+		ps.onDownload(&Download{Endpoint: path})
+	}
+	resp, err := ps.c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status code: %d", resp.StatusCode)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if ps.onDownload != nil {
+		ps.onDownload(&Download{Endpoint: path, Bytes: int64(len(b)), Done: true})
+	}
+	return b, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
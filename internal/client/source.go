@@ -29,17 +29,23 @@ type source interface {
 }
 
 func newHTTPSource(url string, opts *Options) *httpSource {
-	c := http.DefaultClient
-	if opts != nil && opts.HTTPClient != nil {
-		c = opts.HTTPClient
+	hs := &httpSource{url: url, c: http.DefaultClient}
+	if opts != nil {
+		if opts.HTTPClient != nil {
+			hs.c = opts.HTTPClient
+		}
+		hs.onDownload = opts.OnDownload
 	}
-	return &httpSource{url: url, c: c}
+	return hs
 }
 
 // httpSource reads a vulnerability database from an http(s) source.
 type httpSource struct {
 	url string
 	c   *http.Client
+
+	// onDownload, if not nil, is called with the progress of downloads.
+	onDownload func(*Download)
 }
 
 func (hs *httpSource) get(ctx context.Context, endpoint string) (_ []byte, err error) {
@@ -50,6 +56,9 @@ func (hs *httpSource) get(ctx context.Context, endpoint string) (_ []byte, err e
 	if err != nil {
 		return nil, err
 	}
+	if hs.onDownload != nil {
+		hs.onDownload(&Download{Endpoint: endpoint})
+	}
 	resp, err := hs.c.Do(req)
 	if err != nil {
 		return nil, err
@@ -59,14 +68,35 @@ func (hs *httpSource) get(ctx context.Context, endpoint string) (_ []byte, err e
 		return nil, fmt.Errorf("unexpected HTTP status code: %d", resp.StatusCode)
 	}
 
+	body := &countingReader{r: resp.Body}
+
 	// Uncompress the result.
-	r, err := gzip.NewReader(resp.Body)
+	r, err := gzip.NewReader(body)
 	if err != nil {
 		return nil, err
 	}
 	defer r.Close()
 
-	return io.ReadAll(r)
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if hs.onDownload != nil {
+		hs.onDownload(&Download{Endpoint: endpoint, Bytes: body.n, Done: true})
+	}
+	return b, nil
+}
+
+// countingReader is a reader that counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
 }
 
 func newLocalSource(dir string) *localSource {
@@ -27,6 +27,70 @@ type Handler interface {
 	Finding(finding *Finding) error
 }
 
+// A ModuleHandler is a Handler that also handles module summaries.
+// Module summaries are only passed to handlers that implement it.
+type ModuleHandler interface {
+	Handler
+
+	// Module is called for each module summary.
+	Module(module *Module) error
+}
+
+// A BuildHandler is a Handler that also handles the build information
+// of binaries. Build information is only passed to handlers that
+// implement it.
+type BuildHandler interface {
+	Handler
+
+	// Build is called with the build information of the binary.
+	Build(build *Build) error
+}
+
+// A ScopeHandler is a Handler that also handles the scope of a scan.
+// Scopes are only passed to handlers that implement it.
+type ScopeHandler interface {
+	Handler
+
+	// Scope is called with the scope of a source scan.
+	Scope(scope *Scope) error
+}
+
+// An IncompleteHandler is a Handler that also handles the reasons why a
+// scan is incomplete. They are only passed to handlers that implement it.
+type IncompleteHandler interface {
+	Handler
+
+	// Incomplete is called if the scan is incomplete.
+	Incomplete(incomplete *Incomplete) error
+}
+
+// A TimingHandler is a Handler that also handles the timing of a scan.
+// Timings are only passed to handlers that implement it.
+type TimingHandler interface {
+	Handler
+
+	// Timing is called with the timing of the scan.
+	Timing(timing *Timing) error
+}
+
+// A Transformer rewrites the findings of a scan before they are handed
+// to a Handler. It may modify, drop, add, or reorder findings. Findings
+// it returns must refer to OSV entries detected by the scan.
+type Transformer func([]*Finding) ([]*Finding, error)
+
+// A SeverityMapper returns the severity of a finding in the scheme of the
+// organization running the scan, such as "P1", given the path of the
+// vulnerable module, the CVSS v3 vector of the vulnerability, and its base
+// score. The vector is empty and the score is 0 if the OSV entry has no
+// CVSS v3 severity. A finding mapped to "" has no organization severity.
+type SeverityMapper func(module, cvss string, score float64) string
+
+// A PositionMapper rewrites a source position of a finding before it is
+// handed to a Handler, such as to map the files of a build sandbox to the
+// files of a repository checkout. It returns the position to report, and
+// false to omit the position from the finding.
+type PositionMapper func(pos Position) (Position, bool)
+
 // HandleJSON reads the json from the supplied stream and hands the decoded
 // output to the handler.
 func HandleJSON(from io.Reader, to Handler) error {
@@ -37,23 +101,42 @@ func HandleJSON(from io.Reader, to Handler) error {
 		if err := dec.Decode(&msg); err != nil {
 			return err
 		}
-		// dispatch the message
-		var err error
-		if msg.Config != nil {
-			err = to.Config(msg.Config)
-		}
-		if msg.Progress != nil {
-			err = to.Progress(msg.Progress)
-		}
-		if msg.OSV != nil {
-			err = to.OSV(msg.OSV)
-		}
-		if msg.Finding != nil {
-			err = to.Finding(msg.Finding)
-		}
-		if err != nil {
+		if err := handleMessage(&msg, to); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// handleMessage dispatches msg to the handler.
+func handleMessage(msg *Message, to Handler) error {
+	var err error
+	if msg.Config != nil {
+		err = to.Config(msg.Config)
+	}
+	if msg.Progress != nil {
+		err = to.Progress(msg.Progress)
+	}
+	if msg.OSV != nil {
+		err = to.OSV(msg.OSV)
+	}
+	if msg.Finding != nil {
+		err = to.Finding(msg.Finding)
+	}
+	if mh, ok := to.(ModuleHandler); ok && msg.Module != nil {
+		err = mh.Module(msg.Module)
+	}
+	if bh, ok := to.(BuildHandler); ok && msg.Build != nil {
+		err = bh.Build(msg.Build)
+	}
+	if sh, ok := to.(ScopeHandler); ok && msg.Scope != nil {
+		err = sh.Scope(msg.Scope)
+	}
+	if ih, ok := to.(IncompleteHandler); ok && msg.Incomplete != nil {
+		err = ih.Incomplete(msg.Incomplete)
+	}
+	if th, ok := to.(TimingHandler); ok && msg.Timing != nil {
+		err = th.Timing(msg.Timing)
+	}
+	return err
+}
@@ -5,8 +5,8 @@
 package govulncheck
 
 import (
+	"bytes"
 	"encoding/json"
-
 	"io"
 
 	"golang.org/x/vuln/internal/osv"
@@ -14,31 +14,122 @@ import (
 
 type jsonHandler struct {
 	enc *json.Encoder
+
+	// If policy buffers messages, enc writes to buf, which holds the
+	// count messages not flushed to w yet.
+	w      io.Writer
+	buf    *bytes.Buffer
+	count  int
+	policy FlushPolicy
+}
+
+// FlushPolicy tells when a JSON handler flushes the messages it buffers to
+// the underlying writer. The buffered messages are flushed once they take
+// at least Bytes bytes, or once there are Messages of them, whichever comes
+// first, and when the handler is flushed. A threshold of 0 is not used.
+// Messages are always flushed whole, so each chunk written is a sequence
+// of complete JSON values.
+type FlushPolicy struct {
+	Bytes    int
+	Messages int
+}
+
+// buffers reports whether p buffers messages.
+func (p FlushPolicy) buffers() bool {
+	return p.Bytes > 0 || p.Messages > 0
 }
 
 // NewJSONHandler returns a handler that writes govulncheck output as json.
 func NewJSONHandler(w io.Writer) Handler {
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return &jsonHandler{enc: enc}
+	return NewBufferedJSONHandler(w, FlushPolicy{})
+}
+
+// NewBufferedJSONHandler returns a handler that writes govulncheck output
+// as json, buffering messages and flushing them to w as told by policy.
+// Each message is written as soon as it is handled if policy has no
+// thresholds. The handler must be flushed by calling its Flush method once
+// all messages are handled.
+func NewBufferedJSONHandler(w io.Writer, policy FlushPolicy) Handler {
+	h := &jsonHandler{w: w, policy: policy}
+	out := w
+	if policy.buffers() {
+		h.buf = &bytes.Buffer{}
+		out = h.buf
+	}
+	h.enc = json.NewEncoder(out)
+	h.enc.SetIndent("", "  ")
+	return h
+}
+
+// Flush writes the buffered messages to the underlying writer.
+func (h *jsonHandler) Flush() error {
+	if h.buf == nil || h.buf.Len() == 0 {
+		return nil
+	}
+	_, err := h.w.Write(h.buf.Bytes())
+	h.buf.Reset()
+	h.count = 0
+	return err
+}
+
+// encode writes msg, and flushes the buffered messages if they reach a
+// threshold of the flush policy.
+func (h *jsonHandler) encode(msg Message) error {
+	if err := h.enc.Encode(msg); err != nil {
+		return err
+	}
+	if h.buf == nil {
+		return nil
+	}
+	h.count++
+	if (h.policy.Bytes > 0 && h.buf.Len() >= h.policy.Bytes) || (h.policy.Messages > 0 && h.count >= h.policy.Messages) {
+		return h.Flush()
+	}
+	return nil
 }
 
 // Config writes config block in JSON to the underlying writer.
 func (h *jsonHandler) Config(config *Config) error {
-	return h.enc.Encode(Message{Config: config})
+	return h.encode(Message{Config: config})
 }
 
 // Progress writes a progress message in JSON to the underlying writer.
 func (h *jsonHandler) Progress(progress *Progress) error {
-	return h.enc.Encode(Message{Progress: progress})
+	return h.encode(Message{Progress: progress})
 }
 
 // OSV writes an osv entry in JSON to the underlying writer.
 func (h *jsonHandler) OSV(entry *osv.Entry) error {
-	return h.enc.Encode(Message{OSV: entry})
+	return h.encode(Message{OSV: entry})
 }
 
 // Finding writes a finding in JSON to the underlying writer.
 func (h *jsonHandler) Finding(finding *Finding) error {
-	return h.enc.Encode(Message{Finding: finding})
+	return h.encode(Message{Finding: finding})
+}
+
+// Module writes a module summary in JSON to the underlying writer.
+func (h *jsonHandler) Module(module *Module) error {
+	return h.encode(Message{Module: module})
+}
+
+// Build writes build information in JSON to the underlying writer.
+func (h *jsonHandler) Build(build *Build) error {
+	return h.encode(Message{Build: build})
+}
+
+// Scope writes the scope of the scan in JSON to the underlying writer.
+func (h *jsonHandler) Scope(scope *Scope) error {
+	return h.encode(Message{Scope: scope})
+}
+
+// Incomplete writes the reasons why the scan is incomplete in JSON to the
+// underlying writer.
+func (h *jsonHandler) Incomplete(incomplete *Incomplete) error {
+	return h.encode(Message{Incomplete: incomplete})
+}
+
+// Timing writes the timing of the scan in JSON to the underlying writer.
+func (h *jsonHandler) Timing(timing *Timing) error {
+	return h.encode(Message{Timing: timing})
 }
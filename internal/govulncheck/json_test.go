@@ -0,0 +1,76 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package govulncheck_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// chunkWriter records the chunks written to it.
+type chunkWriter struct {
+	chunks []string
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, string(p))
+	return len(p), nil
+}
+
+func TestBufferedJSONHandler(t *testing.T) {
+	var want bytes.Buffer
+	unbuffered := govulncheck.NewJSONHandler(&want)
+	progress := func(h govulncheck.Handler, i int) {
+		if err := h.Progress(&govulncheck.Progress{Message: fmt.Sprintf("message %d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		progress(unbuffered, i)
+	}
+
+	for _, test := range []struct {
+		name       string
+		policy     govulncheck.FlushPolicy
+		wantChunks int
+	}{
+		{"unbuffered", govulncheck.FlushPolicy{}, 5},
+		{"messages", govulncheck.FlushPolicy{Messages: 2}, 3},
+		{"bytes", govulncheck.FlushPolicy{Bytes: 1 << 20}, 1},
+		{"one byte", govulncheck.FlushPolicy{Bytes: 1}, 5},
+		{"first threshold", govulncheck.FlushPolicy{Bytes: 1 << 20, Messages: 4}, 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := &chunkWriter{}
+			h := govulncheck.NewBufferedJSONHandler(w, test.policy)
+			for i := 0; i < 5; i++ {
+				progress(h, i)
+			}
+			if err := h.(interface{ Flush() error }).Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if len(w.chunks) != test.wantChunks {
+				t.Errorf("got %d chunks; want %d", len(w.chunks), test.wantChunks)
+			}
+			// Each chunk holds complete messages.
+			for _, c := range w.chunks {
+				if _, err := govulncheck.ReadRecording(bytes.NewReader([]byte(c))); err != nil {
+					t.Errorf("chunk %q is not a sequence of messages: %v", c, err)
+				}
+			}
+			var got bytes.Buffer
+			for _, c := range w.chunks {
+				got.WriteString(c)
+			}
+			if diff := cmp.Diff(want.String(), got.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package legacy converts the findings of govulncheck into the nested
+// Vuln, Module, Package, and CallStack structure of the earlier
+// govulncheck result, for tools built against that structure.
+//
+// The structure is aggregated from the stream of OSV entries and
+// findings, so a Handler can be passed wherever a govulncheck.Handler
+// is accepted, such as to govulncheck.HandleJSON to convert the JSON
+// output of govulncheck.
+package legacy
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// Vuln represents a single OSV entry.
+type Vuln struct {
+	// OSV contains all data from the OSV entry for this vulnerability.
+	OSV *osv.Entry
+
+	// Modules contains all of the modules in the OSV entry where a
+	// vulnerable package is imported by the target source code or binary.
+	//
+	// For example, a module M with two packages M/p1 and M/p2, where only
+	// p1 is vulnerable, will appear in this list if and only if p1 is
+	// imported by the target source code or binary.
+	Modules []*Module
+}
+
+// IsCalled reports whether the vulnerability is called, therefore
+// affecting the target source code or binary.
+func (v *Vuln) IsCalled() bool {
+	for _, m := range v.Modules {
+		for _, p := range m.Packages {
+			if len(p.CallStacks) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Module represents a specific vulnerability relevant to a single module.
+type Module struct {
+	// Path is the module path of the module containing the vulnerability.
+	//
+	// Importable packages in the standard library will have the path
+	// "stdlib".
+	Path string
+
+	// FoundVersion is the module version where the vulnerability was
+	// found.
+	FoundVersion string
+
+	// FixedVersion is the module version where the vulnerability was
+	// fixed. If there are multiple fixed versions in the OSV report, this
+	// will be the latest fixed version.
+	//
+	// This is empty if a fix is not available.
+	FixedVersion string
+
+	// Packages contains all the vulnerable packages in the OSV entry that
+	// are imported by the target source code or binary.
+	Packages []*Package
+}
+
+// Package is a Go package with known vulnerable symbols.
+type Package struct {
+	// Path is the import path of the package containing the
+	// vulnerability.
+	Path string
+
+	// CallStacks contains a representative call stack for each
+	// vulnerable symbol that is called.
+	//
+	// For vulnerabilities found in binaries, only the vulnerable symbol
+	// is provided.
+	CallStacks []CallStack
+}
+
+// CallStack contains a representative call stack for a vulnerable
+// symbol.
+type CallStack struct {
+	// Symbol is the name of the detected vulnerable function or method.
+	//
+	// This follows the naming convention in the OSV report.
+	Symbol string
+
+	// Summary is a one-line description of the callstack, used by the
+	// default govulncheck mode.
+	//
+	// Example: module3.main calls github.com/shiyanhui/dht.DHT.Run
+	Summary string
+
+	// Frames contains an entry for each stack in the call stack.
+	//
+	// Frames are sorted starting from the entry point to the
+	// imported vulnerable symbol. The last frame in Frames should match
+	// Symbol.
+	Frames []*StackFrame
+}
+
+// StackFrame represents a call stack entry.
+type StackFrame struct {
+	// PkgPath is the package path.
+	PkgPath string
+
+	// FuncName is the function name.
+	FuncName string
+
+	// RecvType is the receiver type, as in "*T", if the called symbol
+	// is a method.
+	//
+	// The client can create the final symbol name by
+	// prepending RecvType to FuncName.
+	RecvType string
+
+	// Position describes an arbitrary source position
+	// including the file, line, and column location.
+	// A Position is valid if the line number is > 0.
+	Position token.Position
+}
+
+// Name returns the full qualified function name from sf,
+// adjusted to remove pointer annotations.
+func (sf *StackFrame) Name() string {
+	n := sf.FuncName
+	if sf.RecvType != "" {
+		n = strings.TrimPrefix(sf.RecvType, "*") + "." + n
+	}
+	if sf.PkgPath != "" {
+		n = sf.PkgPath + "." + n
+	}
+	return n
+}
+
+// Pos returns the position of the call in sf as string.
+// If position is not available, return "".
+func (sf *StackFrame) Pos() string {
+	if sf.Position.IsValid() {
+		return sf.Position.String()
+	}
+	return ""
+}
+
+// Handler is a govulncheck.Handler that aggregates the OSV entries and
+// findings of a scan into Vulns.
+//
+// The zero value is ready to use.
+type Handler struct {
+	osvs     map[string]*osv.Entry
+	findings []*govulncheck.Finding
+}
+
+// Read reads the JSON output of govulncheck from r and returns its Vulns.
+func Read(r io.Reader) ([]*Vuln, error) {
+	h := &Handler{}
+	if err := govulncheck.HandleJSON(r, h); err != nil {
+		return nil, err
+	}
+	return h.Vulns(), nil
+}
+
+// Config does nothing, as the structure has no configuration.
+func (h *Handler) Config(config *govulncheck.Config) error {
+	return nil
+}
+
+// Progress does nothing, as the structure has no progress messages.
+func (h *Handler) Progress(progress *govulncheck.Progress) error {
+	return nil
+}
+
+// OSV records entry, for the Vuln of its findings.
+func (h *Handler) OSV(entry *osv.Entry) error {
+	if h.osvs == nil {
+		h.osvs = map[string]*osv.Entry{}
+	}
+	h.osvs[entry.ID] = entry
+	return nil
+}
+
+// Finding records finding, to be aggregated by Vulns.
+func (h *Handler) Finding(finding *govulncheck.Finding) error {
+	if len(finding.Trace) == 0 {
+		return fmt.Errorf("finding of %s has no trace", finding.OSV)
+	}
+	h.findings = append(h.findings, finding)
+	return nil
+}
+
+// Vulns returns the Vulns of the findings handled so far, sorted by OSV
+// ID, with their modules and packages in the order of their first
+// findings.
+//
+// Each called finding provides a call stack of its package. Findings of
+// the same symbol, which differ by their position or their scan level,
+// add a single call stack. The findings of a module or a package that
+// are not called provide the module or the package, without call stacks.
+func (h *Handler) Vulns() []*Vuln {
+	vulns := map[string]*Vuln{}
+	symbols := map[*Package]map[string]bool{}
+	for _, f := range h.findings {
+		v := vulns[f.OSV]
+		if v == nil {
+			v = &Vuln{OSV: h.osvs[f.OSV]}
+			if v.OSV == nil {
+				v.OSV = &osv.Entry{ID: f.OSV}
+			}
+			vulns[f.OSV] = v
+		}
+		sink := f.Trace[0]
+		m := module(v, sink, f.FixedVersion)
+		if sink.Package == "" {
+			continue
+		}
+		p := pkg(m, sink.Package)
+		if sink.Function == "" {
+			continue
+		}
+		cs := callStack(f)
+		if symbols[p] == nil {
+			symbols[p] = map[string]bool{}
+		}
+		if !symbols[p][cs.Symbol] {
+			symbols[p][cs.Symbol] = true
+			p.CallStacks = append(p.CallStacks, cs)
+		}
+	}
+	var sorted []*Vuln
+	for _, v := range vulns {
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].OSV.ID < sorted[j].OSV.ID })
+	return sorted
+}
+
+// module returns the module of v for frame, the vulnerable frame of a
+// finding, adding it to v if needed.
+func module(v *Vuln, frame *govulncheck.Frame, fixed string) *Module {
+	for _, m := range v.Modules {
+		if m.Path == frame.Module {
+			return m
+		}
+	}
+	m := &Module{Path: frame.Module, FoundVersion: frame.Version, FixedVersion: fixed}
+	v.Modules = append(v.Modules, m)
+	return m
+}
+
+// pkg returns the package of m with path, adding it to m if needed.
+func pkg(m *Module, path string) *Package {
+	for _, p := range m.Packages {
+		if p.Path == path {
+			return p
+		}
+	}
+	p := &Package{Path: path}
+	m.Packages = append(m.Packages, p)
+	return p
+}
+
+// callStack returns the call stack of f, a called finding, whose trace
+// starts at the vulnerable symbol.
+func callStack(f *govulncheck.Finding) CallStack {
+	sink := f.Trace[0]
+	cs := CallStack{Symbol: sink.Function}
+	if sink.Receiver != "" {
+		cs.Symbol = strings.TrimPrefix(sink.Receiver, "*") + "." + sink.Function
+	}
+	for i := len(f.Trace) - 1; i >= 0; i-- {
+		fr := f.Trace[i]
+		sf := &StackFrame{PkgPath: fr.Package, FuncName: fr.Function, RecvType: fr.Receiver}
+		if fr.Position != nil {
+			sf.Position = token.Position{
+				Filename: fr.Position.Filename,
+				Offset:   fr.Position.Offset,
+				Line:     fr.Position.Line,
+				Column:   fr.Position.Column,
+			}
+		}
+		cs.Frames = append(cs.Frames, sf)
+	}
+	cs.Summary = summary(cs.Frames)
+	return cs
+}
+
+// summary returns a one-line description of frames, from the entry
+// point to the vulnerable symbol, as in "a.go:1:2: m.main calls v.V".
+func summary(frames []*StackFrame) string {
+	if len(frames) == 0 {
+		return ""
+	}
+	entry, sink := frames[0], frames[len(frames)-1]
+	var b strings.Builder
+	if pos := entry.Pos(); pos != "" {
+		b.WriteString(pos + ": ")
+	}
+	b.WriteString(entry.Name())
+	if len(frames) == 1 {
+		return b.String()
+	}
+	if len(frames) > 2 {
+		b.WriteString(" calls " + frames[1].Name() + ", which eventually calls ")
+		b.WriteString(sink.Name())
+		return b.String()
+	}
+	b.WriteString(" calls " + sink.Name())
+	return b.String()
+}
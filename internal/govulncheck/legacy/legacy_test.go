@@ -0,0 +1,99 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package legacy_test
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/govulncheck/legacy"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestRead(t *testing.T) {
+	text := &osv.Entry{ID: "GO-2021-0113"}
+	gjson := &osv.Entry{ID: "GO-2021-0054"}
+	textMod := &govulncheck.Frame{Module: "golang.org/x/text", Version: "v0.3.0"}
+	textPkg := &govulncheck.Frame{Module: "golang.org/x/text", Version: "v0.3.0", Package: "golang.org/x/text/language"}
+	parse := &govulncheck.Frame{Module: "golang.org/x/text", Version: "v0.3.0", Package: "golang.org/x/text/language", Function: "Parse"}
+	main := &govulncheck.Frame{Module: "golang.org/vuln", Package: "golang.org/vuln", Function: "main", Position: &govulncheck.Position{Filename: "main.go", Line: 12, Column: 29}}
+	gjsonPkg := &govulncheck.Frame{Module: "github.com/tidwall/gjson", Version: "v1.6.5", Package: "github.com/tidwall/gjson"}
+
+	rec := &govulncheck.Recorder{}
+	rec.Config(&govulncheck.Config{ProtocolVersion: govulncheck.ProtocolVersion})
+	rec.Progress(&govulncheck.Progress{Message: "Scanning..."})
+	rec.OSV(text)
+	rec.OSV(gjson)
+	rec.Finding(&govulncheck.Finding{OSV: text.ID, FixedVersion: "v0.3.7", Trace: []*govulncheck.Frame{textMod}})
+	rec.Finding(&govulncheck.Finding{OSV: gjson.ID, FixedVersion: "v1.6.6", Trace: []*govulncheck.Frame{gjsonPkg}})
+	rec.Finding(&govulncheck.Finding{OSV: text.ID, FixedVersion: "v0.3.7", Trace: []*govulncheck.Frame{textPkg}})
+	rec.Finding(&govulncheck.Finding{OSV: text.ID, FixedVersion: "v0.3.7", Trace: []*govulncheck.Frame{parse, main}})
+	var buf bytes.Buffer
+	if err := rec.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := legacy.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*legacy.Vuln{
+		{
+			OSV: gjson,
+			Modules: []*legacy.Module{{
+				Path:         "github.com/tidwall/gjson",
+				FoundVersion: "v1.6.5",
+				FixedVersion: "v1.6.6",
+				Packages:     []*legacy.Package{{Path: "github.com/tidwall/gjson"}},
+			}},
+		},
+		{
+			OSV: text,
+			Modules: []*legacy.Module{{
+				Path:         "golang.org/x/text",
+				FoundVersion: "v0.3.0",
+				FixedVersion: "v0.3.7",
+				Packages: []*legacy.Package{{
+					Path: "golang.org/x/text/language",
+					CallStacks: []legacy.CallStack{{
+						Symbol:  "Parse",
+						Summary: "main.go:12:29: golang.org/vuln.main calls golang.org/x/text/language.Parse",
+						Frames: []*legacy.StackFrame{
+							{PkgPath: "golang.org/vuln", FuncName: "main", Position: token.Position{Filename: "main.go", Line: 12, Column: 29}},
+							{PkgPath: "golang.org/x/text/language", FuncName: "Parse"},
+						},
+					}},
+				}},
+			}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if got[0].IsCalled() || !got[1].IsCalled() {
+		t.Errorf("got called %t and %t; want false and true", got[0].IsCalled(), got[1].IsCalled())
+	}
+	if pos := got[1].Modules[0].Packages[0].CallStacks[0].Frames[1].Pos(); pos != "" {
+		t.Errorf("got position %q of the vulnerable symbol; want none", pos)
+	}
+}
+
+func TestStackFrameName(t *testing.T) {
+	for _, tc := range []struct {
+		sf   *legacy.StackFrame
+		want string
+	}{
+		{&legacy.StackFrame{PkgPath: "golang.org/x/text/language", FuncName: "Parse"}, "golang.org/x/text/language.Parse"},
+		{&legacy.StackFrame{PkgPath: "golang.org/x/text/language", FuncName: "Parse", RecvType: "*Parser"}, "golang.org/x/text/language.Parser.Parse"},
+		{&legacy.StackFrame{FuncName: "init"}, "init"},
+	} {
+		if got := tc.sf.Name(); got != tc.want {
+			t.Errorf("got %q; want %q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package govulncheck
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"golang.org/x/vuln/internal/osv"
+)
+
+// A MultiHandler is a Handler that hands each message of a scan to all of
+// its handlers, in order, so that a single scan can be written in several
+// formats at once. Module summaries, build information, scopes, reasons
+// why the scan is incomplete, and timings are only handed to the handlers
+// that implement the corresponding optional interfaces.
+//
+// A handler that fails to handle a message does not stop the others: each
+// message is handed to all handlers, and the errors they return are
+// combined, see MultiError. A handler that failed keeps receiving the
+// following messages.
+type MultiHandler struct {
+	handlers []Handler
+}
+
+// NewMultiHandler returns a handler that hands messages to handlers.
+func NewMultiHandler(handlers ...Handler) *MultiHandler {
+	return &MultiHandler{handlers: append([]Handler(nil), handlers...)}
+}
+
+// MultiError is the error of a MultiHandler when several of its handlers
+// fail to handle a message, with an error for each of them, in order.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any of the errors of e matches target.
+func (e MultiError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors of e that matches target, and if one
+// is found, sets target to that error value and returns true.
+func (e MultiError) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// each calls handle for each handler of h and combines the errors it
+// returns. The errors of nested MultiHandlers are flattened, errors equal
+// to one that was already returned, such as the same sentinel error from
+// several handlers, are only reported once, and a single error is
+// returned as is.
+func (h *MultiHandler) each(handle func(Handler) error) error {
+	var errs MultiError
+	add := func(err error) {
+		if !containsError(errs, err) {
+			errs = append(errs, err)
+		}
+	}
+	for _, handler := range h.handlers {
+		err := handle(handler)
+		if me, ok := err.(MultiError); ok {
+			for _, err := range me {
+				add(err)
+			}
+		} else if err != nil {
+			add(err)
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errs
+}
+
+// containsError reports whether err is among errs. Errors of types that
+// are not comparable are never found.
+func containsError(errs []error, err error) bool {
+	if !reflect.TypeOf(err).Comparable() {
+		return false
+	}
+	for _, e := range errs {
+		if reflect.TypeOf(e).Comparable() && e == err {
+			return true
+		}
+	}
+	return false
+}
+
+// Config hands config to all handlers.
+func (h *MultiHandler) Config(config *Config) error {
+	return h.each(func(to Handler) error { return to.Config(config) })
+}
+
+// Progress hands progress to all handlers.
+func (h *MultiHandler) Progress(progress *Progress) error {
+	return h.each(func(to Handler) error { return to.Progress(progress) })
+}
+
+// OSV hands entry to all handlers.
+func (h *MultiHandler) OSV(entry *osv.Entry) error {
+	return h.each(func(to Handler) error { return to.OSV(entry) })
+}
+
+// Finding hands finding to all handlers.
+func (h *MultiHandler) Finding(finding *Finding) error {
+	return h.each(func(to Handler) error { return to.Finding(finding) })
+}
+
+// Module hands module to the handlers that are ModuleHandlers.
+func (h *MultiHandler) Module(module *Module) error {
+	return h.each(func(to Handler) error {
+		if mh, ok := to.(ModuleHandler); ok {
+			return mh.Module(module)
+		}
+		return nil
+	})
+}
+
+// Build hands build to the handlers that are BuildHandlers.
+func (h *MultiHandler) Build(build *Build) error {
+	return h.each(func(to Handler) error {
+		if bh, ok := to.(BuildHandler); ok {
+			return bh.Build(build)
+		}
+		return nil
+	})
+}
+
+// Scope hands scope to the handlers that are ScopeHandlers.
+func (h *MultiHandler) Scope(scope *Scope) error {
+	return h.each(func(to Handler) error {
+		if sh, ok := to.(ScopeHandler); ok {
+			return sh.Scope(scope)
+		}
+		return nil
+	})
+}
+
+// Incomplete hands incomplete to the handlers that are IncompleteHandlers.
+func (h *MultiHandler) Incomplete(incomplete *Incomplete) error {
+	return h.each(func(to Handler) error {
+		if ih, ok := to.(IncompleteHandler); ok {
+			return ih.Incomplete(incomplete)
+		}
+		return nil
+	})
+}
+
+// Timing hands timing to the handlers that are TimingHandlers.
+func (h *MultiHandler) Timing(timing *Timing) error {
+	return h.each(func(to Handler) error {
+		if th, ok := to.(TimingHandler); ok {
+			return th.Timing(timing)
+		}
+		return nil
+	})
+}
+
+// Flush flushes the handlers that have a Flush method, such as buffered
+// JSON handlers and the text output of govulncheck. All of them are
+// flushed even if some fail.
+func (h *MultiHandler) Flush() error {
+	return h.each(func(to Handler) error {
+		if fh, ok := to.(interface{ Flush() error }); ok {
+			return fh.Flush()
+		}
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package govulncheck_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// failing is a Handler whose Finding and Flush methods fail with err.
+type failing struct {
+	textOnly
+	err     error
+	flushed bool
+}
+
+func (h *failing) Finding(f *govulncheck.Finding) error {
+	h.textOnly.Finding(f)
+	return h.err
+}
+
+func (h *failing) Flush() error {
+	h.flushed = true
+	return h.err
+}
+
+func TestMultiHandler(t *testing.T) {
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	rec := &govulncheck.Recorder{}
+	text := &textOnly{}
+	a := &failing{err: errA}
+	b := &failing{err: errB}
+	h := govulncheck.NewMultiHandler(rec, text, a, b)
+
+	if err := h.Config(&govulncheck.Config{ScannerName: "govulncheck"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.OSV(&osv.Entry{ID: "GO-2021-0113"}); err != nil {
+		t.Fatal(err)
+	}
+	err := h.Finding(&govulncheck.Finding{OSV: "GO-2021-0113"})
+	var merr govulncheck.MultiError
+	if !errors.As(err, &merr) || len(merr) != 2 || !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("Finding: got error %v; want the errors of both failing handlers", err)
+	}
+	// A handler that failed keeps receiving messages.
+	if err := h.Progress(&govulncheck.Progress{Message: "done"}); err != nil {
+		t.Fatal(err)
+	}
+	// Optional messages are only handed to the handlers that handle them.
+	if err := h.Module(&govulncheck.Module{Path: "golang.org/x/text"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Flush(); !errors.Is(err, errA) || !errors.Is(err, errB) || !a.flushed || !b.flushed {
+		t.Errorf("Flush: got error %v; want all handlers flushed and both errors", err)
+	}
+
+	want := []string{"config govulncheck", "osv GO-2021-0113", "finding GO-2021-0113", "progress done"}
+	for _, got := range [][]string{text.msgs, a.msgs, b.msgs} {
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("messages mismatch (-want, +got):\n%s", diff)
+		}
+	}
+	if got, want := len(rec.Messages), 5; got != want {
+		t.Errorf("got %d recorded messages; want %d", got, want)
+	}
+	if rec.Messages[4].Module == nil {
+		t.Errorf("got last recorded message %+v; want the module summary", rec.Messages[4])
+	}
+}
+
+func TestMultiHandlerErrors(t *testing.T) {
+	errA := errors.New("a failed")
+	// The same error of several handlers, including of nested
+	// MultiHandlers, is returned once, as is.
+	inner := govulncheck.NewMultiHandler(&failing{err: errA}, &failing{err: errors.New("other")})
+	h := govulncheck.NewMultiHandler(&failing{err: errA}, inner)
+	err := h.Flush()
+	merr, ok := err.(govulncheck.MultiError)
+	if !ok || len(merr) != 2 || merr[0] != errA {
+		t.Errorf("got error %#v; want a flattened MultiError of two errors", err)
+	}
+	h = govulncheck.NewMultiHandler(&failing{err: errA}, &failing{err: errA})
+	if err := h.Flush(); err != errA {
+		t.Errorf("got error %v; want %v", err, errA)
+	}
+}
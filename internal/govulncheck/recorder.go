@@ -0,0 +1,99 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package govulncheck
+
+import (
+	"io"
+
+	"golang.org/x/vuln/internal/osv"
+)
+
+// A Recorder is a Handler that records the messages of a scan, in order,
+// so that they can be replayed later to other handlers. Recordings are
+// serialized as the JSON output of govulncheck, which allows scanning on
+// one machine and producing reports on another without scanning again.
+//
+// The zero value is an empty recording ready to use.
+type Recorder struct {
+	Messages []*Message
+}
+
+// ReadRecording reads a recording from r, which is expected to be the
+// JSON output of govulncheck.
+func ReadRecording(r io.Reader) (*Recorder, error) {
+	rec := &Recorder{}
+	if err := HandleJSON(r, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// WriteJSON writes the recording to w as the JSON output of govulncheck.
+func (r *Recorder) WriteJSON(w io.Writer) error {
+	return r.Replay(NewJSONHandler(w))
+}
+
+// Replay hands the recorded messages to the handler, in order. Module
+// summaries, build information, reasons why the scan is incomplete, and
+// timings are only handed to handlers that implement the corresponding
+// optional interfaces.
+func (r *Recorder) Replay(to Handler) error {
+	for _, msg := range r.Messages {
+		if err := handleMessage(msg, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Config records the config block.
+func (r *Recorder) Config(config *Config) error {
+	return r.record(&Message{Config: config})
+}
+
+// Progress records a progress message.
+func (r *Recorder) Progress(progress *Progress) error {
+	return r.record(&Message{Progress: progress})
+}
+
+// OSV records an osv entry.
+func (r *Recorder) OSV(entry *osv.Entry) error {
+	return r.record(&Message{OSV: entry})
+}
+
+// Finding records a finding.
+func (r *Recorder) Finding(finding *Finding) error {
+	return r.record(&Message{Finding: finding})
+}
+
+// Module records a module summary.
+func (r *Recorder) Module(module *Module) error {
+	return r.record(&Message{Module: module})
+}
+
+// Build records build information.
+func (r *Recorder) Build(build *Build) error {
+	return r.record(&Message{Build: build})
+}
+
+// Scope records the scope of the scan.
+func (r *Recorder) Scope(scope *Scope) error {
+	return r.record(&Message{Scope: scope})
+}
+
+// Incomplete records the reasons why the scan is incomplete.
+func (r *Recorder) Incomplete(incomplete *Incomplete) error {
+	return r.record(&Message{Incomplete: incomplete})
+}
+
+// Timing records the timing of the scan.
+func (r *Recorder) Timing(timing *Timing) error {
+	return r.record(&Message{Timing: timing})
+}
+
+func (r *Recorder) record(msg *Message) error {
+	r.Messages = append(r.Messages, msg)
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package govulncheck_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// textOnly is a Handler that does not implement the optional handler
+// interfaces.
+type textOnly struct {
+	msgs []string
+}
+
+func (h *textOnly) Config(c *govulncheck.Config) error {
+	h.msgs = append(h.msgs, "config "+c.ScannerName)
+	return nil
+}
+
+func (h *textOnly) Progress(p *govulncheck.Progress) error {
+	h.msgs = append(h.msgs, "progress "+p.Message)
+	return nil
+}
+
+func (h *textOnly) OSV(e *osv.Entry) error {
+	h.msgs = append(h.msgs, "osv "+e.ID)
+	return nil
+}
+
+func (h *textOnly) Finding(f *govulncheck.Finding) error {
+	h.msgs = append(h.msgs, "finding "+f.OSV)
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	rec := &govulncheck.Recorder{}
+	rec.Config(&govulncheck.Config{ProtocolVersion: govulncheck.ProtocolVersion, ScannerName: "govulncheck"})
+	rec.Progress(&govulncheck.Progress{Message: "Scanning..."})
+	rec.Build(&govulncheck.Build{GoVersion: "go1.20.4", Path: "golang.org/main"})
+	rec.OSV(&osv.Entry{ID: "GO-2021-0113"})
+	rec.Finding(&govulncheck.Finding{OSV: "GO-2021-0113", Trace: []*govulncheck.Frame{{Module: "golang.org/x/text", Version: "v0.3.0"}}})
+	rec.Module(&govulncheck.Module{Path: "golang.org/x/text", Version: "v0.3.0"})
+	rec.Incomplete(&govulncheck.Incomplete{Reasons: []*govulncheck.IncompleteReason{{Kind: govulncheck.IncompleteAnalysisFailed, Message: "failed"}}})
+
+	var buf bytes.Buffer
+	if err := rec.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := govulncheck.ReadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(rec.Messages, got.Messages); diff != "" {
+		t.Errorf("round trip mismatch (-want, +got):\n%s", diff)
+	}
+
+	h := &textOnly{}
+	if err := got.Replay(h); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"config govulncheck", "progress Scanning...", "osv GO-2021-0113", "finding GO-2021-0113"}
+	if diff := cmp.Diff(want, h.msgs); diff != "" {
+		t.Errorf("replay mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -6,6 +6,10 @@
 package govulncheck
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
 	"time"
 
 	"golang.org/x/vuln/internal/osv"
@@ -19,10 +23,15 @@ const (
 // Message is an entry in the output stream. It will always have exactly one
 // field filled in.
 type Message struct {
-	Config   *Config    `json:"config,omitempty"`
-	Progress *Progress  `json:"progress,omitempty"`
-	OSV      *osv.Entry `json:"osv,omitempty"`
-	Finding  *Finding   `json:"finding,omitempty"`
+	Config     *Config     `json:"config,omitempty"`
+	Progress   *Progress   `json:"progress,omitempty"`
+	OSV        *osv.Entry  `json:"osv,omitempty"`
+	Finding    *Finding    `json:"finding,omitempty"`
+	Module     *Module     `json:"module,omitempty"`
+	Build      *Build      `json:"build,omitempty"`
+	Scope      *Scope      `json:"scope,omitempty"`
+	Incomplete *Incomplete `json:"incomplete,omitempty"`
+	Timing     *Timing     `json:"timing,omitempty"`
 }
 
 type Config struct {
@@ -49,9 +58,86 @@ type Config struct {
 	// vulnerabilities.
 	GoVersion string `json:"go_version,omitempty"`
 
+	// GoVersions, if not empty, are the Go versions, as in "go1.21.5",
+	// against which standard library vulnerabilities are matched instead
+	// of GoVersion, as set by the -go-versions flag. A vulnerability of
+	// the standard library is reported if it affects any of them.
+	GoVersions []string `json:"go_versions,omitempty"`
+
 	// ScanLevel instructs vulncheck to analyze at a specific level of detail.
 	// Valid values include module, package and symbol.
 	ScanLevel ScanLevel `json:"scan_level,omitempty"`
+
+	// ModuleDepth, if positive, limits the scan to modules at most this
+	// many module dependencies away from the main module. For example,
+	// 1 limits the scan to the main module and its direct dependencies.
+	// Vulnerabilities in deeper modules are not reported.
+	ModuleDepth int `json:"module_depth,omitempty"`
+
+	// OSVs, if not empty, limits the scan to the vulnerabilities with
+	// these IDs or aliases, such as CVE IDs. Other vulnerabilities are
+	// neither analyzed nor reported.
+	OSVs []string `json:"osvs,omitempty"`
+
+	// Representative is the strategy picking the representative call
+	// stack of each called finding, set by the -representative flag, one
+	// of "first", "shortest", "static", and "main". It is empty for the
+	// default strategy, "first".
+	Representative string `json:"representative,omitempty"`
+
+	// Repository describes the version control revision of the scanned
+	// code, as provided by the -repo-url, -repo-commit, and -repo-branch
+	// flags, so that findings can be traced back to the exact source.
+	// Govulncheck does not inspect it. It is nil if none is provided.
+	Repository *Repository `json:"repository,omitempty"`
+
+	// GOOS and GOARCH are the platform against which the platforms
+	// affected by vulnerabilities are matched in source mode, which the
+	// -match-platform flag sets to the platform that the scanned packages
+	// are loaded for. They are empty by default, in which case
+	// vulnerabilities of all platforms apply. In binary mode, the platform
+	// of the binary is used instead.
+	GOOS   string `json:"-"`
+	GOARCH string `json:"-"`
+
+	// Sequential, set by the -sequential flag, makes the analysis of
+	// source mode run on a single goroutine and process the
+	// vulnerabilities in the order of their IDs, so that its timings
+	// and orderings are reproducible. The findings are the same.
+	Sequential bool `json:"-"`
+
+	// DispatchTables, set by the -dispatch-tables flag, makes source mode
+	// assume that the function values stored in maps, slices, and arrays,
+	// such as the handlers of a dispatch table, are called by the dynamic
+	// calls of function values of the same signature, even when the call
+	// graph analysis cannot tell. Findings reached this way are labeled
+	// as assumed, as they are less certain.
+	DispatchTables bool `json:"dispatch_tables,omitempty"`
+
+	// AllEntryFunctions, set by the -entry-functions flag, makes source
+	// mode build the call graph even if no vulnerable package is imported,
+	// and report all of its entry functions, not only the ones that reach
+	// vulnerable symbols.
+	AllEntryFunctions bool `json:"-"`
+
+	// EntrySymbols, set by the -entry-symbols flag, are the functions of
+	// the binary that binary mode scopes reachability to: the vulnerable
+	// symbols of the binary that they do not reach through direct calls,
+	// closures, or inlining are reported as imported rather than called.
+	EntrySymbols []string `json:"entry_symbols,omitempty"`
+}
+
+// Repository is the version control revision of the scanned code.
+type Repository struct {
+	// URL is the URL of the repository, such as
+	// "https://github.com/golang/vuln".
+	URL string `json:"url,omitempty"`
+
+	// Commit is the commit of the scanned code, such as a commit SHA.
+	Commit string `json:"commit,omitempty"`
+
+	// Branch is the branch of the commit.
+	Branch string `json:"branch,omitempty"`
 }
 
 type Progress struct {
@@ -67,6 +153,13 @@ type Finding struct {
 	// OSV is the id of the detected vulnerability.
 	OSV string `json:"osv,omitempty"`
 
+	// Hash identifies the finding across scans. It is computed by
+	// IdentityHash and is stable across tool versions, module versions,
+	// and source positions. Output formats that match results across
+	// runs, such as the partialFingerprints of SARIF, use it as the
+	// fingerprint of the finding.
+	Hash string `json:"hash,omitempty"`
+
 	// FixedVersion is the module version where the vulnerability was
 	// fixed. This is empty if a fix is not available.
 	//
@@ -83,11 +176,79 @@ type Finding struct {
 	// fixed version.
 	FixedVersion string `json:"fixed_version,omitempty"`
 
+	// FixStatus tells whether the vulnerability is fixed in a released
+	// version, which is then FixedVersion, is not fixed yet, or whether
+	// this could not be determined. An empty FixedVersion is ambiguous
+	// without it.
+	FixStatus FixStatus `json:"fix_status,omitempty"`
+
+	// Ownership tells whether the vulnerable module is owned by the
+	// organization running the scan, as configured by the -internal
+	// flag, or is a third-party module. It is empty if no internal
+	// modules are configured.
+	Ownership Ownership `json:"ownership,omitempty"`
+
+	// AffectedRange is the affected version range of the OSV report that
+	// contains the version of the vulnerable module in the build graph.
+	//
+	// It is nil when the module version is unknown or the OSV report has
+	// no semver range containing it, for instance when the report only
+	// lists GIT ranges.
+	AffectedRange *AffectedRange `json:"affected_range,omitempty"`
+
+	// Severity is the CVSS v3 vector of the OSV entry with the highest
+	// base score. It is empty if the entry has no CVSS v3 severity.
+	Severity string `json:"severity,omitempty"`
+
+	// OrgSeverity is the severity of the finding in the scheme of the
+	// organization running the scan, mapped from Severity and the
+	// vulnerable module by the -severity-map flag. It is empty if no
+	// mapping is configured or the mapping assigns no severity.
+	OrgSeverity string `json:"org_severity,omitempty"`
+
+	// CWEs are the sorted CWE IDs of the weaknesses of the OSV entry, as
+	// in "CWE-22", so that findings can be grouped by weakness category.
+	// They are empty if the entry has no CWE data.
+	CWEs []string `json:"cwes,omitempty"`
+
+	// ReplacedBy is the module path, followed by "@" and the version if
+	// any, of the module that replaces the vulnerable module when the
+	// replacement has a different path, such as a patched fork or a local
+	// directory. The finding is based on the version of the original
+	// module, so the vulnerability is possibly mitigated by the
+	// replacement.
+	ReplacedBy string `json:"replaced_by,omitempty"`
+
+	// RequiredVersion is the version of the vulnerable module required by
+	// the go.mod file of the main module when it is lower than the version
+	// selected for the build, which the finding is based on. The selected
+	// version, in the first frame of Trace, is raised by the requirements
+	// of other modules.
+	RequiredVersion string `json:"required_version,omitempty"`
+
+	// FirstSeen is the date, as in "2023-05-01", of the scan that first
+	// recorded the finding in the baseline file, which tells how long the
+	// vulnerability has been present. It is only set with the -first-seen
+	// flag, and is empty for findings that are not recorded yet.
+	FirstSeen string `json:"first_seen,omitempty"`
+
+	// GoVersions are the Go versions among those of Config.GoVersions
+	// whose standard library is affected by the vulnerability, which
+	// tells which toolchains need patching. It is only set for findings
+	// in the standard library, when Config.GoVersions is not empty.
+	GoVersions []string `json:"go_versions,omitempty"`
+
+	// Tool is the build-time tool in which the vulnerable package is
+	// imported, when the finding is in a tool dependency of the scanned
+	// module rather than in the scanned code. Such findings are reported
+	// by the -tools flag, and are never called.
+	Tool *Tool `json:"tool,omitempty"`
+
 	// Trace contains an entry for each frame in the trace.
 	//
-	// Frames are sorted starting from the imported vulnerable symbol
-	// until the entry point. The first frame in Frames should match
-	// Symbol.
+	// Frames are sorted as told by TraceOrder. By default, they start
+	// from the imported vulnerable symbol and end at the entry point.
+	// The first frame in Frames should match Symbol.
 	//
 	// In binary mode, trace will contain a single-frame with no position
 	// information.
@@ -95,8 +256,471 @@ type Finding struct {
 	// When a package is imported but no vulnerable symbol is called, the trace
 	// will contain a single-frame with no symbol or position information.
 	Trace []*Frame `json:"trace,omitempty"`
+
+	// TraceOrder is the order of the frames of Trace. It is set when the
+	// order is requested by the -trace-order flag. An empty TraceOrder
+	// means TraceSinkFirst.
+	TraceOrder TraceOrder `json:"trace_order,omitempty"`
+
+	// Through lists the IDs of other OSV entries whose vulnerable symbols
+	// appear as intermediate frames of Trace. Removing the call that leads
+	// to such a frame resolves both this finding and the one for the
+	// listed OSV.
+	//
+	// Through is empty in binary mode and when no other vulnerable
+	// symbol is on the trace.
+	Through []string `json:"through,omitempty"`
+
+	// AssumedCalled reports whether the vulnerability is imported but not
+	// called, and is nonetheless treated as called because its module
+	// matches the -assume-called flag. This is a policy decision rather
+	// than a result of the analysis: Trace has no function, as no call
+	// stack was found.
+	AssumedCalled bool `json:"assumed_called,omitempty"`
+
+	// TestOnly reports whether Trace passes through test code, such as a
+	// function declared in a _test.go file or in a test helper package
+	// designated by the -test-helpers flag, so that the vulnerable symbol
+	// is only called when running tests.
+	//
+	// TestOnly is always false in binary mode, and when neither test files
+	// nor test helpers are analyzed.
+	TestOnly bool `json:"test_only,omitempty"`
+
+	// EntryAPI tells whether Trace enters the vulnerable module, the
+	// module of Trace[0], through its exported API or through internal
+	// code, such as an unexported method reached through an interface or
+	// a function of an internal package. The frame it applies to is the
+	// first frame of the module counted from the entry point. Only in the
+	// first case can changing how the code calls the module plausibly
+	// avoid the vulnerability; otherwise upgrading is the remedy.
+	//
+	// EntryAPI is empty in binary mode and for imported vulnerabilities.
+	EntryAPI EntryAPI `json:"entry_api,omitempty"`
+
+	// TestDependency reports whether the vulnerable module, the module of
+	// Trace[0], is only a dependency of tests: it is only part of the
+	// build through the test variants of the scanned packages, as for a
+	// mocking library or test fixtures imported from _test.go files. The
+	// program itself does not depend on the module.
+	//
+	// TestDependency is always false in binary mode, and when test files
+	// are not analyzed.
+	TestDependency bool `json:"test_dependency,omitempty"`
+
+	// LikelyFalsePositive reports whether all call stacks found to the
+	// vulnerable symbol go through more standard library functions than
+	// the threshold set by the -confidence flag. Such call stacks are
+	// often not realizable, so the finding does not cause a failure.
+	//
+	// LikelyFalsePositive is always false in binary mode and when no
+	// threshold is set.
+	LikelyFalsePositive bool `json:"likely_false_positive,omitempty"`
+
+	// DependencyPath is the chain of modules through which the scanned
+	// code depends on the vulnerable module, from a main module to the
+	// module of Trace[0], following package imports across the fewest
+	// modules. The module after the main module is the direct dependency
+	// to upgrade or remove, and the module before the vulnerable one the
+	// module whose upgrade may drop the vulnerable dependency.
+	//
+	// DependencyPath is empty in binary mode and for findings in tools.
+	DependencyPath []*ModuleVersion `json:"dependency_path,omitempty"`
+
+	// ImportedBy lists the packages of the main module that directly
+	// import the vulnerable package, for vulnerabilities that are imported
+	// but not called. Removing those imports removes the vulnerability.
+	//
+	// ImportedBy is empty in binary mode and for called vulnerabilities.
+	ImportedBy []string `json:"imported_by,omitempty"`
+
+	// CallStacks is the number of distinct call stacks found from the
+	// entry points of the code to the vulnerable symbol, of which Trace
+	// is the representative. Symbols reached in many ways tend to be
+	// harder to stop using. Not all call stacks are analyzed, so this is
+	// a lower bound.
+	//
+	// CallStacks is 0 in binary mode and for imported vulnerabilities.
+	CallStacks int `json:"call_stacks,omitempty"`
+
+	// Merged is the number of findings that this finding stands for when
+	// findings that differ only by the positions of their traces are
+	// merged, as requested by the -merge flag. Trace is the trace of the
+	// first of them. Merged is 0 if no finding was merged into this one.
+	Merged int `json:"merged,omitempty"`
+
+	// Definition is the position of the declaration of the vulnerable
+	// symbol, the function of Trace[0], in the source of its module. It
+	// shows reviewers the vulnerable code itself.
+	//
+	// Definition is nil in binary mode and for imported vulnerabilities.
+	Definition *Position `json:"definition,omitempty"`
+
+	// Reproduce is a govulncheck command that reproduces only this
+	// finding, by scanning the package of the entry point of Trace, or
+	// the packages of ImportedBy, for the OSV entry. It lets developers
+	// verify the finding with a narrow scan.
+	//
+	// Reproduce is empty in binary mode.
+	Reproduce string `json:"reproduce,omitempty"`
+
+	// Repository is the version control revision of the scanned code, the
+	// same as in the Config message. It is only set with the
+	// -repo-findings flag, for consumers that store findings separately.
+	Repository *Repository `json:"repository,omitempty"`
+}
+
+// IdentityHash returns the hash that identifies f across scans.
+//
+// The hash is the lowercase hexadecimal SHA-256 digest of the following
+// fields, each followed by a zero byte, in order: the OSV ID, and the
+// Module, Package, Receiver, and Function of the frame of the trace that
+// is the vulnerable symbol, whatever the TraceOrder, followed by the
+// package of the Tool for findings in build-time tools. Fields that are
+// not set are empty strings. Versions and positions are deliberately
+// excluded, so the hash of a finding does not change when unrelated code
+// moves or the vulnerable module is upgraded to another affected version.
+func (f *Finding) IdentityHash() string {
+	fields := []string{f.OSV, "", "", "", ""}
+	if len(f.Trace) > 0 {
+		fr := f.Trace[0]
+		if f.TraceOrder == TraceEntryFirst {
+			fr = f.Trace[len(f.Trace)-1]
+		}
+		if fr != nil {
+			fields = []string{f.OSV, fr.Module, fr.Package, fr.Receiver, fr.Function}
+		}
+	}
+	if f.Tool != nil {
+		fields = append(fields, f.Tool.Package)
+	}
+	h := sha256.New()
+	for _, field := range fields {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Tool is a build-time tool of the scanned module, which runs when
+// building the module but is not part of the built program.
+type Tool struct {
+	// Package is the import path of the main package of the tool.
+	Package string `json:"package"`
+
+	// Directive is the kind of directive referencing the tool, one of
+	// ToolDirective and GenerateDirective.
+	Directive string `json:"directive"`
+
+	// Position is the position of the directive referencing the tool.
+	Position *Position `json:"position,omitempty"`
+}
+
+const (
+	// ToolDirective is a tool directive of a go.mod file.
+	ToolDirective = "tool"
+
+	// GenerateDirective is a go:generate directive of a Go file that
+	// runs the tool with go run.
+	GenerateDirective = "go:generate"
+)
+
+// TraceOrder is the order of the frames of the trace of a finding.
+type TraceOrder string
+
+const (
+	// TraceSinkFirst means that the trace starts from the vulnerable
+	// symbol and ends at the entry point that calls it. It is the order
+	// in which govulncheck reports traces by default.
+	TraceSinkFirst TraceOrder = "sink_first"
+
+	// TraceEntryFirst means that the trace starts from the entry point
+	// and ends at the vulnerable symbol, in the order of the calls.
+	TraceEntryFirst TraceOrder = "entry_first"
+)
+
+// OrderTrace sorts the frames of the trace of f in order, and records it
+// in f.TraceOrder. The frames are copied to a new slice if they need to
+// be reversed, so that f.Trace can be shared with other findings.
+func (f *Finding) OrderTrace(order TraceOrder) {
+	current := f.TraceOrder
+	if current == "" {
+		current = TraceSinkFirst
+	}
+	if current != order {
+		trace := make([]*Frame, len(f.Trace))
+		for i, fr := range f.Trace {
+			trace[len(trace)-1-i] = fr
+		}
+		f.Trace = trace
+	}
+	f.TraceOrder = order
+}
+
+// FixStatus is the status of the fix of the vulnerability of a finding.
+type FixStatus string
+
+const (
+	// FixStatusFixed means that a released module version fixes the
+	// vulnerability.
+	FixStatusFixed FixStatus = "fixed"
+
+	// FixStatusNotFixed means that the OSV report lists affected
+	// versions of the module, but no version fixes the vulnerability,
+	// for instance because no fix has been released yet.
+	FixStatusNotFixed FixStatus = "not_fixed"
+
+	// FixStatusUnknown means that the fix could not be determined from
+	// the OSV report, for instance because the report has no semver
+	// range for the module.
+	FixStatusUnknown FixStatus = "unknown"
+)
+
+// Ownership is the ownership of the vulnerable module of a finding.
+type Ownership string
+
+const (
+	// OwnershipInternal means that the module matches one of the
+	// configured internal module path patterns.
+	OwnershipInternal Ownership = "internal"
+
+	// OwnershipExternal means that the module is a third-party module,
+	// which includes the standard library.
+	OwnershipExternal Ownership = "external"
+)
+
+// EntryAPI is the kind of API through which the trace of a finding enters
+// the vulnerable module.
+type EntryAPI string
+
+const (
+	// EntryAPIExported means that the first frame of the vulnerable
+	// module is an exported function, or a method of an exported type,
+	// of a package that is not internal.
+	EntryAPIExported EntryAPI = "exported"
+
+	// EntryAPIInternal means that the first frame of the vulnerable
+	// module is unexported, or is in an internal package of the module.
+	EntryAPIInternal EntryAPI = "internal"
+)
+
+// ModuleVersion is a module at a version, as in a DependencyPath.
+type ModuleVersion struct {
+	// Path is the module path. See Frame.Module.
+	Path string `json:"path"`
+
+	// Version is the module version from the build graph. It is empty
+	// for main modules.
+	Version string `json:"version,omitempty"`
+}
+
+// AffectedRange is a range of module versions affected by a vulnerability.
+type AffectedRange struct {
+	// Introduced is the module version where the vulnerability was
+	// introduced. It is empty if all versions before Fixed are affected.
+	Introduced string `json:"introduced,omitempty"`
+
+	// Fixed is the module version where the vulnerability was fixed. It
+	// is empty if no version after Introduced is known to be fixed.
+	Fixed string `json:"fixed,omitempty"`
+}
+
+// Module summarizes the findings of a scan for a single module. Module
+// messages follow all findings, one for each module with findings.
+type Module struct {
+	// Path is the module path. See Frame.Module.
+	Path string `json:"path"`
+
+	// Version is the module version from the build graph.
+	Version string `json:"version,omitempty"`
+
+	// Called is the number of vulnerabilities of the module whose
+	// vulnerable symbols are called.
+	Called int `json:"called_count"`
+
+	// Imported is the number of vulnerabilities of the module that are
+	// imported or required, but whose vulnerable symbols are not called.
+	Imported int `json:"imported_count"`
+
+	// WorstSeverity is the CVSS v3 vector with the highest base score of
+	// the OSV entries of the vulnerabilities of the module. See
+	// Finding.Severity. It is empty if none of them has a CVSS v3
+	// severity.
+	WorstSeverity string `json:"worst_severity,omitempty"`
+
+	// RecommendedVersion is the lowest module version that fixes all of
+	// the vulnerabilities of the module that have a fix. It is empty if
+	// no vulnerability of the module has a fix.
+	RecommendedVersion string `json:"recommended_version,omitempty"`
+
+	// ExportedSymbols is the number of exported functions and methods
+	// of the packages of the module that are part of the build, and
+	// UsedSymbols is how many of them the main module references. They
+	// measure how much of the module API an upgrade of the module may
+	// affect. Both are only set when requested, in source mode.
+	UsedSymbols     int `json:"used_symbols,omitempty"`
+	ExportedSymbols int `json:"exported_symbols,omitempty"`
+}
+
+// Build describes how the binary scanned in binary mode was built, as
+// recorded in its build information. A single Build message follows the
+// progress messages in binary mode. It is reported whether or not the
+// binary has vulnerabilities, so that binaries built with outdated Go
+// toolchains can be found.
+type Build struct {
+	// GoVersion is the version of the Go toolchain that built the
+	// binary, such as "go1.20.4".
+	GoVersion string `json:"go_version"`
+
+	// Path is the package path of the main package of the binary.
+	Path string `json:"path,omitempty"`
+
+	// Module and Version are the path and version of the main module.
+	Module  string `json:"module,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	// Settings are the build settings, such as CGO_ENABLED, GOOS, or
+	// -ldflags, in the order they are recorded in the binary.
+	Settings []*BuildSetting `json:"settings,omitempty"`
+}
+
+// BuildSetting is a key-value pair of the build settings of a binary.
+type BuildSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Scope records what a source scan analyzed, so that the coverage of a
+// scan can be checked after the fact. A single Scope message follows the
+// findings and module summaries of a source scan, before the Incomplete
+// message of an incomplete scan.
+type Scope struct {
+	// Packages is the number of packages loaded for the patterns of the
+	// scan, including test packages if Tests is set.
+	Packages int `json:"packages"`
+
+	// Dependencies is the number of other packages that these packages
+	// depend on, including those of the standard library.
+	Dependencies int `json:"dependencies"`
+
+	// Modules is the number of modules of the dependencies, not counting
+	// the standard library.
+	Modules int `json:"modules"`
+
+	// Tests tells whether test packages were scanned.
+	Tests bool `json:"tests,omitempty"`
+}
+
+// Incomplete tells that a scan may have missed vulnerabilities, and why.
+// A single Incomplete message follows the findings and module summaries
+// of an incomplete scan. The output of a scan without it is complete:
+// the absence of findings then means that no vulnerability affects the
+// code, as far as the vulnerability database knows.
+type Incomplete struct {
+	// Reasons are the reasons why the scan is incomplete. There is at
+	// least one.
+	Reasons []*IncompleteReason `json:"reasons"`
 }
 
+// IncompleteReason is a reason why a scan is incomplete.
+type IncompleteReason struct {
+	// Kind is the kind of the reason.
+	Kind IncompleteKind `json:"kind"`
+
+	// Package is the path of the package that could not be analyzed,
+	// for IncompleteAnalysisFailed.
+	Package string `json:"package,omitempty"`
+
+	// Modules are the paths of the modules that were not scanned, for
+	// IncompleteModuleDepth.
+	Modules []string `json:"modules,omitempty"`
+
+	// Message describes the reason for humans.
+	Message string `json:"message"`
+}
+
+// IncompleteKind is the kind of a reason why a scan is incomplete.
+type IncompleteKind string
+
+const (
+	// IncompleteAnalysisFailed means that the analysis of a package
+	// failed, so that the vulnerabilities reachable through the code of
+	// the package may not be reported.
+	IncompleteAnalysisFailed IncompleteKind = "analysis_failed"
+
+	// IncompleteModuleDepth means that modules further away from the
+	// main module than Config.ModuleDepth were not scanned.
+	IncompleteModuleDepth IncompleteKind = "module_depth"
+
+	// IncompleteGoSum means that go.sum is missing entries for, or does
+	// not match, some modules of the build, so that the packages they
+	// provide were not loaded and their vulnerabilities are not reported.
+	IncompleteGoSum IncompleteKind = "go_sum"
+
+	// IncompleteTimeout means that the -timeout of the scan expired, so
+	// that the vulnerabilities whose call stacks were not searched yet
+	// are only reported as imported, and the later phases of the scan
+	// were skipped.
+	IncompleteTimeout IncompleteKind = "timeout"
+)
+
+// Timing reports where the time of a scan was spent, when requested by
+// the -timing flag. A single Timing message ends the output of the scan.
+type Timing struct {
+	// Phases are the durations of the phases of the scan, in the order
+	// they started. Some phases run concurrently, such as building the
+	// call graph and fetching vulnerabilities, so their durations may
+	// add up to more than the duration of the scan.
+	Phases []*PhaseTiming `json:"phases"`
+
+	// Packages are the times spent searching for the call stacks of the
+	// vulnerable symbols of each package, from the longest to the
+	// shortest. The searches run concurrently.
+	Packages []*PackageTiming `json:"packages,omitempty"`
+}
+
+// PhaseTiming is the duration of a phase of a scan.
+type PhaseTiming struct {
+	// Phase is the phase of the scan.
+	Phase Phase `json:"phase"`
+
+	// Seconds is the duration of the phase in seconds.
+	Seconds float64 `json:"seconds"`
+}
+
+// PackageTiming is the time spent searching for the call stacks of the
+// vulnerable symbols of a package.
+type PackageTiming struct {
+	// Package is the path of the vulnerable package.
+	Package string `json:"package"`
+
+	// Seconds is the search time in seconds.
+	Seconds float64 `json:"seconds"`
+}
+
+// Phase is a phase of a scan.
+type Phase string
+
+const (
+	// PhaseLoad is the loading and type checking of packages.
+	PhaseLoad Phase = "load"
+
+	// PhaseFetch is the fetching of vulnerabilities from the database.
+	PhaseFetch Phase = "fetch"
+
+	// PhaseCallGraph is the building of the SSA program and of the call
+	// graph.
+	PhaseCallGraph Phase = "callgraph"
+
+	// PhaseMatch is the matching of vulnerabilities against the imported
+	// packages and the call graph.
+	PhaseMatch Phase = "match"
+
+	// PhaseCallStacks is the search for the call stacks of vulnerable
+	// symbols.
+	PhaseCallStacks Phase = "callstacks"
+)
+
 // Frame represents an entry in a finding trace.
 type Frame struct {
 	// Module is the module path of the module containing this symbol.
@@ -123,6 +747,80 @@ type Frame struct {
 	// including the file, line, and column location.
 	// A Position is valid if the line number is > 0.
 	Position *Position `json:"position,omitempty"`
+
+	// Inlined is set if the function was inlined into its caller by
+	// the compiler. The position is then the logical source position of
+	// the call to the function, not of the code inlined into the caller.
+	// It is only set in binary mode.
+	Inlined bool `json:"inlined,omitempty"`
+
+	// Unresolved is set if the call to the previous frame of the trace,
+	// made at Position, cannot be statically resolved, such as a call
+	// through an interface or a function value. It is only set in source
+	// mode.
+	Unresolved bool `json:"unresolved,omitempty"`
+
+	// Candidates are the symbols, such as "pkg.T.Method", of the functions
+	// that the unresolved call made at Position may dispatch to, as
+	// determined by the call graph analysis. The function of the previous
+	// frame of the trace is one of them. It is only set in source mode.
+	Candidates []string `json:"candidates,omitempty"`
+
+	// Assumed is set if the call made at Position is only assumed to
+	// reach the function of the previous frame of the trace, which is
+	// stored in a map, slice, or array, as enabled by the -dispatch-tables
+	// flag. It is only set in source mode.
+	Assumed bool `json:"assumed,omitempty"`
+
+	// Callback is set if the call made at Position is of a C function
+	// that is only assumed to call back the function of the previous
+	// frame of the trace, which is exported to C, as the source of the C
+	// function is not part of the package. It is only set in source mode.
+	Callback bool `json:"callback,omitempty"`
+}
+
+// Symbol returns the qualified name of the function of f, of the form
+// "pkg.Recv.Func", or "pkg.Func" for functions without a receiver.
+// Pointer receivers are reported like value receivers, closures by
+// their enclosing function, and init functions as "init". Symbol
+// returns "" if f has no function.
+func (f *Frame) Symbol() string {
+	if f.Function == "" {
+		return ""
+	}
+	var b strings.Builder
+	if f.Package != "" {
+		b.WriteString(f.Package)
+		b.WriteString(".")
+	}
+	if f.Receiver != "" {
+		b.WriteString(strings.TrimPrefix(f.Receiver, "*"))
+		b.WriteString(".")
+	}
+	name := strings.Split(f.Function, "$")[0]
+	if strings.HasPrefix(name, "init#") {
+		name = "init"
+	}
+	b.WriteString(name)
+	return b.String()
+}
+
+// Location returns the symbol of f followed by its position, of the form
+// "pkg.Recv.Func at file:line:col". The position is omitted if it is
+// not valid. See Symbol.
+func (f *Frame) Location() string {
+	s := f.Symbol()
+	if s == "" {
+		s = f.Package
+	}
+	if p := f.Position; p != nil && p.Line > 0 {
+		pos := fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+		if s == "" {
+			return pos
+		}
+		return s + " at " + pos
+	}
+	return s
 }
 
 // Position is a copy of token.Position used to marshal/unmarshal
@@ -7,6 +7,9 @@ package govulncheck_test
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
+	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/test"
 )
 
@@ -15,3 +18,86 @@ func TestImports(t *testing.T) {
 		"golang.org/x/vuln/internal/osv", // allowed to pull in the osv json entries
 	)
 }
+
+func TestIdentityHash(t *testing.T) {
+	f := &govulncheck.Finding{
+		OSV: "GO-2021-0113",
+		Trace: []*govulncheck.Frame{
+			{Module: "golang.org/x/text", Version: "v0.3.0", Package: "golang.org/x/text/language", Function: "Parse"},
+			{Module: "golang.org/vuln", Package: "golang.org/vuln", Function: "main", Position: &govulncheck.Position{Line: 12}},
+		},
+	}
+	// The SHA-256 of "GO-2021-0113\x00golang.org/x/text\x00golang.org/x/text/language\x00\x00Parse\x00".
+	const want = "0742e4f5c1740da4c08d9d59582962fe2ce7882e710f470f843949e8525fb708"
+	got := f.IdentityHash()
+	if got != want {
+		t.Errorf("IdentityHash() = %s; want %s", got, want)
+	}
+
+	// Versions and positions do not affect the hash.
+	moved := &govulncheck.Finding{
+		OSV: f.OSV,
+		Trace: []*govulncheck.Frame{
+			{Module: "golang.org/x/text", Version: "v0.3.5", Package: "golang.org/x/text/language", Function: "Parse"},
+			{Module: "golang.org/vuln", Package: "golang.org/vuln", Function: "run", Position: &govulncheck.Position{Line: 40}},
+		},
+	}
+	if h := moved.IdentityHash(); h != got {
+		t.Errorf("IdentityHash() of moved finding = %s; want %s", h, got)
+	}
+
+	// Nor does the order of the trace.
+	moved.OrderTrace(govulncheck.TraceEntryFirst)
+	if h := moved.IdentityHash(); h != got {
+		t.Errorf("IdentityHash() of finding with the entry point first = %s; want %s", h, got)
+	}
+
+	other := &govulncheck.Finding{
+		OSV: f.OSV,
+		Trace: []*govulncheck.Frame{
+			{Module: "golang.org/x/text", Package: "golang.org/x/text/language", Function: "MustParse"},
+		},
+	}
+	if h := other.IdentityHash(); h == got {
+		t.Errorf("IdentityHash() of finding for another symbol = %s; want a different hash", h)
+	}
+}
+
+func TestFrameLocation(t *testing.T) {
+	pos := &govulncheck.Position{Filename: "a/b.go", Line: 3, Column: 5}
+	for _, test := range []struct {
+		frame *govulncheck.Frame
+		want  string
+	}{
+		{&govulncheck.Frame{Package: "golang.org/p", Function: "F", Position: pos}, "golang.org/p.F at a/b.go:3:5"},
+		{&govulncheck.Frame{Package: "golang.org/p", Receiver: "*T", Function: "M", Position: pos}, "golang.org/p.T.M at a/b.go:3:5"},
+		{&govulncheck.Frame{Package: "golang.org/p", Function: "F$1"}, "golang.org/p.F"},
+		{&govulncheck.Frame{Package: "golang.org/p", Function: "init#1", Position: &govulncheck.Position{}}, "golang.org/p.init"},
+		{&govulncheck.Frame{Module: "golang.org/m", Package: "golang.org/p"}, "golang.org/p"},
+		{&govulncheck.Frame{Position: pos}, "a/b.go:3:5"},
+	} {
+		if got := test.frame.Location(); got != test.want {
+			t.Errorf("Location() of %+v = %q; want %q", test.frame, got, test.want)
+		}
+	}
+}
+
+func TestOrderTrace(t *testing.T) {
+	sink := &govulncheck.Frame{Module: "golang.org/x/text", Package: "golang.org/x/text/language", Function: "Parse"}
+	entry := &govulncheck.Frame{Module: "golang.org/vuln", Package: "golang.org/vuln", Function: "main"}
+	f := &govulncheck.Finding{OSV: "GO-2021-0113", Trace: []*govulncheck.Frame{sink, entry}}
+	shared := f.Trace
+
+	f.OrderTrace(govulncheck.TraceEntryFirst)
+	if want := []*govulncheck.Frame{entry, sink}; !cmp.Equal(f.Trace, want) || f.TraceOrder != govulncheck.TraceEntryFirst {
+		t.Errorf("got trace %v in order %q; want the entry point first", f.Trace, f.TraceOrder)
+	}
+	if shared[0] != sink {
+		t.Errorf("OrderTrace modified the original trace")
+	}
+
+	f.OrderTrace(govulncheck.TraceSinkFirst)
+	if want := []*govulncheck.Frame{sink, entry}; !cmp.Equal(f.Trace, want) || f.TraceOrder != govulncheck.TraceSinkFirst {
+		t.Errorf("got trace %v in order %q; want the vulnerable symbol first", f.Trace, f.TraceOrder)
+	}
+}
@@ -119,6 +119,25 @@ const (
 	ReferenceTypeWeb = ReferenceType("WEB")
 )
 
+// Severity is the severity of a vulnerability according to a
+// quantitative scoring method.
+//
+// See https://ossf.github.io/osv-schema/#severity-field.
+type Severity struct {
+	// Type is the scoring method.
+	Type SeverityType `json:"type"`
+	// Score is the severity according to the scoring method, such as
+	// a CVSS vector string.
+	Score string `json:"score"`
+}
+
+// SeverityType is the method used to score a severity.
+type SeverityType string
+
+// SeverityTypeCVSSV3 is a CVSS v3 vector string, see
+// https://www.first.org/cvss/v3.1/specification-document.
+const SeverityTypeCVSSV3 SeverityType = "CVSS_V3"
+
 // Reference is a reference URL containing additional information,
 // advisories, issue tracker entries, etc., about the vulnerability.
 //
@@ -197,12 +216,19 @@ type Entry struct {
 	// Aliases is a list of IDs for the same vulnerability in other
 	// databases.
 	Aliases []string `json:"aliases,omitempty"`
+	// Related is a list of IDs of closely related vulnerabilities, such
+	// as the same problem in alternate ecosystems.
+	Related []string `json:"related,omitempty"`
 	// Summary gives a one-line, English textual summary of the vulnerability.
 	// It is recommended that this field be kept short, on the order of no more
 	// than 120 characters.
 	Summary string `json:"summary,omitempty"`
 	// Details contains additional English textual details about the vulnerability.
 	Details string `json:"details"`
+	// Severity contains the severities of the vulnerability, as scored
+	// by some quantitative method. The Go vulnerability database does
+	// not publish severities, but other databases may.
+	Severity []Severity `json:"severity,omitempty"`
 	// Affected contains information on the modules and versions
 	// affected by the vulnerability.
 	Affected []Affected `json:"affected"`
@@ -235,4 +261,9 @@ type DatabaseSpecific struct {
 	// The URL of the Go advisory for this vulnerability, of the form
 	// "https://pkg.go.dev/GO-YYYY-XXXX".
 	URL string `json:"url,omitempty"`
+
+	// The CWE IDs of the weaknesses of the vulnerability, of the form
+	// "CWE-XXX", as provided by databases such as the GitHub Advisory
+	// Database.
+	CWEIDs []string `json:"cwe_ids,omitempty"`
 }
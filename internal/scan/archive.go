@@ -0,0 +1,201 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extractSource extracts the source archive of the -archive flag of cfg
+// to a temporary directory, and returns the directory of the module in
+// it along with a function that removes the temporary directory. The
+// archive is read from stdin if the flag is "-".
+//
+// The module is in the directory named by the -archive-dir flag, if it
+// is set. Otherwise, it is at the root of the archive, unless the root
+// has no go.mod file and only holds a single directory, as is common
+// for snapshots of repositories, in which case it is in that directory.
+func extractSource(cfg *config, stdin io.Reader) (_ string, _ func(), err error) {
+	tmp, err := os.MkdirTemp("", "govulncheck-archive")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+	defer func() {
+		if err != nil {
+			cleanup()
+		}
+	}()
+
+	var r io.ReaderAt
+	var size int64
+	if cfg.archive == "-" {
+		// Archives are read at random, so that stdin is spooled first.
+		f, err := os.CreateTemp("", "govulncheck-archive")
+		if err != nil {
+			return "", nil, err
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		if size, err = io.Copy(f, stdin); err != nil {
+			return "", nil, fmt.Errorf("reading archive: %v", err)
+		}
+		r = f
+	} else {
+		f, err := os.Open(absPath(cfg.archive, filepath.FromSlash(cfg.dir)))
+		if err != nil {
+			return "", nil, fmt.Errorf("reading archive: %v", err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return "", nil, fmt.Errorf("reading archive: %v", err)
+		}
+		r, size = f, info.Size()
+	}
+	if err := extractArchive(r, size, tmp); err != nil {
+		return "", nil, fmt.Errorf("extracting archive %s: %v", cfg.archive, err)
+	}
+
+	root := tmp
+	if cfg.archiveDir != "" {
+		root = filepath.Join(tmp, filepath.FromSlash(cfg.archiveDir))
+	} else if _, err := os.Stat(filepath.Join(tmp, "go.mod")); err != nil {
+		if entries, err := os.ReadDir(tmp); err == nil && len(entries) == 1 && entries[0].IsDir() {
+			root = filepath.Join(tmp, entries[0].Name())
+		}
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return "", nil, fmt.Errorf("archive %s has no directory %s", cfg.archive, cfg.archiveDir)
+	}
+	return root, cleanup, nil
+}
+
+// extractArchive extracts the regular files and directories of the zip or
+// tar archive r of the given size to dir. Tar archives may be compressed
+// with gzip. Other kinds of entries, such as symbolic links, are skipped.
+// It is an error for an entry to be outside of dir.
+func extractArchive(r io.ReaderAt, size int64, dir string) error {
+	magic := make([]byte, 4)
+	if _, err := r.ReadAt(magic, 0); err != nil && err != io.EOF {
+		return err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")), bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		return extractZip(r, size, dir)
+	case bytes.HasPrefix(magic, []byte("\x1f\x8b")):
+		gr, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		return extractTar(gr, dir)
+	default:
+		return extractTar(io.NewSectionReader(r, 0, size), dir)
+	}
+}
+
+func extractZip(r io.ReaderAt, size int64, dir string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if !mode.IsDir() && !mode.IsRegular() {
+			continue
+		}
+		name, err := archivePath(dir, f.Name)
+		if err != nil {
+			return err
+		}
+		if mode.IsDir() {
+			if err := os.MkdirAll(name, 0777); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeDir && hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, err := archivePath(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			if err := os.MkdirAll(name, 0777); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeArchiveFile(name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// archivePath returns the path in dir of the archive entry name, which
+// is slash-separated and may start with "./". It errors if the entry is
+// not in dir.
+func archivePath(dir, name string) (string, error) {
+	rel := strings.TrimSuffix(strings.TrimPrefix(name, "./"), "/")
+	if rel == "" || rel == "." {
+		return dir, nil
+	}
+	if !fs.ValidPath(rel) || strings.Contains(rel, `\`) {
+		return "", fmt.Errorf("invalid archive entry %q", name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), nil
+}
+
+// writeArchiveFile writes the contents of r to the file name, creating
+// its directory if needed.
+func writeArchiveFile(name string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
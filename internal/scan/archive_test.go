@@ -0,0 +1,117 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractSource(t *testing.T) {
+	files := map[string]string{
+		"repo-1.0/go.mod":         "module example.com/repo\n",
+		"repo-1.0/main.go":        "package main\n",
+		"repo-1.0/sub/go.mod":     "module example.com/repo/sub\n",
+		"repo-1.0/sub/sub.go":     "package sub\n",
+		"./repo-1.0/doc/index.md": "docs\n",
+	}
+	for _, test := range []struct {
+		name       string
+		archive    []byte
+		archiveDir string
+		wantFile   string
+	}{
+		{"zip", zipArchive(t, files), "", "main.go"},
+		{"tar.gz", tarGzArchive(t, files), "", "main.go"},
+		{"subdir", zipArchive(t, files), "repo-1.0/sub", "sub.go"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config{archive: "-", archiveDir: test.archiveDir}
+			root, cleanup, err := extractSource(cfg, bytes.NewReader(test.archive))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cleanup()
+			if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+				t.Errorf("no go.mod in module root: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(root, test.wantFile)); err != nil {
+				t.Errorf("no %s in module root: %v", test.wantFile, err)
+			}
+			cleanup()
+			if _, err := os.Stat(root); !os.IsNotExist(err) {
+				t.Errorf("module root %s not removed by cleanup", root)
+			}
+		})
+	}
+}
+
+func TestExtractSourceErrors(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		archive    []byte
+		archiveDir string
+		want       string
+	}{
+		{"traversal", zipArchive(t, map[string]string{"../evil.go": "package evil\n"}), "", "invalid archive entry"},
+		{"absolute", tarGzArchive(t, map[string]string{"/evil.go": "package evil\n"}), "", "invalid archive entry"},
+		{"missing dir", zipArchive(t, map[string]string{"go.mod": "module m\n"}), "sub", "has no directory sub"},
+		{"not an archive", []byte("not an archive"), "", "extracting archive"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config{archive: "-", archiveDir: test.archiveDir}
+			_, _, err := extractSource(cfg, bytes.NewReader(test.archive))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// baseline is the set of findings accepted by a previous scan. It is
+// written by the -accept flag to the file named by the -baseline flag,
+// and read from that file by later scans, which then only report the
+// findings that are not in the baseline.
+type baseline struct {
+	Findings []*baselineFinding `json:"findings"`
+}
+
+// baselineFinding is an accepted finding. Findings are identified by
+// Hash, see govulncheck.Finding.IdentityHash. The other fields describe
+// the finding for humans reading the baseline file, except FirstSeen,
+// the date of the scan that first recorded the finding, as in
+// "2023-05-01". It is only set for findings recorded with the
+// -first-seen flag.
+type baselineFinding struct {
+	Hash      string `json:"hash"`
+	OSV       string `json:"osv"`
+	Module    string `json:"module,omitempty"`
+	Symbol    string `json:"symbol,omitempty"`
+	FirstSeen string `json:"first_seen,omitempty"`
+}
+
+// firstSeenLayout is the layout of the dates of first seen findings.
+const firstSeenLayout = "2006-01-02"
+
+// String describes the finding in progress messages.
+func (f *baselineFinding) String() string {
+	switch {
+	case f.Symbol != "":
+		return fmt.Sprintf("%s in %s", f.OSV, f.Symbol)
+	case f.Module != "":
+		return fmt.Sprintf("%s in %s", f.OSV, f.Module)
+	default:
+		return f.OSV
+	}
+}
+
+// newBaseline returns the baseline that accepts findings, which are
+// recorded with their first seen date, if any.
+func newBaseline(findings []*govulncheck.Finding) *baseline {
+	b := &baseline{Findings: []*baselineFinding{}}
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if seen[f.Hash] {
+			continue
+		}
+		seen[f.Hash] = true
+		bf := &baselineFinding{Hash: f.Hash, OSV: f.OSV, FirstSeen: f.FirstSeen}
+		if len(f.Trace) > 0 {
+			bf.Module = f.Trace[0].Module
+			bf.Symbol = symbol(f.Trace[0], false)
+			if bf.Symbol == "" {
+				bf.Symbol = f.Trace[0].Package
+			}
+		}
+		b.Findings = append(b.Findings, bf)
+	}
+	sort.SliceStable(b.Findings, func(i, j int) bool {
+		if b.Findings[i].OSV != b.Findings[j].OSV {
+			return b.Findings[i].OSV < b.Findings[j].OSV
+		}
+		return b.Findings[i].Hash < b.Findings[j].Hash
+	})
+	return b
+}
+
+// readBaseline reads the baseline file at path.
+func readBaseline(path string) (*baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading baseline: %v", err)
+	}
+	b := &baseline{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("parsing baseline %s: %v", path, err)
+	}
+	return b, nil
+}
+
+// writeBaseline writes b to the baseline file at path.
+func writeBaseline(path string, b *baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if err := os.WriteFile(path, data, 0666); err != nil {
+		return fmt.Errorf("writing baseline: %v", err)
+	}
+	return nil
+}
+
+// applyBaseline returns the findings that are not accepted by the
+// baseline file of cfg, if any.
+//
+// If cfg.accept is set, all findings are recorded in the baseline file
+// as accepted, and none are returned. Otherwise, a progress message is
+// written to handler for each accepted finding that is no longer found,
+// for instance because the vulnerability was fixed.
+//
+// If cfg.firstSeen is set, the baseline is instead a history of the
+// findings: all findings are returned, and those recorded in the
+// baseline file carry the date they were first seen. When they are
+// accepted, the findings that are not recorded yet are first seen
+// on the current date.
+func applyBaseline(handler govulncheck.Handler, cfg *config, findings []*govulncheck.Finding) ([]*govulncheck.Finding, error) {
+	if cfg.baseline == "" {
+		return findings, nil
+	}
+	path := absPath(cfg.baseline, filepath.FromSlash(cfg.dir))
+	if cfg.accept {
+		// Accepting findings again keeps their first seen dates. A
+		// missing or malformed baseline file is simply replaced.
+		if old, err := readBaseline(path); err == nil {
+			setFirstSeen(old, findings)
+		}
+		if cfg.firstSeen {
+			today := cfg.now().Format(firstSeenLayout)
+			for _, f := range findings {
+				if f.FirstSeen == "" {
+					f.FirstSeen = today
+				}
+			}
+		}
+		b := newBaseline(findings)
+		if err := writeBaseline(path, b); err != nil {
+			return nil, fmt.Errorf("govulncheck: %v", err)
+		}
+		msg := fmt.Sprintf("Accepted %d findings in baseline %s.", len(b.Findings), cfg.baseline)
+		if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+			return nil, err
+		}
+		if cfg.firstSeen {
+			return findings, nil
+		}
+		return nil, nil
+	}
+	b, err := readBaseline(path)
+	if err != nil {
+		return nil, fmt.Errorf("govulncheck: %v", err)
+	}
+	accepted := map[string]bool{}
+	for _, bf := range b.Findings {
+		accepted[bf.Hash] = true
+	}
+	if cfg.firstSeen {
+		setFirstSeen(b, findings)
+	}
+	found := map[string]bool{}
+	var unaccepted []*govulncheck.Finding
+	for _, f := range findings {
+		found[f.Hash] = true
+		if !accepted[f.Hash] || cfg.firstSeen {
+			unaccepted = append(unaccepted, f)
+		}
+	}
+	for _, bf := range b.Findings {
+		if found[bf.Hash] {
+			continue
+		}
+		msg := fmt.Sprintf("Warning: accepted finding %s is no longer found and may have been fixed. Run govulncheck with -accept to update baseline %s.", bf, cfg.baseline)
+		if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+			return nil, err
+		}
+	}
+	return unaccepted, nil
+}
+
+// setFirstSeen sets the first seen date of the findings recorded with a
+// date in b.
+func setFirstSeen(b *baseline, findings []*govulncheck.Finding) {
+	dates := map[string]string{}
+	for _, bf := range b.Findings {
+		if bf.FirstSeen != "" {
+			dates[bf.Hash] = bf.FirstSeen
+		}
+	}
+	for _, f := range findings {
+		if d, ok := dates[f.Hash]; ok {
+			f.FirstSeen = d
+		}
+	}
+}
+
+// firstSeen returns the earliest first seen date of findings, or "" if
+// none of them is recorded with a date.
+func firstSeen(findings []*findingSummary) string {
+	seen := ""
+	for _, f := range findings {
+		// Dates in firstSeenLayout sort chronologically.
+		if f.FirstSeen != "" && (seen == "" || f.FirstSeen < seen) {
+			seen = f.FirstSeen
+		}
+	}
+	return seen
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/test"
+)
+
+func TestApplyBaseline(t *testing.T) {
+	finding := func(osv, fn string) *govulncheck.Finding {
+		f := &govulncheck.Finding{
+			OSV:   osv,
+			Trace: []*govulncheck.Frame{{Module: "golang.org/vmod", Package: "golang.org/vmod/vuln", Function: fn}},
+		}
+		f.Hash = f.IdentityHash()
+		return f
+	}
+	a, b, c := finding("GO-0000-0001", "A"), finding("GO-0000-0002", "B"), finding("GO-0000-0003", "C")
+
+	dir := t.TempDir()
+	cfg := &config{baseline: "baseline.json", dir: dir, accept: true}
+	h := test.NewMockHandler()
+	got, err := applyBaseline(h, cfg, []*govulncheck.Finding{b, a, a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d findings after accepting; want 0", len(got))
+	}
+	bl, err := readBaseline(filepath.Join(dir, "baseline.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &baseline{Findings: []*baselineFinding{
+		{Hash: a.Hash, OSV: a.OSV, Module: "golang.org/vmod", Symbol: "golang.org/vmod/vuln.A"},
+		{Hash: b.Hash, OSV: b.OSV, Module: "golang.org/vmod", Symbol: "golang.org/vmod/vuln.B"},
+	}}
+	if diff := cmp.Diff(want, bl); diff != "" {
+		t.Errorf("baseline mismatch (-want, +got):\n%s", diff)
+	}
+
+	cfg.accept = false
+	h = test.NewMockHandler()
+	got, err = applyBaseline(h, cfg, []*govulncheck.Finding{b, c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*govulncheck.Finding{c}, got); diff != "" {
+		t.Errorf("findings mismatch (-want, +got):\n%s", diff)
+	}
+	if len(h.ProgressMessages) != 1 || !strings.Contains(h.ProgressMessages[0].Message, "GO-0000-0001 in golang.org/vmod/vuln.A is no longer found") {
+		t.Errorf("got progress messages %v; want a warning for the fixed finding", h.ProgressMessages)
+	}
+
+	cfg.baseline = "missing.json"
+	if _, err := applyBaseline(h, cfg, nil); err == nil {
+		t.Error("want error for missing baseline; got nil")
+	}
+}
+
+func TestApplyBaselineFirstSeen(t *testing.T) {
+	finding := func(osv string) *govulncheck.Finding {
+		f := &govulncheck.Finding{OSV: osv, Trace: []*govulncheck.Frame{{Module: "golang.org/vmod"}}}
+		f.Hash = f.IdentityHash()
+		return f
+	}
+	day := func(d int) func() time.Time {
+		return func() time.Time { return time.Date(2023, 5, d, 12, 0, 0, 0, time.UTC) }
+	}
+
+	dir := t.TempDir()
+	cfg := &config{baseline: "baseline.json", dir: dir, accept: true, firstSeen: true, now: day(1)}
+	h := test.NewMockHandler()
+	got, err := applyBaseline(h, cfg, []*govulncheck.Finding{finding("GO-0000-0001")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].FirstSeen != "2023-05-01" {
+		t.Errorf("got findings %v after accepting; want GO-0000-0001 first seen on 2023-05-01", got)
+	}
+
+	// Accepting again keeps the date of the findings already recorded.
+	cfg.now = day(20)
+	if _, err := applyBaseline(h, cfg, []*govulncheck.Finding{finding("GO-0000-0001"), finding("GO-0000-0002")}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.accept = false
+	got, err = applyBaseline(h, cfg, []*govulncheck.Finding{finding("GO-0000-0001"), finding("GO-0000-0002"), finding("GO-0000-0003")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dates []string
+	for _, f := range got {
+		dates = append(dates, f.OSV+" "+f.FirstSeen)
+	}
+	want := []string{"GO-0000-0001 2023-05-01", "GO-0000-0002 2023-05-20", "GO-0000-0003 "}
+	if diff := cmp.Diff(want, dates); diff != "" {
+		t.Errorf("first seen mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"runtime/debug"
 	"strings"
 	"unicode"
 
@@ -36,20 +37,68 @@ func runBinary(ctx context.Context, handler govulncheck.Handler, cfg *config, cl
 	if err != nil {
 		return fmt.Errorf("govulncheck: %v", err)
 	}
+	if err := cfg.download.finish(); err != nil {
+		return err
+	}
+	if err := reportNotApplicable(handler, vr); err != nil {
+		return err
+	}
+	if bh, ok := handler.(govulncheck.BuildHandler); ok && vr.BuildInfo != nil {
+		if err := bh.Build(buildFromInfo(vr.BuildInfo)); err != nil {
+			return err
+		}
+	}
+	for _, e := range vr.MissingEntrySymbols {
+		msg := fmt.Sprintf("Warning: the entry symbol %s is not a function of the binary, so it reaches no symbols.", e)
+		if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+			return err
+		}
+	}
 	callstacks := binaryCallstacks(vr)
-	return emitResult(handler, vr, callstacks)
+	return emitResult(handler, cfg, vr, callstacks)
 }
 
+// buildFromInfo returns the build information bi as a Build message.
+func buildFromInfo(bi *debug.BuildInfo) *govulncheck.Build {
+	b := &govulncheck.Build{
+		GoVersion: bi.GoVersion,
+		Path:      bi.Path,
+		Module:    bi.Main.Path,
+		Version:   bi.Main.Version,
+	}
+	for _, s := range bi.Settings {
+		b.Settings = append(b.Settings, &govulncheck.BuildSetting{Key: s.Key, Value: s.Value})
+	}
+	return b
+}
+
+// binaryCallstacks returns the call stacks of the vulnerable symbols of
+// vr, made of the symbols themselves. The symbols that are not reached
+// from the entry symbols have no call stacks, so they are reported as
+// imported.
 func binaryCallstacks(vr *vulncheck.Result) map[*vulncheck.Vuln][]vulncheck.CallStack {
 	callstacks := map[*vulncheck.Vuln][]vulncheck.CallStack{}
-	for _, vv := range uniqueVulns(vr.Vulns) {
+	var reached []*vulncheck.Vuln
+	for _, vv := range vr.Vulns {
+		if !vv.Unreached {
+			reached = append(reached, vv)
+		}
+	}
+	for _, vv := range uniqueVulns(reached) {
 		f := &vulncheck.FuncNode{Package: vv.ImportSink, Name: vv.Symbol}
 		parts := strings.Split(vv.Symbol, ".")
 		if len(parts) != 1 {
 			f.RecvType = parts[0]
 			f.Name = parts[1]
 		}
-		callstacks[vv] = []vulncheck.CallStack{{{Function: f}}}
+		entry := vulncheck.StackEntry{Function: f}
+		if vv.Inlined {
+			// Report where the symbol is logically called instead of
+			// no position at all, as the inlined code has the position
+			// of the caller.
+			entry.InlinedAt = vv.BinaryPos
+		}
+		callstacks[vv] = []vulncheck.CallStack{{entry}}
 	}
 	return callstacks
 }
@@ -5,7 +5,11 @@
 package scan
 
 import (
+	"runtime/debug"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
 )
 
 func TestIsExported(t *testing.T) {
@@ -28,3 +32,28 @@ func TestIsExported(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildFromInfo(t *testing.T) {
+	bi := &debug.BuildInfo{
+		GoVersion: "go1.20.4",
+		Path:      "example.com/prog/cmd/prog",
+		Main:      debug.Module{Path: "example.com/prog", Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "GOFLAGS", Value: "-trimpath"},
+		},
+	}
+	want := &govulncheck.Build{
+		GoVersion: "go1.20.4",
+		Path:      "example.com/prog/cmd/prog",
+		Module:    "example.com/prog",
+		Version:   "v1.2.3",
+		Settings: []*govulncheck.BuildSetting{
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "GOFLAGS", Value: "-trimpath"},
+		},
+	}
+	if diff := cmp.Diff(want, buildFromInfo(bi)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// countHandler is a handler that only reports the number of
+// called, imported, and total vulnerabilities as a single line
+// of the form
+//
+//	called=C imported=I total=T
+//
+// It is intended for use in scripts.
+type countHandler struct {
+	w      io.Writer
+	called map[string]bool
+}
+
+func newCountHandler(w io.Writer) *countHandler {
+	return &countHandler{w: w, called: map[string]bool{}}
+}
+
+// Config does nothing, as only counts are reported.
+func (h *countHandler) Config(config *govulncheck.Config) error {
+	return nil
+}
+
+// Progress does nothing, as only counts are reported.
+func (h *countHandler) Progress(progress *govulncheck.Progress) error {
+	return nil
+}
+
+// OSV does nothing, as vulnerabilities are counted from findings.
+func (h *countHandler) OSV(entry *osv.Entry) error {
+	return nil
+}
+
+// Finding records whether the vulnerability of finding is called.
+func (h *countHandler) Finding(finding *govulncheck.Finding) error {
+	if err := validateFindings(finding); err != nil {
+		return err
+	}
+	if finding.Tool != nil {
+		// Build-time tools are not part of the program.
+		return nil
+	}
+	called := isCalledFinding(finding)
+	h.called[finding.OSV] = h.called[finding.OSV] || called
+	return nil
+}
+
+// Flush writes the counts line.
+func (h *countHandler) Flush() error {
+	called := 0
+	for _, c := range h.called {
+		if c {
+			called++
+		}
+	}
+	total := len(h.called)
+	_, err := fmt.Fprintf(h.w, "called=%d imported=%d total=%d\n", called, total-called, total)
+	return err
+}
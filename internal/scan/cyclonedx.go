@@ -0,0 +1,302 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// cyclonedxHandler is a handler that writes the findings as a CycloneDX
+// VEX (Vulnerability Exploitability eXchange) document, which SBOM tools
+// combine with the components of a CycloneDX SBOM. See
+// https://cyclonedx.org/capabilities/vex/. The vulnerabilities carry the
+// details, ratings and dates of their OSV entries, so that the document is
+// also a VDR (Vulnerability Disclosure Report) of the scanned code.
+//
+// Each vulnerability of the findings is analyzed as "exploitable" if it
+// is called, and as "not_affected", with the justification
+// "code_not_reachable", if it is only imported. The vulnerable modules
+// are the components of the document, identified by their package URL.
+type cyclonedxHandler struct {
+	w        io.Writer
+	cfg      *govulncheck.Config
+	osvs     []*osv.Entry
+	findings []*findingSummary
+
+	// testNoFail is set if vulnerabilities that are only called
+	// from tests do not cause failure.
+	testNoFail bool
+}
+
+// newCycloneDXHandler returns a handler that writes to w.
+func newCycloneDXHandler(w io.Writer, cfg *config) *cyclonedxHandler {
+	return &cyclonedxHandler{w: w, cfg: &govulncheck.Config{}, testNoFail: cfg.testNoFail}
+}
+
+// The CycloneDX document, limited to the fields written by govulncheck.
+// See https://cyclonedx.org/docs/1.5/json/.
+type (
+	cdxDocument struct {
+		BOMFormat       string              `json:"bomFormat"`
+		SpecVersion     string              `json:"specVersion"`
+		Version         int                 `json:"version"`
+		Metadata        *cdxMetadata        `json:"metadata"`
+		Components      []*cdxComponent     `json:"components"`
+		Vulnerabilities []*cdxVulnerability `json:"vulnerabilities"`
+	}
+
+	cdxMetadata struct {
+		Tools *cdxTools `json:"tools"`
+	}
+
+	cdxTools struct {
+		Components []*cdxComponent `json:"components"`
+	}
+
+	cdxComponent struct {
+		Type    string `json:"type"`
+		BOMRef  string `json:"bom-ref,omitempty"`
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+		PURL    string `json:"purl,omitempty"`
+	}
+
+	cdxVulnerability struct {
+		ID             string          `json:"id"`
+		Source         *cdxSource      `json:"source"`
+		References     []*cdxReference `json:"references,omitempty"`
+		Ratings        []*cdxRating    `json:"ratings,omitempty"`
+		Description    string          `json:"description,omitempty"`
+		Detail         string          `json:"detail,omitempty"`
+		Recommendation string          `json:"recommendation,omitempty"`
+		CWEs           []int           `json:"cwes,omitempty"`
+		Published      string          `json:"published,omitempty"`
+		Updated        string          `json:"updated,omitempty"`
+		Analysis       *cdxAnalysis    `json:"analysis"`
+		Affects        []*cdxAffect    `json:"affects"`
+	}
+
+	cdxRating struct {
+		Score    float64 `json:"score"`
+		Severity string  `json:"severity"`
+		Method   string  `json:"method"`
+		Vector   string  `json:"vector"`
+	}
+
+	cdxSource struct {
+		Name string `json:"name,omitempty"`
+		URL  string `json:"url,omitempty"`
+	}
+
+	cdxReference struct {
+		ID     string     `json:"id"`
+		Source *cdxSource `json:"source"`
+	}
+
+	cdxAnalysis struct {
+		State         string `json:"state"`
+		Justification string `json:"justification,omitempty"`
+		Detail        string `json:"detail,omitempty"`
+	}
+
+	cdxAffect struct {
+		Ref string `json:"ref"`
+	}
+)
+
+// Analysis states and justifications of CycloneDX.
+const (
+	cdxExploitable      = "exploitable"
+	cdxNotAffected      = "not_affected"
+	cdxInTriage         = "in_triage"
+	cdxCodeNotReachable = "code_not_reachable"
+)
+
+// Config records the scanner and scan level of the document.
+func (h *cyclonedxHandler) Config(config *govulncheck.Config) error {
+	h.cfg = config
+	return nil
+}
+
+// Progress does nothing, so that the output is only made of the document.
+func (h *cyclonedxHandler) Progress(progress *govulncheck.Progress) error {
+	return nil
+}
+
+// OSV gathers osv entries to be written.
+func (h *cyclonedxHandler) OSV(entry *osv.Entry) error {
+	h.osvs = append(h.osvs, entry)
+	return nil
+}
+
+// Finding gathers vulnerability findings to be written.
+func (h *cyclonedxHandler) Finding(finding *govulncheck.Finding) error {
+	if err := validateFindings(finding); err != nil {
+		return err
+	}
+	h.findings = append(h.findings, newFindingSummary(finding))
+	return nil
+}
+
+// Flush writes the document, with a vulnerability for each OSV entry of
+// the findings.
+func (h *cyclonedxHandler) Flush() error {
+	fixupFindings(h.osvs, h.findings)
+	doc := &cdxDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: &cdxMetadata{Tools: &cdxTools{Components: []*cdxComponent{{
+			Type:    "application",
+			Name:    h.cfg.ScannerName,
+			Version: h.cfg.ScannerVersion,
+		}}}},
+		Components:      []*cdxComponent{},
+		Vulnerabilities: []*cdxVulnerability{},
+	}
+	components := map[string]*cdxComponent{}
+	for _, vuln := range groupByVuln(h.findings) {
+		v := h.vulnerability(vuln)
+		affected := map[string]bool{}
+		for _, f := range vuln {
+			c := cdxModule(f.Trace[0])
+			components[c.BOMRef] = c
+			if !affected[c.BOMRef] {
+				affected[c.BOMRef] = true
+				v.Affects = append(v.Affects, &cdxAffect{Ref: c.BOMRef})
+			}
+		}
+		doc.Vulnerabilities = append(doc.Vulnerabilities, v)
+	}
+	for _, c := range components {
+		doc.Components = append(doc.Components, c)
+	}
+	sort.Slice(doc.Components, func(i, j int) bool {
+		return doc.Components[i].BOMRef < doc.Components[j].BOMRef
+	})
+	enc := json.NewEncoder(h.w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if isFailure(h.findings, h.testNoFail) {
+		return errVulnerabilitiesFound
+	}
+	return nil
+}
+
+// vulnerability returns the vulnerability of the document for vuln, the
+// findings of an OSV entry, with no affected components.
+func (h *cyclonedxHandler) vulnerability(vuln []*findingSummary) *cdxVulnerability {
+	entry := vuln[0].OSV
+	v := &cdxVulnerability{
+		ID:          entry.ID,
+		Source:      &cdxSource{Name: "Go Vulnerability Database", URL: "https://pkg.go.dev/vuln/" + entry.ID},
+		Description: entry.Summary,
+		Affects:     []*cdxAffect{},
+	}
+	if entry.DatabaseSpecific != nil && entry.DatabaseSpecific.URL != "" {
+		v.Source.URL = entry.DatabaseSpecific.URL
+	}
+	if v.Description == "" {
+		v.Description = entry.Details
+	} else if entry.Details != v.Description {
+		v.Detail = entry.Details
+	}
+	if vector, score := cvss3Severity(entry); vector != "" {
+		v.Ratings = []*cdxRating{cdxCVSS3Rating(vector, score)}
+	}
+	if !entry.Published.IsZero() {
+		v.Published = entry.Published.UTC().Format(time.RFC3339)
+	}
+	if !entry.Modified.IsZero() {
+		v.Updated = entry.Modified.UTC().Format(time.RFC3339)
+	}
+	for _, alias := range entry.Aliases {
+		v.References = append(v.References, &cdxReference{
+			ID:     alias,
+			Source: &cdxSource{URL: "https://osv.dev/vulnerability/" + alias},
+		})
+	}
+	for _, id := range vuln[0].CWEs {
+		// CycloneDX identifies weaknesses by the number of their CWE ID.
+		if n, err := strconv.Atoi(strings.TrimPrefix(id, "CWE-")); err == nil {
+			v.CWEs = append(v.CWEs, n)
+		}
+	}
+	frame := vuln[0].Trace[0]
+	if fixed := moduleVersionString(frame.Module, vuln[0].FixedVersion); fixed != "" {
+		if frame.Module == internal.GoStdModulePath {
+			v.Recommendation = fmt.Sprintf("Upgrade Go to %s.", fixed)
+		} else {
+			v.Recommendation = fmt.Sprintf("Upgrade %s to %s.", frame.Module, fixed)
+		}
+	}
+	switch {
+	case h.cfg.ScanLevel != "" && !h.cfg.ScanLevel.WantSymbols():
+		v.Analysis = &cdxAnalysis{
+			State:  cdxInTriage,
+			Detail: fmt.Sprintf("The vulnerable code is required, but calls are not analyzed at scan level %s.", h.cfg.ScanLevel),
+		}
+	case isCalled(vuln):
+		v.Analysis = &cdxAnalysis{State: cdxExploitable, Detail: "The vulnerable code is called."}
+		if !hasCallStack(vuln) {
+			v.Analysis.Detail = "The vulnerable code is imported, and treated as called by the -assume-called flag."
+		}
+	default:
+		v.Analysis = &cdxAnalysis{
+			State:         cdxNotAffected,
+			Justification: cdxCodeNotReachable,
+			Detail:        "The vulnerable code is imported, but not called.",
+		}
+	}
+	return v
+}
+
+// cdxModule returns the component of the module of frame, identified by
+// its package URL, as in "pkg:golang/golang.org/x/text@v0.3.0".
+func cdxModule(frame *govulncheck.Frame) *cdxComponent {
+	c := &cdxComponent{Type: "library", Name: frame.Module, Version: frame.Version}
+	c.PURL = "pkg:golang/" + frame.Module
+	if frame.Version != "" {
+		c.PURL += "@" + frame.Version
+	}
+	c.BOMRef = c.PURL
+	return c
+}
+
+// cdxCVSS3Rating returns the rating of the CVSS v3 vector with base score
+// score, whose severity is the qualitative rating of the score. See
+// https://www.first.org/cvss/v3.1/specification-document#5-Qualitative-Severity-Rating-Scale.
+func cdxCVSS3Rating(vector string, score float64) *cdxRating {
+	r := &cdxRating{Score: score, Method: "CVSSv3", Vector: vector}
+	if strings.HasPrefix(vector, "CVSS:3.1/") {
+		r.Method = "CVSSv31"
+	}
+	switch {
+	case score >= 9:
+		r.Severity = "critical"
+	case score >= 7:
+		r.Severity = "high"
+	case score >= 4:
+		r.Severity = "medium"
+	case score > 0:
+		r.Severity = "low"
+	default:
+		r.Severity = "none"
+	}
+	return r
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestCycloneDXHandler(t *testing.T) {
+	vuln := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln", Function: "V"}
+	imported := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln"}
+	main := &govulncheck.Frame{Module: "golang.org/main", Package: "golang.org/main", Function: "main"}
+	for _, test := range []struct {
+		name  string
+		level govulncheck.ScanLevel
+		want  map[string]string
+		err   error
+	}{
+		{
+			name:  "symbol",
+			level: "symbol",
+			want:  map[string]string{"GO-0000-0001": cdxExploitable, "GO-0000-0002": cdxNotAffected},
+			err:   errVulnerabilitiesFound,
+		},
+		{
+			name:  "package",
+			level: "package",
+			want:  map[string]string{"GO-0000-0002": cdxInTriage},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := newCycloneDXHandler(buf, &config{})
+			if err := h.Config(&govulncheck.Config{ScannerName: "govulncheck", ScanLevel: test.level}); err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range []*osv.Entry{{ID: "GO-0000-0001", Aliases: []string{"CVE-0000-0001"}, Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}}, {ID: "GO-0000-0002"}} {
+				if err := h.OSV(entry); err != nil {
+					t.Fatal(err)
+				}
+			}
+			findings := []*govulncheck.Finding{
+				{OSV: "GO-0000-0002", Trace: []*govulncheck.Frame{imported}},
+			}
+			if test.level.WantSymbols() {
+				findings = append(findings, &govulncheck.Finding{OSV: "GO-0000-0001", FixedVersion: "v1.0.1", CWEs: []string{"CWE-22"}, Trace: []*govulncheck.Frame{vuln, main}})
+			}
+			for _, f := range findings {
+				if err := h.Finding(f); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := h.Flush(); err != test.err {
+				t.Fatalf("got error %v; want %v", err, test.err)
+			}
+			var doc cdxDocument
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]string{}
+			for _, v := range doc.Vulnerabilities {
+				got[v.ID] = v.Analysis.State
+				if len(v.Affects) != 1 || v.Affects[0].Ref != "pkg:golang/golang.org/vmod@v1.0.0" {
+					t.Errorf("%s: got affects %v; want the vulnerable module", v.ID, v.Affects)
+				}
+				if v.ID == "GO-0000-0001" && (len(v.References) != 1 || v.Recommendation != "Upgrade golang.org/vmod to v1.0.1.") {
+					t.Errorf("%s: got references %v and recommendation %q", v.ID, v.References, v.Recommendation)
+				}
+				if v.ID == "GO-0000-0001" && !reflect.DeepEqual(v.CWEs, []int{22}) {
+					t.Errorf("%s: got CWEs %v; want [22]", v.ID, v.CWEs)
+				}
+				if v.ID == "GO-0000-0001" && (len(v.Ratings) != 1 || v.Ratings[0].Severity != "critical" || v.Ratings[0].Method != "CVSSv31") {
+					t.Errorf("%s: got ratings %v; want a critical CVSSv31 rating", v.ID, v.Ratings)
+				}
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("analysis states mismatch (-want, +got):\n%s", diff)
+			}
+			if len(doc.Components) != 1 {
+				t.Errorf("got %d components; want 1", len(doc.Components))
+			}
+		})
+	}
+}
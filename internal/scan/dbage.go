@@ -0,0 +1,45 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// checkDBAge returns an error if the vulnerability database of cfg was
+// last modified more than the -max-db-age flag ago, or if its last
+// modification time is unknown, so that a cached or vendored database
+// does not silently go stale. If the -db-age-warn flag is set, it writes
+// a warning to handler instead.
+func checkDBAge(handler govulncheck.Handler, cfg *config) error {
+	if cfg.maxDBAge == 0 {
+		return nil
+	}
+	var msg string
+	if cfg.DBLastModified == nil {
+		msg = fmt.Sprintf("the last modification time of vulnerability database %s is unknown, so its age cannot be checked", cfg.db)
+	} else if age := cfg.now().Sub(*cfg.DBLastModified); age > cfg.maxDBAge {
+		msg = fmt.Sprintf("vulnerability database %s is older than the -max-db-age of %s: it was last updated on %s", cfg.db, formatAge(cfg.maxDBAge), cfg.DBLastModified.UTC().Format("2006-01-02"))
+	} else {
+		return nil
+	}
+	if cfg.dbAgeWarn {
+		return handler.Progress(&govulncheck.Progress{Message: "Warning: " + msg + "."})
+	}
+	return fmt.Errorf("govulncheck: %s", msg)
+}
+
+// formatAge formats d as a number of days if it is a whole number of
+// days, as in "7d", and as a duration otherwise, as in "36h0m0s".
+func formatAge(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
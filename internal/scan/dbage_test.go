@@ -0,0 +1,70 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/vuln/internal/test"
+)
+
+func TestCheckDBAge(t *testing.T) {
+	modified := time.Date(2023, 4, 3, 15, 57, 51, 0, time.UTC)
+	now := func() time.Time { return modified.Add(10 * 24 * time.Hour) }
+	for _, tc := range []struct {
+		name     string
+		maxAge   time.Duration
+		warn     bool
+		modified *time.Time
+		wantErr  string
+		wantMsg  string
+	}{
+		{name: "unchecked", modified: &modified},
+		{name: "fresh", maxAge: 14 * 24 * time.Hour, modified: &modified},
+		{
+			name:     "stale",
+			maxAge:   7 * 24 * time.Hour,
+			modified: &modified,
+			wantErr:  "govulncheck: vulnerability database https://vuln.go.dev is older than the -max-db-age of 7d: it was last updated on 2023-04-03",
+		},
+		{
+			name:     "stale warning",
+			maxAge:   36 * time.Hour,
+			warn:     true,
+			modified: &modified,
+			wantMsg:  "Warning: vulnerability database https://vuln.go.dev is older than the -max-db-age of 36h0m0s: it was last updated on 2023-04-03.",
+		},
+		{
+			name:    "unknown",
+			maxAge:  7 * 24 * time.Hour,
+			wantErr: "govulncheck: the last modification time of vulnerability database https://vuln.go.dev is unknown, so its age cannot be checked",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config{db: "https://vuln.go.dev", now: now, maxDBAge: tc.maxAge, dbAgeWarn: tc.warn}
+			cfg.DBLastModified = tc.modified
+			h := test.NewMockHandler()
+			err := checkDBAge(h, cfg)
+			if gotErr := errString(err); gotErr != tc.wantErr {
+				t.Errorf("got error %q; want %q", gotErr, tc.wantErr)
+			}
+			var gotMsg string
+			if len(h.ProgressMessages) > 0 {
+				gotMsg = h.ProgressMessages[0].Message
+			}
+			if gotMsg != tc.wantMsg {
+				t.Errorf("got message %q; want %q", gotMsg, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// dependencyPaths are the shortest chains of module dependencies, through
+// package imports, from the main modules to the packages they depend on.
+type dependencyPaths struct {
+	// importer is the package through which each package, by path, is
+	// reached first, or nil for the packages of the main modules.
+	importer map[string]*packages.Package
+	pkgs     map[string]*packages.Package
+}
+
+// newDependencyPaths computes the dependency paths from the packages of
+// the main modules among pkgs. The paths cross the fewest module
+// boundaries: they are found by a 0-1 breadth-first search, where imports
+// within a module have weight 0 and imports of another module weight 1.
+// Imports are visited in order of their paths, so that the paths are
+// reproducible.
+func newDependencyPaths(pkgs []*packages.Package) *dependencyPaths {
+	dp := &dependencyPaths{importer: map[string]*packages.Package{}, pkgs: map[string]*packages.Package{}}
+	dist := map[string]int{}
+	var queue []*packages.Package
+	for _, p := range pkgs {
+		if p.Module == nil || !p.Module.Main || dp.pkgs[p.PkgPath] != nil {
+			continue
+		}
+		dp.pkgs[p.PkgPath] = p
+		dp.importer[p.PkgPath] = nil
+		dist[p.PkgPath] = 0
+		queue = append(queue, p)
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		var paths []string
+		for path := range p.Imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			imp := p.Imports[path]
+			if imp.Module == nil {
+				continue
+			}
+			d := dist[p.PkgPath]
+			if frameFromPackage(p).Module != frameFromPackage(imp).Module {
+				d++
+			}
+			if old, ok := dist[imp.PkgPath]; ok && old <= d {
+				continue
+			}
+			dist[imp.PkgPath] = d
+			dp.importer[imp.PkgPath] = p
+			dp.pkgs[imp.PkgPath] = imp
+			if d == dist[p.PkgPath] {
+				queue = append([]*packages.Package{imp}, queue...)
+			} else {
+				queue = append(queue, imp)
+			}
+		}
+	}
+	return dp
+}
+
+// path returns the chain of modules, from a main module to the module of
+// the package with path pkgPath, through which the package is imported,
+// or nil if it is not reached. The modules are identified as in traces.
+func (dp *dependencyPaths) path(pkgPath string) []*govulncheck.ModuleVersion {
+	p := dp.pkgs[pkgPath]
+	if p == nil {
+		return nil
+	}
+	var path []*govulncheck.ModuleVersion
+	for ; p != nil; p = dp.importer[p.PkgPath] {
+		fr := frameFromPackage(p)
+		if n := len(path); n > 0 && path[n-1].Path == fr.Module {
+			continue
+		}
+		path = append(path, &govulncheck.ModuleVersion{Path: fr.Module, Version: fr.Version})
+	}
+	// Reverse the chain to start from the main module.
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+func TestDependencyPaths(t *testing.T) {
+	mod := func(path, version string) *packages.Module {
+		return &packages.Module{Path: path, Version: version}
+	}
+	mainMod := &packages.Module{Path: "golang.org/main", Main: true}
+	vmod, xmod, ymod, zmod := mod("golang.org/vmod", "v1.0.0"), mod("golang.org/xmod", "v0.2.0"), mod("golang.org/ymod", "v0.3.0"), mod("golang.org/zmod", "v0.4.0")
+	vuln := &packages.Package{PkgPath: "golang.org/vmod/vuln", Module: vmod}
+	// Within a module, imports are free: the path through the internal
+	// package of the main module and x crosses two modules, while the
+	// one through y and z crosses three.
+	x := &packages.Package{PkgPath: "golang.org/xmod/x", Module: xmod, Imports: map[string]*packages.Package{"golang.org/vmod/vuln": vuln}}
+	z := &packages.Package{PkgPath: "golang.org/zmod/z", Module: zmod, Imports: map[string]*packages.Package{"golang.org/vmod/vuln": vuln}}
+	y := &packages.Package{PkgPath: "golang.org/ymod/y", Module: ymod, Imports: map[string]*packages.Package{"golang.org/zmod/z": z}}
+	helper := &packages.Package{PkgPath: "golang.org/main/internal/helper", Module: mainMod, Imports: map[string]*packages.Package{"golang.org/xmod/x": x}}
+	main := &packages.Package{PkgPath: "golang.org/main", Module: mainMod, Imports: map[string]*packages.Package{
+		"golang.org/main/internal/helper": helper,
+		"golang.org/ymod/y":               y,
+	}}
+
+	dp := newDependencyPaths([]*packages.Package{main})
+	want := []*govulncheck.ModuleVersion{
+		{Path: "golang.org/main"},
+		{Path: "golang.org/xmod", Version: "v0.2.0"},
+		{Path: "golang.org/vmod", Version: "v1.0.0"},
+	}
+	if diff := cmp.Diff(want, dp.path("golang.org/vmod/vuln")); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	want = []*govulncheck.ModuleVersion{
+		{Path: "golang.org/main"},
+		{Path: "golang.org/ymod", Version: "v0.3.0"},
+		{Path: "golang.org/zmod", Version: "v0.4.0"},
+	}
+	if diff := cmp.Diff(want, dp.path("golang.org/zmod/z")); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if got := dp.path("golang.org/other"); got != nil {
+		t.Errorf("got path %v for a package that is not imported; want nil", got)
+	}
+}
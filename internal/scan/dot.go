@@ -0,0 +1,145 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// dotHandler is a handler that writes the call stacks of the findings
+// of each called vulnerability as a Graphviz DOT graph, which is the
+// union of the traces of its findings. See https://graphviz.org/doc/info/lang.html.
+//
+// Nodes are functions, labeled by their short symbol name, and edges are
+// calls, labeled by the position of the call site. The vulnerable
+// symbols are outlined in red, functions of the standard library are
+// filled in grey, and calls that cannot be statically resolved are
+// dashed. Vulnerabilities that are only imported have no call stacks
+// and are not written.
+type dotHandler struct {
+	w        io.Writer
+	osvs     []*osv.Entry
+	findings []*findingSummary
+
+	// testNoFail is set if vulnerabilities that are only called
+	// from tests do not cause failure.
+	testNoFail bool
+}
+
+// newDOTHandler returns a handler that writes to w.
+func newDOTHandler(w io.Writer, cfg *config) *dotHandler {
+	return &dotHandler{w: w, testNoFail: cfg.testNoFail}
+}
+
+// Config does nothing, as the graphs only show findings.
+func (h *dotHandler) Config(config *govulncheck.Config) error {
+	return nil
+}
+
+// Progress does nothing, so that the output is only made of graphs.
+func (h *dotHandler) Progress(progress *govulncheck.Progress) error {
+	return nil
+}
+
+// OSV gathers osv entries to be written.
+func (h *dotHandler) OSV(entry *osv.Entry) error {
+	h.osvs = append(h.osvs, entry)
+	return nil
+}
+
+// Finding gathers vulnerability findings to be written.
+func (h *dotHandler) Finding(finding *govulncheck.Finding) error {
+	if err := validateFindings(finding); err != nil {
+		return err
+	}
+	h.findings = append(h.findings, newFindingSummary(finding))
+	return nil
+}
+
+// Flush writes a graph for each called vulnerability.
+func (h *dotHandler) Flush() error {
+	fixupFindings(h.osvs, h.findings)
+	first := true
+	for _, vuln := range groupByVuln(h.findings) {
+		if !hasCallStack(vuln) {
+			continue
+		}
+		if !first {
+			if _, err := fmt.Fprintln(h.w); err != nil {
+				return err
+			}
+		}
+		first = false
+		if _, err := io.WriteString(h.w, dotGraph(vuln)); err != nil {
+			return err
+		}
+	}
+	if isFailure(h.findings, h.testNoFail) {
+		return errVulnerabilitiesFound
+	}
+	return nil
+}
+
+// dotGraph returns the DOT graph of the traces of the findings of
+// a single vulnerability.
+func dotGraph(vuln []*findingSummary) string {
+	var nodes, edges strings.Builder
+	seen := map[string]bool{}
+	node := func(fr *govulncheck.Frame, vulnerable bool) string {
+		id := dotQuote(fr.Symbol())
+		if seen[id] {
+			return id
+		}
+		seen[id] = true
+		attrs := []string{"label=" + dotQuote(symbol(fr, true))}
+		if vulnerable {
+			attrs = append(attrs, "color=red")
+		}
+		if fr.Module == internal.GoStdModulePath {
+			attrs = append(attrs, "style=filled", "fillcolor=lightgrey")
+		}
+		fmt.Fprintf(&nodes, "\t%s [%s];\n", id, strings.Join(attrs, ", "))
+		return id
+	}
+	for _, f := range vuln {
+		if f.Trace[0].Function == "" {
+			continue
+		}
+		callee := node(f.Trace[0], true)
+		for _, fr := range f.Trace[1:] {
+			caller := node(fr, false)
+			var attrs []string
+			if pos := posToString(fr.Position); pos != "" {
+				attrs = append(attrs, "label="+dotQuote(pos))
+			}
+			if fr.Unresolved {
+				attrs = append(attrs, "style=dashed")
+			}
+			edge := fmt.Sprintf("\t%s -> %s", caller, callee)
+			if len(attrs) > 0 {
+				edge += " [" + strings.Join(attrs, ", ") + "]"
+			}
+			if !seen[edge] {
+				seen[edge] = true
+				fmt.Fprintf(&edges, "%s;\n", edge)
+			}
+			callee = caller
+		}
+	}
+	id := dotQuote(vuln[0].OSV.ID)
+	return fmt.Sprintf("digraph %s {\n\tlabel=%s;\n\tnode [shape=box];\n%s%s}\n", id, id, nodes.String(), edges.String())
+}
+
+// dotQuote returns s as a quoted DOT string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestDOTHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := newDOTHandler(buf, &config{})
+	for _, entry := range []*osv.Entry{{ID: "GO-0000-0001"}, {ID: "GO-0000-0002"}} {
+		if err := h.OSV(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vuln := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln", Function: "V"}
+	main := &govulncheck.Frame{Module: "golang.org/main", Package: "golang.org/main", Function: "main", Position: &govulncheck.Position{Filename: "main.go", Line: 3, Column: 7}}
+	findings := []*govulncheck.Finding{
+		{
+			OSV: "GO-0000-0001",
+			Trace: []*govulncheck.Frame{
+				vuln,
+				{Module: "stdlib", Package: "fmt", Function: "Println", Position: &govulncheck.Position{Filename: "print.go", Line: 5, Column: 2}, Unresolved: true},
+				main,
+			},
+		},
+		{
+			OSV:   "GO-0000-0001",
+			Trace: []*govulncheck.Frame{vuln, main},
+		},
+		{
+			// Imported vulnerabilities have no graph.
+			OSV:   "GO-0000-0002",
+			Trace: []*govulncheck.Frame{{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln"}},
+		},
+	}
+	for _, f := range findings {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Flush(); err != errVulnerabilitiesFound {
+		t.Errorf("got error %v; want %v", err, errVulnerabilitiesFound)
+	}
+	want := `digraph "GO-0000-0001" {
+	label="GO-0000-0001";
+	node [shape=box];
+	"golang.org/vmod/vuln.V" [label="vuln.V", color=red];
+	"fmt.Println" [label="fmt.Println", style=filled, fillcolor=lightgrey];
+	"golang.org/main.main" [label="main.main"];
+	"fmt.Println" -> "golang.org/vmod/vuln.V" [label="print.go:5:2", style=dashed];
+	"golang.org/main.main" -> "fmt.Println" [label="main.go:3:7"];
+	"golang.org/main.main" -> "golang.org/vmod/vuln.V" [label="main.go:3:7"];
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestDOTQuote(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"a.B", `"a.B"`},
+		{`a"b`, `"a\"b"`},
+		{`a\b`, `"a\\b"`},
+	} {
+		if got := dotQuote(test.in); got != test.want {
+			t.Errorf("dotQuote(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+}
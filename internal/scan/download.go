@@ -0,0 +1,96 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// downloadReporter reports the downloads of an HTTP vulnerability database
+// as progress messages, so that a slow download is not mistaken for a
+// hang. Downloads are only reported once a handler is set, which is after
+// the introductory message is written. It also warns about the entries of
+// the -db-overlay directory, overlay, that replace database entries.
+type downloadReporter struct {
+	db      string
+	overlay string
+
+	mu      sync.Mutex // guards the fields below
+	handler govulncheck.Handler
+	started bool  // whether a download was reported since the last finish
+	bytes   int64 // compressed bytes downloaded since the last finish
+	err     error // error of the handler
+}
+
+// newDownloadReporter returns a reporter of the downloads of database db.
+func newDownloadReporter(db string) *downloadReporter {
+	return &downloadReporter{db: db}
+}
+
+// setHandler starts reporting downloads to handler.
+func (r *downloadReporter) setHandler(handler govulncheck.Handler) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handler = handler
+}
+
+// download is the client.Options.OnDownload callback.
+func (r *downloadReporter) download(d *client.Download) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.handler == nil || r.err != nil {
+		return
+	}
+	if d.Done {
+		r.bytes += d.Bytes
+		return
+	}
+	if !r.started {
+		r.started = true
+		msg := fmt.Sprintf("Downloading vulnerability data from %s...", r.db)
+		r.err = r.handler.Progress(&govulncheck.Progress{Message: msg})
+	}
+}
+
+// duplicate is the client.Options.OnDuplicate callback. The overlay entry
+// takes precedence, but the database entry may have been updated since the
+// overlay entry was copied from it, such as with a fix.
+func (r *downloadReporter) duplicate(d *client.Duplicate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.handler == nil || r.err != nil {
+		return
+	}
+	msg := fmt.Sprintf("Warning: the entry %s of the -db-overlay directory %s replaces the entry with the same ID in %s.", d.ID, r.overlay, r.db)
+	if d.DatabaseModified.After(d.OverlayModified) {
+		msg += fmt.Sprintf(" The replaced entry is more recent, as it was modified on %s.", d.DatabaseModified.Format("2006-01-02"))
+	}
+	r.err = r.handler.Progress(&govulncheck.Progress{Message: msg})
+}
+
+// finish reports the amount of data downloaded since the first download
+// reported after the previous call to finish, if any. It returns the
+// first error of the handler.
+func (r *downloadReporter) finish() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil || !r.started {
+		return r.err
+	}
+	msg := fmt.Sprintf("Downloaded %d kB of vulnerability data.", (r.bytes+999)/1000)
+	r.started, r.bytes = false, 0
+	r.err = r.handler.Progress(&govulncheck.Progress{Message: msg})
+	return r.err
+}
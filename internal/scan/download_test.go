@@ -0,0 +1,74 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/test"
+)
+
+func TestDownloadReporter(t *testing.T) {
+	h := test.NewMockHandler()
+	r := newDownloadReporter("https://vuln.example.com")
+
+	// Downloads before the handler is set are not reported.
+	r.download(&client.Download{Endpoint: "index/db"})
+	r.download(&client.Download{Endpoint: "index/db", Bytes: 100, Done: true})
+
+	r.setHandler(h)
+	if err := r.finish(); err != nil {
+		t.Fatal(err)
+	}
+	r.download(&client.Download{Endpoint: "index/modules"})
+	r.download(&client.Download{Endpoint: "ID/GO-2023-0001"})
+	r.download(&client.Download{Endpoint: "index/modules", Bytes: 150000, Done: true})
+	r.download(&client.Download{Endpoint: "ID/GO-2023-0001", Bytes: 1200, Done: true})
+	if err := r.finish(); err != nil {
+		t.Fatal(err)
+	}
+	// No downloads since the last finish.
+	if err := r.finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, p := range h.ProgressMessages {
+		got = append(got, p.Message)
+	}
+	want := []string{
+		"Downloading vulnerability data from https://vuln.example.com...",
+		"Downloaded 152 kB of vulnerability data.",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("progress mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDownloadReporterDuplicate(t *testing.T) {
+	h := test.NewMockHandler()
+	r := newDownloadReporter("https://vuln.example.com")
+	r.overlay = "overlay"
+	r.setHandler(h)
+	older := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	r.duplicate(&client.Duplicate{ID: "GO-2023-0001", DatabaseModified: older, OverlayModified: newer})
+	r.duplicate(&client.Duplicate{ID: "GO-2023-0002", DatabaseModified: newer, OverlayModified: older})
+
+	var got []string
+	for _, p := range h.ProgressMessages {
+		got = append(got, p.Message)
+	}
+	want := []string{
+		"Warning: the entry GO-2023-0001 of the -db-overlay directory overlay replaces the entry with the same ID in https://vuln.example.com.",
+		"Warning: the entry GO-2023-0002 of the -db-overlay directory overlay replaces the entry with the same ID in https://vuln.example.com. The replaced entry is more recent, as it was modified on 2023-05-01.",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("progress mismatch (-want +got):\n%s", diff)
+	}
+}
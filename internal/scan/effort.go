@@ -0,0 +1,90 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"sort"
+
+	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+const (
+	// sortEffort is the value of the -sort flag ordering findings by
+	// remediation effort.
+	sortEffort = "effort"
+)
+
+// Remediation efforts of findings, from the easiest to fix to the hardest.
+const (
+	// effortDirect is the effort of upgrading a module that the main
+	// module depends on directly, or the Go toolchain, to a fixed version.
+	effortDirect = iota
+
+	// effortIndirect is the effort of upgrading a module that is only
+	// reached through other dependencies, which may need to be upgraded
+	// too.
+	effortIndirect
+
+	// effortNoFix is the effort of a vulnerability without a fixed
+	// version, which needs the code to stop using the vulnerable symbols
+	// or a fork of the module.
+	effortNoFix
+)
+
+// remediationEffort estimates the effort of fixing finding f.
+func remediationEffort(f *govulncheck.Finding) int {
+	if f.FixedVersion == "" {
+		return effortNoFix
+	}
+	if isDirect(f) {
+		return effortDirect
+	}
+	return effortIndirect
+}
+
+// isDirect reports whether the vulnerable module of finding f is the
+// standard library or a direct dependency of the main module, that is,
+// whether the main module calls into the module, or imports a package of
+// it if f is not called. Findings of binaries, which do not record how
+// dependencies are related, are considered direct.
+func isDirect(f *govulncheck.Finding) bool {
+	if f.Trace[0].Module == internal.GoStdModulePath {
+		return true
+	}
+	if len(f.Trace) == 1 {
+		return f.Trace[0].Function != "" || len(f.ImportedBy) > 0
+	}
+	// Skip the frames of the vulnerable module to find the module
+	// calling into it.
+	i := 1
+	for i < len(f.Trace) && f.Trace[i].Module == f.Trace[0].Module {
+		i++
+	}
+	return i == len(f.Trace) || f.Trace[i].Module == f.Trace[len(f.Trace)-1].Module
+}
+
+// sortByEffort sorts findings by increasing remediation effort, and
+// then by decreasing severity of their OSV entry in osvs. The order of
+// findings with the same effort and severity is preserved. Findings are
+// sorted in a new slice.
+func sortByEffort(findings []*govulncheck.Finding, osvs map[string]*osv.Entry) []*govulncheck.Finding {
+	findings = append([]*govulncheck.Finding(nil), findings...)
+	scores := map[string]float64{}
+	for _, f := range findings {
+		if _, ok := scores[f.OSV]; !ok {
+			scores[f.OSV] = severityScore(osvs[f.OSV])
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		fi, fj := findings[i], findings[j]
+		if ei, ej := remediationEffort(fi), remediationEffort(fj); ei != ej {
+			return ei < ej
+		}
+		return scores[fi.OSV] > scores[fj.OSV]
+	})
+	return findings
+}
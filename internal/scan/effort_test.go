@@ -0,0 +1,71 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// trace returns a trace of called functions in modules, from the
+// vulnerable symbol to the entry point.
+func trace(modules ...string) []*govulncheck.Frame {
+	var frames []*govulncheck.Frame
+	for _, m := range modules {
+		frames = append(frames, &govulncheck.Frame{Module: m, Function: "F"})
+	}
+	return frames
+}
+
+func TestRemediationEffort(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		finding *govulncheck.Finding
+		want    int
+	}{
+		{"direct call", &govulncheck.Finding{FixedVersion: "v1.0.1", Trace: trace("vmod", "vmod", "main")}, effortDirect},
+		{"indirect call", &govulncheck.Finding{FixedVersion: "v1.0.1", Trace: trace("vmod", "dep", "main")}, effortIndirect},
+		{"stdlib", &govulncheck.Finding{FixedVersion: "v1.20.5", Trace: trace("stdlib", "dep", "main")}, effortDirect},
+		{"direct import", &govulncheck.Finding{FixedVersion: "v1.0.1", Trace: []*govulncheck.Frame{{Module: "vmod"}}, ImportedBy: []string{"main"}}, effortDirect},
+		{"indirect import", &govulncheck.Finding{FixedVersion: "v1.0.1", Trace: []*govulncheck.Frame{{Module: "vmod"}}}, effortIndirect},
+		{"binary", &govulncheck.Finding{FixedVersion: "v1.0.1", Trace: trace("vmod")}, effortDirect},
+		{"no fix", &govulncheck.Finding{Trace: trace("vmod", "main")}, effortNoFix},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := remediationEffort(test.finding); got != test.want {
+				t.Errorf("got effort %d; want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSortByEffort(t *testing.T) {
+	osvs := map[string]*osv.Entry{
+		"LOW":   {ID: "LOW", Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"}}},
+		"HIGH":  {ID: "HIGH", Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+		"NONE":  {ID: "NONE"},
+		"NOFIX": {ID: "NOFIX", Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+	}
+	findings := []*govulncheck.Finding{
+		{OSV: "NOFIX", Trace: trace("vmod", "main")},
+		{OSV: "HIGH", FixedVersion: "v1.0.1", Trace: trace("vmod", "dep", "main")},
+		{OSV: "NONE", FixedVersion: "v1.0.1", Trace: trace("vmod", "main")},
+		{OSV: "LOW", FixedVersion: "v1.0.1", Trace: trace("vmod", "main")},
+	}
+	var got []string
+	for _, f := range sortByEffort(findings, osvs) {
+		got = append(got, f.OSV)
+	}
+	want := []string{"LOW", "NONE", "HIGH", "NOFIX"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if findings[0].OSV != "NOFIX" {
+		t.Error("sortByEffort modified its argument")
+	}
+}
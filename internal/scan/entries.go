@@ -0,0 +1,88 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// entryFunctions are the entry functions from which the call graph of a
+// source scan is built, which are the surface considered reachable. They
+// are written to the file named by the -entry-functions flag, whether or
+// not any vulnerability is found, so that the roots of the analysis can be
+// reviewed before its findings are trusted.
+type entryFunctions struct {
+	Functions []*entryFunction `json:"functions"`
+}
+
+// entryFunction identifies an entry function by its package, name, and
+// receiver, as in findings, and the position of its declaration, if
+// known.
+type entryFunction struct {
+	Package  string                `json:"package"`
+	Function string                `json:"function"`
+	Receiver string                `json:"receiver,omitempty"`
+	Position *govulncheck.Position `json:"position,omitempty"`
+}
+
+// newEntryFunctions returns the entry functions of vr, sorted by package,
+// receiver, and name, with positions reported relative to base.
+func newEntryFunctions(vr *vulncheck.Result, base string) *entryFunctions {
+	ef := &entryFunctions{Functions: []*entryFunction{}}
+	for _, fn := range vr.AllEntryFunctions {
+		e := &entryFunction{Function: fn.Name, Position: position(fn.Position(), base)}
+		if fn.Package != nil {
+			e.Package = fn.Package.PkgPath
+			e.Receiver = fn.Receiver()
+		}
+		ef.Functions = append(ef.Functions, e)
+	}
+	sort.SliceStable(ef.Functions, func(i, j int) bool {
+		fi, fj := ef.Functions[i], ef.Functions[j]
+		if fi.Package != fj.Package {
+			return fi.Package < fj.Package
+		}
+		if fi.Receiver != fj.Receiver {
+			return fi.Receiver < fj.Receiver
+		}
+		return fi.Function < fj.Function
+	})
+	return ef
+}
+
+// writeEntryFunctions writes ef to path.
+func writeEntryFunctions(path string, ef *entryFunctions) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ef); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0666); err != nil {
+		return fmt.Errorf("writing entry functions: %v", err)
+	}
+	return nil
+}
+
+// applyEntryFunctions writes the entry functions of vr to the file named
+// by the -entry-functions flag of cfg, if any.
+func applyEntryFunctions(cfg *config, vr *vulncheck.Result) error {
+	if cfg.entryFunctions == "" {
+		return nil
+	}
+	base := filepath.FromSlash(cfg.dir)
+	if err := writeEntryFunctions(absPath(cfg.entryFunctions, base), newEntryFunctions(vr, cfg.posBase)); err != nil {
+		return fmt.Errorf("govulncheck: %v", err)
+	}
+	return nil
+}
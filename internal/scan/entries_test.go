@@ -0,0 +1,63 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"encoding/json"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+func TestApplyEntryFunctions(t *testing.T) {
+	dir := t.TempDir()
+	main := &packages.Package{PkgPath: "golang.org/entry"}
+	lib := &packages.Package{PkgPath: "golang.org/entry/lib"}
+	pos := &token.Position{Filename: filepath.Join(dir, "main.go"), Offset: 30, Line: 3, Column: 6}
+	vr := &vulncheck.Result{AllEntryFunctions: []*vulncheck.FuncNode{
+		{Name: "Get", RecvType: "*golang.org/entry/lib.Client", Package: lib},
+		{Name: "main", Package: main, Pos: pos},
+		{Name: "Parse", Package: lib},
+	}}
+
+	cfg := &config{dir: dir, entryFunctions: "entries.json", posBase: dir}
+	if err := applyEntryFunctions(cfg, vr); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "entries.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &entryFunctions{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	want := &entryFunctions{Functions: []*entryFunction{
+		{Package: "golang.org/entry", Function: "main", Position: &govulncheck.Position{Filename: "main.go", Offset: 30, Line: 3, Column: 6}},
+		{Package: "golang.org/entry/lib", Function: "Parse"},
+		{Package: "golang.org/entry/lib", Function: "Get", Receiver: "*Client"},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Without entry functions, an empty list is written.
+	if err := applyEntryFunctions(cfg, &vulncheck.Result{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "entries.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "{\n  \"functions\": []\n}\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"go/token"
+	"strings"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// entryAPI returns the kind of API through which trace, ordered from the
+// vulnerable symbol to the entry point, enters the vulnerable module: that
+// of the first frame of the module of trace[0] counted from the entry.
+func entryAPI(trace []*govulncheck.Frame) govulncheck.EntryAPI {
+	if len(trace) == 0 || trace[0].Function == "" {
+		return ""
+	}
+	mod := trace[0].Module
+	for i := len(trace) - 1; i >= 0; i-- {
+		if fr := trace[i]; fr.Module == mod {
+			if isExportedFrame(fr) {
+				return govulncheck.EntryAPIExported
+			}
+			return govulncheck.EntryAPIInternal
+		}
+	}
+	return ""
+}
+
+// isExportedFrame reports whether the function of fr is part of the API of
+// its module: an exported function, or a method of an exported type, of a
+// package that is not internal. The functions of the closures of exported
+// functions, named "F$1" by ssa, count as exported, as they are returned
+// by them.
+func isExportedFrame(fr *govulncheck.Frame) bool {
+	if isInternalPackage(fr.Package) || !token.IsExported(fr.Function) {
+		return false
+	}
+	return fr.Receiver == "" || token.IsExported(strings.TrimPrefix(fr.Receiver, "*"))
+}
+
+// isInternalPackage reports whether the package path has an "internal"
+// element, which restricts its importers to the tree of its parent.
+func isInternalPackage(path string) bool {
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "internal" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+func TestEntryAPI(t *testing.T) {
+	main := &govulncheck.Frame{Module: "golang.org/main", Package: "golang.org/main", Function: "main"}
+	sink := &govulncheck.Frame{Module: "golang.org/vmod", Package: "golang.org/vmod/vuln", Function: "parse"}
+	frame := func(pkg, fn, recv string) *govulncheck.Frame {
+		return &govulncheck.Frame{Module: "golang.org/vmod", Package: pkg, Function: fn, Receiver: recv}
+	}
+	for _, test := range []struct {
+		name  string
+		trace []*govulncheck.Frame
+		want  govulncheck.EntryAPI
+	}{
+		{"imported", []*govulncheck.Frame{{Module: "golang.org/vmod", Package: "golang.org/vmod/vuln"}}, ""},
+		{"function", []*govulncheck.Frame{sink, frame("golang.org/vmod/vuln", "Parse", ""), main}, govulncheck.EntryAPIExported},
+		{"method", []*govulncheck.Frame{sink, frame("golang.org/vmod/vuln", "Get", "*Client"), main}, govulncheck.EntryAPIExported},
+		{"closure", []*govulncheck.Frame{sink, frame("golang.org/vmod/vuln", "Handler$1", ""), main}, govulncheck.EntryAPIExported},
+		{"unexported receiver", []*govulncheck.Frame{sink, frame("golang.org/vmod/vuln", "Read", "*reader"), main}, govulncheck.EntryAPIInternal},
+		{"internal package", []*govulncheck.Frame{sink, frame("golang.org/vmod/internal/wire", "Decode", ""), main}, govulncheck.EntryAPIInternal},
+		{"sink", []*govulncheck.Frame{sink, main}, govulncheck.EntryAPIInternal},
+		// Only the first frame of the module from the entry point counts.
+		{"callback", []*govulncheck.Frame{
+			sink, frame("golang.org/vmod/vuln", "walk", ""), {Module: "golang.org/main", Package: "golang.org/main", Function: "visit"},
+			frame("golang.org/vmod/vuln", "Walk", ""), main,
+		}, govulncheck.EntryAPIExported},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := entryAPI(test.trace); got != test.want {
+				t.Errorf("got %q; want %q", got, test.want)
+			}
+		})
+	}
+}
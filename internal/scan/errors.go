@@ -6,7 +6,11 @@ package scan
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
 )
 
 //lint:file-ignore ST1005 Ignore staticcheck message about error formatting
@@ -31,6 +35,13 @@ var (
 used to build govulncheck and the Go version on PATH. Consider rebuilding
 govulncheck with the current Go version.`)
 
+	// errGoSumMismatch is used to indicate that loading packages failed
+	// because go.sum is missing entries or does not match the module graph.
+	errGoSumMismatch = errors.New(`Loading packages failed because go.sum is incomplete or out of date, so some
+dependencies could not be resolved and cannot be analyzed. Run go mod download
+to add missing go.sum entries, or go mod tidy to bring go.mod and go.sum in
+sync with your code, and then run govulncheck again.`)
+
 	// errNoGoMod indicates that a go.mod file was not found in this module.
 	errNoGoMod = errors.New(`no go.mod file
 
@@ -65,3 +76,65 @@ func isGoVersionMismatchError(err error) bool {
 	return strings.Contains(msg, "This application uses version go") &&
 		strings.Contains(msg, "It may fail to process source files")
 }
+
+// isGoSumError checks if err is due to go.sum missing entries for, or
+// having mismatched checksums of, modules in the build graph.
+func isGoSumError(err error) bool {
+	msg := err.Error()
+	// See cmd/go/internal/modload and cmd/go/internal/modfetch.
+	return strings.Contains(msg, "missing go.sum entry") ||
+		strings.Contains(msg, "updates to go.sum needed") ||
+		(strings.Contains(msg, "checksum mismatch") && strings.Contains(msg, "go.sum"))
+}
+
+// goSumErrors returns the errors of pkgs and their dependencies that are
+// due to go.sum, as told by isGoSumError. The packages that cannot be
+// resolved because of them are not loaded, which causes type errors in
+// their importers, but the other packages are. It returns nil if some
+// other kind of error occurred, so that loading failed for other reasons.
+func goSumErrors(pkgs []*packages.Package) []packages.Error {
+	var errs []packages.Error
+	other := false
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			switch {
+			case isGoSumError(err):
+				errs = append(errs, err)
+			case err.Kind != packages.TypeError:
+				other = true
+			}
+		}
+	})
+	if other {
+		return nil
+	}
+	return errs
+}
+
+// goSumReason returns the reason why a source scan whose loading of
+// packages had the go.sum errors errs is incomplete, or nil if there are
+// none.
+func goSumReason(errs []packages.Error) *govulncheck.IncompleteReason {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &govulncheck.IncompleteReason{
+		Kind:    govulncheck.IncompleteGoSum,
+		Message: fmt.Sprintf("go.sum is incomplete or out of date, so %d %s could not be resolved", len(errs), choose(len(errs) == 1, "import", "imports")),
+	}
+}
+
+// goSumWarning returns the warning about the go.sum errors errs, which
+// caused some packages not to be loaded.
+func goSumWarning(errs []packages.Error) string {
+	var b strings.Builder
+	b.WriteString(`Warning: go.sum is incomplete or out of date, so some dependencies could not be
+resolved and their vulnerabilities are not reported. Run go mod download to add
+missing go.sum entries, or go mod tidy to bring go.mod and go.sum in sync with
+your code, and then run govulncheck again.
+`)
+	for _, err := range errs {
+		fmt.Fprintf(&b, "\n%v", err)
+	}
+	return b.String()
+}
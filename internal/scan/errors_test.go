@@ -0,0 +1,99 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/packages/packagestest"
+	"golang.org/x/vuln/internal/testenv"
+)
+
+func TestIsGoSumError(t *testing.T) {
+	for _, test := range []struct {
+		msg  string
+		want bool
+	}{
+		{"main.go:3:8: missing go.sum entry for module providing package golang.org/x/text/language (imported by example.com/m); to add:\n\tgo get example.com/m", true},
+		{"go: updates to go.sum needed, disabled by -mod=readonly", true},
+		{"verifying golang.org/x/text@v0.3.0: checksum mismatch\n\tdownloaded: h1:g61t\n\tgo.sum:     h1:AAAA", true},
+		{"-: package foo is not in GOROOT (/tmp/foo)", false},
+	} {
+		if got := isGoSumError(errors.New(test.msg)); got != test.want {
+			t.Errorf("isGoSumError(%q) = %t; want %t", test.msg, got, test.want)
+		}
+	}
+}
+
+func TestGoSumErrors(t *testing.T) {
+	testenv.NeedsGoBuild(t)
+
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			import (
+				_ "golang.org/amod/a"
+				_ "golang.org/bmod/b"
+			)
+			`,
+			},
+		},
+		{
+			Name:  "golang.org/amod@v0.5.0",
+			Files: map[string]interface{}{"a/a.go": "package a"},
+		},
+		{
+			Name:  "golang.org/bmod@v0.5.0",
+			Files: map[string]interface{}{"b/b.go": "package b"},
+		},
+	})
+	defer e.Cleanup()
+
+	// Drop the go.sum entries of golang.org/bmod.
+	sumFile := filepath.Join(e.Config.Dir, "go.sum")
+	data, err := os.ReadFile(sumFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if !strings.HasPrefix(line, "golang.org/bmod ") {
+			lines = append(lines, line)
+		}
+	}
+	if err := os.WriteFile(sumFile, []byte(strings.Join(lines, "")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e.Config.Env = append(e.Config.Env, "GOFLAGS=-mod=readonly")
+	e.Config.Mode = packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedTypes
+	pkgs, err := packages.Load(e.Config, "golang.org/entry/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := goSumErrors(pkgs)
+	if len(errs) != 1 || !strings.Contains(errs[0].Msg, "golang.org/bmod/b") {
+		t.Fatalf("got go.sum errors %v, want one for golang.org/bmod/b", errs)
+	}
+	// The packages of golang.org/amod are still loaded.
+	if a := pkgs[0].Imports["golang.org/amod/a"]; a == nil || len(a.Errors) > 0 || a.Types == nil {
+		t.Errorf("golang.org/amod/a was not loaded")
+	}
+
+	// Errors not due to go.sum make loading fail.
+	pkgs[0].Errors = append(pkgs[0].Errors, packages.Error{Msg: "syntax error", Kind: packages.ParseError})
+	if errs := goSumErrors(pkgs); errs != nil {
+		t.Errorf("got go.sum errors %v along with a parse error, want none", errs)
+	}
+}
@@ -10,23 +10,169 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/vuln/internal/govulncheck"
+	isem "golang.org/x/vuln/internal/semver"
 )
 
 type config struct {
 	govulncheck.Config
-	patterns []string
-	mode     string
-	db       string
-	json     bool
-	dir      string
-	tags     []string
-	test     bool
-	show     []string
-	env      []string
+	patterns  []string
+	mode      string
+	db        string
+	dbOverlay string
+	json      bool
+	format    string
+	count     bool
+	dir       string
+	tags      []string
+	test      bool
+	// testNoFail is set when test files are analyzed, but
+	// vulnerabilities only called from tests do not cause failure.
+	testNoFail bool
+	show       []string
+	env        []string
+	relPath    string
+	overlay    string
+	baseline   string
+	surface    bool
+	accept     bool
+	worst      bool
+	merge      bool
+	timing     bool
+	tools      bool
+	// flush tells when JSON output is flushed to stdout.
+	flush govulncheck.FlushPolicy
+	// traceOrder is the -trace-order flag, and order the order it sets
+	// for the traces of JSON output, which is empty by default.
+	traceOrder string
+	order      govulncheck.TraceOrder
+	sortBy     string
+	// posFormat is the format of the positions of text output, one of
+	// colon, paren, and vscode, or "" for colon.
+	posFormat  string
+	internal   string
+	archive    string
+	archiveDir string
+
+	// confidence is the threshold of the -confidence flag, or nil if
+	// it is not set. Called findings whose call stacks all have a
+	// higher confidence score are likely false positives, which are
+	// reported as imported if confidenceDrop is set.
+	confidence     *int
+	confidenceDrop bool
+
+	// assumeCalled are the comma-separated glob patterns of the module
+	// paths whose imported vulnerabilities are treated as called.
+	assumeCalled string
+
+	// testHelpers are the comma-separated glob patterns of the paths of
+	// the test helper packages, whose exported functions are entry
+	// points and whose call stacks are test-only.
+	testHelpers string
+
+	// severityMap is the file mapping findings to the severities of the
+	// organization, and severity the mapper in use, which is nil if
+	// findings are not mapped.
+	severityMap string
+	severity    govulncheck.SeverityMapper
+
+	// goVersion is the Go version of the -go-version flag, against which
+	// the standard library is matched instead of the version of the
+	// toolchain, toolchainVersion, which loads packages.
+	goVersion        string
+	toolchainVersion string
+
+	// tryUpgrades are the module@version upgrades of the -try-upgrade
+	// flag, and modfile the go.mod file where they are applied, which
+	// replaces the go.mod file of the scanned module if it is set.
+	tryUpgrades []string
+	modfile     string
+
+	// maxHops is the maximum number of calls from an entry point to a
+	// vulnerable symbol of the representative call stacks of called
+	// findings, or 0 if the call stacks are not limited.
+	maxHops int
+
+	// changed are the symbols of the -changed flag, as in "pkg.T.Method".
+	// If set, vulnerabilities are only reported as called if some of
+	// their call stacks passes through one of them.
+	changed []string
+
+	// verify is set if the traces of called findings are checked against
+	// the call graph by a second, independent pass, which reports the
+	// findings it cannot corroborate.
+	verify bool
+
+	// withoutCalls are the comma-separated glob patterns of the paths of
+	// the packages whose outgoing calls are pruned from the call graph to
+	// report which vulnerabilities would no longer be called.
+	withoutCalls string
+
+	// repo is the version control revision of the -repo-url, -repo-commit,
+	// and -repo-branch flags, which is also recorded on each finding if
+	// repoFindings is set.
+	repo         govulncheck.Repository
+	repoFindings bool
+
+	// excludeStdlib is set if the findings in the standard library are
+	// only counted instead of being reported.
+	excludeStdlib bool
+
+	// matchPlatform is set if the vulnerabilities that only affect other
+	// platforms than the one the go command loads packages for are
+	// reported as not applicable in source mode.
+	matchPlatform bool
+
+	// separateTestDeps is set if the findings in the modules that are
+	// only dependencies of tests are written apart from the others.
+	separateTestDeps bool
+
+	// reachabilitySnapshot and reachabilityDiff are the files to which
+	// the reachability of vulnerable symbols is written, and with which
+	// it is compared.
+	reachabilitySnapshot string
+	reachabilityDiff     string
+
+	// entryFunctions is the file to which the entry functions of the
+	// call graph are written.
+	entryFunctions string
+
+	// binaryFile is the binary built from the scanned code against which
+	// the vulnerable symbols called in the source are checked.
+	binaryFile string
+
+	// timeout is the wall-clock budget of a source scan, past which the
+	// analysis stops and its partial results are reported. There is no
+	// budget if it is 0.
+	timeout time.Duration
+
+	// firstSeen is set if the baseline records the date each finding
+	// was first seen, and accepted findings are reported with it. The
+	// date of the findings first seen by the scan is that of now.
+	firstSeen bool
+	now       func() time.Time
+
+	// maxDBAge is the maximum age of the vulnerability database, past
+	// which the scan fails, or only warns if dbAgeWarn is set. The age
+	// is not checked if it is 0.
+	maxDBAge  time.Duration
+	dbAgeWarn bool
+
+	hooks Hooks
+
+	// download reports the downloads of the vulnerability database. It
+	// is nil if they are not reported.
+	download *downloadReporter
+
+	// posBase is the directory that source positions are reported
+	// relative to. It is resolved from relPath when scanning source
+	// and empty when positions are reported as absolute paths.
+	posBase string
 }
 
 const (
@@ -39,15 +185,70 @@ const (
 func parseFlags(cfg *config, stderr io.Writer, args []string) error {
 	var tagsFlag buildutil.TagsFlag
 	var showFlag showFlag
+	var osvFlag osvFlag
+	var tryUpgradeFlag tryUpgradeFlag
+	var goVersionsFlag goVersionsFlag
 	flags := flag.NewFlagSet("", flag.ContinueOnError)
 	flags.SetOutput(stderr)
-	flags.BoolVar(&cfg.json, "json", false, "output JSON")
-	flags.BoolVar(&cfg.test, "test", false, "analyze test files (only valid for source mode)")
+	flags.BoolVar(&cfg.json, "json", false, "output JSON (same as -format=json)")
+	flags.StringVar(&cfg.format, "format", formatText, "specify the output format, one of text, json, github, dot, cyclonedx, ids, or sarif")
+	flags.StringVar(&cfg.archive, "archive", "", "scan the source in the zip or tar `file`, or read from stdin if file is - (only valid for source mode)")
+	flags.StringVar(&cfg.archiveDir, "archive-dir", "", "scan the module in `dir` of the -archive file")
+	flags.BoolVar(&cfg.accept, "accept", false, "record the current findings as accepted in the -baseline file")
+	flags.StringVar(&cfg.binaryFile, "binary", "", "only report vulnerable symbols as called if they are also compiled into the binary `file` built from the scanned code (only valid for source mode)")
+	flags.StringVar(&cfg.baseline, "baseline", "", "only report findings that are not accepted in the baseline `file`")
+	flags.BoolVar(&cfg.firstSeen, "first-seen", false, "record the date each finding is first seen in the -baseline file, and report accepted findings with that date")
+	flags.Var(&confidenceFlag{cfg}, "confidence", "mark called findings whose call stacks all go through more than `n` standard library functions as likely false positives, or report them as imported with n,drop (only valid for source mode)")
+	flags.BoolVar(&cfg.count, "count", false, "output only the number of called, imported, and total vulnerabilities")
+	flags.Var(&testFlag{cfg}, "test", "analyze test files, or set to nofail to analyze test files without failing on vulnerabilities only called from tests (only valid for source mode)")
+	flags.IntVar(&cfg.ModuleDepth, "depth", 0, "only scan modules at most `n` dependencies away from the main module, or all modules if n is 0 (only valid for source mode)")
 	flags.StringVar(&cfg.dir, "C", "", "change to `dir` before running govulncheck")
 	flags.StringVar(&cfg.db, "db", "https://vuln.go.dev", "vulnerability database `url`")
+	flags.StringVar(&cfg.goVersion, "go-version", "", "match standard library vulnerabilities against Go `version`, such as go1.20.3, instead of the version of the go command (only valid for source mode)")
+	flags.Var(&maxAgeFlag{&cfg.maxDBAge}, "max-db-age", "fail if the vulnerability database was last modified more than `age` ago, such as 7d or 36h, or never if age is 0")
+	flags.BoolVar(&cfg.dbAgeWarn, "db-age-warn", false, "only warn instead of failing when the vulnerability database is older than the -max-db-age")
+	flags.StringVar(&cfg.dbOverlay, "db-overlay", "", "add the OSV entries in `dir` to the vulnerability database, replacing entries with the same ID")
 	flags.StringVar(&cfg.mode, "mode", modeSource, "supports source or binary")
 	flags.Var(&tagsFlag, "tags", "comma-separated `list` of build tags")
 	flags.Var(&showFlag, "show", "enable display of additional information specified by `list`")
+	flags.Var(&osvFlag, "osv", "only scan for the vulnerabilities in `list`, a comma-separated list of OSV IDs or aliases")
+	flags.StringVar(&cfg.overlay, "overlay", "", "read a build overlay from `file`, as for go build -overlay (only valid for source mode)")
+	flags.BoolVar(&cfg.surface, "surface", false, "report how many exported functions of each vulnerable module are used (only valid for source mode)")
+	flags.BoolVar(&cfg.excludeStdlib, "exclude-stdlib", false, "only count the vulnerabilities of the Go standard library instead of reporting them")
+	flags.BoolVar(&cfg.matchPlatform, "match-platform", false, "report the vulnerabilities that only affect other platforms than GOOS and GOARCH as not applicable (only valid for source mode)")
+	flags.StringVar(&cfg.internal, "internal", "", "mark findings in modules matching the comma-separated glob `patterns` as internal, as for GOPRIVATE")
+	flags.StringVar(&cfg.assumeCalled, "assume-called", "", "treat the vulnerabilities imported from modules matching the comma-separated glob `patterns` as called, as for GOPRIVATE")
+	flags.BoolVar(&cfg.separateTestDeps, "separate-test-deps", false, "report the vulnerabilities of modules that are only dependencies of tests in a section of their own (requires -test)")
+	flags.StringVar(&cfg.testHelpers, "test-helpers", "", "analyze the exported functions of the packages matching the comma-separated glob `patterns` as test entry points (only valid for source mode)")
+	flags.StringVar(&cfg.severityMap, "severity-map", "", "assign the severities of your organization to findings by the rules in `file`")
+	flags.IntVar(&cfg.flush.Bytes, "flush-bytes", 0, "buffer JSON output and write it once at least `n` bytes are buffered (only valid for JSON output)")
+	flags.IntVar(&cfg.flush.Messages, "flush-messages", 0, "buffer JSON output and write it once `n` messages are buffered (only valid for JSON output)")
+	flags.StringVar(&cfg.traceOrder, "trace-order", "", "report JSON traces starting from the vulnerable symbol if `order` is sink, or from the entry point if it is entry (only valid for JSON output)")
+	flags.Var((*symbolsFlag)(&cfg.changed), "changed", "only report vulnerabilities as called if they are reached through one of the changed functions, a comma-separated list of `symbols` such as pkg.Func or pkg.T.Method (only valid for source mode)")
+	flags.BoolVar(&cfg.verify, "verify", false, "check the trace of each called finding against the call graph in a second, independent pass, and warn about the findings it cannot corroborate (only valid for source mode)")
+	flags.IntVar(&cfg.maxHops, "max-hops", 0, "only report vulnerabilities as called if they are reached within `n` calls of an entry point, or at any depth if n is 0 (only valid for source mode)")
+	flags.StringVar(&cfg.withoutCalls, "without-calls", "", "report which vulnerabilities would no longer be called if the packages matching the comma-separated glob `patterns` made no calls (only valid for source mode)")
+	flags.BoolVar(&cfg.merge, "merge", false, "merge findings whose traces only differ by positions")
+	flags.DurationVar(&cfg.timeout, "timeout", 0, "stop the analysis after `duration` and report its partial results, marked as incomplete (only valid for source mode)")
+	flags.BoolVar(&cfg.timing, "timing", false, "report the time spent in each phase of the analysis (only valid for source mode)")
+	flags.BoolVar(&cfg.tools, "tools", false, "also scan the build-time tools referenced by tool and go:generate directives (only valid for source mode)")
+	flags.BoolVar(&cfg.worst, "worst", false, "only report the most severe finding of each module")
+	flags.StringVar(&cfg.reachabilitySnapshot, "reachability-snapshot", "", "write the calls that reach vulnerable symbols to `file` (only valid for source mode)")
+	flags.StringVar(&cfg.reachabilityDiff, "reachability-diff", "", "report how the vulnerable symbols reached and their calls changed since the snapshot in `file` (only valid for source mode)")
+	flags.StringVar(&cfg.entryFunctions, "entry-functions", "", "write the package, name, and position of each entry function of the call graph to `file`, whether or not vulnerabilities are found (only valid for source mode)")
+	flags.Var(&tryUpgradeFlag, "try-upgrade", "report how the findings would change with the comma-separated module@version upgrades in `list` (only valid for source mode)")
+	flags.StringVar(&cfg.repo.URL, "repo-url", "", "record `url` as the repository of the scanned code in the output")
+	flags.StringVar(&cfg.repo.Commit, "repo-commit", "", "record `commit` as the commit of the scanned code in the output")
+	flags.StringVar(&cfg.repo.Branch, "repo-branch", "", "record `branch` as the branch of the scanned code in the output")
+	flags.BoolVar(&cfg.repoFindings, "repo-findings", false, "also record the repository of the scanned code on each finding")
+	flags.StringVar(&cfg.Representative, "representative", "", "pick the representative call stack of called findings by `strategy`, one of first, shortest, static, or main (only valid for source mode)")
+	flags.Var(&goVersionsFlag, "go-versions", "match standard library vulnerabilities against each Go version in `list`, a comma-separated list such as go1.20.12,go1.21.5, and report the affected versions (only valid for source mode)")
+	flags.Var((*symbolsFlag)(&cfg.EntrySymbols), "entry-symbols", "only report vulnerabilities as called if they are reached through the direct calls of the binary from one of the comma-separated `symbols`, such as main.serve (only valid for binary mode)")
+	flags.BoolVar(&cfg.DispatchTables, "dispatch-tables", false, "assume that functions stored in maps and slices are called by dynamic calls of the same signature, and label such findings as assumed (only valid for source mode)")
+	flags.BoolVar(&cfg.Sequential, "sequential", false, "run the analysis on a single goroutine, in a reproducible order, for profiling (only valid for source mode)")
+	flags.StringVar(&cfg.sortBy, "sort", "", "sort findings by `order`; effort reports the findings that are easiest to fix first")
+	flags.StringVar(&cfg.posFormat, "position-format", "", "write the positions of text output in `format`, one of colon for file:line:col, paren for file(line,col), vscode for vscode://file links, or offset for file:line:col:#offset with the byte offset")
+	flags.StringVar(&cfg.relPath, "relpath", "", "report source positions relative to `dir`, or to the main module root if dir is \"module\"")
 	scanLevel := flags.String("scan-level", "symbol", "set the scanning level desired, one of module, package or symbol")
 	flags.Usage = func() {
 		fmt.Fprint(flags.Output(), `Govulncheck reports known vulnerabilities in dependencies.
@@ -74,7 +275,22 @@ Usage:
 	}
 	cfg.tags = tagsFlag
 	cfg.show = showFlag
+	cfg.OSVs = osvFlag
+	cfg.tryUpgrades = tryUpgradeFlag
+	cfg.GoVersions = goVersionsFlag
 	cfg.ScanLevel = govulncheck.ScanLevel(*scanLevel)
+	cfg.AllEntryFunctions = cfg.entryFunctions != ""
+	if cfg.repo != (govulncheck.Repository{}) {
+		cfg.Repository = &cfg.repo
+	}
+	if cfg.json {
+		if cfg.format != formatText && cfg.format != formatJSON {
+			fmt.Fprintf(flags.Output(), "the -json flag cannot be combined with -format=%s\n", cfg.format)
+			return errUsage
+		}
+		cfg.format = formatJSON
+	}
+	cfg.json = cfg.format == formatJSON
 	if err := validateConfig(cfg); err != nil {
 		fmt.Fprintln(flags.Output(), err)
 		return errUsage
@@ -82,6 +298,26 @@ Usage:
 	return nil
 }
 
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatGitHub = "github"
+	formatDOT    = "dot"
+	formatCDX    = "cyclonedx"
+	formatIDs    = "ids"
+	formatSARIF  = "sarif"
+)
+
+var supportedFormats = map[string]bool{
+	formatText:   true,
+	formatJSON:   true,
+	formatGitHub: true,
+	formatDOT:    true,
+	formatCDX:    true,
+	formatIDs:    true,
+	formatSARIF:  true,
+}
+
 var supportedModes = map[string]bool{
 	modeSource:  true,
 	modeBinary:  true,
@@ -93,21 +329,108 @@ func validateConfig(cfg *config) error {
 	if _, ok := supportedModes[cfg.mode]; !ok {
 		return fmt.Errorf("%q is not a valid mode", cfg.mode)
 	}
+	if _, ok := supportedFormats[cfg.format]; !ok {
+		return fmt.Errorf("%q is not a valid format", cfg.format)
+	}
+	if cfg.ModuleDepth < 0 {
+		return fmt.Errorf("the -depth flag must not be negative")
+	}
+	if cfg.maxHops < 0 {
+		return fmt.Errorf("the -max-hops flag must not be negative")
+	}
+	if cfg.flush.Bytes < 0 {
+		return fmt.Errorf("the -flush-bytes flag must not be negative")
+	}
+	if cfg.flush.Messages < 0 {
+		return fmt.Errorf("the -flush-messages flag must not be negative")
+	}
+	if cfg.timeout < 0 {
+		return fmt.Errorf("the -timeout flag must not be negative")
+	}
+	if v := cfg.goVersion; v != "" {
+		if !strings.HasPrefix(v, "go") {
+			cfg.goVersion = "go" + v
+		}
+		if isem.GoTagToSemver(cfg.goVersion) == "" {
+			return fmt.Errorf("%q is not a valid Go version", v)
+		}
+	}
+	for i, v := range cfg.GoVersions {
+		if !strings.HasPrefix(v, "go") {
+			cfg.GoVersions[i] = "go" + v
+		}
+		if isem.GoTagToSemver(cfg.GoVersions[i]) == "" {
+			return fmt.Errorf("%q is not a valid Go version", v)
+		}
+	}
+	switch cfg.traceOrder {
+	case "":
+	case traceOrderSink:
+		cfg.order = govulncheck.TraceSinkFirst
+	case traceOrderEntry:
+		cfg.order = govulncheck.TraceEntryFirst
+	default:
+		return fmt.Errorf("%q is not a valid trace order", cfg.traceOrder)
+	}
+	if cfg.accept && cfg.baseline == "" {
+		return fmt.Errorf("the -accept flag requires the -baseline flag")
+	}
+	if cfg.firstSeen && cfg.baseline == "" {
+		return fmt.Errorf("the -first-seen flag requires the -baseline flag")
+	}
+	if cfg.separateTestDeps && !cfg.test {
+		return fmt.Errorf("the -separate-test-deps flag requires the -test flag")
+	}
+	if cfg.dbAgeWarn && cfg.maxDBAge == 0 {
+		return fmt.Errorf("the -db-age-warn flag requires the -max-db-age flag")
+	}
+	if cfg.repoFindings && cfg.Repository == nil {
+		return fmt.Errorf("the -repo-findings flag requires the -repo-url, -repo-commit, or -repo-branch flag")
+	}
+	if cfg.archiveDir != "" && cfg.archive == "" {
+		return fmt.Errorf("the -archive-dir flag requires the -archive flag")
+	}
+	switch cfg.posFormat {
+	case "", posFormatColon, posFormatParen, posFormatVSCode, posFormatOffset:
+	default:
+		return fmt.Errorf("%q is not a valid position format", cfg.posFormat)
+	}
+	if cfg.sortBy != "" && cfg.sortBy != sortEffort {
+		return fmt.Errorf("%q is not a valid sort order", cfg.sortBy)
+	}
+	if r := cfg.Representative; r != "" && !supportedRepresentatives[r] {
+		return fmt.Errorf("%q is not a valid representative call stack strategy", r)
+	}
+	if cfg.Representative == representativeFirst {
+		// The default strategy is not recorded.
+		cfg.Representative = ""
+	}
+	for _, f := range flagSupports {
+		if !f.isSet(cfg) {
+			continue
+		}
+		if len(f.modes) > 0 && !contains(f.modes, cfg.mode) {
+			return fmt.Errorf("the -%s flag is not supported in %s mode", f.name, cfg.mode)
+		}
+		if len(f.formats) > 0 && !contains(f.formats, cfg.format) {
+			return fmt.Errorf("the -%s flag is not supported for %s output", f.name, formatName(cfg.format))
+		}
+	}
+	if cfg.count && len(cfg.show) > 0 {
+		return fmt.Errorf("the -show flag is not supported for count output")
+	}
 	switch cfg.mode {
 	case modeSource:
 		if len(cfg.patterns) == 1 && isFile(cfg.patterns[0]) {
 			return fmt.Errorf("%q is a file.\n\n%v", cfg.patterns[0], errNoBinaryFlag)
 		}
 	case modeBinary:
-		if cfg.test {
-			return fmt.Errorf("the -test flag is not supported in binary mode")
-		}
-		if len(cfg.tags) > 0 {
-			return fmt.Errorf("the -tags flag is not supported in binary mode")
-		}
 		if len(cfg.patterns) != 1 {
 			return fmt.Errorf("only 1 binary can be analyzed at a time")
 		}
+		if len(cfg.EntrySymbols) > 0 && !cfg.ScanLevel.WantSymbols() {
+			return fmt.Errorf("the -entry-symbols flag requires -scan-level=symbol")
+		}
 		if !isFile(cfg.patterns[0]) {
 			return fmt.Errorf("%q is not a file", cfg.patterns[0])
 		}
@@ -115,22 +438,7 @@ func validateConfig(cfg *config) error {
 		if len(cfg.patterns) != 0 {
 			return fmt.Errorf("patterns are not accepted in convert mode")
 		}
-		if cfg.dir != "" {
-			return fmt.Errorf("the -C flag is not supported in convert mode")
-		}
-		if cfg.test {
-			return fmt.Errorf("the -test flag is not supported in convert mode")
-		}
-		if len(cfg.tags) > 0 {
-			return fmt.Errorf("the -tags flag is not supported in convert mode")
-		}
 	case modeQuery:
-		if cfg.test {
-			return fmt.Errorf("the -test flag is not supported in query mode")
-		}
-		if len(cfg.tags) > 0 {
-			return fmt.Errorf("the -tags flag is not supported in query mode")
-		}
 		if !cfg.json {
 			return fmt.Errorf("the -json flag must be set in query mode")
 		}
@@ -142,12 +450,102 @@ func validateConfig(cfg *config) error {
 			}
 		}
 	}
-	if cfg.json && len(cfg.show) > 0 {
-		return fmt.Errorf("the -show flag is not supported for JSON output")
-	}
 	return nil
 }
 
+// A flagSupport tells in which modes and for which output formats a flag
+// is supported.
+type flagSupport struct {
+	// name is the name of the flag, without the leading dash.
+	name string
+
+	// isSet reports whether the flag is set in cfg.
+	isSet func(cfg *config) bool
+
+	// modes and formats are the modes and formats that support the
+	// flag, or all of them if empty.
+	modes   []string
+	formats []string
+}
+
+var (
+	// sourceModes only analyze source code, and binaryModes binaries.
+	sourceModes = []string{modeSource}
+	binaryModes = []string{modeBinary}
+
+	// scanModes are the modes that report the findings of a scan.
+	scanModes = []string{modeSource, modeBinary}
+
+	// dbModes are the modes that read the vulnerability database.
+	dbModes = []string{modeSource, modeBinary, modeQuery}
+
+	textFormats = []string{formatText}
+	jsonFormats = []string{formatJSON}
+)
+
+// flagSupports are the flags that are only supported in some modes or for
+// some output formats, sorted by name. The flags that are not listed are
+// supported everywhere.
+var flagSupports = []flagSupport{
+	{name: "C", isSet: func(cfg *config) bool { return cfg.dir != "" }, modes: dbModes},
+	{name: "archive", isSet: func(cfg *config) bool { return cfg.archive != "" }, modes: sourceModes},
+	{name: "assume-called", isSet: func(cfg *config) bool { return cfg.assumeCalled != "" }, modes: scanModes},
+	{name: "baseline", isSet: func(cfg *config) bool { return cfg.baseline != "" }, modes: scanModes},
+	{name: "binary", isSet: func(cfg *config) bool { return cfg.binaryFile != "" }, modes: sourceModes},
+	{name: "changed", isSet: func(cfg *config) bool { return len(cfg.changed) > 0 }, modes: sourceModes},
+	{name: "confidence", isSet: func(cfg *config) bool { return cfg.confidence != nil }, modes: sourceModes},
+	{name: "count", isSet: func(cfg *config) bool { return cfg.count }, formats: textFormats},
+	{name: "db-overlay", isSet: func(cfg *config) bool { return cfg.dbOverlay != "" }, modes: dbModes},
+	{name: "depth", isSet: func(cfg *config) bool { return cfg.ModuleDepth != 0 }, modes: sourceModes},
+	{name: "dispatch-tables", isSet: func(cfg *config) bool { return cfg.DispatchTables }, modes: sourceModes},
+	{name: "entry-functions", isSet: func(cfg *config) bool { return cfg.entryFunctions != "" }, modes: sourceModes},
+	{name: "entry-symbols", isSet: func(cfg *config) bool { return len(cfg.EntrySymbols) > 0 }, modes: binaryModes},
+	{name: "exclude-stdlib", isSet: func(cfg *config) bool { return cfg.excludeStdlib }, modes: scanModes},
+	{name: "flush-bytes", isSet: func(cfg *config) bool { return cfg.flush.Bytes > 0 }, modes: dbModes, formats: jsonFormats},
+	{name: "flush-messages", isSet: func(cfg *config) bool { return cfg.flush.Messages > 0 }, modes: dbModes, formats: jsonFormats},
+	{name: "go-version", isSet: func(cfg *config) bool { return cfg.goVersion != "" }, modes: sourceModes},
+	{name: "go-versions", isSet: func(cfg *config) bool { return len(cfg.GoVersions) > 0 }, modes: sourceModes},
+	{name: "internal", isSet: func(cfg *config) bool { return cfg.internal != "" }, modes: scanModes},
+	{name: "match-platform", isSet: func(cfg *config) bool { return cfg.matchPlatform }, modes: sourceModes},
+	{name: "max-db-age", isSet: func(cfg *config) bool { return cfg.maxDBAge != 0 }, modes: dbModes},
+	{name: "max-hops", isSet: func(cfg *config) bool { return cfg.maxHops != 0 }, modes: sourceModes},
+	{name: "merge", isSet: func(cfg *config) bool { return cfg.merge }, modes: scanModes},
+	{name: "osv", isSet: func(cfg *config) bool { return len(cfg.OSVs) > 0 }, modes: dbModes},
+	{name: "overlay", isSet: func(cfg *config) bool { return cfg.overlay != "" }, modes: sourceModes},
+	{name: "position-format", isSet: func(cfg *config) bool { return cfg.posFormat != "" }, modes: scanModes},
+	{name: "reachability-diff", isSet: func(cfg *config) bool { return cfg.reachabilityDiff != "" }, modes: sourceModes},
+	{name: "reachability-snapshot", isSet: func(cfg *config) bool { return cfg.reachabilitySnapshot != "" }, modes: sourceModes},
+	{name: "relpath", isSet: func(cfg *config) bool { return cfg.relPath != "" }, modes: sourceModes},
+	{name: "repo-branch", isSet: func(cfg *config) bool { return cfg.repo.Branch != "" }, modes: dbModes},
+	{name: "repo-commit", isSet: func(cfg *config) bool { return cfg.repo.Commit != "" }, modes: dbModes},
+	{name: "repo-url", isSet: func(cfg *config) bool { return cfg.repo.URL != "" }, modes: dbModes},
+	{name: "representative", isSet: func(cfg *config) bool { return cfg.Representative != "" }, modes: sourceModes},
+	{name: "sequential", isSet: func(cfg *config) bool { return cfg.Sequential }, modes: sourceModes},
+	{name: "severity-map", isSet: func(cfg *config) bool { return cfg.severityMap != "" }, modes: scanModes},
+	{name: "show", isSet: func(cfg *config) bool { return len(cfg.show) > 0 }, formats: textFormats},
+	{name: "sort", isSet: func(cfg *config) bool { return cfg.sortBy != "" }, modes: scanModes},
+	{name: "surface", isSet: func(cfg *config) bool { return cfg.surface }, modes: sourceModes},
+	{name: "tags", isSet: func(cfg *config) bool { return len(cfg.tags) > 0 }, modes: sourceModes},
+	{name: "test", isSet: func(cfg *config) bool { return cfg.test }, modes: sourceModes},
+	{name: "test-helpers", isSet: func(cfg *config) bool { return cfg.testHelpers != "" }, modes: sourceModes},
+	{name: "timeout", isSet: func(cfg *config) bool { return cfg.timeout != 0 }, modes: sourceModes},
+	{name: "timing", isSet: func(cfg *config) bool { return cfg.timing }, modes: sourceModes},
+	{name: "tools", isSet: func(cfg *config) bool { return cfg.tools }, modes: sourceModes},
+	{name: "trace-order", isSet: func(cfg *config) bool { return cfg.traceOrder != "" }, modes: dbModes, formats: jsonFormats},
+	{name: "try-upgrade", isSet: func(cfg *config) bool { return len(cfg.tryUpgrades) > 0 }, modes: sourceModes},
+	{name: "verify", isSet: func(cfg *config) bool { return cfg.verify }, modes: sourceModes},
+	{name: "without-calls", isSet: func(cfg *config) bool { return cfg.withoutCalls != "" }, modes: sourceModes},
+	{name: "worst", isSet: func(cfg *config) bool { return cfg.worst }, modes: scanModes},
+}
+
+// formatName returns the name of format in error messages.
+func formatName(format string) string {
+	if format == formatJSON {
+		return "JSON"
+	}
+	return format
+}
+
 func isFile(path string) bool {
 	s, err := os.Stat(path)
 	if err != nil {
@@ -179,3 +577,163 @@ func (v *showFlag) Set(s string) error {
 
 func (f *showFlag) Get() interface{} { return *f }
 func (f *showFlag) String() string   { return "<options>" }
+
+// osvFlag is the -osv flag, a comma-separated list of OSV IDs or
+// aliases. It may be repeated.
+type osvFlag []string
+
+func (v *osvFlag) Set(s string) error {
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			*v = append(*v, id)
+		}
+	}
+	return nil
+}
+
+func (f *osvFlag) Get() interface{} { return *f }
+func (f *osvFlag) String() string   { return "<ids>" }
+
+// symbolsFlag is the -changed or -entry-symbols flag, a comma-separated
+// list of symbols.
+// It may be repeated.
+type symbolsFlag []string
+
+func (v *symbolsFlag) Set(s string) error {
+	for _, symbol := range strings.Split(s, ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			*v = append(*v, symbol)
+		}
+	}
+	return nil
+}
+
+func (f *symbolsFlag) Get() interface{} { return *f }
+func (f *symbolsFlag) String() string   { return "<symbols>" }
+
+// goVersionsFlag is the -go-versions flag, a comma-separated list of Go
+// versions. It may be repeated.
+type goVersionsFlag []string
+
+func (v *goVersionsFlag) Set(s string) error {
+	for _, version := range strings.Split(s, ",") {
+		if version = strings.TrimSpace(version); version != "" {
+			*v = append(*v, version)
+		}
+	}
+	return nil
+}
+
+func (f *goVersionsFlag) Get() interface{} { return *f }
+func (f *goVersionsFlag) String() string   { return "<versions>" }
+
+// tryUpgradeFlag is the -try-upgrade flag, a comma-separated list of
+// module@version upgrades. It may be repeated.
+type tryUpgradeFlag []string
+
+func (v *tryUpgradeFlag) Set(s string) error {
+	for _, u := range strings.Split(s, ",") {
+		u = strings.TrimSpace(u)
+		if mod, version, ok := strings.Cut(u, "@"); !ok || mod == "" || version == "" {
+			return fmt.Errorf("%q is not of the form module@version", u)
+		}
+		*v = append(*v, u)
+	}
+	return nil
+}
+
+func (f *tryUpgradeFlag) Get() interface{} { return *f }
+func (f *tryUpgradeFlag) String() string   { return "<upgrades>" }
+
+// testFlag is the -test flag. It is a boolean flag that additionally
+// accepts the value nofail.
+type testFlag struct {
+	cfg *config
+}
+
+func (f *testFlag) Set(s string) error {
+	if s == testNoFail {
+		f.cfg.test, f.cfg.testNoFail = true, true
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("must be true, false, or %s", testNoFail)
+	}
+	f.cfg.test, f.cfg.testNoFail = b, false
+	return nil
+}
+
+func (f *testFlag) IsBoolFlag() bool { return true }
+
+func (f *testFlag) String() string {
+	switch {
+	case f.cfg == nil || !f.cfg.test:
+		return "false"
+	case f.cfg.testNoFail:
+		return testNoFail
+	default:
+		return "true"
+	}
+}
+
+const testNoFail = "nofail"
+
+// confidenceFlag is the -confidence flag, a threshold of the confidence
+// score of call stacks that is optionally followed by ",drop".
+type confidenceFlag struct {
+	cfg *config
+}
+
+func (f *confidenceFlag) Set(s string) error {
+	v := strings.TrimSuffix(s, confidenceDrop)
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return fmt.Errorf("must be a non-negative number, optionally followed by %s", confidenceDrop)
+	}
+	f.cfg.confidence, f.cfg.confidenceDrop = &n, v != s
+	return nil
+}
+
+func (f *confidenceFlag) String() string {
+	if f.cfg == nil || f.cfg.confidence == nil {
+		return ""
+	}
+	s := strconv.Itoa(*f.cfg.confidence)
+	if f.cfg.confidenceDrop {
+		s += confidenceDrop
+	}
+	return s
+}
+
+const confidenceDrop = ",drop"
+
+// maxAgeFlag is the -max-db-age flag, a duration that may also be a
+// number of days, as in "7d".
+type maxAgeFlag struct {
+	age *time.Duration
+}
+
+func (f *maxAgeFlag) Set(s string) error {
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || n < 0 {
+			return fmt.Errorf("must be a non-negative duration, such as 7d or 36h")
+		}
+		*f.age = time.Duration(n) * 24 * time.Hour
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return fmt.Errorf("must be a non-negative duration, such as 7d or 36h")
+	}
+	*f.age = d
+	return nil
+}
+
+func (f *maxAgeFlag) String() string {
+	if f.age == nil || *f.age == 0 {
+		return ""
+	}
+	return formatAge(*f.age)
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestFlagSupports checks that each flag of flagSupports is accepted in
+// exactly the modes and for the output formats it lists.
+func TestFlagSupports(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	if err := os.WriteFile(bin, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// values are the values the flags are set to, or "" for boolean flags.
+	values := map[string]string{
+		"C":                     dir,
+		"archive":               "src.zip",
+		"assume-called":         "golang.org/x",
+		"baseline":              "baseline.json",
+		"binary":                "bin",
+		"changed":               "pkg.Func",
+		"confidence":            "3",
+		"count":                 "",
+		"db-overlay":            "overlay",
+		"depth":                 "1",
+		"dispatch-tables":       "",
+		"entry-functions":       "entries.json",
+		"entry-symbols":         "main.main",
+		"exclude-stdlib":        "",
+		"flush-bytes":           "1",
+		"flush-messages":        "1",
+		"go-version":            "go1.20.3",
+		"go-versions":           "go1.20.3,go1.21.0",
+		"internal":              "golang.org/x",
+		"match-platform":        "",
+		"max-db-age":            "7d",
+		"max-hops":              "1",
+		"merge":                 "",
+		"osv":                   "GO-2023-0001",
+		"overlay":               "overlay.json",
+		"position-format":       "colon",
+		"reachability-diff":     "snapshot.json",
+		"reachability-snapshot": "snapshot.json",
+		"relpath":               "module",
+		"repo-branch":           "main",
+		"repo-commit":           "abc",
+		"repo-url":              "https://example.com/repo",
+		"representative":        "shortest",
+		"sequential":            "",
+		"severity-map":          "severities.json",
+		"show":                  "traces",
+		"sort":                  "effort",
+		"surface":               "",
+		"tags":                  "foo",
+		"test":                  "",
+		"test-helpers":          "golang.org/x/*",
+		"timeout":               "1m",
+		"timing":                "",
+		"tools":                 "",
+		"trace-order":           "sink",
+		"try-upgrade":           "golang.org/x/text@v0.3.8",
+		"verify":                "",
+		"without-calls":         "golang.org/x/*",
+		"worst":                 "",
+	}
+	patterns := map[string][]string{
+		modeSource:  {"./..."},
+		modeBinary:  {bin},
+		modeConvert: nil,
+		modeQuery:   {"golang.org/x/text@v0.3.0"},
+	}
+	var modes, formats []string
+	for m := range supportedModes {
+		modes = append(modes, m)
+	}
+	for f := range supportedFormats {
+		formats = append(formats, f)
+	}
+	sort.Strings(modes)
+	sort.Strings(formats)
+
+	for i, f := range flagSupports {
+		if i > 0 && flagSupports[i-1].name >= f.name {
+			t.Errorf("flagSupports is not sorted by name at -%s", f.name)
+		}
+		value, ok := values[f.name]
+		if !ok {
+			t.Errorf("no test value for the -%s flag", f.name)
+			continue
+		}
+		arg := "-" + f.name
+		if value != "" {
+			arg += "=" + value
+		}
+		for _, mode := range modes {
+			for _, format := range formats {
+				if mode == modeQuery && format != formatJSON {
+					// Query mode only supports JSON output.
+					continue
+				}
+				args := append([]string{"-mode=" + mode, "-format=" + format, arg}, patterns[mode]...)
+				var stderr bytes.Buffer
+				err := parseFlags(&config{}, &stderr, args)
+				var want string
+				switch {
+				case len(f.modes) > 0 && !contains(f.modes, mode):
+					want = "the -" + f.name + " flag is not supported in " + mode + " mode"
+				case len(f.formats) > 0 && !contains(f.formats, format):
+					want = "the -" + f.name + " flag is not supported for " + formatName(format) + " output"
+				}
+				got := strings.TrimSpace(stderr.String())
+				if want == "" && err != nil {
+					t.Errorf("%s: got error %q, want none", strings.Join(args, " "), got)
+				} else if want != "" && got != want {
+					t.Errorf("%s: got error %q, want %q", strings.Join(args, " "), got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// githubHandler is a handler that writes findings as GitHub Actions
+// workflow commands, which GitHub shows as annotations of the files
+// of a pull request. See
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions.
+//
+// Called vulnerabilities are reported as warnings at the position where
+// the top module calls into other code toward the vulnerable symbol.
+// Vulnerabilities that are only imported have no such position and are
+// reported as notices that are not attached to a file.
+type githubHandler struct {
+	w        io.Writer
+	osvs     []*osv.Entry
+	findings []*findingSummary
+
+	// workspace is the directory that file names are reported
+	// relative to, which is the root of the checked out repository.
+	workspace string
+
+	// testNoFail is set if vulnerabilities that are only called
+	// from tests do not cause failure.
+	testNoFail bool
+}
+
+// newGitHubHandler returns a handler that writes to w. The workspace is
+// read from the GITHUB_WORKSPACE environment variable of cfg.
+func newGitHubHandler(w io.Writer, cfg *config) *githubHandler {
+	h := &githubHandler{w: w, testNoFail: cfg.testNoFail}
+	const workspacePrefix = "GITHUB_WORKSPACE="
+	for _, env := range cfg.env {
+		if val := strings.TrimPrefix(env, workspacePrefix); val != env {
+			h.workspace = val
+		}
+	}
+	return h
+}
+
+// Config does nothing, as workflow commands only report findings.
+func (h *githubHandler) Config(config *govulncheck.Config) error {
+	return nil
+}
+
+// Progress writes progress messages to the workflow log.
+func (h *githubHandler) Progress(progress *govulncheck.Progress) error {
+	_, err := fmt.Fprintln(h.w, progress.Message)
+	return err
+}
+
+// OSV gathers osv entries to be written.
+func (h *githubHandler) OSV(entry *osv.Entry) error {
+	h.osvs = append(h.osvs, entry)
+	return nil
+}
+
+// Finding gathers vulnerability findings to be written.
+func (h *githubHandler) Finding(finding *govulncheck.Finding) error {
+	if err := validateFindings(finding); err != nil {
+		return err
+	}
+	h.findings = append(h.findings, newFindingSummary(finding))
+	return nil
+}
+
+// Flush writes a workflow command for each called finding, and one for
+// each vulnerability that is only imported.
+func (h *githubHandler) Flush() error {
+	fixupFindings(h.osvs, h.findings)
+	for _, vuln := range groupByVuln(h.findings) {
+		if !isCalled(vuln) {
+			if err := h.command("notice", vuln[0], nil); err != nil {
+				return err
+			}
+			continue
+		}
+		seen := map[string]bool{}
+		for _, f := range vuln {
+			if !isCalledFinding(f.Finding) {
+				continue
+			}
+			pos := f.Trace[topFrame(f.Trace)].Position
+			key := posToString(pos)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if err := h.command("warning", f, pos); err != nil {
+				return err
+			}
+		}
+	}
+	if isFailure(h.findings, h.testNoFail) {
+		return errVulnerabilitiesFound
+	}
+	return nil
+}
+
+// command writes the workflow command named cmd for finding f at pos, or
+// with no file if pos is not a valid position.
+func (h *githubHandler) command(cmd string, f *findingSummary, pos *govulncheck.Position) error {
+	var props []string
+	if pos != nil && pos.Line > 0 {
+		props = append(props,
+			"file="+escapeProperty(h.filename(pos.Filename)),
+			fmt.Sprintf("line=%d", pos.Line),
+			fmt.Sprintf("col=%d", pos.Column))
+	}
+	props = append(props, "title="+escapeProperty(f.OSV.ID))
+	_, err := fmt.Fprintf(h.w, "::%s %s::%s\n", cmd, strings.Join(props, ","), escapeData(githubMessage(f)))
+	return err
+}
+
+// filename returns filename relative to the workspace, if possible.
+func (h *githubHandler) filename(filename string) string {
+	return filepath.ToSlash(relativeFilename(filename, h.workspace))
+}
+
+// githubMessage returns the message of the workflow command for f.
+func githubMessage(f *findingSummary) string {
+	var b strings.Builder
+	b.WriteString(f.OSV.ID)
+	b.WriteString(": ")
+	description := f.OSV.Summary
+	if description == "" {
+		description = f.OSV.Details
+	}
+	b.WriteString(description)
+	b.WriteString("\n")
+	if f.Trace[0].Function == "" {
+		b.WriteString("\nThe vulnerable code is imported, but not called.")
+		if f.AssumedCalled {
+			b.WriteString(" It is treated as called by the -assume-called flag.")
+		}
+		if len(f.ImportedBy) > 0 {
+			fmt.Fprintf(&b, "\nImported by: %s", strings.Join(f.ImportedBy, ", "))
+		}
+		if f.Tool != nil {
+			fmt.Fprintf(&b, "\nBuild-time tool: %s (%s)", f.Tool.Package, toolPosition(f.Tool))
+		}
+	} else {
+		b.WriteString("\nTrace: ")
+		b.WriteString(compactCalls(f.Trace, topFrame(f.Trace)))
+	}
+	frame := f.Trace[0]
+	path := frame.Module
+	if frame.Module == internal.GoStdModulePath {
+		path = frame.Package
+	}
+	fmt.Fprintf(&b, "\nFound in: %s@%s", path, moduleVersionString(frame.Module, frame.Version))
+	if fixed := moduleVersionString(frame.Module, f.FixedVersion); fixed != "" {
+		fmt.Fprintf(&b, "\nFixed in: %s@%s", path, fixed)
+	} else if f.FixStatus == govulncheck.FixStatusUnknown {
+		b.WriteString("\nFixed in: unknown")
+	} else {
+		b.WriteString("\nFixed in: N/A")
+	}
+	if f.OSV.DatabaseSpecific != nil && f.OSV.DatabaseSpecific.URL != "" {
+		fmt.Fprintf(&b, "\nMore info: %s", f.OSV.DatabaseSpecific.URL)
+	}
+	return b.String()
+}
+
+// escapeData escapes s for use as the message of a workflow command.
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeProperty escapes s for use as a property value of a workflow
+// command.
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestGitHubHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := newGitHubHandler(buf, &config{env: []string{"GITHUB_WORKSPACE=/repo"}})
+	entry := &osv.Entry{
+		ID:               "GO-0000-0001",
+		Summary:          "100% bad, really",
+		DatabaseSpecific: &osv.DatabaseSpecific{URL: "https://pkg.go.dev/vuln/GO-0000-0001"},
+	}
+	if err := h.OSV(entry); err != nil {
+		t.Fatal(err)
+	}
+	findings := []*govulncheck.Finding{
+		{
+			OSV: entry.ID,
+			Trace: []*govulncheck.Frame{
+				{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln", Function: "V"},
+				{Module: "golang.org/main", Package: "golang.org/main", Function: "main", Position: &govulncheck.Position{Filename: "/repo/a,b/main.go", Line: 3, Column: 7}},
+			},
+		},
+		{
+			// Binary mode findings have no position.
+			OSV: entry.ID,
+			Trace: []*govulncheck.Frame{
+				{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln", Function: "W"},
+			},
+		},
+	}
+	for _, f := range findings {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Flush(); err != errVulnerabilitiesFound {
+		t.Errorf("got error %v; want %v", err, errVulnerabilitiesFound)
+	}
+	want := "::warning file=a%2Cb/main.go,line=3,col=7,title=GO-0000-0001::GO-0000-0001: 100%25 bad, really%0A%0ATrace: main.main calls vuln.V%0AFound in: golang.org/vmod@v1.0.0%0AFixed in: N/A%0AMore info: https://pkg.go.dev/vuln/GO-0000-0001\n" +
+		"::warning title=GO-0000-0001::GO-0000-0001: 100%25 bad, really%0A%0ATrace: vuln.W%0AFound in: golang.org/vmod@v1.0.0%0AFixed in: N/A%0AMore info: https://pkg.go.dev/vuln/GO-0000-0001\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
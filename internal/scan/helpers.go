@@ -0,0 +1,61 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"sort"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// isTestHelper reports whether the package with path pkgPath is a test
+// helper package given patterns, the comma-separated list of glob
+// patterns of the -test-helpers flag, which match package path prefixes
+// as for the GOPRIVATE environment variable.
+func isTestHelper(patterns, pkgPath string) bool {
+	return patterns != "" && module.MatchPrefixPatterns(patterns, pkgPath)
+}
+
+// testHelpers returns the test helper packages matching patterns among the
+// dependencies of pkgs that are not in pkgs, sorted by path. Such helpers
+// are usually only imported by tests, and their exported functions are
+// not entry points of the analysis otherwise.
+func testHelpers(pkgs []*packages.Package, patterns string) []*packages.Package {
+	if patterns == "" {
+		return nil
+	}
+	seen := map[string]bool{}
+	for _, pkg := range pkgs {
+		seen[pkg.PkgPath] = true
+	}
+	var helpers []*packages.Package
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if seen[pkg.PkgPath] || !isTestHelper(patterns, pkg.PkgPath) {
+			return
+		}
+		seen[pkg.PkgPath] = true
+		helpers = append(helpers, pkg)
+	})
+	sort.Slice(helpers, func(i, j int) bool {
+		return helpers[i].PkgPath < helpers[j].PkgPath
+	})
+	return helpers
+}
+
+// entryFilter returns the filter of the entry packages of the analysis for
+// cfg, which accepts the test helper packages in addition to the packages
+// accepted by the EntryPackage hook. It returns nil if all packages are
+// entry points.
+func entryFilter(cfg *config) vulncheck.EntryFilter {
+	filter := cfg.hooks.EntryPackage
+	if filter == nil || cfg.testHelpers == "" {
+		return filter
+	}
+	return func(pkgPath string) bool {
+		return filter(pkgPath) || isTestHelper(cfg.testHelpers, pkgPath)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// idsHandler is a handler that writes a compact summary of the
+// vulnerabilities of the findings, with a JSON object per line for each
+// OSV entry, sorted by ID. The traces and positions of the findings are
+// left out, so that the sets of vulnerabilities found by periodic scans
+// are cheap to collect and compare.
+type idsHandler struct {
+	w        io.Writer
+	osvs     []*osv.Entry
+	findings []*findingSummary
+
+	// testNoFail is set if vulnerabilities that are only called
+	// from tests do not cause failure.
+	testNoFail bool
+}
+
+// newIDsHandler returns a handler that writes to w.
+func newIDsHandler(w io.Writer, cfg *config) *idsHandler {
+	return &idsHandler{w: w, testNoFail: cfg.testNoFail}
+}
+
+// idsRecord is the summary of the findings of an OSV entry.
+type idsRecord struct {
+	OSV     string   `json:"osv"`
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Severity is the CVSS v3 vector of the findings with the highest
+	// base score, if any.
+	Severity string `json:"severity,omitempty"`
+
+	// Called is set if some of the findings are called.
+	Called bool `json:"called"`
+}
+
+// Config does nothing, as only findings are summarized.
+func (h *idsHandler) Config(config *govulncheck.Config) error {
+	return nil
+}
+
+// Progress does nothing, so that the output is only made of records.
+func (h *idsHandler) Progress(progress *govulncheck.Progress) error {
+	return nil
+}
+
+// OSV gathers osv entries to be written.
+func (h *idsHandler) OSV(entry *osv.Entry) error {
+	h.osvs = append(h.osvs, entry)
+	return nil
+}
+
+// Finding gathers vulnerability findings to be written.
+func (h *idsHandler) Finding(finding *govulncheck.Finding) error {
+	if err := validateFindings(finding); err != nil {
+		return err
+	}
+	h.findings = append(h.findings, newFindingSummary(finding))
+	return nil
+}
+
+// Flush writes a record for each OSV entry of the findings.
+func (h *idsHandler) Flush() error {
+	fixupFindings(h.osvs, h.findings)
+	var records []*idsRecord
+	for _, vuln := range groupByVuln(h.findings) {
+		r := &idsRecord{OSV: vuln[0].OSV.ID, Aliases: vuln[0].OSV.Aliases, Called: isCalled(vuln)}
+		var score float64
+		for _, f := range vuln {
+			if s, err := cvss3BaseScore(f.Severity); err == nil && (r.Severity == "" || s > score) {
+				r.Severity, score = f.Severity, s
+			}
+		}
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].OSV < records[j].OSV })
+	enc := json.NewEncoder(h.w)
+	enc.SetEscapeHTML(false)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	if isFailure(h.findings, h.testNoFail) {
+		return errVulnerabilitiesFound
+	}
+	return nil
+}
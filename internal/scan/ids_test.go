@@ -0,0 +1,50 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestIDsHandler(t *testing.T) {
+	const (
+		high     = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+		moderate = "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"
+	)
+	vuln := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln", Function: "V"}
+	imported := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln"}
+	main := &govulncheck.Frame{Module: "golang.org/main", Package: "golang.org/main", Function: "main"}
+
+	buf := &bytes.Buffer{}
+	h := newIDsHandler(buf, &config{})
+	for _, entry := range []*osv.Entry{{ID: "GO-0000-0002"}, {ID: "GO-0000-0001", Aliases: []string{"CVE-0000-0001"}}} {
+		if err := h.OSV(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []*govulncheck.Finding{
+		{OSV: "GO-0000-0002", Trace: []*govulncheck.Frame{imported}},
+		{OSV: "GO-0000-0001", Severity: moderate, Trace: []*govulncheck.Frame{imported}},
+		{OSV: "GO-0000-0001", Severity: high, Trace: []*govulncheck.Frame{vuln, main}},
+	} {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Flush(); err != errVulnerabilitiesFound {
+		t.Fatalf("got error %v; want %v", err, errVulnerabilitiesFound)
+	}
+	want := `{"osv":"GO-0000-0001","aliases":["CVE-0000-0001"],"severity":"` + high + `","called":true}
+{"osv":"GO-0000-0002","called":false}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"strings"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// mergeFindings returns findings with the findings that differ only by
+// the positions of their traces merged into the first of them, which is
+// representative of the others. The Merged field of a merged finding is
+// set to the number of findings it stands for.
+func mergeFindings(findings []*govulncheck.Finding) []*govulncheck.Finding {
+	var merged []*govulncheck.Finding
+	counts := map[string]int{}
+	first := map[string]*govulncheck.Finding{}
+	for _, f := range findings {
+		key := mergeKey(f)
+		counts[key]++
+		if _, ok := first[key]; ok {
+			continue
+		}
+		first[key] = f
+		merged = append(merged, f)
+	}
+	for key, f := range first {
+		if counts[key] > 1 {
+			f.Merged = counts[key]
+		}
+	}
+	return merged
+}
+
+// mergeKey returns the key of the findings that f is merged with, which
+// is made of the OSV ID of f and the functions of its trace.
+func mergeKey(f *govulncheck.Finding) string {
+	fields := []string{f.OSV}
+	for _, fr := range f.Trace {
+		fields = append(fields, fr.Module, fr.Package, fr.Receiver, fr.Function)
+	}
+	return strings.Join(fields, "\x00")
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+func TestMergeFindings(t *testing.T) {
+	finding := func(id, caller string, line int) *govulncheck.Finding {
+		return &govulncheck.Finding{OSV: id, Trace: []*govulncheck.Frame{
+			{Module: "golang.org/vmod", Package: "golang.org/vmod/vuln", Function: "V"},
+			{Module: "golang.org/main", Package: "golang.org/main", Function: caller, Position: &govulncheck.Position{Filename: "main.go", Line: line}},
+		}}
+	}
+	var (
+		a1 = finding("GO-0000-0001", "gen", 1)
+		a2 = finding("GO-0000-0001", "gen", 2)
+		a3 = finding("GO-0000-0001", "gen", 3)
+		b  = finding("GO-0000-0001", "main", 4)
+		c  = finding("GO-0000-0002", "gen", 5)
+	)
+	got := mergeFindings([]*govulncheck.Finding{a1, b, a2, c, a3})
+	want := []*govulncheck.Finding{a1, b, c}
+	if len(got) != len(want) {
+		t.Fatalf("got %d findings, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("#%d: got finding at line %d, want line %d", i, got[i].Trace[1].Position.Line, want[i].Trace[1].Position.Line)
+		}
+	}
+	for _, test := range []struct {
+		f    *govulncheck.Finding
+		want int
+	}{{a1, 3}, {b, 0}, {c, 0}} {
+		if test.f.Merged != test.want {
+			t.Errorf("finding at line %d: got Merged %d, want %d", test.f.Trace[1].Position.Line, test.f.Merged, test.want)
+		}
+	}
+}
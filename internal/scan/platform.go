@@ -0,0 +1,28 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// reportNotApplicable writes a progress message to handler for each
+// vulnerability of vr that only affects other platforms than the one of
+// the scanned code. Such vulnerabilities are not reported as findings, as
+// they cannot apply to how the code is built.
+func reportNotApplicable(handler govulncheck.Handler, vr *vulncheck.Result) error {
+	for _, entry := range vr.NotApplicable {
+		msg := fmt.Sprintf("Vulnerability %s only affects %s, so it does not apply to the platform of the scanned code.",
+			entry.ID, strings.Join(platforms("", entry), ", "))
+		if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
 	isem "golang.org/x/vuln/internal/semver"
+	"golang.org/x/vuln/internal/vulncheck"
 )
 
 // runQuery reports vulnerabilities that apply to the queries in the config.
@@ -34,10 +35,16 @@ func runQuery(ctx context.Context, handler govulncheck.Handler, cfg *config, c *
 	if err != nil {
 		return err
 	}
+	if err := cfg.download.finish(); err != nil {
+		return err
+	}
 
 	ids := make(map[string]bool)
 	for _, resp := range resps {
 		for _, entry := range resp.Entries {
+			if len(cfg.OSVs) > 0 && !vulncheck.MatchesOSVs(entry, cfg.OSVs) {
+				continue
+			}
 			if _, ok := ids[entry.ID]; !ok {
 				err := handler.OSV(entry)
 				if err != nil {
@@ -0,0 +1,263 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// reachability is a snapshot of the part of the call graph of a scan that
+// reaches vulnerable symbols. It is written to the file named by the
+// -reachability-snapshot flag, and compared with the current scan by the
+// -reachability-diff flag, so that the changes of a dependency upgrade to
+// the reachable vulnerable symbols are reported even when the findings
+// stay the same.
+type reachability struct {
+	Symbols []*reachableSymbol `json:"symbols"`
+}
+
+// reachableSymbol is a vulnerable symbol reached by the call graph, along
+// with the calls that lead to it from the entry points, as in
+// "golang.org/vuln.main -> golang.org/x/text/language.Parse". Calls are
+// identified by their caller and callee only, as positions change with
+// unrelated edits.
+type reachableSymbol struct {
+	OSV    string   `json:"osv"`
+	Symbol string   `json:"symbol"`
+	Calls  []string `json:"calls"`
+}
+
+// String describes s in progress messages.
+func (s *reachableSymbol) String() string {
+	return fmt.Sprintf("%s in %s", s.OSV, s.Symbol)
+}
+
+// newReachability returns the snapshot of the reachability of the
+// vulnerable symbols of vr.
+func newReachability(vr *vulncheck.Result) *reachability {
+	r := &reachability{Symbols: []*reachableSymbol{}}
+	seen := map[string]bool{}
+	for _, vv := range vr.Vulns {
+		if vv.CallSink == nil {
+			continue
+		}
+		s := &reachableSymbol{OSV: vv.OSV.ID, Symbol: funcSymbol(vv.CallSink), Calls: callsTo(vv.CallSink)}
+		if key := s.String(); !seen[key] {
+			seen[key] = true
+			r.Symbols = append(r.Symbols, s)
+		}
+	}
+	sort.Slice(r.Symbols, func(i, j int) bool {
+		if r.Symbols[i].OSV != r.Symbols[j].OSV {
+			return r.Symbols[i].OSV < r.Symbols[j].OSV
+		}
+		return r.Symbols[i].Symbol < r.Symbols[j].Symbol
+	})
+	return r
+}
+
+// callsTo returns the sorted calls of the call graph that lead to sink.
+func callsTo(sink *vulncheck.FuncNode) []string {
+	calls := map[string]bool{}
+	visited := map[*vulncheck.FuncNode]bool{sink: true}
+	queue := []*vulncheck.FuncNode{sink}
+	for len(queue) > 0 {
+		fn := queue[0]
+		queue = queue[1:]
+		for _, cs := range fn.CallSites {
+			calls[funcSymbol(cs.Parent)+" -> "+funcSymbol(fn)] = true
+			if !visited[cs.Parent] {
+				visited[cs.Parent] = true
+				queue = append(queue, cs.Parent)
+			}
+		}
+	}
+	sorted := []string{}
+	for c := range calls {
+		sorted = append(sorted, c)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// funcSymbol returns the full symbol name of fn, as in "pkg.Recv.Func".
+func funcSymbol(fn *vulncheck.FuncNode) string {
+	fr := &govulncheck.Frame{Function: fn.Name}
+	if fn.Package != nil {
+		fr.Package = fn.Package.PkgPath
+		fr.Receiver = fn.Receiver()
+	}
+	return symbol(fr, false)
+}
+
+// readReachability reads the reachability snapshot at path.
+func readReachability(path string) (*reachability, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading reachability snapshot: %v", err)
+	}
+	r := &reachability{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("parsing reachability snapshot %s: %v", path, err)
+	}
+	return r, nil
+}
+
+// writeReachability writes r to the reachability snapshot at path.
+func writeReachability(path string, r *reachability) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// Calls are easier to read with their arrows unescaped.
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0666); err != nil {
+		return fmt.Errorf("writing reachability snapshot: %v", err)
+	}
+	return nil
+}
+
+// diffReachability describes the changes from old to cur, in order: the
+// vulnerabilities that are no longer reachable, those that are newly
+// reachable, and then, for the vulnerabilities reachable in both, the
+// vulnerable symbols that are no longer or newly reached and the symbols
+// reached by different calls.
+func diffReachability(old, cur *reachability) []string {
+	oldOSVs, curOSVs := reachableOSVs(old), reachableOSVs(cur)
+	var removed, introduced, changed []string
+	for _, id := range osvIDs(oldOSVs) {
+		if curOSVs[id] == nil {
+			removed = append(removed, fmt.Sprintf("removed reachability to %s", id))
+		}
+	}
+	for _, id := range osvIDs(curOSVs) {
+		if oldOSVs[id] == nil {
+			introduced = append(introduced, fmt.Sprintf("introduced reachability to %s", id))
+			continue
+		}
+		changed = append(changed, diffSymbols(oldOSVs[id], curOSVs[id])...)
+	}
+	return append(append(removed, introduced...), changed...)
+}
+
+// diffSymbols describes the changes from old to cur, the reachable symbols
+// of a vulnerability.
+func diffSymbols(old, cur map[string]*reachableSymbol) []string {
+	var changes []string
+	for _, name := range symbolNames(old) {
+		if cur[name] == nil {
+			changes = append(changes, fmt.Sprintf("no longer reaches %s", old[name]))
+		}
+	}
+	for _, name := range symbolNames(cur) {
+		s := cur[name]
+		if old[name] == nil {
+			changes = append(changes, fmt.Sprintf("now reaches %s", s))
+			continue
+		}
+		added, dropped := diffCalls(old[name].Calls, s.Calls)
+		if added > 0 || dropped > 0 {
+			changes = append(changes, fmt.Sprintf("changed the calls that reach %s: %d added, %d removed", s, added, dropped))
+		}
+	}
+	return changes
+}
+
+// diffCalls returns the number of calls in cur that are not in old, and
+// the number of calls in old that are not in cur.
+func diffCalls(old, cur []string) (added, dropped int) {
+	oldSet := map[string]bool{}
+	for _, c := range old {
+		oldSet[c] = true
+	}
+	curSet := map[string]bool{}
+	for _, c := range cur {
+		curSet[c] = true
+		if !oldSet[c] {
+			added++
+		}
+	}
+	for _, c := range old {
+		if !curSet[c] {
+			dropped++
+		}
+	}
+	return added, dropped
+}
+
+// reachableOSVs returns the reachable symbols of r by OSV ID and symbol.
+func reachableOSVs(r *reachability) map[string]map[string]*reachableSymbol {
+	osvs := map[string]map[string]*reachableSymbol{}
+	for _, s := range r.Symbols {
+		if osvs[s.OSV] == nil {
+			osvs[s.OSV] = map[string]*reachableSymbol{}
+		}
+		osvs[s.OSV][s.Symbol] = s
+	}
+	return osvs
+}
+
+// osvIDs returns the sorted OSV IDs of osvs.
+func osvIDs(osvs map[string]map[string]*reachableSymbol) []string {
+	var ids []string
+	for id := range osvs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// symbolNames returns the sorted names of symbols.
+func symbolNames(symbols map[string]*reachableSymbol) []string {
+	var names []string
+	for name := range symbols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyReachability compares the reachability of the vulnerable symbols of
+// vr with the snapshot named by the -reachability-diff flag of cfg, if any,
+// and writes a progress message to handler describing the changes. It then
+// writes the snapshot of vr to the file named by the -reachability-snapshot
+// flag, if any, which can be the same file.
+func applyReachability(handler govulncheck.Handler, cfg *config, vr *vulncheck.Result) error {
+	if cfg.reachabilityDiff == "" && cfg.reachabilitySnapshot == "" {
+		return nil
+	}
+	r := newReachability(vr)
+	base := filepath.FromSlash(cfg.dir)
+	if cfg.reachabilityDiff != "" {
+		old, err := readReachability(absPath(cfg.reachabilityDiff, base))
+		if err != nil {
+			return fmt.Errorf("govulncheck: %v", err)
+		}
+		msg := fmt.Sprintf("No changes to the reachability of vulnerable symbols since snapshot %s.", cfg.reachabilityDiff)
+		if changes := diffReachability(old, r); len(changes) > 0 {
+			msg = fmt.Sprintf("Changes to the reachability of vulnerable symbols since snapshot %s:\n  %s", cfg.reachabilityDiff, strings.Join(changes, "\n  "))
+		}
+		if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+			return err
+		}
+	}
+	if cfg.reachabilitySnapshot != "" {
+		if err := writeReachability(absPath(cfg.reachabilitySnapshot, base), r); err != nil {
+			return fmt.Errorf("govulncheck: %v", err)
+		}
+	}
+	return nil
+}
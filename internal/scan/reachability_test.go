@@ -0,0 +1,99 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/test"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+func TestNewReachability(t *testing.T) {
+	main := &packages.Package{PkgPath: "golang.org/entry"}
+	vuln := &packages.Package{PkgPath: "golang.org/vmod/vuln"}
+	entry := &vulncheck.FuncNode{Name: "main", Package: main}
+	helper := &vulncheck.FuncNode{Name: "helper", Package: main, CallSites: []*vulncheck.CallSite{{Parent: entry}}}
+	sink := &vulncheck.FuncNode{Name: "Get", RecvType: "*golang.org/vmod/vuln.Client", Package: vuln, CallSites: []*vulncheck.CallSite{
+		{Parent: entry}, {Parent: helper}, {Parent: helper},
+	}}
+	vr := &vulncheck.Result{Vulns: []*vulncheck.Vuln{
+		{OSV: &osv.Entry{ID: "GO-0000-0002"}, CallSink: sink},
+		{OSV: &osv.Entry{ID: "GO-0000-0001"}, CallSink: sink},
+		{OSV: &osv.Entry{ID: "GO-0000-0003"}},
+	}}
+	calls := []string{
+		"golang.org/entry.helper -> golang.org/vmod/vuln.Client.Get",
+		"golang.org/entry.main -> golang.org/entry.helper",
+		"golang.org/entry.main -> golang.org/vmod/vuln.Client.Get",
+	}
+	want := &reachability{Symbols: []*reachableSymbol{
+		{OSV: "GO-0000-0001", Symbol: "golang.org/vmod/vuln.Client.Get", Calls: calls},
+		{OSV: "GO-0000-0002", Symbol: "golang.org/vmod/vuln.Client.Get", Calls: calls},
+	}}
+	if diff := cmp.Diff(want, newReachability(vr)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestDiffReachability(t *testing.T) {
+	old := &reachability{Symbols: []*reachableSymbol{
+		{OSV: "GO-0000-0001", Symbol: "vuln.A", Calls: []string{"main.main -> vuln.A"}},
+		{OSV: "GO-0000-0002", Symbol: "vuln.B", Calls: []string{"main.main -> vuln.B"}},
+		{OSV: "GO-0000-0002", Symbol: "vuln.C", Calls: []string{"main.main -> vuln.C"}},
+		{OSV: "GO-0000-0003", Symbol: "vuln.D", Calls: []string{"main.main -> vuln.D", "main.f -> vuln.D"}},
+	}}
+	cur := &reachability{Symbols: []*reachableSymbol{
+		{OSV: "GO-0000-0002", Symbol: "vuln.B", Calls: []string{"main.main -> vuln.B"}},
+		{OSV: "GO-0000-0002", Symbol: "vuln.E", Calls: []string{"main.main -> vuln.E"}},
+		{OSV: "GO-0000-0003", Symbol: "vuln.D", Calls: []string{"main.g -> vuln.D", "main.main -> vuln.D"}},
+		{OSV: "GO-0000-0004", Symbol: "vuln.F", Calls: []string{"main.main -> vuln.F"}},
+	}}
+	want := []string{
+		"removed reachability to GO-0000-0001",
+		"introduced reachability to GO-0000-0004",
+		"no longer reaches GO-0000-0002 in vuln.C",
+		"now reaches GO-0000-0002 in vuln.E",
+		"changed the calls that reach GO-0000-0003 in vuln.D: 1 added, 1 removed",
+	}
+	if diff := cmp.Diff(want, diffReachability(old, cur)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if got := diffReachability(cur, cur); len(got) != 0 {
+		t.Errorf("got changes %v between identical snapshots; want none", got)
+	}
+}
+
+func TestApplyReachability(t *testing.T) {
+	sink := &vulncheck.FuncNode{Name: "A", Package: &packages.Package{PkgPath: "vuln"}}
+	vr := &vulncheck.Result{Vulns: []*vulncheck.Vuln{{OSV: &osv.Entry{ID: "GO-0000-0001"}, CallSink: sink}}}
+
+	dir := t.TempDir()
+	cfg := &config{dir: dir, reachabilitySnapshot: "reachability.json"}
+	h := test.NewMockHandler()
+	if err := applyReachability(h, cfg, vr); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.ProgressMessages) != 0 {
+		t.Errorf("got progress messages %v when writing a snapshot; want none", h.ProgressMessages)
+	}
+
+	cfg = &config{dir: dir, reachabilityDiff: "reachability.json"}
+	if err := applyReachability(h, cfg, &vulncheck.Result{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.ProgressMessages) != 1 || !strings.Contains(h.ProgressMessages[0].Message, "removed reachability to GO-0000-0001") {
+		t.Errorf("got progress messages %v; want the removed reachability", h.ProgressMessages)
+	}
+
+	cfg.reachabilityDiff = "missing.json"
+	if err := applyReachability(h, cfg, vr); err == nil {
+		t.Error("want error for missing snapshot; got nil")
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"sort"
+
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// Values of the -representative flag, the strategies picking the
+// representative call stack of a called vulnerable symbol. The stacks
+// going through other vulnerable symbols of the same package are never
+// picked, nor the test-only stacks when others exercise the symbol.
+const (
+	// representativeFirst picks the first stack of the search, which
+	// orders stacks by confidence, length, and number of dynamic calls.
+	// It is the default.
+	representativeFirst = "first"
+
+	// representativeShortest picks the stack with the fewest calls.
+	representativeShortest = "shortest"
+
+	// representativeStatic picks the stack with the fewest dynamic calls,
+	// such as calls of interface methods and function values.
+	representativeStatic = "static"
+
+	// representativeMain picks the stack whose last function in the main
+	// module is the closest to the vulnerable symbol.
+	representativeMain = "main"
+)
+
+var supportedRepresentatives = map[string]bool{
+	representativeFirst:    true,
+	representativeShortest: true,
+	representativeStatic:   true,
+	representativeMain:     true,
+}
+
+// orderCallStacks sorts stacks, from the entry point to the vulnerable
+// symbol, by the preference of strategy. It keeps the order of the search
+// for stacks that are equally preferred, and for the default strategy.
+func orderCallStacks(stacks []vulncheck.CallStack, strategy string) {
+	var key func(vulncheck.CallStack) int
+	switch strategy {
+	case representativeShortest:
+		key = func(s vulncheck.CallStack) int { return len(s) }
+	case representativeStatic:
+		key = dynamicCalls
+	case representativeMain:
+		key = callsFromMain
+	default:
+		return
+	}
+	sort.SliceStable(stacks, func(i, j int) bool { return key(stacks[i]) < key(stacks[j]) })
+}
+
+// dynamicCalls returns the number of calls of stack that are not
+// statically resolved.
+func dynamicCalls(stack vulncheck.CallStack) int {
+	n := 0
+	for _, e := range stack {
+		if e.Call != nil && !e.Call.Resolved {
+			n++
+		}
+	}
+	return n
+}
+
+// callsFromMain returns the number of calls of stack from its last
+// function in the main module to the vulnerable symbol, or the number of
+// frames of stack if none of its functions is in the main module.
+func callsFromMain(stack vulncheck.CallStack) int {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if pkg := stack[i].Function.Package; pkg != nil && pkg.Module != nil && pkg.Module.Main {
+			return len(stack) - 1 - i
+		}
+	}
+	return len(stack)
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+func TestOrderCallStacks(t *testing.T) {
+	main := &packages.Package{PkgPath: "golang.org/entry", Module: &packages.Module{Path: "golang.org/entry", Main: true}}
+	dep := &packages.Package{PkgPath: "golang.org/dep", Module: &packages.Module{Path: "golang.org/dep"}}
+	fn := func(name string, pkg *packages.Package) *vulncheck.FuncNode {
+		return &vulncheck.FuncNode{Name: name, Package: pkg}
+	}
+	entry, sink := fn("main", main), fn("Vuln", dep)
+	static, dynamic := &vulncheck.CallSite{Resolved: true}, &vulncheck.CallSite{}
+	stacks := []vulncheck.CallStack{
+		// The first stack of the search, and the shortest.
+		{{Function: entry, Call: dynamic}, {Function: fn("A", dep), Call: dynamic}, {Function: sink}},
+		// No dynamic calls.
+		{{Function: entry, Call: static}, {Function: fn("B", dep), Call: static}, {Function: fn("C", dep), Call: static}, {Function: sink}},
+		// Closest to the main module.
+		{{Function: entry, Call: static}, {Function: fn("D", main), Call: static}, {Function: fn("E", main), Call: dynamic}, {Function: sink}},
+	}
+	names := func(stacks []vulncheck.CallStack) []string {
+		var names []string
+		for _, s := range stacks {
+			var fns []string
+			for _, e := range s {
+				fns = append(fns, e.Function.Name)
+			}
+			names = append(names, strings.Join(fns, "->"))
+		}
+		return names
+	}
+	for _, tc := range []struct {
+		strategy string
+		want     string
+	}{
+		{representativeFirst, "main->A->Vuln"},
+		{representativeShortest, "main->A->Vuln"},
+		{representativeStatic, "main->B->C->Vuln"},
+		{representativeMain, "main->D->E->Vuln"},
+	} {
+		t.Run(tc.strategy, func(t *testing.T) {
+			got := append([]vulncheck.CallStack{}, stacks...)
+			orderCallStacks(got, tc.strategy)
+			if diff := cmp.Diff(tc.want, names(got)[0]); diff != "" {
+				t.Errorf("representative mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
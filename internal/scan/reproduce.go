@@ -0,0 +1,65 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"strings"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// reproduceCommand returns a govulncheck command that reproduces only
+// finding f of a source scan configured by cfg. The command scans the
+// package of the entry point of the trace of f, or the packages of the
+// main module that import the vulnerable package if f is not called, for
+// the OSV entry of f. If neither is known, the patterns of the scan are
+// scanned instead.
+func reproduceCommand(cfg *config, f *govulncheck.Finding) string {
+	args := []string{"govulncheck"}
+	if cfg.dir != "" {
+		args = append(args, "-C", cfg.dir)
+	}
+	if cfg.ScanLevel != "" && !cfg.ScanLevel.WantSymbols() {
+		args = append(args, "-scan-level="+string(cfg.ScanLevel))
+	}
+	if cfg.test {
+		args = append(args, "-test")
+	}
+	if len(cfg.tags) > 0 {
+		args = append(args, "-tags="+strings.Join(cfg.tags, ","))
+	}
+	if f.Tool != nil {
+		args = append(args, "-tools")
+	}
+	args = append(args, "-osv", f.OSV)
+	args = append(args, entryPackages(cfg, f)...)
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
+
+// entryPackages returns the packages to scan to reproduce finding f.
+func entryPackages(cfg *config, f *govulncheck.Finding) []string {
+	if last := f.Trace[len(f.Trace)-1]; f.Trace[0].Function != "" && last.Package != "" {
+		// Entry points in test files belong to the package under test,
+		// or to its external test package.
+		pkg := strings.TrimSuffix(last.Package, ".test")
+		return []string{strings.TrimSuffix(pkg, "_test")}
+	}
+	if len(f.ImportedBy) > 0 {
+		return f.ImportedBy
+	}
+	return cfg.patterns
+}
+
+// shellQuote quotes s for a POSIX shell, unless it only contains
+// characters that need no quoting.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:=,@+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+func TestReproduceCommand(t *testing.T) {
+	called := &govulncheck.Finding{
+		OSV: "GO-2021-0113",
+		Trace: []*govulncheck.Frame{
+			{Module: "golang.org/x/text", Package: "golang.org/x/text/language", Function: "Parse"},
+			{Module: "example.com/m", Package: "example.com/m/cmd_test", Function: "TestParse"},
+		},
+	}
+	imported := &govulncheck.Finding{
+		OSV:        "GO-2021-0054",
+		Trace:      []*govulncheck.Frame{{Module: "github.com/tidwall/gjson", Package: "github.com/tidwall/gjson"}},
+		ImportedBy: []string{"example.com/m/a", "example.com/m/b"},
+	}
+	required := &govulncheck.Finding{
+		OSV:   "GO-2021-0265",
+		Trace: []*govulncheck.Frame{{Module: "github.com/tidwall/gjson"}},
+	}
+	for _, test := range []struct {
+		name    string
+		cfg     *config
+		finding *govulncheck.Finding
+		want    string
+	}{
+		{"called", &config{}, called, "govulncheck -osv GO-2021-0113 example.com/m/cmd"},
+		{"imported", &config{dir: "my dir", tags: []string{"a", "b"}}, imported, "govulncheck -C 'my dir' -tags=a,b -osv GO-2021-0054 example.com/m/a example.com/m/b"},
+		{"required", &config{Config: govulncheck.Config{ScanLevel: "module"}, test: true, patterns: []string{"./..."}}, required, "govulncheck -scan-level=module -test -osv GO-2021-0265 ./..."},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := reproduceCommand(test.cfg, test.finding); got != test.want {
+				t.Errorf("got %q; want %q", got, test.want)
+			}
+		})
+	}
+}
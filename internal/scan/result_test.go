@@ -73,3 +73,27 @@ func TestFrame(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatPosition(t *testing.T) {
+	pos := &govulncheck.Position{Filename: "/src/mod/main.go", Line: 12, Column: 3}
+	for _, test := range []struct {
+		format string
+		pos    *govulncheck.Position
+		want   string
+	}{
+		{"", pos, "/src/mod/main.go:12:3"},
+		{posFormatColon, pos, "/src/mod/main.go:12:3"},
+		{posFormatParen, pos, "/src/mod/main.go(12,3)"},
+		{posFormatVSCode, pos, "vscode://file/src/mod/main.go:12:3"},
+		{posFormatOffset, &govulncheck.Position{Filename: "/src/mod/main.go", Offset: 245, Line: 12, Column: 3}, "/src/mod/main.go:12:3:#245"},
+		{posFormatOffset, &govulncheck.Position{Filename: "/src/mod/main.go", Line: 1, Column: 1}, "/src/mod/main.go:1:1:#0"},
+		// The offsets of positions in binaries are unknown.
+		{posFormatOffset, pos, "/src/mod/main.go:12:3"},
+		{posFormatParen, &govulncheck.Position{Filename: "/src/mod/main.go"}, ""},
+		{posFormatVSCode, nil, ""},
+	} {
+		if got := formatPosition(test.pos, test.format); got != test.want {
+			t.Errorf("formatPosition(%v, %q) = %q, want %q", test.pos, test.format, got, test.want)
+		}
+	}
+}
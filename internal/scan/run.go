@@ -17,21 +17,95 @@ import (
 
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
 )
 
+// Hooks are extension points for in-process users of govulncheck.
+type Hooks struct {
+	// Transformers are applied to the findings of a scan, in order,
+	// before the findings are emitted. Each transformer receives the
+	// findings returned by the previous one. An error returned by a
+	// transformer aborts the scan.
+	Transformers []govulncheck.Transformer
+
+	// OnCallEdge, if not nil, is called in source mode for each call
+	// graph edge explored while searching for call stacks that lead to
+	// vulnerable symbols. See vulncheck.CallStacksWithEdges.
+	OnCallEdge vulncheck.EdgeFunc
+
+	// EntryPackage, if not nil, reports whether the package with path
+	// pkgPath, among those matched by the patterns, is an entry point of
+	// source analysis. Other matched packages are only analyzed as
+	// dependencies of the entry points, so that vulnerabilities are only
+	// reported when they are reachable from the entry points. See
+	// vulncheck.SourceWithEntryFilter.
+	EntryPackage vulncheck.EntryFilter
+
+	// EntryFunctions, if not empty, are the only entry points of source
+	// analysis, so that only the vulnerabilities reachable from them are
+	// reported as called, and EntryPackage is ignored. The scan fails if
+	// some of them is not a function or method of a package matched by
+	// the patterns. See vulncheck.SourceWithEntryFunctions.
+	EntryFunctions []vulncheck.EntryFunction
+
+	// SeverityMapper, if not nil, assigns the severities of the
+	// organization running the scan to findings, which are reported in
+	// govulncheck.Finding.OrgSeverity. It takes precedence over the
+	// -severity-map flag.
+	SeverityMapper govulncheck.SeverityMapper
+
+	// PositionMapper, if not nil, rewrites the source positions of the
+	// findings of source mode, in their traces, definitions, and tool
+	// directives, after they are made relative by the -relpath flag and
+	// before the findings are transformed and emitted.
+	PositionMapper govulncheck.PositionMapper
+
+	// Handler, if not nil, receives the output of the scan instead of a
+	// handler writing to stdout in the format selected by the flags. In
+	// convert mode, it receives the messages read from the JSON input.
+	// The scan fails with the error returned by its Flush method, if any.
+	// A govulncheck.MultiHandler hands the output to several handlers.
+	Handler govulncheck.Handler
+}
+
 // RunGovulncheck performs main govulncheck functionality and exits the
 // program upon success with an appropriate exit status. Otherwise,
 // returns an error.
-func RunGovulncheck(ctx context.Context, env []string, r io.Reader, stdout io.Writer, stderr io.Writer, args []string) error {
-	cfg := &config{env: env}
+func RunGovulncheck(ctx context.Context, env []string, r io.Reader, stdout io.Writer, stderr io.Writer, args []string, hooks Hooks) error {
+	cfg := &config{env: env, hooks: hooks, now: time.Now}
 	if err := parseFlags(cfg, stderr, args); err != nil {
 		return err
 	}
 	if cfg.mode == modeConvert {
-		return convertJSONToText(r, stdout)
+		if cfg.hooks.Handler != nil {
+			if err := handleJSON(r, cfg.hooks.Handler); err != nil {
+				return err
+			}
+			return Flush(cfg.hooks.Handler)
+		}
+		text, newHandler := convertHandler(cfg)
+		err := convertJSONTo(r, stdout, newHandler)
+		if text && err == errVulnerabilitiesFound {
+			// Converting to text does not fail for the findings.
+			return nil
+		}
+		return err
 	}
 
-	client, err := client.NewClient(cfg.db, nil)
+	severity, err := severityMapper(cfg)
+	if err != nil {
+		return err
+	}
+	cfg.severity = severity
+
+	cfg.download = newDownloadReporter(cfg.db)
+	opts := &client.Options{OnDownload: cfg.download.download}
+	if cfg.dbOverlay != "" {
+		cfg.download.overlay = cfg.dbOverlay
+		opts.Overlay = absPath(cfg.dbOverlay, filepath.FromSlash(cfg.dir))
+		opts.OnDuplicate = cfg.download.duplicate
+	}
+	client, err := client.NewClient(cfg.db, opts)
 	if err != nil {
 		return fmt.Errorf("creating client: %w", err)
 	}
@@ -39,11 +113,32 @@ func RunGovulncheck(ctx context.Context, env []string, r io.Reader, stdout io.Wr
 	prepareConfig(ctx, cfg, client)
 	var handler govulncheck.Handler
 	switch {
+	case cfg.hooks.Handler != nil:
+		handler = cfg.hooks.Handler
 	case cfg.json:
-		handler = govulncheck.NewJSONHandler(stdout)
+		handler = govulncheck.NewBufferedJSONHandler(stdout, cfg.flush)
+		if cfg.order != "" {
+			handler = &traceOrderHandler{Handler: handler, order: cfg.order}
+		}
+	case cfg.count:
+		handler = newCountHandler(stdout)
+	case cfg.format == formatGitHub:
+		handler = newGitHubHandler(stdout, cfg)
+	case cfg.format == formatDOT:
+		handler = newDOTHandler(stdout, cfg)
+	case cfg.format == formatCDX:
+		handler = newCycloneDXHandler(stdout, cfg)
+	case cfg.format == formatIDs:
+		handler = newIDsHandler(stdout, cfg)
+	case cfg.format == formatSARIF:
+		handler = newSARIFHandler(stdout, cfg)
 	default:
 		th := NewTextHandler(stdout)
 		th.Show(cfg.show)
+		th.testNoFail = cfg.testNoFail
+		th.separateTestDeps = cfg.separateTestDeps
+		th.keepOrder = cfg.sortBy != ""
+		th.posFormat = cfg.posFormat
 		handler = th
 	}
 
@@ -51,17 +146,51 @@ func RunGovulncheck(ctx context.Context, env []string, r io.Reader, stdout io.Wr
 	if err := handler.Config(&cfg.Config); err != nil {
 		return err
 	}
+	cfg.download.setHandler(handler)
+	if err := checkDBAge(handler, cfg); err != nil {
+		if cfg.json {
+			Flush(handler)
+		}
+		return err
+	}
 
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
 	switch cfg.mode {
 	case modeSource:
 		dir := filepath.FromSlash(cfg.dir)
-		err = runSource(ctx, handler, cfg, client, dir)
+		if cfg.archive != "" {
+			root, cleanup, err := extractSource(cfg, r)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			dir = root
+		}
+		if len(cfg.tryUpgrades) == 0 {
+			err = runSource(ctx, handler, cfg, client, dir)
+			break
+		}
+		// The messages of the scan are also recorded, so that its
+		// findings can be compared with those of the upgrades. A
+		// MultiHandler passes the optional messages on to handler.
+		rec := &govulncheck.Recorder{}
+		if err = runSource(ctx, govulncheck.NewMultiHandler(handler, rec), cfg, client, dir); err == nil {
+			err = tryUpgrades(ctx, handler, cfg, client, dir, recordedFindings(rec))
+		}
 	case modeBinary:
 		err = runBinary(ctx, handler, cfg, client)
 	case modeQuery:
 		err = runQuery(ctx, handler, cfg, client)
 	}
 	if err != nil {
+		if cfg.json {
+			// Write the messages buffered before the failure.
+			Flush(handler)
+		}
 		return err
 	}
 	if err := Flush(handler); err != nil {
@@ -74,18 +203,15 @@ func prepareConfig(ctx context.Context, cfg *config, client *client.Client) {
 	cfg.ProtocolVersion = govulncheck.ProtocolVersion
 	cfg.DB = cfg.db
 	if cfg.mode == modeSource && cfg.GoVersion == "" {
-		const goverPrefix = "GOVERSION="
-		for _, env := range cfg.env {
-			if val := strings.TrimPrefix(env, goverPrefix); val != env {
-				cfg.GoVersion = val
-			}
-		}
-		if cfg.GoVersion == "" {
-			if out, err := exec.Command("go", "env", "GOVERSION").Output(); err == nil {
-				cfg.GoVersion = string(out)
-			}
+		cfg.toolchainVersion = toolchainVersion(cfg)
+		cfg.GoVersion = cfg.toolchainVersion
+		if cfg.goVersion != "" {
+			cfg.GoVersion = cfg.goVersion
 		}
 	}
+	if cfg.mode == modeSource && cfg.matchPlatform && cfg.GOOS == "" && cfg.GOARCH == "" {
+		cfg.GOOS, cfg.GOARCH = goPlatform(cfg)
+	}
 	if bi, ok := debug.ReadBuildInfo(); ok {
 		scannerVersion(cfg, bi)
 	}
@@ -94,6 +220,47 @@ func prepareConfig(ctx context.Context, cfg *config, client *client.Client) {
 	}
 }
 
+// toolchainVersion returns the version of the Go toolchain that loads
+// the packages of cfg, as in "go1.21.3", or "" if it is unknown.
+func toolchainVersion(cfg *config) string {
+	const goverPrefix = "GOVERSION="
+	var version string
+	for _, e := range cfg.env {
+		if val := strings.TrimPrefix(e, goverPrefix); val != e {
+			version = val
+		}
+	}
+	if version == "" {
+		if vars := goEnv(cfg, "GOVERSION"); len(vars) == 1 {
+			version = vars[0]
+		}
+	}
+	return version
+}
+
+// goPlatform returns the GOOS and GOARCH that the go command loads the
+// packages of cfg for, or empty strings if they are unknown.
+func goPlatform(cfg *config) (goos, goarch string) {
+	vars := goEnv(cfg, "GOOS", "GOARCH")
+	if len(vars) != 2 {
+		return "", ""
+	}
+	return vars[0], vars[1]
+}
+
+// goEnv returns the values of the go env variables vars in the directory
+// and environment of cfg, or nil if they cannot be read.
+func goEnv(cfg *config, vars ...string) []string {
+	cmd := exec.Command("go", append([]string{"env"}, vars...)...)
+	cmd.Dir = filepath.FromSlash(cfg.dir)
+	cmd.Env = cfg.env
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
 // scannerVersion reconstructs the current version of
 // this binary used from the build info.
 func scannerVersion(cfg *config, bi *debug.BuildInfo) {
@@ -133,13 +300,34 @@ func scannerVersion(cfg *config, bi *debug.BuildInfo) {
 	cfg.ScannerVersion = buf.String()
 }
 
-// convertJSONToText converts r, which is expected to be the JSON output of govulncheck,
-// into the text output, and writes the output to w.
-func convertJSONToText(r io.Reader, w io.Writer) error {
-	h := NewTextHandler(w)
-	if err := govulncheck.HandleJSON(r, h); err != nil {
+// convertHandler returns the function creating the handler that writes
+// the output of cfg in convert mode, and whether the output is text.
+func convertHandler(cfg *config) (text bool, newHandler func(io.Writer) govulncheck.Handler) {
+	switch {
+	case cfg.count:
+		return false, func(w io.Writer) govulncheck.Handler { return newCountHandler(w) }
+	case cfg.format == formatGitHub:
+		return false, func(w io.Writer) govulncheck.Handler { return newGitHubHandler(w, cfg) }
+	case cfg.format == formatDOT:
+		return false, func(w io.Writer) govulncheck.Handler { return newDOTHandler(w, cfg) }
+	case cfg.format == formatCDX:
+		return false, func(w io.Writer) govulncheck.Handler { return newCycloneDXHandler(w, cfg) }
+	case cfg.format == formatIDs:
+		return false, func(w io.Writer) govulncheck.Handler { return newIDsHandler(w, cfg) }
+	case cfg.format == formatSARIF:
+		return false, func(w io.Writer) govulncheck.Handler { return newSARIFHandler(w, cfg) }
+	default:
+		return true, func(w io.Writer) govulncheck.Handler { return NewTextHandler(w) }
+	}
+}
+
+// convertJSONTo converts r, which is expected to be the JSON output of
+// govulncheck, into the output of the handler that newHandler creates
+// for w, and returns the error of flushing the handler.
+func convertJSONTo(r io.Reader, w io.Writer, newHandler func(io.Writer) govulncheck.Handler) error {
+	h := newHandler(w)
+	if err := handleJSON(r, h); err != nil {
 		return err
 	}
-	Flush(h)
-	return nil
+	return Flush(h)
 }
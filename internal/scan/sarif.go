@@ -0,0 +1,349 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// sarifHandler is a handler that writes the findings as a SARIF 2.1.0
+// log, which GitHub code scanning and other static analysis tools
+// consume. See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+//
+// Each vulnerability of the findings is a rule, described by its OSV
+// entry, and each finding a result of the rule. Called findings are
+// located at the position where the top module calls into other code
+// toward the vulnerable symbol, like GitHub annotations, and their code
+// flow is their trace. Findings that are only imported have no such
+// position, and are located at the go.mod file of the scanned module.
+type sarifHandler struct {
+	w        io.Writer
+	cfg      *govulncheck.Config
+	osvs     []*osv.Entry
+	findings []*findingSummary
+
+	// workspace is the directory that file names are reported
+	// relative to, which is the root of the checked out repository.
+	workspace string
+
+	// binary is set in binary mode, where findings have no go.mod file
+	// to be located at.
+	binary bool
+
+	// testNoFail is set if vulnerabilities that are only called
+	// from tests do not cause failure.
+	testNoFail bool
+}
+
+// newSARIFHandler returns a handler that writes to w. The workspace is
+// read from the GITHUB_WORKSPACE environment variable of cfg, as for
+// GitHub output.
+func newSARIFHandler(w io.Writer, cfg *config) *sarifHandler {
+	return &sarifHandler{
+		w:          w,
+		cfg:        &govulncheck.Config{},
+		workspace:  newGitHubHandler(nil, cfg).workspace,
+		binary:     cfg.mode == modeBinary,
+		testNoFail: cfg.testNoFail,
+	}
+}
+
+// The SARIF log, limited to the properties written by govulncheck.
+// See https://json.schemastore.org/sarif-2.1.0.json.
+type (
+	sarifLog struct {
+		Schema  string      `json:"$schema"`
+		Version string      `json:"version"`
+		Runs    []*sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    *sarifTool     `json:"tool"`
+		Results []*sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver *sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string       `json:"name"`
+		Version        string       `json:"version,omitempty"`
+		InformationURI string       `json:"informationUri"`
+		Rules          []*sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string          `json:"id"`
+		ShortDescription *sarifMessage   `json:"shortDescription"`
+		FullDescription  *sarifMessage   `json:"fullDescription,omitempty"`
+		HelpURI          string          `json:"helpUri,omitempty"`
+		Help             *sarifMessage   `json:"help,omitempty"`
+		Properties       *sarifRuleProps `json:"properties,omitempty"`
+	}
+
+	sarifRuleProps struct {
+		Tags []string `json:"tags,omitempty"`
+		// SecuritySeverity is the CVSS v3 base score of the vulnerability,
+		// by which GitHub code scanning ranks security alerts.
+		SecuritySeverity string `json:"security-severity,omitempty"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleID              string            `json:"ruleId"`
+		RuleIndex           int               `json:"ruleIndex"`
+		Level               string            `json:"level"`
+		Message             *sarifMessage     `json:"message"`
+		Locations           []*sarifLocation  `json:"locations,omitempty"`
+		CodeFlows           []*sarifCodeFlow  `json:"codeFlows,omitempty"`
+		PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+		Message          *sarifMessage          `json:"message,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion           `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+
+	sarifCodeFlow struct {
+		ThreadFlows []*sarifThreadFlow `json:"threadFlows"`
+	}
+
+	sarifThreadFlow struct {
+		Locations []*sarifThreadFlowLocation `json:"locations"`
+	}
+
+	sarifThreadFlowLocation struct {
+		Location *sarifLocation `json:"location"`
+	}
+)
+
+// Result levels and the values of SARIF.
+const (
+	sarifError   = "error"
+	sarifWarning = "warning"
+	sarifNote    = "note"
+
+	// sarifSrcRoot is the base of the artifacts located relative to the
+	// root of the sources, which SARIF consumers resolve.
+	sarifSrcRoot = "%SRCROOT%"
+
+	// sarifHashKey is the key of the partial fingerprint of results, the
+	// identity hash of their finding, by which SARIF consumers match the
+	// results of successive scans.
+	sarifHashKey = "govulncheck/hash/v1"
+)
+
+// Config records the scanner of the log.
+func (h *sarifHandler) Config(config *govulncheck.Config) error {
+	h.cfg = config
+	return nil
+}
+
+// Progress does nothing, so that the output is only made of the log.
+func (h *sarifHandler) Progress(progress *govulncheck.Progress) error {
+	return nil
+}
+
+// OSV gathers osv entries to be written.
+func (h *sarifHandler) OSV(entry *osv.Entry) error {
+	h.osvs = append(h.osvs, entry)
+	return nil
+}
+
+// Finding gathers vulnerability findings to be written.
+func (h *sarifHandler) Finding(finding *govulncheck.Finding) error {
+	if err := validateFindings(finding); err != nil {
+		return err
+	}
+	h.findings = append(h.findings, newFindingSummary(finding))
+	return nil
+}
+
+// Flush writes the log, with a single run that has a rule for each OSV
+// entry of the findings and a result for each finding.
+func (h *sarifHandler) Flush() error {
+	fixupFindings(h.osvs, h.findings)
+	name := h.cfg.ScannerName
+	if name == "" {
+		name = "govulncheck"
+	}
+	run := &sarifRun{
+		Tool: &sarifTool{Driver: &sarifDriver{
+			Name:           name,
+			Version:        h.cfg.ScannerVersion,
+			InformationURI: "https://pkg.go.dev/golang.org/x/vuln/cmd/govulncheck",
+			Rules:          []*sarifRule{},
+		}},
+		Results: []*sarifResult{},
+	}
+	for _, vuln := range groupByVuln(h.findings) {
+		index := len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifVulnRule(vuln[0]))
+		for _, f := range vuln {
+			run.Results = append(run.Results, h.result(f, index))
+		}
+	}
+	log := &sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []*sarifRun{run},
+	}
+	enc := json.NewEncoder(h.w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(log); err != nil {
+		return err
+	}
+	if isFailure(h.findings, h.testNoFail) {
+		return errVulnerabilitiesFound
+	}
+	return nil
+}
+
+// sarifVulnRule returns the rule of the vulnerability of f, described by
+// its OSV entry.
+func sarifVulnRule(f *findingSummary) *sarifRule {
+	entry := f.OSV
+	r := &sarifRule{
+		ID:               entry.ID,
+		ShortDescription: &sarifMessage{Text: entry.ID},
+		HelpURI:          "https://pkg.go.dev/vuln/" + entry.ID,
+		Properties:       &sarifRuleProps{Tags: []string{"security", "vulnerability"}},
+	}
+	if entry.Summary != "" {
+		r.ShortDescription.Text = entry.ID + ": " + entry.Summary
+	}
+	if entry.Details != "" {
+		r.FullDescription = &sarifMessage{Text: entry.Details}
+	}
+	if entry.DatabaseSpecific != nil && entry.DatabaseSpecific.URL != "" {
+		r.HelpURI = entry.DatabaseSpecific.URL
+	}
+	help := entry.Details
+	if help == "" {
+		help = entry.Summary
+	}
+	if len(entry.Aliases) > 0 {
+		help += "\n\nAliases: " + strings.Join(entry.Aliases, ", ")
+	}
+	help += "\n\nMore info: " + r.HelpURI
+	r.Help = &sarifMessage{Text: strings.TrimSpace(help)}
+	r.Properties.Tags = append(r.Properties.Tags, f.CWEs...)
+	if _, score := cvss3Severity(entry); score > 0 {
+		r.Properties.SecuritySeverity = fmt.Sprintf("%.1f", score)
+	}
+	return r
+}
+
+// result returns the result of finding f of the rule at index.
+func (h *sarifHandler) result(f *findingSummary, index int) *sarifResult {
+	r := &sarifResult{
+		RuleID:    f.OSV.ID,
+		RuleIndex: index,
+		Level:     sarifNote,
+		// The message of GitHub annotations, which tells what to
+		// upgrade, is also fit for code scanning.
+		Message: &sarifMessage{Text: strings.TrimSpace(githubMessage(f))},
+	}
+	if f.Hash != "" {
+		r.PartialFingerprints = map[string]string{sarifHashKey: f.Hash}
+	}
+	if isCalledFinding(f.Finding) {
+		r.Level = sarifError
+		if f.LikelyFalsePositive || (h.testNoFail && f.TestOnly) {
+			r.Level = sarifWarning
+		}
+	}
+	if f.Trace[0].Function != "" {
+		if loc := h.location(f.Trace[topFrame(f.Trace)].Position, nil); loc != nil {
+			r.Locations = []*sarifLocation{loc}
+			r.CodeFlows = h.codeFlows(f.Trace)
+			return r
+		}
+	}
+	if !h.binary {
+		r.Locations = []*sarifLocation{{PhysicalLocation: &sarifPhysicalLocation{
+			ArtifactLocation: &sarifArtifactLocation{URI: "go.mod", URIBaseID: sarifSrcRoot},
+		}}}
+	}
+	return r
+}
+
+// codeFlows returns the code flow of trace, from the entry point to the
+// vulnerable symbol, made of the calls whose position is known.
+func (h *sarifHandler) codeFlows(trace []*govulncheck.Frame) []*sarifCodeFlow {
+	flow := &sarifThreadFlow{}
+	for i := len(trace) - 1; i > 0; i-- {
+		var b strings.Builder
+		addSymbolName(&b, trace[i], true)
+		b.WriteString(" calls ")
+		addSymbolName(&b, trace[i-1], true)
+		if loc := h.location(trace[i].Position, &sarifMessage{Text: b.String()}); loc != nil {
+			flow.Locations = append(flow.Locations, &sarifThreadFlowLocation{Location: loc})
+		}
+	}
+	if len(flow.Locations) == 0 {
+		return nil
+	}
+	return []*sarifCodeFlow{{ThreadFlows: []*sarifThreadFlow{flow}}}
+}
+
+// location returns the location of pos with msg, or nil if pos is not a
+// valid position.
+func (h *sarifHandler) location(pos *govulncheck.Position, msg *sarifMessage) *sarifLocation {
+	if pos == nil || pos.Line <= 0 || pos.Filename == "" {
+		return nil
+	}
+	return &sarifLocation{
+		PhysicalLocation: &sarifPhysicalLocation{
+			ArtifactLocation: h.artifact(pos.Filename),
+			Region:           &sarifRegion{StartLine: pos.Line, StartColumn: pos.Column},
+		},
+		Message: msg,
+	}
+}
+
+// artifact returns the location of the file filename, relative to the
+// root of the sources if filename is relative or in the workspace, and as
+// a file URI otherwise.
+func (h *sarifHandler) artifact(filename string) *sarifArtifactLocation {
+	name := relativeFilename(filename, h.workspace)
+	if !filepath.IsAbs(name) {
+		return &sarifArtifactLocation{URI: filepath.ToSlash(name), URIBaseID: sarifSrcRoot}
+	}
+	path := filepath.ToSlash(name)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths, as in "C:/src", are rooted under "/".
+		path = "/" + path
+	}
+	return &sarifArtifactLocation{URI: (&url.URL{Scheme: "file", Path: path}).String()}
+}
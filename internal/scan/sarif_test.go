@@ -0,0 +1,102 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestSARIFHandler(t *testing.T) {
+	vuln := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln", Function: "V",
+		Position: &govulncheck.Position{Filename: "/root/vmod/vuln/vuln.go", Line: 5, Column: 2}}
+	imported := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v1.0.0", Package: "golang.org/vmod/vuln"}
+	main := &govulncheck.Frame{Module: "golang.org/main", Package: "golang.org/main", Function: "main",
+		Position: &govulncheck.Position{Filename: "/ws/main.go", Line: 10, Column: 3}}
+
+	buf := &bytes.Buffer{}
+	h := newSARIFHandler(buf, &config{env: []string{"GITHUB_WORKSPACE=/ws"}})
+	if err := h.Config(&govulncheck.Config{ScannerName: "govulncheck", ScannerVersion: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []*osv.Entry{{ID: "GO-0000-0001", Summary: "A vulnerability"}, {ID: "GO-0000-0002"}} {
+		if err := h.OSV(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []*govulncheck.Finding{
+		{OSV: "GO-0000-0001", FixedVersion: "v1.0.1", CWEs: []string{"CWE-22"}, Hash: "h1", Trace: []*govulncheck.Frame{vuln, main}},
+		{OSV: "GO-0000-0002", Trace: []*govulncheck.Frame{imported}},
+	} {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Flush(); err != errVulnerabilitiesFound {
+		t.Fatalf("got error %v; want %v", err, errVulnerabilitiesFound)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("got %d runs; want 1", len(log.Runs))
+	}
+	run := log.Runs[0]
+	if d := run.Tool.Driver; d.Name != "govulncheck" || d.Version != "v1.0.0" {
+		t.Errorf("got driver %s %s; want govulncheck v1.0.0", d.Name, d.Version)
+	}
+	var rules []string
+	for _, r := range run.Tool.Driver.Rules {
+		rules = append(rules, r.ID)
+	}
+	if diff := cmp.Diff([]string{"GO-0000-0002", "GO-0000-0001"}, rules); diff != "" {
+		t.Errorf("rules mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"security", "vulnerability", "CWE-22"}, run.Tool.Driver.Rules[1].Properties.Tags); diff != "" {
+		t.Errorf("tags mismatch (-want, +got):\n%s", diff)
+	}
+
+	want := []*sarifResult{
+		{
+			RuleID:    "GO-0000-0002",
+			RuleIndex: 0,
+			Level:     sarifNote,
+			Message:   &sarifMessage{Text: strings.TrimSpace(githubMessage(h.findings[0]))},
+			Locations: []*sarifLocation{{PhysicalLocation: &sarifPhysicalLocation{
+				ArtifactLocation: &sarifArtifactLocation{URI: "go.mod", URIBaseID: sarifSrcRoot},
+			}}},
+		},
+		{
+			RuleID:    "GO-0000-0001",
+			RuleIndex: 1,
+			Level:     sarifError,
+			Message:   &sarifMessage{Text: strings.TrimSpace(githubMessage(h.findings[1]))},
+			Locations: []*sarifLocation{{PhysicalLocation: &sarifPhysicalLocation{
+				ArtifactLocation: &sarifArtifactLocation{URI: "main.go", URIBaseID: sarifSrcRoot},
+				Region:           &sarifRegion{StartLine: 10, StartColumn: 3},
+			}}},
+			CodeFlows: []*sarifCodeFlow{{ThreadFlows: []*sarifThreadFlow{{Locations: []*sarifThreadFlowLocation{{
+				Location: &sarifLocation{
+					PhysicalLocation: &sarifPhysicalLocation{
+						ArtifactLocation: &sarifArtifactLocation{URI: "main.go", URIBaseID: sarifSrcRoot},
+						Region:           &sarifRegion{StartLine: 10, StartColumn: 3},
+					},
+					Message: &sarifMessage{Text: "main.main calls vuln.V"},
+				},
+			}}}}}},
+			PartialFingerprints: map[string]string{sarifHashKey: "h1"},
+		},
+	}
+	if diff := cmp.Diff(want, run.Results); diff != "" {
+		t.Errorf("results mismatch (-want, +got):\n%s", diff)
+	}
+}
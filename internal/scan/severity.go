@@ -0,0 +1,85 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// severityMap maps findings to the severities of the organization
+// running the scan. It is read from the file named by the -severity-map
+// flag.
+type severityMap struct {
+	// Rules are tried in order, and a finding is given the severity of
+	// the first rule it matches. Findings matching no rule are given
+	// no severity.
+	Rules []*severityRule `json:"rules"`
+}
+
+// severityRule maps the findings in the modules matching Modules, a
+// comma-separated list of glob patterns as for GOPRIVATE, whose CVSS v3
+// base score is at least MinScore, to Severity. An empty Modules matches
+// all modules.
+type severityRule struct {
+	Modules  string  `json:"modules,omitempty"`
+	MinScore float64 `json:"min_score,omitempty"`
+	Severity string  `json:"severity"`
+}
+
+// readSeverityMap reads the severity map at path.
+func readSeverityMap(path string) (*severityMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading severity map: %v", err)
+	}
+	m := &severityMap{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parsing severity map %s: %v", path, err)
+	}
+	for i, r := range m.Rules {
+		if r.Severity == "" {
+			return nil, fmt.Errorf("parsing severity map %s: rule %d has no severity", path, i+1)
+		}
+	}
+	return m, nil
+}
+
+// severity returns the severity of the first rule of m matching a
+// finding in modulePath whose CVSS v3 base score is score, or "".
+func (m *severityMap) severity(modulePath, _ string, score float64) string {
+	for _, r := range m.Rules {
+		if r.Modules != "" && !module.MatchPrefixPatterns(r.Modules, modulePath) {
+			continue
+		}
+		if score < r.MinScore {
+			continue
+		}
+		return r.Severity
+	}
+	return ""
+}
+
+// severityMapper returns the mapper of findings to the severities of the
+// organization, which is the mapper of the hooks if any, or else the one
+// read from the -severity-map file, or nil if there is neither.
+func severityMapper(cfg *config) (govulncheck.SeverityMapper, error) {
+	if cfg.hooks.SeverityMapper != nil {
+		return cfg.hooks.SeverityMapper, nil
+	}
+	if cfg.severityMap == "" {
+		return nil, nil
+	}
+	m, err := readSeverityMap(absPath(cfg.severityMap, filepath.FromSlash(cfg.dir)))
+	if err != nil {
+		return nil, fmt.Errorf("govulncheck: %v", err)
+	}
+	return m.severity, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestSeverityMap(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "severity.json"), []byte(`{"rules": [
+		{"modules": "golang.org/x/*", "min_score": 9, "severity": "P0"},
+		{"min_score": 7, "severity": "P1"},
+		{"modules": "example.com/internal", "severity": "P2"}
+	]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mapper, err := severityMapper(&config{severityMap: "severity.json", dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		module string
+		score  float64
+		want   string
+	}{
+		{"golang.org/x/text", 9.8, "P0"},
+		{"github.com/tidwall/gjson", 9.8, "P1"},
+		{"golang.org/x/text", 7.5, "P1"},
+		{"example.com/internal", 5, "P2"},
+		{"example.com/internal/sub", 0, "P2"},
+		{"github.com/tidwall/gjson", 6.9, ""},
+	} {
+		if got := mapper(test.module, "", test.score); got != test.want {
+			t.Errorf("severity of %s with score %v = %q; want %q", test.module, test.score, got, test.want)
+		}
+	}
+
+	// The mapper of the hooks takes precedence over the file.
+	hook := func(string, string, float64) string { return "hook" }
+	mapper, err = severityMapper(&config{severityMap: "severity.json", dir: dir, hooks: Hooks{SeverityMapper: hook}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mapper("golang.org/x/text", "", 9.8); got != "hook" {
+		t.Errorf("got severity %q; want the one of the hook", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"rules": [{"min_score": 7}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = severityMapper(&config{severityMap: "bad.json", dir: dir})
+	if err == nil || !strings.Contains(err.Error(), "rule 1 has no severity") {
+		t.Errorf("got error %v; want a rule without severity", err)
+	}
+}
+
+func TestEmitterSeverity(t *testing.T) {
+	const vector = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+	var gotModule, gotVector string
+	var gotScore float64
+	cfg := &config{severity: func(module, cvss string, score float64) string {
+		gotModule, gotVector, gotScore = module, cvss, score
+		return "P0"
+	}}
+	f := &govulncheck.Finding{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{{Module: "golang.org/vmod"}}}
+	e := &emitter{cfg: cfg, buffered: true, osvs: map[string]*osv.Entry{
+		"GO-0000-0001": {ID: "GO-0000-0001", Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: vector}}},
+	}}
+	if err := e.add(f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Severity != vector || f.OrgSeverity != "P0" {
+		t.Errorf("got severities %q and %q; want %q and P0", f.Severity, f.OrgSeverity, vector)
+	}
+	if gotModule != "golang.org/vmod" || gotVector != vector || gotScore != 9.8 {
+		t.Errorf("mapper called with %q, %q, %v; want golang.org/vmod, %q, 9.8", gotModule, gotVector, gotScore, vector)
+	}
+}
@@ -6,12 +6,16 @@ package scan
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/token"
+	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/vuln/internal"
@@ -27,19 +31,43 @@ import (
 // symbol is actually exercised) or just imported by the package
 // (likely having a non-affecting outcome).
 func runSource(ctx context.Context, handler govulncheck.Handler, cfg *config, client *client.Client, dir string) error {
+	if cfg.goVersion != "" && cfg.toolchainVersion != "" && cfg.goVersion != cfg.toolchainVersion {
+		msg := fmt.Sprintf("Warning: the standard library is matched against %s, but packages are loaded by the %s toolchain, whose standard library may differ.", cfg.goVersion, cfg.toolchainVersion)
+		if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+			return err
+		}
+	}
 	var pkgs []*packages.Package
 	graph := vulncheck.NewPackageGraph(cfg.GoVersion)
-	pkgConfig := &packages.Config{
-		Dir:   dir,
-		Tests: cfg.test,
-		Env:   cfg.env,
+	pkgConfig, err := packagesConfig(cfg, dir)
+	if err != nil {
+		return fmt.Errorf("govulncheck: %v", err)
+	}
+	pkgConfig.Context = ctx
+	start := time.Now()
+	pkgs, err = graph.LoadPackages(pkgConfig, cfg.tags, cfg.patterns)
+	load := time.Since(start)
+	// The packages that cannot be resolved because of go.sum are not
+	// loaded, but the others are still scanned.
+	var sumErrs []packages.Error
+	if err != nil && ctx.Err() == nil {
+		if sumErrs = goSumErrors(pkgs); len(sumErrs) > 0 {
+			err = nil
+		}
 	}
-	pkgs, err := graph.LoadPackages(pkgConfig, cfg.tags, cfg.patterns)
 	if err != nil {
+		// Nothing is known before the packages are loaded, so the scan
+		// has no partial results.
+		if timedOut(cfg, ctx.Err()) {
+			return fmt.Errorf("govulncheck: the -timeout of %v expired while loading packages", cfg.timeout)
+		}
 		// Try to provide a meaningful and actionable error message.
 		if !fileExists(filepath.Join(dir, "go.mod")) {
 			return fmt.Errorf("govulncheck: %v", errNoGoMod)
 		}
+		if isGoSumError(err) {
+			return fmt.Errorf("govulncheck: %v\n\n%v", errGoSumMismatch, err)
+		}
 		if isGoVersionMismatchError(err) {
 			return fmt.Errorf("govulncheck: %v\n\n%v", errGoVersionMismatch, err)
 		}
@@ -48,114 +76,998 @@ func runSource(ctx context.Context, handler govulncheck.Handler, cfg *config, cl
 	if err := handler.Progress(sourceProgressMessage(pkgs)); err != nil {
 		return err
 	}
-	vr, err := vulncheck.Source(ctx, pkgs, &cfg.Config, client, graph)
+	if len(sumErrs) > 0 {
+		if err := handler.Progress(&govulncheck.Progress{Message: goSumWarning(sumErrs)}); err != nil {
+			return err
+		}
+	}
+	if cfg.ScanLevel.WantSymbols() {
+		for _, path := range untypedPackages(pkgs, pkgConfig.Mode) {
+			msg := fmt.Sprintf("Warning: package %s was loaded without complete type information, so vulnerabilities reachable through it may not be reported.", path)
+			if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+				return err
+			}
+		}
+	}
+	cfg.posBase = positionBase(cfg.relPath, dir, pkgs)
+	var vr *vulncheck.Result
+	if entries := cfg.hooks.EntryFunctions; len(entries) > 0 {
+		vr, err = vulncheck.SourceWithEntryFunctions(ctx, pkgs, &cfg.Config, client, graph, entries)
+	} else {
+		// Test helpers that are only dependencies of the scanned
+		// packages are analyzed as entry points of their own.
+		entryPkgs := append(pkgs[:len(pkgs):len(pkgs)], testHelpers(pkgs, cfg.testHelpers)...)
+		vr, err = vulncheck.SourceWithEntryFilter(ctx, entryPkgs, &cfg.Config, client, graph, entryFilter(cfg))
+	}
 	if err != nil {
+		if timedOut(cfg, ctx.Err()) {
+			return fmt.Errorf("govulncheck: the -timeout of %v expired while fetching vulnerabilities", cfg.timeout)
+		}
+		return err
+	}
+	// The scan goes on with partial results once the -timeout expires,
+	// and timeout then tells why it is incomplete.
+	var timeout *govulncheck.IncompleteReason
+	if vr.Cancelled {
+		timeout = timeoutReason(cfg, "before the call graph was built, so all vulnerabilities are reported as imported")
+	}
+	if err := cfg.download.finish(); err != nil {
+		return err
+	}
+	for _, r := range vr.Requirements {
+		if len(r.Patched) == 0 {
+			continue
+		}
+		if err := handler.Progress(patchedProgressMessage(r)); err != nil {
+			return err
+		}
+	}
+	for _, d := range vr.Diagnostics {
+		msg := fmt.Sprintf("Warning: analysis of package %s failed, so vulnerabilities reachable through it may not be reported: %s", d.PkgPath, d.Message)
+		if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+			return err
+		}
+	}
+	if err := reportNotApplicable(handler, vr); err != nil {
+		return err
+	}
+	if err := applyReachability(handler, cfg, vr); err != nil {
+		return err
+	}
+	if err := applyEntryFunctions(cfg, vr); err != nil {
+		return err
+	}
+	if err := applyWithoutCalls(handler, cfg, vr); err != nil {
+		return err
+	}
+	var compiled *compiledSymbols
+	if timeout == nil {
+		// Without a call graph, no symbol is reported as called.
+		compiled, err = scanBinarySymbols(ctx, handler, cfg, client, pkgs)
+		if err != nil {
+			return err
+		}
+	}
+	// Emit the findings of each vulnerability as soon as its call
+	// stacks are known, as the search may take long for large programs.
+	e := newEmitter(handler, cfg, vr)
+	if cfg.test {
+		e.testDeps = testOnlyModules(pkgs)
+	}
+	e.depPaths = newDependencyPaths(pkgs)
+	filter := newCallStackFilter(vr.Vulns, cfg.testHelpers, cfg.Representative)
+	// initErrs are the distinct problems met resolving the positions of
+	// init functions, which are reported once the search is complete.
+	var initErrs []string
+	seenInitErrs := make(map[string]bool)
+	start = time.Now()
+	stream := vulncheck.StreamCallStacks
+	if cfg.Sequential {
+		stream = vulncheck.StreamCallStacksSequentially
+	}
+	err = stream(ctx, vr, cfg.hooks.OnCallEdge, func(vv *vulncheck.Vuln, stacks []vulncheck.CallStack) error {
+		if compiled != nil {
+			// Without its symbol in the -binary, vv is reported
+			// as imported.
+			stacks = compiled.filter(vv, stacks)
+		}
+		unlikely := isLowConfidence(stacks, cfg.confidence)
+		if unlikely && cfg.confidenceDrop {
+			// Without its call stacks, vv is reported as imported.
+			return nil
+		}
+		// Without representative call stacks within -max-hops, or
+		// through the -changed functions, vv is reported as imported,
+		// as with low confidence.
+		vcs := shallowStacks(filter.filter(vv, changedStacks(stacks, cfg.changed)), cfg.maxHops)
+		for _, cs := range vcs {
+			for _, err := range updateStackInitPositions(cs) {
+				if msg := err.Error(); !seenInitErrs[msg] {
+					seenInitErrs[msg] = true
+					initErrs = append(initErrs, msg)
+				}
+			}
+		}
+		return e.called(vv, vcs, len(stacks), unlikely)
+	})
+	if timedOut(cfg, err) {
+		if timeout == nil {
+			timeout = timeoutReason(cfg, "while searching for call stacks, so the vulnerabilities not searched yet are reported as imported")
+		}
+	} else if err != nil {
 		return err
 	}
-	callStacks := vulncheck.CallStacks(vr)
-	filterCallStacks(callStacks)
-	return emitResult(handler, vr, callStacks)
+	callStacks := time.Since(start)
+	for _, msg := range initErrs {
+		msg = fmt.Sprintf("Warning: %s, so the position of its init call is not reported.", msg)
+		if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+			return err
+		}
+	}
+	if compiled != nil {
+		for _, msg := range compiled.messages() {
+			if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.verify {
+		if err := handler.Progress(&govulncheck.Progress{Message: verifySummary(e.verified, e.unverified)}); err != nil {
+			return err
+		}
+	}
+	if cfg.tools && timeout == nil {
+		tvr, tools, err := scanTools(ctx, handler, cfg, client, dir, pkgs)
+		if err != nil && timedOut(cfg, ctx.Err()) {
+			timeout = timeoutReason(cfg, "while scanning the build-time tools, so their vulnerabilities are not reported")
+		} else if err != nil {
+			return err
+		}
+		if err := cfg.download.finish(); err != nil {
+			return err
+		}
+		if tvr != nil {
+			if err := e.tools(tvr, tools); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.surface {
+		e.surface = apiSurface(pkgs)
+	}
+	e.importers = mainImporters(pkgs)
+	if err := e.flush(); err != nil {
+		return err
+	}
+	if sh, ok := handler.(govulncheck.ScopeHandler); ok {
+		if err := sh.Scope(sourceScope(cfg, pkgs)); err != nil {
+			return err
+		}
+	}
+	if ih, ok := handler.(govulncheck.IncompleteHandler); ok {
+		if inc := incompleteSource(vr, cfg.ModuleDepth, timeout, goSumReason(sumErrs)); inc != nil {
+			if err := ih.Incomplete(inc); err != nil {
+				return err
+			}
+		}
+	}
+	if th, ok := handler.(govulncheck.TimingHandler); ok && cfg.timing {
+		return th.Timing(timingSource(load, callStacks, vr))
+	}
+	return nil
 }
 
-func filterCallStacks(callstacks map[*vulncheck.Vuln][]vulncheck.CallStack) {
-	type key struct {
-		id  string
-		pkg string
-		mod string
+// untypedPackages returns the sorted paths of the packages among pkgs and
+// their dependencies that lack the syntax or type information requested by
+// mode, which happens when loading them only partially succeeded. The call
+// graph is unreliable through such packages.
+func untypedPackages(pkgs []*packages.Package, mode packages.LoadMode) []string {
+	var paths []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		missing := mode&packages.NeedTypes != 0 && (pkg.Types == nil || pkg.IllTyped) ||
+			mode&packages.NeedTypesInfo != 0 && pkg.TypesInfo == nil ||
+			mode&packages.NeedSyntax != 0 && len(pkg.Syntax) < len(pkg.CompiledGoFiles)
+		if missing {
+			paths = append(paths, pkg.PkgPath)
+		}
+	})
+	sort.Strings(paths)
+	return paths
+}
+
+// incompleteSource returns the reasons why the source scan with result vr
+// is incomplete, or nil if it is complete. The scan is incomplete if the
+// analysis of some packages failed, if modules further away from the
+// main module than depth were not scanned, or for the reasons found
+// apart from vr, such as the expiry of the -timeout, that are not nil.
+func incompleteSource(vr *vulncheck.Result, depth int, others ...*govulncheck.IncompleteReason) *govulncheck.Incomplete {
+	var reasons []*govulncheck.IncompleteReason
+	for _, d := range vr.Diagnostics {
+		reasons = append(reasons, &govulncheck.IncompleteReason{
+			Kind:    govulncheck.IncompleteAnalysisFailed,
+			Package: d.PkgPath,
+			Message: fmt.Sprintf("analysis of package %s failed: %s", d.PkgPath, d.Message),
+		})
+	}
+	if len(vr.SkippedModules) > 0 {
+		var mods []string
+		for _, m := range vr.SkippedModules {
+			mods = append(mods, m.Path)
+		}
+		sort.Strings(mods)
+		reasons = append(reasons, &govulncheck.IncompleteReason{
+			Kind:    govulncheck.IncompleteModuleDepth,
+			Modules: mods,
+			Message: fmt.Sprintf("%d %s more than %d module %s away from the main module %s not scanned",
+				len(mods), choose(len(mods) == 1, "module", "modules"),
+				depth, choose(depth == 1, "dependency", "dependencies"),
+				choose(len(mods) == 1, "was", "were")),
+		})
+	}
+	for _, r := range others {
+		if r != nil {
+			reasons = append(reasons, r)
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return &govulncheck.Incomplete{Reasons: reasons}
+}
+
+// mainImporters returns the sorted paths of the packages of the main
+// module that directly import each package, keyed by the path of the
+// imported package, among pkgs and their dependencies.
+func mainImporters(pkgs []*packages.Package) map[string][]string {
+	seen := map[string]map[string]bool{}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Module == nil || !pkg.Module.Main {
+			return
+		}
+		for path := range pkg.Imports {
+			if seen[path] == nil {
+				seen[path] = map[string]bool{}
+			}
+			seen[path][pkg.PkgPath] = true
+		}
+	})
+	importers := map[string][]string{}
+	for path, set := range seen {
+		for importer := range set {
+			importers[path] = append(importers[path], importer)
+		}
+		sort.Strings(importers[path])
+	}
+	return importers
+}
+
+// positionBase returns the absolute directory that positions should be
+// reported relative to, as specified by relPath for a scan run in dir.
+// It returns "" if relPath is empty or the main module is unknown.
+//
+// If relPath is "module", the root directory of the main module of
+// pkgs is used.
+func positionBase(relPath, dir string, pkgs []*packages.Package) string {
+	if relPath == "" {
+		return ""
+	}
+	if relPath != "module" {
+		return absPath(relPath, dir)
 	}
+	for _, p := range pkgs {
+		if p.Module != nil && p.Module.Main {
+			return absPath(p.Module.Dir, dir)
+		}
+	}
+	return ""
+}
+
+// packagesConfig returns the configuration for loading the packages
+// to analyze in dir. The load mode required by vulncheck is added
+// when the packages are loaded.
+func packagesConfig(cfg *config, dir string) (*packages.Config, error) {
+	pkgConfig := &packages.Config{
+		Dir:   dir,
+		Tests: cfg.test,
+		Env:   cfg.env,
+	}
+	if cfg.modfile != "" {
+		pkgConfig.BuildFlags = append(pkgConfig.BuildFlags, "-modfile="+cfg.modfile)
+	}
+	if cfg.overlay != "" {
+		overlay, err := readOverlay(cfg.overlay, dir)
+		if err != nil {
+			return nil, err
+		}
+		pkgConfig.Overlay = overlay
+	}
+	return pkgConfig, nil
+}
+
+// readOverlay reads the overlay file at path, which has the
+// format accepted by the go build -overlay flag, and returns
+// the contents of the replacement files keyed by the absolute
+// paths of the files they replace. Relative paths are
+// interpreted relative to dir.
+func readOverlay(path, dir string) (map[string][]byte, error) {
+	b, err := os.ReadFile(absPath(path, dir))
+	if err != nil {
+		return nil, fmt.Errorf("reading overlay: %v", err)
+	}
+	var ov struct {
+		Replace map[string]string
+	}
+	if err := json.Unmarshal(b, &ov); err != nil {
+		return nil, fmt.Errorf("parsing overlay %s: %v", path, err)
+	}
+	overlay := make(map[string][]byte)
+	for from, to := range ov.Replace {
+		if to == "" {
+			return nil, fmt.Errorf("overlay %s: deleting %s is not supported", path, from)
+		}
+		contents, err := os.ReadFile(absPath(to, dir))
+		if err != nil {
+			return nil, fmt.Errorf("reading overlay: %v", err)
+		}
+		overlay[absPath(from, dir)] = contents
+	}
+	return overlay, nil
+}
+
+// absPath returns path as an absolute path, interpreting
+// relative paths relative to dir.
+func absPath(path, dir string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// callStackFilter reduces the call stacks of vulnerabilities to a single
+// representative one.
+type callStackFilter struct {
+	// vulnsPerPkg holds the called symbols of each vulnerable package.
+	vulnsPerPkg map[callStackKey][]*vulncheck.Vuln
+
+	// helpers are the patterns of the -test-helpers flag.
+	helpers string
+
+	// strategy is the strategy of the -representative flag.
+	strategy string
+}
+
+type callStackKey struct {
+	id  string
+	pkg string
+	mod string
+}
+
+// newCallStackFilter returns a filter for the call stacks of vulns, where
+// helpers are the patterns of the test helper packages, and strategy
+// picks the representative call stacks.
+func newCallStackFilter(vulns []*vulncheck.Vuln, helpers, strategy string) *callStackFilter {
 	// Collect all called symbols for a package.
 	// Needed for creating unique call stacks.
-	vulnsPerPkg := make(map[key][]*vulncheck.Vuln)
-	for vv := range callstacks {
+	f := &callStackFilter{vulnsPerPkg: make(map[callStackKey][]*vulncheck.Vuln), helpers: helpers, strategy: strategy}
+	for _, vv := range vulns {
 		if vv.CallSink != nil {
-			k := key{id: vv.OSV.ID, pkg: vv.ImportSink.PkgPath, mod: vv.ImportSink.Module.Path}
-			vulnsPerPkg[k] = append(vulnsPerPkg[k], vv)
+			k := f.key(vv)
+			f.vulnsPerPkg[k] = append(f.vulnsPerPkg[k], vv)
 		}
 	}
-	for vv, stacks := range callstacks {
-		var filtered []vulncheck.CallStack
-		if vv.CallSink != nil {
-			k := key{id: vv.OSV.ID, pkg: vv.ImportSink.PkgPath, mod: vv.ImportSink.Module.Path}
-			vcs := uniqueCallStack(vv, stacks, vulnsPerPkg[k])
-			if vcs != nil {
-				filtered = []vulncheck.CallStack{vcs}
+	return f
+}
+
+func (f *callStackFilter) key(vv *vulncheck.Vuln) callStackKey {
+	return callStackKey{id: vv.OSV.ID, pkg: vv.ImportSink.PkgPath, mod: vv.ImportSink.Module.Path}
+}
+
+// filter returns the representative call stack among stacks, the call
+// stacks of vv, or nil if there is none.
+func (f *callStackFilter) filter(vv *vulncheck.Vuln, stacks []vulncheck.CallStack) []vulncheck.CallStack {
+	if vv.CallSink == nil {
+		return nil
+	}
+	orderCallStacks(stacks, f.strategy)
+	// Prefer stacks that are exercised outside of tests.
+	sort.SliceStable(stacks, func(i, j int) bool {
+		return !isTestOnly(stacks[i], f.helpers) && isTestOnly(stacks[j], f.helpers)
+	})
+	if vcs := uniqueCallStack(vv, stacks, f.vulnsPerPkg[f.key(vv)]); vcs != nil {
+		return []vulncheck.CallStack{vcs}
+	}
+	return nil
+}
+
+// isLowConfidence reports whether all of stacks have a confidence score
+// above threshold, that is, go through more standard library functions
+// than threshold. It returns false if threshold is nil.
+func isLowConfidence(stacks []vulncheck.CallStack, threshold *int) bool {
+	if threshold == nil || len(stacks) == 0 {
+		return false
+	}
+	for _, stack := range stacks {
+		if vulncheck.Confidence(stack) <= *threshold {
+			return false
+		}
+	}
+	return true
+}
+
+// shallowStacks returns the stacks that take at most maxHops calls from
+// their entry point to their vulnerable symbol, or all of stacks if
+// maxHops is 0.
+func shallowStacks(stacks []vulncheck.CallStack, maxHops int) []vulncheck.CallStack {
+	if maxHops == 0 {
+		return stacks
+	}
+	var shallow []vulncheck.CallStack
+	for _, stack := range stacks {
+		if len(stack)-1 <= maxHops {
+			shallow = append(shallow, stack)
+		}
+	}
+	return shallow
+}
+
+// changedStacks returns the stacks that pass through a function whose
+// symbol, as in "pkg.T.Method", is among changed, or all of stacks if
+// changed is empty. The closures of a function are part of it.
+func changedStacks(stacks []vulncheck.CallStack, changed []string) []vulncheck.CallStack {
+	if len(changed) == 0 {
+		return stacks
+	}
+	symbols := map[string]bool{}
+	for _, s := range changed {
+		symbols[s] = true
+	}
+	var through []vulncheck.CallStack
+	for _, stack := range stacks {
+		for _, e := range stack {
+			fr := &govulncheck.Frame{Function: e.Function.Name}
+			if e.Function.Package != nil {
+				fr.Package = e.Function.Package.PkgPath
+				fr.Receiver = e.Function.Receiver()
+			}
+			if symbols[fr.Symbol()] {
+				through = append(through, stack)
+				break
 			}
 		}
-		callstacks[vv] = filtered
 	}
+	return through
 }
 
-func emitResult(handler govulncheck.Handler, vr *vulncheck.Result, callstacks map[*vulncheck.Vuln][]vulncheck.CallStack) error {
-	osvs := map[string]*osv.Entry{}
-	// first deal with all the affected vulnerabilities
-	emitted := map[string]bool{}
-	seen := map[string]bool{}
+// isTestOnly reports whether stack passes through test code, that is, a
+// function declared in a test file, in the generated main package of a
+// test binary, or in a test helper package matching helpers.
+func isTestOnly(stack vulncheck.CallStack, helpers string) bool {
+	for _, e := range stack {
+		f := e.Function
+		if f.Package != nil && (strings.HasSuffix(f.Package.PkgPath, ".test") || isTestHelper(helpers, f.Package.PkgPath)) {
+			return true
+		}
+		if f.Pos != nil && strings.HasSuffix(f.Pos.Filename, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
+
+// emitResult emits the findings of vr, whose call stacks are in
+// callstacks, and the module summaries to handler.
+func emitResult(handler govulncheck.Handler, cfg *config, vr *vulncheck.Result, callstacks map[*vulncheck.Vuln][]vulncheck.CallStack) error {
+	e := newEmitter(handler, cfg, vr)
 	for _, vv := range vr.Vulns {
-		osvs[vv.OSV.ID] = vv.OSV
-		fixed := fixedVersion(vv.ImportSink.Module.Path, vv.OSV.Affected)
-		stacks := callstacks[vv]
-		for _, stack := range stacks {
-			emitted[vv.OSV.ID] = true
-			emitFinding(handler, osvs, seen, &govulncheck.Finding{
-				OSV:          vv.OSV.ID,
-				FixedVersion: fixed,
-				Trace:        tracefromEntries(stack),
-			})
+		if err := e.called(vv, callstacks[vv], 0, false); err != nil {
+			return err
 		}
 	}
+	return e.flush()
+}
+
+// An emitter emits the findings of a scan and the module summaries to a
+// handler. The findings of called vulnerabilities are emitted as soon as
+// they are added, unless all findings must be known before any of them is
+// emitted, which is the case when a baseline or transformers are applied,
+// when findings are merged or sorted, or when only the worst finding of
+// each module is reported.
+type emitter struct {
+	handler govulncheck.Handler
+	cfg     *config
+	vulns   []*vulncheck.Vuln
+	osvs    map[string]*osv.Entry
+	sinks   map[*vulncheck.FuncNode][]string
+
+	// buffered is set if findings are only emitted by flush.
+	buffered bool
+
+	// findings are the findings added so far.
+	findings []*govulncheck.Finding
+
+	// emitted is the set of OSVs that have findings, and seen the set
+	// of OSVs that were passed to the handler.
+	emitted map[string]bool
+	seen    map[string]bool
+
+	// testDeps are the paths of the modules that are only dependencies
+	// of tests, when test files are analyzed.
+	testDeps map[string]bool
+
+	// depPaths are the chains of module dependencies from the main
+	// modules to the vulnerable packages, in source mode.
+	depPaths *dependencyPaths
+
+	// surface is the API usage of the modules, which is added to the
+	// module summaries if it is not nil.
+	surface map[string]*apiUsage
+
+	// importers are the packages of the main module that directly
+	// import each package, which are added to the imported findings.
+	importers map[string][]string
+
+	// required are the versions of the modules required by go.mod
+	// files that are lower than the versions selected for the build.
+	required map[string]string
+
+	// excluded is the set of OSVs of the standard library excluded by
+	// the -exclude-stdlib flag, mapped to whether they are called.
+	excluded map[string]bool
+
+	// entries are the entry functions of the call graph, against which
+	// the traces of called findings are checked with the -verify flag,
+	// and verified and unverified count the findings that were checked
+	// and could not be corroborated.
+	entries    map[*vulncheck.FuncNode]bool
+	verified   int
+	unverified int
+}
+
+// newEmitter returns an emitter of the findings of vr to handler.
+func newEmitter(handler govulncheck.Handler, cfg *config, vr *vulncheck.Result) *emitter {
+	e := &emitter{
+		handler:  handler,
+		cfg:      cfg,
+		vulns:    vr.Vulns,
+		osvs:     make(map[string]*osv.Entry),
+		sinks:    sinkOSVs(vr.Vulns),
+		buffered: cfg.baseline != "" || len(cfg.hooks.Transformers) > 0 || cfg.merge || cfg.worst || cfg.sortBy != "",
+		emitted:  make(map[string]bool),
+		seen:     make(map[string]bool),
+		required: make(map[string]string),
+		excluded: make(map[string]bool),
+	}
 	for _, vv := range vr.Vulns {
-		if emitted[vv.OSV.ID] {
-			continue
+		addOSV(e.osvs, vv.OSV)
+	}
+	for _, r := range vr.Requirements {
+		e.required[r.Module.Path] = r.Version
+	}
+	if cfg.verify {
+		e.entries = make(map[*vulncheck.FuncNode]bool)
+		for _, fn := range vr.EntryFunctions {
+			e.entries[fn] = true
 		}
-		stacks := callstacks[vv]
-		if len(stacks) != 0 {
+	}
+	return e
+}
+
+// addOSV adds entry to osvs. Entries are filtered for the version of one
+// of their vulnerable modules, so the affected modules of the entries with
+// the same ID are combined, and the entry of a vulnerability then lists the
+// affected ranges of all of its modules in the build.
+func addOSV(osvs map[string]*osv.Entry, entry *osv.Entry) {
+	prev, ok := osvs[entry.ID]
+	if !ok {
+		osvs[entry.ID] = entry
+		return
+	}
+	modules := map[string]bool{}
+	for _, a := range prev.Affected {
+		modules[a.Module.Path] = true
+	}
+	merged := *prev
+	merged.Affected = prev.Affected[:len(prev.Affected):len(prev.Affected)]
+	for _, a := range entry.Affected {
+		if !modules[a.Module.Path] {
+			merged.Affected = append(merged.Affected, a)
+		}
+	}
+	if len(merged.Affected) > len(prev.Affected) {
+		osvs[entry.ID] = &merged
+	}
+}
+
+// called adds a finding for each call stack of vv in stacks, which are
+// representative of the count call stacks found. The findings are likely
+// false positives if unlikely is set.
+func (e *emitter) called(vv *vulncheck.Vuln, stacks []vulncheck.CallStack, count int, unlikely bool) error {
+	if len(stacks) == 0 {
+		return nil
+	}
+	fixed, status := fixedVersion(vv.ImportSink.Module.Path, vv.OSV.Affected)
+	affected := vulnAffectedRange(vv)
+	for _, stack := range stacks {
+		e.emitted[vv.OSV.ID] = true
+		trace := tracefromEntries(stack, e.cfg.posBase)
+		if e.cfg.verify {
+			if err := e.verify(vv, trace); err != nil {
+				return err
+			}
+		}
+		err := e.add(&govulncheck.Finding{
+			OSV:           vv.OSV.ID,
+			FixedVersion:  fixed,
+			FixStatus:     status,
+			AffectedRange: affected,
+			ReplacedBy:    replacedBy(vv.ImportSink.Module),
+			Trace:         trace,
+			Definition:    definitionPosition(stack, e.cfg.posBase),
+			Through:       throughOSVs(vv, stack, e.sinks),
+			TestOnly:      isTestOnly(stack, e.cfg.testHelpers),
+			EntryAPI:      entryAPI(trace),
+			CallStacks:    count,
+
+			LikelyFalsePositive: unlikely,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify checks trace, the trace of a called finding of vv, against the
+// call graph, and warns if it cannot be corroborated.
+func (e *emitter) verify(vv *vulncheck.Vuln, trace []*govulncheck.Frame) error {
+	e.verified++
+	reason := verifyTrace(vv, e.entries, trace)
+	if reason == "" {
+		return nil
+	}
+	e.unverified++
+	msg := fmt.Sprintf("Warning: the finding of %s for %s.%s could not be corroborated by the call graph: %s.", vv.OSV.ID, vv.ImportSink.PkgPath, vv.Symbol, reason)
+	return e.handler.Progress(&govulncheck.Progress{Message: msg})
+}
+
+// add adds f to the findings and emits it unless e is buffered.
+func (e *emitter) add(f *govulncheck.Finding) error {
+	if e.cfg.excludeStdlib && f.Trace[0].Module == internal.GoStdModulePath {
+		e.excluded[f.OSV] = e.excluded[f.OSV] || isCalledFinding(f)
+		return nil
+	}
+	mapPositions(e.cfg.hooks.PositionMapper, f)
+	f.Hash = f.IdentityHash()
+	f.Ownership = ownership(e.cfg.internal, f.Trace[0].Module)
+	f.TestDependency = f.Tool == nil && e.testDeps[f.Trace[0].Module]
+	if f.Tool == nil && e.depPaths != nil {
+		f.DependencyPath = e.depPaths.path(f.Trace[0].Package)
+	}
+	f.RequiredVersion = e.required[f.Trace[0].Module]
+	if e.cfg.repoFindings {
+		f.Repository = e.cfg.Repository
+	}
+	if len(e.cfg.GoVersions) > 0 && f.Trace[0].Module == internal.GoStdModulePath {
+		f.GoVersions = affectedGoVersions(e.osvs[f.OSV], e.cfg.GoVersions)
+	}
+	var score float64
+	f.Severity, score = cvss3Severity(e.osvs[f.OSV])
+	f.CWEs = cweIDs(e.osvs[f.OSV])
+	if e.cfg.severity != nil {
+		f.OrgSeverity = e.cfg.severity(f.Trace[0].Module, f.Severity, score)
+	}
+	if e.cfg.mode == modeSource {
+		f.Reproduce = reproduceCommand(e.cfg, f)
+	}
+	e.findings = append(e.findings, f)
+	if e.buffered {
+		return nil
+	}
+	return emitFinding(e.handler, e.osvs, e.seen, f)
+}
+
+// reportExcluded writes a progress message with the number of
+// vulnerabilities of the standard library excluded by the -exclude-stdlib
+// flag, if any.
+func (e *emitter) reportExcluded() error {
+	if len(e.excluded) == 0 {
+		return nil
+	}
+	called := 0
+	for _, c := range e.excluded {
+		if c {
+			called++
+		}
+	}
+	n := len(e.excluded)
+	msg := fmt.Sprintf("Excluded %d %s of the Go standard library, of which %d %s called, as requested by -exclude-stdlib.",
+		n, choose(n == 1, "vulnerability", "vulnerabilities"), called, choose(called == 1, "is", "are"))
+	return e.handler.Progress(&govulncheck.Progress{Message: msg})
+}
+
+// flush adds a finding for each vulnerability that is only imported,
+// emits the findings not emitted yet, and then the module summaries.
+func (e *emitter) flush() error {
+	for _, vv := range e.vulns {
+		if e.emitted[vv.OSV.ID] {
 			continue
 		}
-		emitted[vv.OSV.ID] = true
-		emitFinding(handler, osvs, seen, &govulncheck.Finding{
-			OSV:          vv.OSV.ID,
-			FixedVersion: fixedVersion(vv.ImportSink.Module.Path, vv.OSV.Affected),
-			Trace:        []*govulncheck.Frame{frameFromPackage(vv.ImportSink)},
+		e.emitted[vv.OSV.ID] = true
+		fixed, status := fixedVersion(vv.ImportSink.Module.Path, vv.OSV.Affected)
+		err := e.add(&govulncheck.Finding{
+			OSV:           vv.OSV.ID,
+			FixedVersion:  fixed,
+			FixStatus:     status,
+			AffectedRange: vulnAffectedRange(vv),
+			ReplacedBy:    replacedBy(vv.ImportSink.Module),
+			Trace:         []*govulncheck.Frame{frameFromPackage(vv.ImportSink)},
+			ImportedBy:    e.importers[vv.ImportSink.PkgPath],
+			AssumedCalled: assumedCalled(e.cfg.assumeCalled, vv.ImportSink.Module.Path),
 		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := e.reportExcluded(); err != nil {
+		return err
+	}
+	if e.buffered {
+		findings, err := applyBaseline(e.handler, e.cfg, e.findings)
+		if err != nil {
+			return err
+		}
+		findings, err = transformFindings(e.cfg.hooks.Transformers, findings)
+		if err != nil {
+			return err
+		}
+		// The module summaries still count all the findings.
+		e.findings = findings
+		if e.cfg.merge {
+			findings = mergeFindings(findings)
+		}
+		if e.cfg.worst {
+			findings = worstFindings(findings, e.osvs)
+		}
+		if e.cfg.sortBy == sortEffort {
+			findings = sortByEffort(findings, e.osvs)
+		}
+		for _, f := range findings {
+			if err := emitFinding(e.handler, e.osvs, e.seen, f); err != nil {
+				return err
+			}
+		}
+	}
+	if mh, ok := e.handler.(govulncheck.ModuleHandler); ok {
+		// Build-time tools are not part of the program.
+		var findings []*govulncheck.Finding
+		for _, f := range e.findings {
+			if f.Tool == nil {
+				findings = append(findings, f)
+			}
+		}
+		mods := moduleSummaries(findings, e.osvs)
+		addSurface(mods, e.surface)
+		for _, m := range mods {
+			if err := mh.Module(m); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
+// transformFindings applies transformers to findings in order.
+func transformFindings(transformers []govulncheck.Transformer, findings []*govulncheck.Finding) ([]*govulncheck.Finding, error) {
+	for i, t := range transformers {
+		var err error
+		findings, err = t(findings)
+		if err != nil {
+			return nil, fmt.Errorf("govulncheck: transformer #%d: %w", i+1, err)
+		}
+	}
+	return findings, nil
+}
+
 func emitFinding(handler govulncheck.Handler, osvs map[string]*osv.Entry, seen map[string]bool, finding *govulncheck.Finding) error {
+	if finding.Hash == "" {
+		finding.Hash = finding.IdentityHash()
+	}
 	if !seen[finding.OSV] {
+		entry, ok := osvs[finding.OSV]
+		if !ok {
+			return fmt.Errorf("govulncheck: finding for unknown OSV %q", finding.OSV)
+		}
 		seen[finding.OSV] = true
-		if err := handler.OSV(osvs[finding.OSV]); err != nil {
+		if err := handler.OSV(entry); err != nil {
 			return err
 		}
 	}
 	return handler.Finding(finding)
 }
 
+// vulnAffectedRange returns the affected range of v.OSV containing
+// the version of the vulnerable module in the build graph.
+func vulnAffectedRange(v *vulncheck.Vuln) *govulncheck.AffectedRange {
+	mod := v.ImportSink.Module
+	version := mod.Version
+	if mod.Replace != nil && !vulncheck.IsPathReplaced(mod) {
+		version = mod.Replace.Version
+	}
+	return affectedRange(mod.Path, version, v.OSV.Affected)
+}
+
+// replacedBy returns the module replacing mod, as reported in
+// govulncheck.Finding.ReplacedBy, or "" if mod is not replaced
+// by a module with a different path.
+func replacedBy(mod *packages.Module) string {
+	if !vulncheck.IsPathReplaced(mod) {
+		return ""
+	}
+	if mod.Replace.Version == "" {
+		return mod.Replace.Path
+	}
+	return mod.Replace.Path + "@" + mod.Replace.Version
+}
+
+// sinkOSVs maps each vulnerable function in vulns to the IDs
+// of the OSV entries for which it is a call sink.
+func sinkOSVs(vulns []*vulncheck.Vuln) map[*vulncheck.FuncNode][]string {
+	sinks := make(map[*vulncheck.FuncNode][]string)
+	for _, vv := range vulns {
+		if vv.CallSink == nil {
+			continue
+		}
+		ids := sinks[vv.CallSink]
+		if !contains(ids, vv.OSV.ID) {
+			sinks[vv.CallSink] = append(ids, vv.OSV.ID)
+		}
+	}
+	return sinks
+}
+
+// throughOSVs returns the sorted IDs of the OSV entries, other than
+// the one of v, whose vulnerable symbols are frames of stack.
+func throughOSVs(v *vulncheck.Vuln, stack vulncheck.CallStack, sinks map[*vulncheck.FuncNode][]string) []string {
+	var ids []string
+	for _, e := range stack {
+		if e.Function == v.CallSink {
+			continue
+		}
+		for _, id := range sinks[e.Function] {
+			if id != v.OSV.ID && !contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
 // tracefromEntries creates a sequence of
 // frames from vcs. Position of a Frame is the
 // call position of the corresponding stack entry.
-func tracefromEntries(vcs vulncheck.CallStack) []*govulncheck.Frame {
+// If base is not empty, position file names are
+// made relative to base.
+func tracefromEntries(vcs vulncheck.CallStack, base string) []*govulncheck.Frame {
 	var frames []*govulncheck.Frame
 	for i := len(vcs) - 1; i >= 0; i-- {
 		e := vcs[i]
 		fr := frameFromPackage(e.Function.Package)
 		fr.Function = e.Function.Name
 		fr.Receiver = e.Function.Receiver()
-		if e.Call == nil || e.Call.Pos == nil {
-			fr.Position = nil
-		} else {
-			fr.Position = &govulncheck.Position{
-				Filename: e.Call.Pos.Filename,
-				Offset:   e.Call.Pos.Offset,
-				Line:     e.Call.Pos.Line,
-				Column:   e.Call.Pos.Column,
-			}
+		if e.Call != nil {
+			fr.Position = position(e.Call.Pos, base)
+			fr.Unresolved = !e.Call.Resolved
+			fr.Candidates = candidateSymbols(e.Call)
+			fr.Assumed = e.Call.Assumed
+			fr.Callback = e.Call.Callback
+		}
+		if e.InlinedAt != nil {
+			fr.Position = position(e.InlinedAt, base)
+			fr.Inlined = true
 		}
 		frames = append(frames, fr)
 	}
 	return frames
 }
 
+// candidateSymbols returns the sorted symbols of the candidate callees of
+// cs, if it is an unresolved call.
+func candidateSymbols(cs *vulncheck.CallSite) []string {
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, fn := range cs.Candidates {
+		// Wrappers of pointer receivers have the symbol of their method.
+		if s := funcSymbol(fn); !seen[s] {
+			seen[s] = true
+			symbols = append(symbols, s)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// definitionPosition returns the position of the declaration of the
+// vulnerable function at the end of vcs, or nil if it is not known.
+func definitionPosition(vcs vulncheck.CallStack, base string) *govulncheck.Position {
+	if len(vcs) == 0 {
+		return nil
+	}
+	pos := vcs[len(vcs)-1].Function.Pos
+	if pos == nil || !pos.IsValid() {
+		return nil
+	}
+	return position(pos, base)
+}
+
+// position converts pos to a position reported relative to base. It
+// returns nil if pos is nil.
+func position(pos *token.Position, base string) *govulncheck.Position {
+	if pos == nil {
+		return nil
+	}
+	return &govulncheck.Position{
+		Filename: relativeFilename(pos.Filename, base),
+		Offset:   pos.Offset,
+		Line:     pos.Line,
+		Column:   pos.Column,
+	}
+}
+
+// relativeFilename returns filename relative to base. It returns
+// filename unchanged if base is empty or filename cannot be made
+// relative to base.
+func relativeFilename(filename, base string) string {
+	if base == "" || !filepath.IsAbs(filename) {
+		return filename
+	}
+	rel, err := filepath.Rel(base, filename)
+	if err != nil {
+		return filename
+	}
+	return rel
+}
+
+// mapPositions replaces the positions of f by their mapping by mapper, if
+// mapper is not nil, and removes the positions that mapper omits.
+func mapPositions(mapper govulncheck.PositionMapper, f *govulncheck.Finding) {
+	if mapper == nil {
+		return
+	}
+	mapPos := func(pos *govulncheck.Position) *govulncheck.Position {
+		if pos == nil {
+			return nil
+		}
+		p, ok := mapper(*pos)
+		if !ok {
+			return nil
+		}
+		return &p
+	}
+	for _, fr := range f.Trace {
+		fr.Position = mapPos(fr.Position)
+	}
+	f.Definition = mapPos(f.Definition)
+	if f.Tool != nil {
+		// Tools are shared by the findings in them.
+		tool := *f.Tool
+		tool.Position = mapPos(tool.Position)
+		f.Tool = &tool
+	}
+}
+
 func frameFromPackage(pkg *packages.Package) *govulncheck.Frame {
 	fr := &govulncheck.Frame{}
 	if pkg != nil {
@@ -163,7 +1075,10 @@ func frameFromPackage(pkg *packages.Package) *govulncheck.Frame {
 		fr.Version = pkg.Module.Version
 		fr.Package = pkg.PkgPath
 	}
-	if pkg.Module.Replace != nil {
+	// A module replaced by a different path is reported by its
+	// original path and version, as that is what its vulnerabilities
+	// are checked against. See govulncheck.Finding.ReplacedBy.
+	if pkg.Module.Replace != nil && !vulncheck.IsPathReplaced(pkg.Module) {
 		fr.Module = pkg.Module.Replace.Path
 		fr.Version = pkg.Module.Replace.Version
 	}
@@ -193,6 +1108,27 @@ func sourceProgressMessage(topPkgs []*packages.Package) *govulncheck.Progress {
 	return &govulncheck.Progress{Message: msg}
 }
 
+// sourceScope returns the scope of the scan of topPkgs, with the same
+// counts as the progress message of sourceProgressMessage.
+func sourceScope(cfg *config, topPkgs []*packages.Package) *govulncheck.Scope {
+	pkgs, mods := depPkgsAndMods(topPkgs)
+	return &govulncheck.Scope{Packages: len(topPkgs), Dependencies: pkgs, Modules: mods, Tests: cfg.test}
+}
+
+// patchedProgressMessage returns a progress message noting that the
+// vulnerabilities patched by the selected version of the module of r
+// are not reported, although go.mod requires a version they affect.
+func patchedProgressMessage(r *vulncheck.Requirement) *govulncheck.Progress {
+	var ids []string
+	for _, e := range r.Patched {
+		ids = append(ids, e.ID)
+	}
+	sort.Strings(ids)
+	msg := fmt.Sprintf("Note: go.mod requires %s@%s, which is affected by %s, but the build selects %s@%s because of other requirements, so %s not reported.",
+		r.Module.Path, r.Version, strings.Join(ids, ", "), r.Module.Path, r.Module.Version, choose(len(ids) == 1, "it is", "they are"))
+	return &govulncheck.Progress{Message: msg}
+}
+
 // depPkgsAndMods returns the number of packages that
 // topPkgs depend on and the number of their modules.
 func depPkgsAndMods(topPkgs []*packages.Package) (int, int) {
@@ -242,18 +1178,31 @@ func depPkgsAndMods(topPkgs []*packages.Package) (int, int) {
 }
 
 // updateInitPositions populates non-existing positions of init functions
-// and their respective calls in callStacks (see #51575).
-func updateInitPositions(callStacks map[*vulncheck.Vuln][]vulncheck.CallStack) {
+// and their respective calls in callStacks (see #51575). Positions that
+// cannot be resolved are left unset and the problems are returned.
+func updateInitPositions(callStacks map[*vulncheck.Vuln][]vulncheck.CallStack) []error {
+	var errs []error
 	for _, css := range callStacks {
 		for _, cs := range css {
-			for i := range cs {
-				updateInitPosition(&cs[i])
-				if i != len(cs)-1 {
-					updateInitCallPosition(&cs[i], cs[i+1])
-				}
+			errs = append(errs, updateStackInitPositions(cs)...)
+		}
+	}
+	return errs
+}
+
+// updateStackInitPositions is like updateInitPositions for the single
+// call stack cs.
+func updateStackInitPositions(cs vulncheck.CallStack) []error {
+	var errs []error
+	for i := range cs {
+		updateInitPosition(&cs[i])
+		if i != len(cs)-1 {
+			if err := updateInitCallPosition(&cs[i], cs[i+1]); err != nil {
+				errs = append(errs, err)
 			}
 		}
 	}
+	return errs
 }
 
 // updateInitCallPosition updates the position of a call to init in a stack frame, if
@@ -264,35 +1213,48 @@ func updateInitPositions(callStacks map[*vulncheck.Vuln][]vulncheck.CallStack) {
 //
 //	P.init -> P.init#d: P.init is an implicit init. We say it calls the explicit
 //	P.init#d at the place of "package P" statement.
-func updateInitCallPosition(curr *vulncheck.StackEntry, next vulncheck.StackEntry) {
+func updateInitCallPosition(curr *vulncheck.StackEntry, next vulncheck.StackEntry) error {
 	call := curr.Call
-	if !isInit(next.Function) || (call.Pos != nil && call.Pos.IsValid()) {
+	if !next.Function.IsInit() || (call.Pos != nil && call.Pos.IsValid()) {
 		// Skip non-init functions and inits whose call site position is available.
-		return
+		return nil
 	}
 
 	var pos token.Position
 	if curr.Function.Name == "init" && curr.Function.Package == next.Function.Package {
 		// We have implicit P.init calling P.init#d. Set the call position to
-		// be at "package P" statement position.
-		pos = packageStatementPos(curr.Function.Package)
+		// be at "package P" statement position, which is the position of
+		// the implicit P.init.
+		if p := curr.Function.Position(); p != nil {
+			pos = *p
+		}
 	} else {
 		// Choose the beginning of the import statement as the position.
-		pos = importStatementPos(curr.Function.Package, next.Function.Package.PkgPath)
+		var err error
+		pos, err = importStatementPos(curr.Function.Package, next.Function.Package.PkgPath)
+		if err != nil {
+			return err
+		}
 	}
 
 	call.Pos = &pos
+	return nil
 }
 
-func importStatementPos(pkg *packages.Package, importPath string) token.Position {
+// importStatementPos returns the position of the import of importPath
+// in pkg. It returns an error if an import specification of pkg is
+// malformed and no import of importPath is found.
+func importStatementPos(pkg *packages.Package, importPath string) (token.Position, error) {
 	var importSpec *ast.ImportSpec
+	var specErr error
 spec:
 	for _, f := range pkg.Syntax {
 		for _, impSpec := range f.Imports {
 			// Import spec paths have quotation marks.
 			impSpecPath, err := strconv.Unquote(impSpec.Path.Value)
 			if err != nil {
-				panic(fmt.Sprintf("import specification: package path has no quotation marks: %v", err))
+				specErr = fmt.Errorf("import specification at %v: package path has no quotation marks", pkg.Fset.Position(impSpec.Pos()))
+				continue
 			}
 			if impSpecPath == importPath {
 				importSpec = impSpec
@@ -302,41 +1264,30 @@ spec:
 	}
 
 	if importSpec == nil {
+		if specErr != nil {
+			return token.Position{}, fmt.Errorf("couldn't resolve init position for package %s: %v", pkg.PkgPath, specErr)
+		}
 		// for sanity, in case of a wild call graph imprecision
-		return token.Position{}
+		return token.Position{}, nil
 	}
 
 	// Choose the beginning of the import statement as the position.
-	return pkg.Fset.Position(importSpec.Pos())
-}
-
-func packageStatementPos(pkg *packages.Package) token.Position {
-	if len(pkg.Syntax) == 0 {
-		return token.Position{}
-	}
-	// Choose beginning of the package statement as the position. Pick
-	// the first file since it is as good as any.
-	return pkg.Fset.Position(pkg.Syntax[0].Package)
+	return pkg.Fset.Position(importSpec.Pos()), nil
 }
 
 // updateInitPosition updates the position of P.init function in a stack frame if one
 // is not available. The new position is the position of the "package P" statement.
 func updateInitPosition(se *vulncheck.StackEntry) {
 	fun := se.Function
-	if !isInit(fun) || (fun.Pos != nil && fun.Pos.IsValid()) {
+	if !fun.IsInit() || (fun.Pos != nil && fun.Pos.IsValid()) {
 		// Skip non-init functions and inits whose position is available.
 		return
 	}
-
-	pos := packageStatementPos(fun.Package)
-	fun.Pos = &pos
-}
-
-func isInit(f *vulncheck.FuncNode) bool {
-	// A source init function, or anonymous functions used in inits, will
-	// be named "init#x" by vulncheck (more precisely, ssa), where x is a
-	// positive integer. Implicit inits are named simply "init".
-	return f.Name == "init" || strings.HasPrefix(f.Name, "init#")
+	if pos := fun.Position(); pos != nil {
+		fun.Pos = pos
+	} else {
+		fun.Pos = &token.Position{}
+	}
 }
 
 // uniqueCallStack returns the first unique call stack among css, if any.
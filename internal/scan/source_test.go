@@ -6,17 +6,26 @@ package scan
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/packages/packagestest"
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/test"
 	"golang.org/x/vuln/internal/vulncheck"
 )
 
@@ -62,6 +71,176 @@ func TestUniqueCallStack(t *testing.T) {
 	}
 }
 
+func TestThroughOSVs(t *testing.T) {
+	a := &vulncheck.FuncNode{Name: "A"}
+	v1 := &vulncheck.FuncNode{Name: "V1"}
+	v2 := &vulncheck.FuncNode{Name: "V2"}
+
+	vuln1 := &vulncheck.Vuln{OSV: &osv.Entry{ID: "GO-1"}, Symbol: "V1", CallSink: v1}
+	vuln2 := &vulncheck.Vuln{OSV: &osv.Entry{ID: "GO-2"}, Symbol: "V2", CallSink: v2}
+	vuln3 := &vulncheck.Vuln{OSV: &osv.Entry{ID: "GO-3"}, Symbol: "V1", CallSink: v1}
+	sinks := sinkOSVs([]*vulncheck.Vuln{vuln1, vuln2, vuln3})
+
+	callStack := func(fs ...*vulncheck.FuncNode) vulncheck.CallStack {
+		var cs vulncheck.CallStack
+		for _, f := range fs {
+			cs = append(cs, vulncheck.StackEntry{Function: f})
+		}
+		return cs
+	}
+
+	for _, test := range []struct {
+		name string
+		vuln *vulncheck.Vuln
+		cs   vulncheck.CallStack
+		want []string
+	}{
+		// A -> V1: no other vulnerable symbol on the stack.
+		{"direct", vuln1, callStack(a, v1), nil},
+		// A -> V1 -> V2: V1 is a sink of both GO-1 and GO-3.
+		{"through", vuln2, callStack(a, v1, v2), []string{"GO-1", "GO-3"}},
+		// A -> V2 -> V1: the sink itself is not reported.
+		{"sink", vuln1, callStack(a, v2, v1), []string{"GO-2"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := throughOSVs(test.vuln, test.cs, sinks)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRelativeFilename(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "home", "user", "mod")
+	for _, test := range []struct {
+		filename, base, want string
+	}{
+		{filepath.Join(base, "a", "a.go"), "", filepath.Join(base, "a", "a.go")},
+		{filepath.Join(base, "a", "a.go"), base, filepath.Join("a", "a.go")},
+		{filepath.Join(base, "..", "b.go"), base, filepath.Join("..", "b.go")},
+		{filepath.Join("a", "a.go"), base, filepath.Join("a", "a.go")},
+	} {
+		if got := relativeFilename(test.filename, test.base); got != test.want {
+			t.Errorf("relativeFilename(%q, %q) = %q; want %q", test.filename, test.base, got, test.want)
+		}
+	}
+}
+
+func TestMapPositions(t *testing.T) {
+	mapper := func(pos govulncheck.Position) (govulncheck.Position, bool) {
+		if strings.HasPrefix(pos.Filename, "generated/") {
+			return pos, false
+		}
+		pos.Filename = path.Join("repo", pos.Filename)
+		return pos, true
+	}
+	tool := &govulncheck.Tool{Package: "example.com/gen", Position: &govulncheck.Position{Filename: "go.mod", Line: 5}}
+	f := &govulncheck.Finding{
+		Trace: []*govulncheck.Frame{
+			{Function: "V", Position: &govulncheck.Position{Filename: "generated/v.go", Line: 3}},
+			{Function: "main", Position: &govulncheck.Position{Filename: "main.go", Line: 7}},
+			{Function: "init"},
+		},
+		Definition: &govulncheck.Position{Filename: "v.go", Line: 1},
+		Tool:       tool,
+	}
+	mapPositions(mapper, f)
+	want := &govulncheck.Finding{
+		Trace: []*govulncheck.Frame{
+			{Function: "V"},
+			{Function: "main", Position: &govulncheck.Position{Filename: "repo/main.go", Line: 7}},
+			{Function: "init"},
+		},
+		Definition: &govulncheck.Position{Filename: "repo/v.go", Line: 1},
+		Tool:       &govulncheck.Tool{Package: "example.com/gen", Position: &govulncheck.Position{Filename: "repo/go.mod", Line: 5}},
+	}
+	if diff := cmp.Diff(want, f); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if tool.Position.Filename != "go.mod" {
+		t.Errorf("got shared tool position %q; want it unchanged", tool.Position.Filename)
+	}
+}
+
+func TestCandidateSymbols(t *testing.T) {
+	pkg := &packages.Package{PkgPath: "golang.org/entry"}
+	cs := &vulncheck.CallSite{Candidates: []*vulncheck.FuncNode{
+		{Name: "Parse", RecvType: "golang.org/entry.textParser", Package: pkg},
+		{Name: "Parse", RecvType: "golang.org/entry.undParser", Package: pkg},
+		// The wrapper of the method for pointer receivers.
+		{Name: "Parse", RecvType: "*golang.org/entry.textParser", Package: pkg},
+	}}
+	want := []string{"golang.org/entry.textParser.Parse", "golang.org/entry.undParser.Parse"}
+	if diff := cmp.Diff(want, candidateSymbols(cs)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestReadOverlay(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, contents string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("replacement.go", "package a")
+	write("overlay.json", `{"Replace": {"a/a.go": "replacement.go"}}`)
+
+	got, err := readOverlay("overlay.json", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]byte{filepath.Join(dir, "a", "a.go"): []byte("package a")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	write("delete.json", `{"Replace": {"a/a.go": ""}}`)
+	if _, err := readOverlay("delete.json", dir); err == nil {
+		t.Error("want error for deleted file in overlay; got nil")
+	}
+}
+
+func TestTransformFindings(t *testing.T) {
+	findings := []*govulncheck.Finding{{OSV: "A"}, {OSV: "B"}, {OSV: "C"}}
+	var order []string
+	dropB := func(fs []*govulncheck.Finding) ([]*govulncheck.Finding, error) {
+		order = append(order, "dropB")
+		var out []*govulncheck.Finding
+		for _, f := range fs {
+			if f.OSV != "B" {
+				out = append(out, f)
+			}
+		}
+		return out, nil
+	}
+	reverse := func(fs []*govulncheck.Finding) ([]*govulncheck.Finding, error) {
+		order = append(order, "reverse")
+		for i, j := 0, len(fs)-1; i < j; i, j = i+1, j-1 {
+			fs[i], fs[j] = fs[j], fs[i]
+		}
+		return fs, nil
+	}
+	got, err := transformFindings([]govulncheck.Transformer{dropB, reverse}, findings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*govulncheck.Finding{{OSV: "C"}, {OSV: "A"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"dropB", "reverse"}, order); diff != "" {
+		t.Errorf("order mismatch (-want, +got):\n%s", diff)
+	}
+
+	errBad := errors.New("bad")
+	fail := func([]*govulncheck.Finding) ([]*govulncheck.Finding, error) { return nil, errBad }
+	if _, err := transformFindings([]govulncheck.Transformer{reverse, fail}, findings); !errors.Is(err, errBad) {
+		t.Errorf("got error %v; want %v", err, errBad)
+	}
+}
+
 func TestSummarizeCallStack(t *testing.T) {
 	for _, test := range []struct {
 		in, want string
@@ -103,7 +282,7 @@ func TestSummarizeCallStack(t *testing.T) {
 		},
 	} {
 		in := stringToFinding(test.in)
-		got := compactTrace(in)
+		got := compactTrace(in, posFormatColon)
 		if got != test.want {
 			t.Errorf("%s:\ngot  %s\nwant %s", test.in, got, test.want)
 		}
@@ -214,7 +393,9 @@ func TestInits(t *testing.T) {
 	}
 
 	cs := vulncheck.CallStacks(result)
-	updateInitPositions(cs)
+	if errs := updateInitPositions(cs); len(errs) > 0 {
+		t.Fatal(errs)
+	}
 
 	want := map[string][][]string{
 		"A": {{
@@ -239,6 +420,35 @@ func TestInits(t *testing.T) {
 	}
 }
 
+func TestImportStatementPos(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "x.go", "package x\n\nimport (\n\t\"a\"\n\t\"b\"\n)\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{PkgPath: "x", Fset: fset, Syntax: []*ast.File{f}}
+
+	pos, err := importStatementPos(pkg, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pos.String(), "x.go:5:2"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// A malformed import spec is reported and does not prevent
+	// finding the other imports.
+	f.Imports[0].Path.Value = "a"
+	if _, err := importStatementPos(pkg, "b"); err != nil {
+		t.Errorf("got %v, want no error", err)
+	}
+	_, err = importStatementPos(pkg, "c")
+	want := "couldn't resolve init position for package x: import specification at x.go:4:2: package path has no quotation marks"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
 // strStacks creates a string representation of a call stacks map where
 // vulnerability is represented with its ID and stack entry is a string
 // "N:<package path.function name>  F:<function position> C:< call position>"
@@ -268,3 +478,187 @@ func strStacks(callStacks map[*vulncheck.Vuln][]vulncheck.CallStack) map[string]
 	}
 	return m
 }
+
+func TestEmitter(t *testing.T) {
+	mainPkg := &packages.Package{PkgPath: "golang.org/entry", Module: &packages.Module{Path: "golang.org/entry", Main: true}}
+	vulnPkg := &packages.Package{PkgPath: "golang.org/vmod/vuln", Module: &packages.Module{Path: "golang.org/vmod", Version: "v1.0.0"}}
+	entry := &vulncheck.FuncNode{Name: "main", Package: mainPkg}
+	sink := &vulncheck.FuncNode{Name: "V", Package: vulnPkg}
+	called := &vulncheck.Vuln{OSV: &osv.Entry{ID: "GO-0000-0001"}, ImportSink: vulnPkg, CallSink: sink}
+	imported := &vulncheck.Vuln{OSV: &osv.Entry{ID: "GO-0000-0002"}, ImportSink: vulnPkg}
+	vr := &vulncheck.Result{Vulns: []*vulncheck.Vuln{called, imported}}
+	stacks := []vulncheck.CallStack{{{Function: entry, Call: &vulncheck.CallSite{Parent: entry, Name: "V"}}, {Function: sink}}}
+
+	for _, tc := range []struct {
+		name string
+		cfg  *config
+		// number of findings emitted before flush
+		want int
+	}{
+		{name: "stream", cfg: &config{}, want: 1},
+		{name: "transformers", cfg: &config{hooks: Hooks{Transformers: []govulncheck.Transformer{
+			func(fs []*govulncheck.Finding) ([]*govulncheck.Finding, error) { return fs, nil },
+		}}}, want: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := test.NewMockHandler()
+			e := newEmitter(h, tc.cfg, vr)
+			if err := e.called(called, stacks, 3, false); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.called(imported, nil, 0, false); err != nil {
+				t.Fatal(err)
+			}
+			if got := len(h.FindingMessages); got != tc.want {
+				t.Errorf("got %d findings before flush; want %d", got, tc.want)
+			}
+			if err := e.flush(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range h.FindingMessages {
+				got = append(got, fmt.Sprintf("%s:%d", f.OSV, f.CallStacks))
+			}
+			want := []string{"GO-0000-0001:3", "GO-0000-0002:0"}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("findings mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAddOSV(t *testing.T) {
+	affected := func(path string) osv.Affected {
+		return osv.Affected{Module: osv.Module{Path: path}}
+	}
+	// The entry of a vulnerability is filtered for each of its modules.
+	text := &osv.Entry{ID: "GO-0000-0001", Affected: []osv.Affected{affected("golang.org/x/text")}}
+	net := &osv.Entry{ID: "GO-0000-0001", Affected: []osv.Affected{affected("golang.org/x/net")}}
+	other := &osv.Entry{ID: "GO-0000-0002", Affected: []osv.Affected{affected("golang.org/x/net")}}
+
+	osvs := map[string]*osv.Entry{}
+	for _, e := range []*osv.Entry{text, text, net, other} {
+		addOSV(osvs, e)
+	}
+	want := map[string]*osv.Entry{
+		"GO-0000-0001": {ID: "GO-0000-0001", Affected: []osv.Affected{affected("golang.org/x/text"), affected("golang.org/x/net")}},
+		"GO-0000-0002": other,
+	}
+	if diff := cmp.Diff(want, osvs); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if len(text.Affected) != 1 {
+		t.Errorf("addOSV modified the affected modules of an entry: %v", text.Affected)
+	}
+}
+
+func TestIncompleteSource(t *testing.T) {
+	complete := &vulncheck.Result{}
+	if got := incompleteSource(complete, 0, nil); got != nil {
+		t.Errorf("complete scan: got %v; want nil", got)
+	}
+
+	vr := &vulncheck.Result{
+		Diagnostics: []*vulncheck.Diagnostic{{PkgPath: "golang.org/entry/x", Message: "building ssa: boom"}},
+		SkippedModules: []*packages.Module{
+			{Path: "golang.org/zmod"},
+			{Path: "golang.org/amod"},
+		},
+	}
+	want := &govulncheck.Incomplete{Reasons: []*govulncheck.IncompleteReason{
+		{
+			Kind:    govulncheck.IncompleteAnalysisFailed,
+			Package: "golang.org/entry/x",
+			Message: "analysis of package golang.org/entry/x failed: building ssa: boom",
+		},
+		{
+			Kind:    govulncheck.IncompleteModuleDepth,
+			Modules: []string{"golang.org/amod", "golang.org/zmod"},
+			Message: "2 modules more than 2 module dependencies away from the main module were not scanned",
+		},
+	}}
+	if diff := cmp.Diff(want, incompleteSource(vr, 2, nil)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	timeout := timeoutReason(&config{timeout: 90 * time.Second}, "while searching for call stacks")
+	want = &govulncheck.Incomplete{Reasons: []*govulncheck.IncompleteReason{{
+		Kind:    govulncheck.IncompleteTimeout,
+		Message: "the -timeout of 1m30s expired while searching for call stacks",
+	}}}
+	if diff := cmp.Diff(want, incompleteSource(complete, 0, timeout)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestUntypedPackages(t *testing.T) {
+	file := &ast.File{}
+	typed := func(path string) *packages.Package {
+		return &packages.Package{
+			PkgPath:         path,
+			Types:           types.NewPackage(path, "p"),
+			TypesInfo:       &types.Info{},
+			CompiledGoFiles: []string{"p.go"},
+			Syntax:          []*ast.File{file},
+			Imports:         map[string]*packages.Package{},
+		}
+	}
+	noTypes, illTyped, noSyntax := typed("golang.org/a"), typed("golang.org/b"), typed("golang.org/c")
+	noTypes.Types, noTypes.TypesInfo = nil, nil
+	illTyped.IllTyped = true
+	noSyntax.Syntax = nil
+	root := typed("golang.org/entry")
+	for _, p := range []*packages.Package{noSyntax, illTyped, noTypes, typed("golang.org/d")} {
+		root.Imports[p.PkgPath] = p
+	}
+
+	mode := packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo
+	want := []string{"golang.org/a", "golang.org/b", "golang.org/c"}
+	if diff := cmp.Diff(want, untypedPackages([]*packages.Package{root}, mode)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	// Only the information requested by the mode is expected.
+	if got := untypedPackages([]*packages.Package{root}, packages.NeedSyntax); !cmp.Equal(got, []string{"golang.org/c"}) {
+		t.Errorf("got %v for packages.NeedSyntax; want [golang.org/c]", got)
+	}
+}
+
+func TestChangedStacks(t *testing.T) {
+	pkg := &packages.Package{PkgPath: "golang.org/main"}
+	main := &vulncheck.FuncNode{Name: "main", Package: pkg}
+	serve := &vulncheck.FuncNode{Name: "ServeFoo", RecvType: "*golang.org/main.Handler", Package: pkg}
+	closure := &vulncheck.FuncNode{Name: "ServeFoo$1", RecvType: "*golang.org/main.Handler", Package: pkg}
+	other := &vulncheck.FuncNode{Name: "ServeBar", Package: pkg}
+	vuln := &vulncheck.FuncNode{Name: "V", Package: &packages.Package{PkgPath: "golang.org/vmod/vuln"}}
+
+	callStack := func(fs ...*vulncheck.FuncNode) vulncheck.CallStack {
+		var cs vulncheck.CallStack
+		for _, f := range fs {
+			cs = append(cs, vulncheck.StackEntry{Function: f})
+		}
+		return cs
+	}
+	stacks := []vulncheck.CallStack{
+		callStack(main, serve, vuln),
+		callStack(main, closure, vuln),
+		callStack(main, other, vuln),
+	}
+	for _, test := range []struct {
+		changed []string
+		want    []string
+	}{
+		{nil, []string{"ServeFoo", "ServeFoo$1", "ServeBar"}},
+		{[]string{"golang.org/main.Handler.ServeFoo"}, []string{"ServeFoo", "ServeFoo$1"}},
+		{[]string{"golang.org/main.ServeBar", "golang.org/main.Other"}, []string{"ServeBar"}},
+		{[]string{"golang.org/main.ServeFoo"}, nil},
+	} {
+		// The stacks are identified by their second function.
+		var got []string
+		for _, stack := range changedStacks(stacks, test.changed) {
+			got = append(got, stack[1].Function.Name)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("changedStacks(%v) mismatch (-want, +got):\n%s", test.changed, diff)
+		}
+	}
+}
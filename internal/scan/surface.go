@@ -0,0 +1,138 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// apiUsage describes how much of the API of a module is used by the
+// main module.
+type apiUsage struct {
+	// exported is the set of exported functions and methods of the
+	// loaded packages of the module.
+	exported map[string]bool
+
+	// used is the subset of exported that is referenced by the
+	// main module.
+	used map[string]bool
+}
+
+// apiSurface returns the API usage of each module of pkgs and their
+// dependencies, keyed by module path. Functions and methods are
+// identified by their qualified name, so that the variants of a package
+// and the instances of a generic function are counted once. Methods of
+// interfaces are not counted.
+func apiSurface(pkgs []*packages.Package) map[string]*apiUsage {
+	surface := map[string]*apiUsage{}
+	usage := func(mod string) *apiUsage {
+		u := surface[mod]
+		if u == nil {
+			u = &apiUsage{exported: map[string]bool{}, used: map[string]bool{}}
+			surface[mod] = u
+		}
+		return u
+	}
+	modules := map[string]string{} // package path to module path
+	var mains []*packages.Package
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		mod := pkgModulePath(pkg)
+		modules[pkg.PkgPath] = mod
+		if pkg.Module != nil && pkg.Module.Main {
+			mains = append(mains, pkg)
+			return
+		}
+		if pkg.Types == nil {
+			return
+		}
+		u := usage(mod)
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			switch obj := scope.Lookup(name).(type) {
+			case *types.Func:
+				if name := apiName(obj); name != "" {
+					u.exported[name] = true
+				}
+			case *types.TypeName:
+				named, ok := obj.Type().(*types.Named)
+				if !ok || !obj.Exported() {
+					continue
+				}
+				for i := 0; i < named.NumMethods(); i++ {
+					if name := apiName(named.Method(i)); name != "" {
+						u.exported[name] = true
+					}
+				}
+			}
+		}
+	})
+	for _, pkg := range mains {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, obj := range pkg.TypesInfo.Uses {
+			fn, ok := obj.(*types.Func)
+			if !ok || fn.Pkg() == nil {
+				continue
+			}
+			mod, ok := modules[fn.Pkg().Path()]
+			if !ok || surface[mod] == nil {
+				continue
+			}
+			if name := apiName(fn); name != "" && surface[mod].exported[name] {
+				surface[mod].used[name] = true
+			}
+		}
+	}
+	return surface
+}
+
+// apiName returns the qualified name of fn, of the form "pkg.Func" or
+// "pkg.T.Method", or "" if fn is not exported or is an interface method.
+func apiName(fn *types.Func) string {
+	if !fn.Exported() || fn.Pkg() == nil {
+		return ""
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return fn.Pkg().Path() + "." + fn.Name()
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || !named.Obj().Exported() {
+		// Methods of interfaces and of unexported types.
+		return ""
+	}
+	if _, ok := named.Underlying().(*types.Interface); ok {
+		return ""
+	}
+	return fn.Pkg().Path() + "." + named.Obj().Name() + "." + fn.Name()
+}
+
+// pkgModulePath returns the path of the module of pkg, as it is
+// reported in findings.
+func pkgModulePath(pkg *packages.Package) string {
+	if pkg.Module == nil {
+		return internal.GoStdModulePath
+	}
+	return pkg.Module.Path
+}
+
+// addSurface adds the API usage in surface to the module summaries mods.
+func addSurface(mods []*govulncheck.Module, surface map[string]*apiUsage) {
+	for _, m := range mods {
+		if u := surface[m.Path]; u != nil && len(u.exported) > 0 {
+			m.UsedSymbols = len(u.used)
+			m.ExportedSymbols = len(u.exported)
+		}
+	}
+}
@@ -5,9 +5,11 @@
 package scan
 
 import (
+	"fmt"
 	"go/token"
 	"io"
 	"path"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -29,6 +31,14 @@ type summaryCounters struct {
 	VulnerabilitiesCalled int
 	ModulesCalled         int
 	StdlibCalled          bool
+
+	// TestOnlyCalled is the number of called vulnerabilities
+	// that are only called from tests.
+	TestOnlyCalled int
+
+	// LikelyFalsePositives is the number of called vulnerabilities
+	// whose findings are all likely false positives.
+	LikelyFalsePositives int
 }
 
 func fixupFindings(osvs []*osv.Entry, findings []*findingSummary) {
@@ -43,6 +53,20 @@ func groupByVuln(findings []*findingSummary) [][]*findingSummary {
 	})
 }
 
+// groupByVulnInOrder is like groupByVuln, but vulnerabilities are in the
+// order of their first finding instead of being sorted by ID.
+func groupByVulnInOrder(findings []*findingSummary) [][]*findingSummary {
+	first := map[string]int{}
+	for i, f := range findings {
+		if _, ok := first[f.OSV.ID]; !ok {
+			first[f.OSV.ID] = i
+		}
+	}
+	return groupBy(findings, func(left, right *findingSummary) int {
+		return first[left.OSV.ID] - first[right.OSV.ID]
+	})
+}
+
 func groupByModule(findings []*findingSummary) [][]*findingSummary {
 	return groupBy(findings, func(left, right *findingSummary) int {
 		return strings.Compare(left.Trace[0].Module, right.Trace[0].Module)
@@ -75,14 +99,17 @@ func groupBy(findings []*findingSummary, compare func(left, right *findingSummar
 }
 
 func counters(findings []*findingSummary) summaryCounters {
-	vulns := map[string]struct{}{}
+	vulns := map[string]bool{}    // whether the vulnerability is only called from tests
+	unlikely := map[string]bool{} // whether the vulnerability is likely a false positive
 	modules := map[string]struct{}{}
 	for _, f := range findings {
-		if f.Trace[0].Function == "" {
+		if !isCalledFinding(f.Finding) {
 			continue
 		}
 		id := f.OSV.ID
-		vulns[id] = struct{}{}
+		testOnly, ok := vulns[id]
+		vulns[id] = f.TestOnly && (testOnly || !ok)
+		unlikely[id] = f.LikelyFalsePositive && (unlikely[id] || !ok)
 		mod := f.Trace[0].Module
 		modules[mod] = struct{}{}
 	}
@@ -90,6 +117,16 @@ func counters(findings []*findingSummary) summaryCounters {
 		VulnerabilitiesCalled: len(vulns),
 		ModulesCalled:         len(modules),
 	}
+	for _, testOnly := range vulns {
+		if testOnly {
+			result.TestOnlyCalled++
+		}
+	}
+	for _, u := range unlikely {
+		if u {
+			result.LikelyFalsePositives++
+		}
+	}
 	if _, found := modules[internal.GoStdModulePath]; found {
 		result.StdlibCalled = true
 		result.ModulesCalled--
@@ -97,7 +134,126 @@ func counters(findings []*findingSummary) summaryCounters {
 	return result
 }
 
+// fixGroup is a single module upgrade and the vulnerabilities it resolves.
+type fixGroup struct {
+	// Module is the path of the module to upgrade.
+	Module string
+	// Version is the lowest version of Module that fixes all of OSVs.
+	Version string
+	// OSVs are the IDs of the vulnerabilities fixed in Version.
+	OSVs []string
+	// Unfixed are the IDs of the vulnerabilities in Module that have
+	// no fixed version.
+	Unfixed []string
+}
+
+// groupByFix clusters the called findings by module and computes, for each
+// module, the single version that fixes all of the module's vulnerabilities.
+// The groups are sorted by module path.
+func groupByFix(findings []*findingSummary) []*fixGroup {
+	var called []*govulncheck.Finding
+	for _, f := range findings {
+		if isCalledFinding(f.Finding) {
+			called = append(called, f.Finding)
+		}
+	}
+	return fixGroups(called)
+}
+
+// fixGroups clusters findings by module and computes, for each module, the
+// single version that fixes all of the module's vulnerabilities, as planned
+// by PlanUpgrades. The groups are sorted by module path.
+func fixGroups(findings []*govulncheck.Finding) []*fixGroup {
+	byModule := map[string]*fixGroup{}
+	for _, u := range PlanUpgrades(findings, false) {
+		byModule[u.Module] = &fixGroup{Module: u.Module, Version: u.Version, OSVs: u.OSVs}
+	}
+	seen := map[[2]string]bool{}
+	for _, f := range findings {
+		if f.FixedVersion != "" {
+			continue
+		}
+		mod := f.Trace[0].Module
+		key := [2]string{mod, f.OSV}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		g := byModule[mod]
+		if g == nil {
+			g = &fixGroup{Module: mod}
+			byModule[mod] = g
+		}
+		g.Unfixed = append(g.Unfixed, f.OSV)
+	}
+	var groups []*fixGroup
+	for _, g := range byModule {
+		sort.Strings(g.Unfixed)
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Module < groups[j].Module
+	})
+	return groups
+}
+
+// moduleSummaries summarizes findings per module, in the order of
+// module paths. The severities of the vulnerabilities are those of their
+// entries in osvs.
+func moduleSummaries(findings []*govulncheck.Finding, osvs map[string]*osv.Entry) []*govulncheck.Module {
+	called := map[[2]string]bool{}
+	versions := map[string]string{}
+	for _, f := range findings {
+		fr := f.Trace[0]
+		key := [2]string{fr.Module, f.OSV}
+		called[key] = called[key] || isCalledFinding(f)
+		if versions[fr.Module] == "" {
+			versions[fr.Module] = fr.Version
+		}
+	}
+	var mods []*govulncheck.Module
+	for _, g := range fixGroups(findings) {
+		m := &govulncheck.Module{
+			Path:               g.Module,
+			Version:            versions[g.Module],
+			RecommendedVersion: g.Version,
+		}
+		var worst float64
+		for _, ids := range [][]string{g.OSVs, g.Unfixed} {
+			for _, id := range ids {
+				if called[[2]string{g.Module, id}] {
+					m.Called++
+				} else {
+					m.Imported++
+				}
+				if vector, score := cvss3Severity(osvs[id]); vector != "" && (m.WorstSeverity == "" || score > worst) {
+					m.WorstSeverity, worst = vector, score
+				}
+			}
+		}
+		mods = append(mods, m)
+	}
+	return mods
+}
+
+// isCalledFinding reports whether the vulnerability of f is called, or is
+// treated as called by the -assume-called flag.
+func isCalledFinding(f *govulncheck.Finding) bool {
+	return f.Trace[0].Function != "" || f.AssumedCalled
+}
+
 func isCalled(findings []*findingSummary) bool {
+	for _, f := range findings {
+		if isCalledFinding(f.Finding) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCallStack reports whether some of findings have a call stack to the
+// vulnerable symbol, unlike findings that are only assumed to be called.
+func hasCallStack(findings []*findingSummary) bool {
 	for _, f := range findings {
 		if f.Trace[0].Function != "" {
 			return true
@@ -105,6 +261,94 @@ func isCalled(findings []*findingSummary) bool {
 	}
 	return false
 }
+
+// isAssumedCalled reports whether some of findings are treated as called
+// by the -assume-called flag, and none of them has a call stack.
+func isAssumedCalled(findings []*findingSummary) bool {
+	return isCalled(findings) && !hasCallStack(findings)
+}
+
+// isCalledOutsideTests is like isCalled, but ignores findings
+// that are only called from tests.
+func isCalledOutsideTests(findings []*findingSummary) bool {
+	for _, f := range findings {
+		if isCalledFinding(f.Finding) && !f.TestOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// isTestDependency reports whether all of findings are in modules that
+// are only dependencies of tests.
+func isTestDependency(findings []*findingSummary) bool {
+	for _, f := range findings {
+		if !f.TestDependency {
+			return false
+		}
+	}
+	return len(findings) > 0
+}
+
+// isInternalAPI reports whether some of findings are called, and the
+// traces of all of those enter the vulnerable module through its internal
+// code, so that only upgrading the module can avoid the vulnerability.
+func isInternalAPI(findings []*findingSummary) bool {
+	for _, f := range findings {
+		if isCalledFinding(f.Finding) && f.EntryAPI != govulncheck.EntryAPIInternal {
+			return false
+		}
+	}
+	return isCalled(findings)
+}
+
+// isLikelyFalsePositive reports whether some of findings are called,
+// and all of those are likely false positives.
+func isLikelyFalsePositive(findings []*findingSummary) bool {
+	for _, f := range findings {
+		if isCalledFinding(f.Finding) && !f.LikelyFalsePositive {
+			return false
+		}
+	}
+	return isCalled(findings)
+}
+
+// isDispatchAssumed reports whether some of findings are called, and the
+// traces of all of those go through calls that are only assumed by the
+// -dispatch-tables flag.
+func isDispatchAssumed(findings []*findingSummary) bool {
+	for _, f := range findings {
+		if isCalledFinding(f.Finding) && !hasAssumedFrame(f.Trace) {
+			return false
+		}
+	}
+	return isCalled(findings)
+}
+
+// hasAssumedFrame reports whether some frame of trace is an assumed call.
+func hasAssumedFrame(trace []*govulncheck.Frame) bool {
+	for _, fr := range trace {
+		if fr.Assumed {
+			return true
+		}
+	}
+	return false
+}
+
+// isFailure reports whether findings cause govulncheck to fail, which is
+// the case if some of them are called, or treated as called, and are not
+// likely false positives.
+// If testNoFail is set, findings that are only called from tests do not
+// cause failure either.
+func isFailure(findings []*findingSummary, testNoFail bool) bool {
+	for _, f := range findings {
+		if !isCalledFinding(f.Finding) || f.LikelyFalsePositive || (testNoFail && f.TestOnly) {
+			continue
+		}
+		return true
+	}
+	return false
+}
 func getOSV(osvs []*osv.Entry, id string) *osv.Entry {
 	for _, entry := range osvs {
 		if entry.ID == id {
@@ -120,7 +364,7 @@ func getOSV(osvs []*osv.Entry, id string) *osv.Entry {
 func newFindingSummary(f *govulncheck.Finding) *findingSummary {
 	return &findingSummary{
 		Finding: f,
-		Compact: compactTrace(f),
+		Compact: compactTrace(f, posFormatColon),
 	}
 }
 
@@ -171,9 +415,47 @@ func platforms(mod string, e *osv.Entry) []string {
 }
 
 func posToString(p *govulncheck.Position) string {
+	return formatPosition(p, posFormatColon)
+}
+
+// Formats of the -position-format flag, for the positions of text output
+// to be clickable in the terminal of an editor.
+const (
+	posFormatColon  = "colon"  // file:line:col
+	posFormatParen  = "paren"  // file(line,col)
+	posFormatVSCode = "vscode" // vscode://file/abs/file:line:col
+	posFormatOffset = "offset" // file:line:col:#offset
+)
+
+// formatPosition returns p in format, one of the -position-format flag,
+// or "" if p is not valid.
+func formatPosition(p *govulncheck.Position, format string) string {
 	if p == nil || p.Line <= 0 {
 		return ""
 	}
+	switch format {
+	case posFormatParen:
+		return fmt.Sprintf("%s(%d,%d)", AbsRelShorter(p.Filename), p.Line, p.Column)
+	case posFormatVSCode:
+		name := p.Filename
+		if abs, err := filepath.Abs(name); err == nil {
+			name = abs
+		}
+		// Windows paths, as in C:/x.go, need a leading slash too.
+		name = filepath.ToSlash(name)
+		if !strings.HasPrefix(name, "/") {
+			name = "/" + name
+		}
+		return fmt.Sprintf("vscode://file%s:%d:%d", name, p.Line, p.Column)
+	case posFormatOffset:
+		s := fmt.Sprintf("%s:%d:%d", AbsRelShorter(p.Filename), p.Line, p.Column)
+		// Only the start of a file is at offset 0, so other positions
+		// at offset 0, such as those of binaries, have no known offset.
+		if p.Offset > 0 || p.Line == 1 && p.Column == 1 {
+			s += fmt.Sprintf(":#%d", p.Offset)
+		}
+		return s
+	}
 	return token.Position{
 		Filename: AbsRelShorter(p.Filename),
 		Offset:   p.Offset,
@@ -195,48 +477,60 @@ func symbol(frame *govulncheck.Frame, short bool) string {
 // show those two points.
 // If the vulnerable symbol is in the users code, it will show the entry point
 // and the vulnerable symbol.
-func compactTrace(finding *govulncheck.Finding) string {
+// The position of the description is written in format, as by
+// formatPosition.
+func compactTrace(finding *govulncheck.Finding, format string) string {
 	if len(finding.Trace) < 1 {
 		return ""
 	}
-	iTop := len(finding.Trace) - 1
-	topModule := finding.Trace[iTop].Module
-	// search for the exit point of the top module
-	for i, frame := range finding.Trace {
-		if frame.Module == topModule {
-			iTop = i
-			break
-		}
-	}
-
-	if iTop == 0 {
-		// all in one module, reset to the end
-		iTop = len(finding.Trace) - 1
+	iTop := topFrame(finding.Trace)
+	calls := compactCalls(finding.Trace, iTop)
+	if topPos := formatPosition(finding.Trace[iTop].Position, format); topPos != "" {
+		return topPos + ": " + calls
 	}
+	return calls
+}
 
+// compactCalls is like compactTrace, but omits the position. It describes
+// the calls from the frame at index iTop of trace.
+func compactCalls(trace []*govulncheck.Frame, iTop int) string {
 	buf := &strings.Builder{}
-	topPos := posToString(finding.Trace[iTop].Position)
-	if topPos != "" {
-		buf.WriteString(topPos)
-		buf.WriteString(": ")
-	}
-
 	if iTop > 0 {
-		addSymbolName(buf, finding.Trace[iTop], true)
+		addSymbolName(buf, trace[iTop], true)
 		buf.WriteString(" calls ")
 	}
 	if iTop > 1 {
-		addSymbolName(buf, finding.Trace[iTop-1], true)
+		addSymbolName(buf, trace[iTop-1], true)
 		buf.WriteString(", which")
 		if iTop > 2 {
 			buf.WriteString(" eventually")
 		}
 		buf.WriteString(" calls ")
 	}
-	addSymbolName(buf, finding.Trace[0], true)
+	addSymbolName(buf, trace[0], true)
 	return buf.String()
 }
 
+// topFrame returns the index in trace of the exit point of the top module,
+// that is, the frame of the top module that calls into other code. If the
+// whole trace is in one module, it returns the index of the entry point.
+func topFrame(trace []*govulncheck.Frame) int {
+	iTop := len(trace) - 1
+	topModule := trace[iTop].Module
+	// search for the exit point of the top module
+	for i, frame := range trace {
+		if frame.Module == topModule {
+			iTop = i
+			break
+		}
+	}
+	if iTop == 0 {
+		// all in one module, reset to the end
+		iTop = len(trace) - 1
+	}
+	return iTop
+}
+
 // notIdentifier reports whether ch is an invalid identifier character.
 func notIdentifier(ch rune) bool {
 	return !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' ||
@@ -266,25 +560,11 @@ func importPathToAssumedName(importPath string) string {
 }
 
 func addSymbolName(w io.Writer, frame *govulncheck.Frame, short bool) {
-	if frame.Function == "" {
-		return
-	}
-	if frame.Package != "" {
-		pkg := frame.Package
-		if short {
-			pkg = importPathToAssumedName(frame.Package)
-		}
-		io.WriteString(w, pkg)
-		io.WriteString(w, ".")
-	}
-	if frame.Receiver != "" {
-		if frame.Receiver[0] == '*' {
-			io.WriteString(w, frame.Receiver[1:])
-		} else {
-			io.WriteString(w, frame.Receiver)
-		}
-		io.WriteString(w, ".")
+	if short && frame.Package != "" {
+		// Use the name the package is likely imported as.
+		short := *frame
+		short.Package = importPathToAssumedName(frame.Package)
+		frame = &short
 	}
-	funcname := strings.Split(frame.Function, "$")[0]
-	io.WriteString(w, funcname)
+	io.WriteString(w, frame.Symbol())
 }
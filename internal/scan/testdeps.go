@@ -0,0 +1,51 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal"
+)
+
+// testOnlyModules returns the set of the paths of the modules that are
+// only dependencies of tests among pkgs, loaded with their tests: the
+// modules of the packages that are only imported by the test variants of
+// pkgs, and not by the packages of the program. The modules are
+// identified as in the traces of findings. The standard library, which is
+// always part of the program, is never among them.
+func testOnlyModules(pkgs []*packages.Package) map[string]bool {
+	var roots []*packages.Package
+	for _, pkg := range pkgs {
+		if !isTestVariant(pkg) {
+			roots = append(roots, pkg)
+		}
+	}
+	program := map[string]bool{internal.GoStdModulePath: true}
+	packages.Visit(roots, nil, func(pkg *packages.Package) {
+		if pkg.Module != nil {
+			program[frameFromPackage(pkg).Module] = true
+		}
+	})
+	testOnly := map[string]bool{}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Module == nil {
+			return
+		}
+		if mod := frameFromPackage(pkg).Module; !program[mod] {
+			testOnly[mod] = true
+		}
+	})
+	return testOnly
+}
+
+// isTestVariant reports whether pkg is only built for tests: the package
+// of the test files of a package, a package recompiled for them, or the
+// generated main package of a test binary. Their IDs are of the form
+// "p [p.test]", "p_test [p.test]", and "p.test", respectively.
+func isTestVariant(pkg *packages.Package) bool {
+	return strings.Contains(pkg.ID, " [") || strings.HasSuffix(pkg.ID, ".test")
+}
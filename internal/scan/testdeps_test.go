@@ -0,0 +1,36 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestTestOnlyModules(t *testing.T) {
+	mainMod := &packages.Module{Path: "golang.org/main", Main: true}
+	client := &packages.Package{ID: "golang.org/client/http", PkgPath: "golang.org/client/http", Module: &packages.Module{Path: "golang.org/client"}}
+	mock := &packages.Package{ID: "golang.org/mock/mock", PkgPath: "golang.org/mock/mock", Module: &packages.Module{Path: "golang.org/mock"}}
+	// A module replaced by a fork is identified by its original path.
+	fixtures := &packages.Package{ID: "golang.org/fixtures", PkgPath: "golang.org/fixtures", Module: &packages.Module{
+		Path: "golang.org/fixtures", Replace: &packages.Module{Path: "golang.org/fork", Version: "v1.0.0"},
+	}}
+	pkg := &packages.Package{ID: "golang.org/main", PkgPath: "golang.org/main", Module: mainMod,
+		Imports: map[string]*packages.Package{"golang.org/client/http": client}}
+	testPkg := &packages.Package{ID: "golang.org/main [golang.org/main.test]", PkgPath: "golang.org/main", Module: mainMod,
+		Imports: map[string]*packages.Package{"golang.org/client/http": client, "golang.org/mock/mock": mock}}
+	xtestPkg := &packages.Package{ID: "golang.org/main_test [golang.org/main.test]", PkgPath: "golang.org/main_test", Module: mainMod,
+		Imports: map[string]*packages.Package{"golang.org/main": testPkg, "golang.org/fixtures": fixtures}}
+	testMain := &packages.Package{ID: "golang.org/main.test", PkgPath: "golang.org/main.test", Module: mainMod,
+		Imports: map[string]*packages.Package{"golang.org/main": testPkg, "golang.org/main_test": xtestPkg}}
+
+	got := testOnlyModules([]*packages.Package{pkg, testPkg, xtestPkg, testMain})
+	want := map[string]bool{"golang.org/mock": true, "golang.org/fixtures": true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -37,11 +37,48 @@ type TextHandler struct {
 	w        io.Writer
 	osvs     []*osv.Entry
 	findings []*findingSummary
+	modules  []*govulncheck.Module
+
+	// toolFindings are the findings in build-time tools, which are
+	// written apart from the findings in the program.
+	toolFindings []*findingSummary
+
+	// testDepFindings are the findings in modules that are only
+	// dependencies of tests, which are written apart from the others
+	// if separateTestDeps is set.
+	testDepFindings []*findingSummary
+
+	// incomplete holds the reasons why the scan is incomplete, if it is.
+	incomplete *govulncheck.Incomplete
+
+	// timing holds where the time of the scan was spent, if requested.
+	timing *govulncheck.Timing
 
 	err error
 
-	showColor  bool
-	showTraces bool
+	showColor      bool
+	showTraces     bool
+	showFixes      bool
+	showReferences bool
+	showReproduce  bool
+	showRanges     bool
+
+	// testNoFail is set if vulnerabilities that are only called
+	// from tests do not cause failure.
+	testNoFail bool
+
+	// separateTestDeps is set if the findings in modules that are only
+	// dependencies of tests are written apart from the others. They are
+	// then neither counted in the summary nor cause failure.
+	separateTestDeps bool
+
+	// keepOrder is set if vulnerabilities are written in the order of
+	// their first findings, which are sorted, instead of by ID.
+	keepOrder bool
+
+	// posFormat is the format of the -position-format flag in which
+	// positions are written, or "" for the default format.
+	posFormat string
 }
 
 const (
@@ -57,6 +94,14 @@ func (h *TextHandler) Show(show []string) {
 			h.showTraces = true
 		case "color":
 			h.showColor = true
+		case "fixes":
+			h.showFixes = true
+		case "references":
+			h.showReferences = true
+		case "reproduce":
+			h.showReproduce = true
+		case "ranges":
+			h.showRanges = true
 		}
 	}
 }
@@ -70,15 +115,30 @@ func Flush(h govulncheck.Handler) error {
 
 func (h *TextHandler) Flush() error {
 	if len(h.findings) == 0 {
-		return nil
+		if len(h.toolFindings) > 0 || len(h.testDepFindings) > 0 {
+			h.toolVulnerabilities()
+			h.testDependencyVulnerabilities()
+			h.summary(nil)
+		}
+		h.incompleteReasons()
+		h.timings()
+		return h.err
 	}
 	fixupFindings(h.osvs, h.findings)
 	h.byVulnerability(h.findings)
+	if h.showFixes {
+		h.fixes(h.findings)
+	}
+	h.surface(h.modules)
+	h.toolVulnerabilities()
+	h.testDependencyVulnerabilities()
 	h.summary(h.findings)
+	h.incompleteReasons()
+	h.timings()
 	if h.err != nil {
 		return h.err
 	}
-	if isCalled(h.findings) {
+	if isFailure(h.findings, h.testNoFail) {
 		return errVulnerabilitiesFound
 	}
 	return nil
@@ -118,17 +178,58 @@ func (h *TextHandler) OSV(entry *osv.Entry) error {
 	return nil
 }
 
+// Build writes the Go version that built the binary being scanned.
+func (h *TextHandler) Build(build *govulncheck.Build) error {
+	h.print("Binary ")
+	if build.Path != "" {
+		h.print(build.Path, " ")
+	}
+	h.print("built with ")
+	h.style(goStyle, build.GoVersion)
+	h.print(".\n\n")
+	return h.err
+}
+
+// Module gathers module summaries to be written.
+func (h *TextHandler) Module(module *govulncheck.Module) error {
+	h.modules = append(h.modules, module)
+	return nil
+}
+
+// Incomplete gathers the reasons why the scan is incomplete to be written.
+func (h *TextHandler) Incomplete(incomplete *govulncheck.Incomplete) error {
+	h.incomplete = incomplete
+	return nil
+}
+
 // Finding gathers vulnerability findings to be written.
 func (h *TextHandler) Finding(finding *govulncheck.Finding) error {
 	if err := validateFindings(finding); err != nil {
 		return err
 	}
-	h.findings = append(h.findings, newFindingSummary(finding))
+	s := newFindingSummary(finding)
+	if h.posFormat != "" {
+		s.Compact = compactTrace(finding, h.posFormat)
+	}
+	if finding.Tool != nil {
+		h.toolFindings = append(h.toolFindings, s)
+		return nil
+	}
+	if h.separateTestDeps && finding.TestDependency {
+		h.testDepFindings = append(h.testDepFindings, s)
+		return nil
+	}
+	h.findings = append(h.findings, s)
 	return nil
 }
 
 func (h *TextHandler) byVulnerability(findings []*findingSummary) {
-	byVuln := groupByVuln(findings)
+	var byVuln [][]*findingSummary
+	if h.keepOrder {
+		byVuln = groupByVulnInOrder(findings)
+	} else {
+		byVuln = groupByVuln(findings)
+	}
 	called := 0
 	for _, findings := range byVuln {
 		if isCalled(findings) {
@@ -162,6 +263,48 @@ func (h *TextHandler) byVulnerability(findings []*findingSummary) {
 	}
 }
 
+// toolVulnerabilities writes the vulnerabilities of the build-time tools,
+// which are not part of the program.
+func (h *TextHandler) toolVulnerabilities() {
+	if len(h.toolFindings) == 0 {
+		return
+	}
+	fixupFindings(h.osvs, h.toolFindings)
+	byVuln := groupByVuln(h.toolFindings)
+	h.print("\n")
+	h.style(sectionStyle, "=== Build-time tools ===\n")
+	h.print("\nFound ", len(byVuln))
+	h.print(choose(len(byVuln) == 1, ` vulnerability`, ` vulnerabilities`))
+	h.print(" in packages imported by the tools that build your code.\nThey are not part of your program, but run when it is built.\n\n")
+	for i, findings := range byVuln {
+		if i > 0 {
+			h.print("\n")
+		}
+		h.vulnerability(i, findings)
+	}
+}
+
+// testDependencyVulnerabilities writes the vulnerabilities of the modules
+// that are only dependencies of tests, if they are written apart.
+func (h *TextHandler) testDependencyVulnerabilities() {
+	if len(h.testDepFindings) == 0 {
+		return
+	}
+	fixupFindings(h.osvs, h.testDepFindings)
+	byVuln := groupByVuln(h.testDepFindings)
+	h.print("\n")
+	h.style(sectionStyle, "=== Test-only dependencies ===\n")
+	h.print("\nFound ", len(byVuln))
+	h.print(choose(len(byVuln) == 1, ` vulnerability`, ` vulnerabilities`))
+	h.print(" in modules that only your tests depend on, such as\ntest fixtures and mocks. They are not part of your program.\n\n")
+	for i, findings := range byVuln {
+		if i > 0 {
+			h.print("\n")
+		}
+		h.vulnerability(i, findings)
+	}
+}
+
 func (h *TextHandler) vulnerability(index int, findings []*findingSummary) {
 	h.style(keyStyle, "Vulnerability")
 	h.print(" #", index+1, ": ")
@@ -181,6 +324,37 @@ func (h *TextHandler) vulnerability(index int, findings []*findingSummary) {
 	h.print("\n")
 	h.style(keyStyle, "  More info:")
 	h.print(" ", findings[0].OSV.DatabaseSpecific.URL, "\n")
+	if cwes := findings[0].CWEs; len(cwes) > 0 {
+		h.style(keyStyle, "  Weaknesses:")
+		h.print(" ", strings.Join(cwes, ", "), "\n")
+	}
+	if h.showReferences {
+		h.references(findings[0].OSV)
+	}
+	if isCalled(findings) && !isCalledOutsideTests(findings) {
+		h.style(keyStyle, "  Only called from tests.")
+		h.print("\n")
+	}
+	if !h.separateTestDeps && isTestDependency(findings) {
+		h.style(keyStyle, "  Only in modules that are dependencies of tests.")
+		h.print("\n")
+	}
+	if isLikelyFalsePositive(findings) {
+		h.style(keyStyle, "  Likely a false positive, as all call stacks go through the standard library.")
+		h.print("\n")
+	}
+	if isInternalAPI(findings) {
+		h.style(keyStyle, "  Only reached through the internal code of the vulnerable module, so upgrading it is the remedy.")
+		h.print("\n")
+	}
+	if isDispatchAssumed(findings) {
+		h.style(keyStyle, "  Only reached through functions stored in maps or slices, as assumed by the -dispatch-tables flag.")
+		h.print("\n")
+	}
+	if isAssumedCalled(findings) {
+		h.style(keyStyle, "  Treated as called by the -assume-called flag, but no call stacks were found.")
+		h.print("\n")
+	}
 
 	byModule := groupByModule(findings)
 	first := true
@@ -205,16 +379,47 @@ func (h *TextHandler) vulnerability(index int, findings []*findingSummary) {
 			h.style(keyStyle, "Module: ")
 			h.print(mod)
 		}
+		if o := module[0].Ownership; o != "" {
+			h.print(" (", o, ")")
+		}
 		h.print("\n    ")
 		h.style(keyStyle, "Found in: ")
 		h.print(path, "@", foundVersion, "\n    ")
 		h.style(keyStyle, "Fixed in: ")
 		if fixedVersion != "" {
 			h.print(path, "@", fixedVersion)
+		} else if module[0].FixStatus == govulncheck.FixStatusUnknown {
+			h.print("unknown")
 		} else {
 			h.print("N/A")
 		}
 		h.print("\n")
+		if h.showRanges {
+			if ranges := affectedRanges(mod, module[0].OSV); len(ranges) > 0 {
+				h.style(keyStyle, "    Affected ranges: ")
+				h.print(strings.Join(ranges, "; "), "\n")
+			}
+		}
+		if s := module[0].OrgSeverity; s != "" {
+			h.style(keyStyle, "    Severity: ")
+			h.print(s, "\n")
+		}
+		if seen := firstSeen(module); seen != "" {
+			h.style(keyStyle, "    First seen: ")
+			h.print(seen, "\n")
+		}
+		if versions := module[0].GoVersions; len(versions) > 0 {
+			h.style(keyStyle, "    Affected Go versions: ")
+			h.print(strings.Join(versions, ", "), "\n")
+		}
+		if required := module[0].RequiredVersion; required != "" {
+			h.style(keyStyle, "    Required in go.mod: ")
+			h.print(path, "@", moduleVersionString(lastFrame.Module, required), " (raised to ", foundVersion, " by other requirements)\n")
+		}
+		if module[0].ReplacedBy != "" {
+			h.style(keyStyle, "    Replaced by: ")
+			h.print(module[0].ReplacedBy, " (possibly mitigated)\n")
+		}
 		platforms := platforms(mod, module[0].OSV)
 		if len(platforms) > 0 {
 			h.style(keyStyle, "    Platforms: ")
@@ -226,7 +431,31 @@ func (h *TextHandler) vulnerability(index int, findings []*findingSummary) {
 			}
 			h.print("\n")
 		}
+		if importers := module[0].ImportedBy; len(importers) > 0 {
+			h.style(keyStyle, "    Imported by: ")
+			h.print(strings.Join(importers, ", "), "\n")
+		}
+		if tools := toolNames(module); len(tools) > 0 {
+			h.style(keyStyle, "    Tools: ")
+			h.print(strings.Join(tools, ", "), "\n")
+		}
 		h.traces(module)
+		if h.showReproduce {
+			h.reproduce(module)
+		}
+	}
+}
+
+// reproduce writes the distinct commands that reproduce findings.
+func (h *TextHandler) reproduce(findings []*findingSummary) {
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if f.Reproduce == "" || seen[f.Reproduce] {
+			continue
+		}
+		seen[f.Reproduce] = true
+		h.style(keyStyle, "    Reproduce with: ")
+		h.print(f.Reproduce, "\n")
 	}
 }
 
@@ -243,21 +472,140 @@ func (h *TextHandler) traces(traces []*findingSummary) {
 
 		h.print("      #", i+1, ": ")
 		if !h.showTraces {
-			h.print(entry.Compact, "\n")
+			h.print(entry.Compact)
+			h.merged(entry)
+			h.print("\n")
 		} else {
-			h.print("for function ", symbol(entry.Trace[0], false), "\n")
+			h.print("for function ", symbol(entry.Trace[0], false))
+			h.merged(entry)
+			h.print("\n")
 			for i := len(entry.Trace) - 1; i >= 0; i-- {
 				t := entry.Trace[i]
 				h.print("        ")
 				if t.Position != nil {
-					h.print(posToString(t.Position), ": ")
+					h.print(formatPosition(t.Position, h.posFormat), ": ")
+				}
+				h.print(symbol(t, false))
+				if t.Inlined {
+					h.print(" (inlined)")
+				}
+				if len(t.Candidates) > 0 {
+					h.print(" (dynamic call, may dispatch to ", strings.Join(t.Candidates, ", "), ")")
 				}
-				h.print(symbol(t, false), "\n")
+				if t.Assumed {
+					h.print(" (assumed call of a function stored in a map or slice)")
+				}
+				if t.Callback {
+					h.print(" (assumed callback from C)")
+				}
+				h.print("\n")
 			}
 		}
 	}
 }
 
+// merged writes the number of findings merged into entry, if any.
+func (h *TextHandler) merged(entry *findingSummary) {
+	if entry.Merged > 1 {
+		h.print(" (and ", entry.Merged-1, " more at other positions)")
+	}
+}
+
+// references writes the references of entry, such as links to the fix,
+// and the IDs of related vulnerabilities.
+func (h *TextHandler) references(entry *osv.Entry) {
+	if len(entry.References) > 0 {
+		h.style(keyStyle, "  References:")
+		h.print("\n")
+		for _, ref := range entry.References {
+			h.print("    ", ref.Type, ": ", ref.URL, "\n")
+		}
+	}
+	if len(entry.Related) > 0 {
+		h.style(keyStyle, "  Related:")
+		h.print(" ", strings.Join(entry.Related, ", "), "\n")
+	}
+}
+
+// affectedRanges describes the affected ranges of modulePath in entry by
+// their events, as in "SEMVER introduced 0, fixed 1.18.6", in the order
+// of the entry.
+func affectedRanges(modulePath string, entry *osv.Entry) []string {
+	var ranges []string
+	for _, a := range entry.Affected {
+		if a.Module.Path != modulePath {
+			continue
+		}
+		for _, r := range a.Ranges {
+			var events []string
+			for _, e := range r.Events {
+				if e.Introduced != "" {
+					events = append(events, "introduced "+e.Introduced)
+				}
+				if e.Fixed != "" {
+					events = append(events, "fixed "+e.Fixed)
+				}
+			}
+			ranges = append(ranges, string(r.Type)+" "+strings.Join(events, ", "))
+		}
+	}
+	return ranges
+}
+
+// fixes writes the module upgrades that resolve the called vulnerabilities,
+// one line per module.
+func (h *TextHandler) fixes(findings []*findingSummary) {
+	groups := groupByFix(findings)
+	if len(groups) == 0 {
+		return
+	}
+	h.print("\n")
+	h.style(sectionStyle, "=== Fixes ===\n")
+	h.print("\n")
+	for _, g := range groups {
+		name := g.Module
+		if name == internal.GoStdModulePath {
+			name = "the Go standard library"
+		}
+		if len(g.OSVs) > 0 {
+			h.print("Upgrade ", name, " to ")
+			h.style(valueStyle, moduleVersionString(g.Module, g.Version))
+			h.print(" to fix ", len(g.OSVs))
+			h.print(choose(len(g.OSVs) == 1, ` vulnerability: `, ` vulnerabilities: `))
+			h.print(strings.Join(g.OSVs, ", "), "\n")
+		}
+		if len(g.Unfixed) > 0 {
+			h.print("No fixed version of ", name, " is available for ")
+			h.print(strings.Join(g.Unfixed, ", "), "\n")
+		}
+	}
+}
+
+// surface prints how much of the API of each module is used, for the
+// modules whose API usage was computed.
+func (h *TextHandler) surface(modules []*govulncheck.Module) {
+	var printed bool
+	for _, m := range modules {
+		if m.ExportedSymbols == 0 {
+			continue
+		}
+		if !printed {
+			h.print("\n")
+			h.style(sectionStyle, "=== API Usage ===\n")
+			h.print("\n")
+			printed = true
+		}
+		name := m.Path
+		if name == internal.GoStdModulePath {
+			name = "the Go standard library"
+		}
+		h.print("Your code uses ")
+		h.style(valueStyle, m.UsedSymbols)
+		h.print(" of ", m.ExportedSymbols, " exported functions and methods of ", name)
+		h.print(fmt.Sprintf(" (%.1f%%)\n", 100*float64(m.UsedSymbols)/float64(m.ExportedSymbols)))
+	}
+}
+
 func (h *TextHandler) summary(findings []*findingSummary) {
 	counters := counters(findings)
 	h.print("\n")
@@ -281,6 +629,28 @@ func (h *TextHandler) summary(findings []*findingSummary) {
 		h.print(` the Go standard library`)
 	}
 	h.print(".\n")
+	if counters.TestOnlyCalled > 0 {
+		h.style(valueStyle, counters.TestOnlyCalled)
+		h.print(choose(counters.TestOnlyCalled == 1, ` of them is`, ` of them are`))
+		h.print(" only called from tests.\n")
+	}
+	if counters.LikelyFalsePositives > 0 {
+		h.style(valueStyle, counters.LikelyFalsePositives)
+		h.print(choose(counters.LikelyFalsePositives == 1, ` of them is`, ` of them are`))
+		h.print(choose(counters.LikelyFalsePositives == 1, " likely a false positive.\n", " likely false positives.\n"))
+	}
+}
+
+// incompleteReasons writes the reasons why the scan is incomplete, so that
+// an absence of findings is not mistaken for an absence of vulnerabilities.
+func (h *TextHandler) incompleteReasons() {
+	if h.incomplete == nil {
+		return
+	}
+	h.print("\nThe scan is incomplete, so some vulnerabilities may not be reported:\n")
+	for _, r := range h.incomplete.Reasons {
+		h.print("  - ", r.Message, "\n")
+	}
 }
 
 func (h *TextHandler) style(style style, values ...any) {
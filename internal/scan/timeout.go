@@ -0,0 +1,40 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// timedOut reports whether err, as returned by the Err method of the
+// context of the scan, is due to the expiry of the -timeout of cfg.
+func timedOut(cfg *config, err error) bool {
+	return cfg.timeout > 0 && errors.Is(err, context.DeadlineExceeded)
+}
+
+// timeoutReason returns the reason why a source scan whose -timeout
+// expired is incomplete, where consequence tells when the -timeout
+// expired and what was not analyzed.
+func timeoutReason(cfg *config, consequence string) *govulncheck.IncompleteReason {
+	return &govulncheck.IncompleteReason{
+		Kind:    govulncheck.IncompleteTimeout,
+		Message: fmt.Sprintf("the -timeout of %v expired %s", cfg.timeout, consequence),
+	}
+}
+
+// isTimeout reports whether a scan is incomplete because its -timeout
+// expired.
+func isTimeout(inc *govulncheck.Incomplete) bool {
+	for _, r := range inc.Reasons {
+		if r.Kind == govulncheck.IncompleteTimeout {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"sort"
+	"time"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// timingSource returns the timing of a source scan that spent load
+// loading packages, and callStacks searching for the call stacks of the
+// vulnerabilities of vr. The other phases are timed by vulncheck.
+func timingSource(load, callStacks time.Duration, vr *vulncheck.Result) *govulncheck.Timing {
+	t := &govulncheck.Timing{}
+	phase := func(p govulncheck.Phase, d time.Duration) {
+		t.Phases = append(t.Phases, &govulncheck.PhaseTiming{Phase: p, Seconds: d.Seconds()})
+	}
+	phase(govulncheck.PhaseLoad, load)
+	phase(govulncheck.PhaseFetch, vr.Timings.Fetch)
+	if vr.Timings.CallGraph > 0 {
+		phase(govulncheck.PhaseCallGraph, vr.Timings.CallGraph)
+	}
+	phase(govulncheck.PhaseMatch, vr.Timings.Match)
+	if len(vr.Timings.CallStacks) > 0 {
+		phase(govulncheck.PhaseCallStacks, callStacks)
+	}
+	for pkg, d := range vr.Timings.CallStacks {
+		t.Packages = append(t.Packages, &govulncheck.PackageTiming{Package: pkg, Seconds: d.Seconds()})
+	}
+	sort.Slice(t.Packages, func(i, j int) bool {
+		pi, pj := t.Packages[i], t.Packages[j]
+		if pi.Seconds != pj.Seconds {
+			return pi.Seconds > pj.Seconds
+		}
+		return pi.Package < pj.Package
+	})
+	return t
+}
+
+// phaseNames are the descriptions of the phases of a scan in text output.
+var phaseNames = map[govulncheck.Phase]string{
+	govulncheck.PhaseLoad:       "loading packages",
+	govulncheck.PhaseFetch:      "fetching vulnerabilities",
+	govulncheck.PhaseCallGraph:  "building the call graph",
+	govulncheck.PhaseMatch:      "matching vulnerabilities",
+	govulncheck.PhaseCallStacks: "searching for call stacks",
+}
+
+// Timing gathers the timing of the scan to be written.
+func (h *TextHandler) Timing(timing *govulncheck.Timing) error {
+	h.timing = timing
+	return nil
+}
+
+// timings writes where the time of the scan was spent, if known.
+func (h *TextHandler) timings() {
+	if h.timing == nil {
+		return
+	}
+	h.print("\n")
+	h.style(sectionStyle, "=== Timing ===\n")
+	h.print("\n")
+	for _, p := range h.timing.Phases {
+		name := phaseNames[p.Phase]
+		if name == "" {
+			name = string(p.Phase)
+		}
+		h.print(name, ": ", seconds(p.Seconds), "\n")
+	}
+	if len(h.timing.Packages) == 0 {
+		return
+	}
+	h.print("\nCall stack search time per vulnerable package:\n")
+	for _, p := range h.timing.Packages {
+		h.print("  ", p.Package, ": ", seconds(p.Seconds), "\n")
+	}
+}
+
+// seconds formats s seconds as a duration rounded to the millisecond.
+func seconds(s float64) string {
+	return time.Duration(s * float64(time.Second)).Round(time.Millisecond).String()
+}
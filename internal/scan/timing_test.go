@@ -0,0 +1,63 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+func TestTiming(t *testing.T) {
+	vr := &vulncheck.Result{Timings: vulncheck.Timings{
+		Fetch:     300 * time.Millisecond,
+		CallGraph: 2 * time.Second,
+		Match:     50 * time.Millisecond,
+		CallStacks: map[string]time.Duration{
+			"golang.org/x/text/language": 400 * time.Millisecond,
+			"github.com/tidwall/gjson":   1500 * time.Millisecond,
+		},
+	}}
+	timing := timingSource(1200*time.Millisecond, 1600*time.Millisecond, vr)
+
+	var buf bytes.Buffer
+	h := NewTextHandler(&buf)
+	if err := h.Timing(timing); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := `
+=== Timing ===
+
+loading packages: 1.2s
+fetching vulnerabilities: 300ms
+building the call graph: 2s
+matching vulnerabilities: 50ms
+searching for call stacks: 1.6s
+
+Call stack search time per vulnerable package:
+  github.com/tidwall/gjson: 1.5s
+  golang.org/x/text/language: 400ms
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Phases that did not run are not reported.
+	timing = timingSource(time.Second, 0, &vulncheck.Result{})
+	var got []govulncheck.Phase
+	for _, p := range timing.Phases {
+		got = append(got, p.Phase)
+	}
+	if diff := cmp.Diff([]govulncheck.Phase{govulncheck.PhaseLoad, govulncheck.PhaseFetch, govulncheck.PhaseMatch}, got); diff != "" {
+		t.Errorf("phases mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// toolReference is a reference of the main module to a build-time tool.
+type toolReference struct {
+	tool *govulncheck.Tool
+
+	// version is the version of the tool module requested by a
+	// go:generate directive, as in "go run pkg@version", if any.
+	version string
+}
+
+// findTools returns the references to build-time tools of the main
+// modules of pkgs: the tool directives of their go.mod files, and the
+// go:generate directives of their packages that run a package outside
+// of the main modules with go run. Positions are reported relative to
+// base.
+func findTools(pkgs []*packages.Package, base string) []*toolReference {
+	var refs []*toolReference
+	gomods := map[string]bool{}
+	var files []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Module == nil || !pkg.Module.Main {
+			return
+		}
+		if gomod := pkg.Module.GoMod; gomod != "" && !gomods[gomod] {
+			gomods[gomod] = true
+			refs = append(refs, toolDirectives(gomod, base)...)
+		}
+		files = append(files, pkg.GoFiles...)
+	})
+	sort.Strings(files)
+	seen := map[string]bool{}
+	for _, file := range files {
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		refs = append(refs, generateDirectives(file, base)...)
+	}
+	return refs
+}
+
+// toolDirectives returns the tools referenced by the tool directives of
+// the go.mod file at path. Unreadable files are skipped, as the packages
+// of the module have been loaded already.
+func toolDirectives(path, base string) []*toolReference {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	// Older versions of the modfile package do not know tool directives,
+	// so they are read from the syntax tree.
+	f, err := modfile.ParseLax(path, data, nil)
+	if err != nil {
+		return nil
+	}
+	var refs []*toolReference
+	add := func(line *modfile.Line, tokens []string) {
+		if len(tokens) != 1 {
+			return
+		}
+		refs = append(refs, &toolReference{tool: &govulncheck.Tool{
+			Package:   tokens[0],
+			Directive: govulncheck.ToolDirective,
+			Position:  position(&token.Position{Filename: path, Offset: line.Start.Byte, Line: line.Start.Line, Column: line.Start.LineRune}, base),
+		}})
+	}
+	for _, stmt := range f.Syntax.Stmt {
+		switch stmt := stmt.(type) {
+		case *modfile.Line:
+			if len(stmt.Token) > 0 && stmt.Token[0] == "tool" {
+				add(stmt, stmt.Token[1:])
+			}
+		case *modfile.LineBlock:
+			if len(stmt.Token) == 1 && stmt.Token[0] == "tool" {
+				for _, line := range stmt.Line {
+					add(line, line.Token)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// generateDirectives returns the tools run with go run by the
+// go:generate directives of the Go file at path. Packages in the main
+// module, which are named by relative paths, are not tools of their own.
+func generateDirectives(path, base string) []*toolReference {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var refs []*toolReference
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	// offset is the byte offset of the line last scanned.
+	var offset, next int
+	sc.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			offset, next = next, next+advance
+		}
+		return advance, token, err
+	})
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "//go:generate ") {
+			continue
+		}
+		pkg := goRunPackage(strings.Fields(strings.TrimPrefix(line, "//go:generate ")))
+		if pkg == "" || strings.HasPrefix(pkg, ".") {
+			continue
+		}
+		ref := &toolReference{tool: &govulncheck.Tool{
+			Directive: govulncheck.GenerateDirective,
+			Position:  position(&token.Position{Filename: path, Offset: offset, Line: n, Column: 1}, base),
+		}}
+		ref.tool.Package, ref.version, _ = strings.Cut(pkg, "@")
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// goRunPackage returns the package run by the command in args if it is
+// "go run [flags] package [arguments]", or "".
+func goRunPackage(args []string) string {
+	if len(args) < 3 || args[0] != "go" || args[1] != "run" {
+		return ""
+	}
+	for _, arg := range args[2:] {
+		if !strings.HasPrefix(arg, "-") {
+			if strings.HasSuffix(arg, ".go") {
+				return ""
+			}
+			return arg
+		}
+	}
+	return ""
+}
+
+// toolPackage is a loaded build-time tool along with the packages that
+// it imports, directly or not.
+type toolPackage struct {
+	tool    *govulncheck.Tool
+	imports map[string]bool
+}
+
+// scanTools scans the build-time tools referenced by the main modules of
+// pkgs for vulnerable imports. Only tools in modules of the build list
+// of the main modules can be loaded; the others are reported by progress
+// messages to handler.
+func scanTools(ctx context.Context, handler govulncheck.Handler, cfg *config, client *client.Client, dir string, pkgs []*packages.Package) (*vulncheck.Result, []*toolPackage, error) {
+	refs := findTools(pkgs, cfg.posBase)
+	var patterns []string
+	byPackage := map[string][]*toolReference{}
+	for _, ref := range refs {
+		if ref.version != "" {
+			msg := fmt.Sprintf("Warning: the tool %s@%s run by %s is not checked for vulnerabilities, as only tools at the versions required by go.mod are checked.",
+				ref.tool.Package, ref.version, toolPosition(ref.tool))
+			if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if byPackage[ref.tool.Package] == nil {
+			patterns = append(patterns, ref.tool.Package)
+		}
+		byPackage[ref.tool.Package] = append(byPackage[ref.tool.Package], ref)
+	}
+	if len(patterns) == 0 {
+		return nil, nil, nil
+	}
+	graph := vulncheck.NewPackageGraph(cfg.GoVersion)
+	pkgConfig, err := packagesConfig(cfg, dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("govulncheck: %v", err)
+	}
+	pkgConfig.Tests = false
+	toolPkgs, err := graph.LoadPackages(pkgConfig, cfg.tags, patterns)
+	if err != nil {
+		msg := fmt.Sprintf("Warning: the build-time tools could not be loaded, so their vulnerabilities are not reported: %v", err)
+		return nil, nil, handler.Progress(&govulncheck.Progress{Message: msg})
+	}
+	// Tools are not analyzed further than their imports, as they are
+	// not part of the program.
+	toolCfg := cfg.Config
+	toolCfg.ScanLevel = "package"
+	vr, err := vulncheck.Source(ctx, toolPkgs, &toolCfg, client, graph)
+	if err != nil {
+		return nil, nil, err
+	}
+	var tools []*toolPackage
+	for _, pkg := range toolPkgs {
+		if pkg.Module == nil || pkg.Module.Main {
+			// Packages of the main modules are not third-party tools.
+			continue
+		}
+		imports := map[string]bool{}
+		packages.Visit([]*packages.Package{pkg}, func(p *packages.Package) bool {
+			imports[p.PkgPath] = true
+			return true
+		}, nil)
+		for _, ref := range byPackage[pkg.PkgPath] {
+			tools = append(tools, &toolPackage{tool: ref.tool, imports: imports})
+		}
+	}
+	return vr, tools, nil
+}
+
+// toolPosition describes the directive referencing tool, as in
+// "go:generate at gen.go:3:1".
+func toolPosition(tool *govulncheck.Tool) string {
+	s := tool.Directive
+	if tool.Directive == govulncheck.ToolDirective {
+		s += " directive"
+	}
+	if p := tool.Position; p != nil {
+		s += fmt.Sprintf(" at %s:%d:%d", p.Filename, p.Line, p.Column)
+	}
+	return s
+}
+
+// tools adds a finding for each vulnerability of vr, the result of
+// scanning the build-time tools, in each of the tools that imports its
+// vulnerable package.
+func (e *emitter) tools(vr *vulncheck.Result, tools []*toolPackage) error {
+	// vr has a vulnerability for each vulnerable symbol of a package,
+	// but the findings in tools are only imported.
+	type key struct {
+		osv, pkg string
+		tool     *toolPackage
+	}
+	seen := map[key]bool{}
+	for _, vv := range vr.Vulns {
+		addOSV(e.osvs, vv.OSV)
+		fixed, status := fixedVersion(vv.ImportSink.Module.Path, vv.OSV.Affected)
+		for _, t := range tools {
+			k := key{vv.OSV.ID, vv.ImportSink.PkgPath, t}
+			if !t.imports[vv.ImportSink.PkgPath] || seen[k] {
+				continue
+			}
+			seen[k] = true
+			err := e.add(&govulncheck.Finding{
+				OSV:           vv.OSV.ID,
+				FixedVersion:  fixed,
+				FixStatus:     status,
+				AffectedRange: vulnAffectedRange(vv),
+				ReplacedBy:    replacedBy(vv.ImportSink.Module),
+				Trace:         []*govulncheck.Frame{frameFromPackage(vv.ImportSink)},
+				Tool:          t.tool,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// toolNames returns the descriptions of the distinct build-time tools of
+// findings, in order, as in "example.com/gen (go:generate at gen.go:3:1)".
+func toolNames(findings []*findingSummary) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if f.Tool == nil {
+			continue
+		}
+		name := fmt.Sprintf("%s (%s)", f.Tool.Package, toolPosition(f.Tool))
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+func TestGoRunPackage(t *testing.T) {
+	for _, test := range []struct {
+		command string
+		want    string
+	}{
+		{"go run example.com/gen -o tags.go", "example.com/gen"},
+		{"go run -mod=mod golang.org/x/tools/cmd/stringer@v0.1.0 -type=T", "golang.org/x/tools/cmd/stringer@v0.1.0"},
+		{"go run gen.go", ""},
+		{"go build ./...", ""},
+		{"stringer -type=T", ""},
+	} {
+		if got := goRunPackage(strings.Fields(test.command)); got != test.want {
+			t.Errorf("goRunPackage(%q) = %q; want %q", test.command, got, test.want)
+		}
+	}
+}
+
+func TestToolDirectives(t *testing.T) {
+	dir := t.TempDir()
+	gomod := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(gomod, []byte(`module example.com/m
+
+go 1.24
+
+tool example.com/gen
+
+tool (
+	golang.org/x/tools/cmd/stringer
+)
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var got []govulncheck.Tool
+	for _, ref := range toolDirectives(gomod, dir) {
+		got = append(got, *ref.tool)
+	}
+	want := []govulncheck.Tool{
+		{Package: "example.com/gen", Directive: govulncheck.ToolDirective, Position: &govulncheck.Position{Filename: "go.mod", Offset: 31, Line: 5, Column: 1}},
+		{Package: "golang.org/x/tools/cmd/stringer", Directive: govulncheck.ToolDirective, Position: &govulncheck.Position{Filename: "go.mod", Offset: 61, Line: 8, Column: 2}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestGenerateDirectives(t *testing.T) {
+	dir := t.TempDir()
+	gen := filepath.Join(dir, "gen.go")
+	// Lines may end with CRLF.
+	if err := os.WriteFile(gen, []byte("package m\r\n\n//go:generate go run gen.go\n//go:generate go run example.com/gen@v1.0.0 -o tags.go\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var got []govulncheck.Tool
+	for _, ref := range generateDirectives(gen, dir) {
+		got = append(got, *ref.tool)
+	}
+	want := []govulncheck.Tool{
+		{Package: "example.com/gen", Directive: govulncheck.GenerateDirective, Position: &govulncheck.Position{Filename: "gen.go", Offset: 40, Line: 4, Column: 1}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"io"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+const (
+	// traceOrderSink and traceOrderEntry are the values of the
+	// -trace-order flag, which report traces starting from the
+	// vulnerable symbol and from the entry point, respectively.
+	traceOrderSink  = "sink"
+	traceOrderEntry = "entry"
+)
+
+// traceOrderHandler is a handler that sorts the traces of findings in
+// order before passing them to the handler it wraps. The findings of
+// govulncheck are always built with the vulnerable symbol first, which
+// the other handlers rely on, so traces are only reordered for output.
+// The other messages, including those of the optional interfaces of
+// govulncheck.Handler, are passed as is.
+type traceOrderHandler struct {
+	govulncheck.Handler
+	order govulncheck.TraceOrder
+}
+
+// Finding passes a copy of finding with its trace sorted in h.order to the
+// wrapped handler.
+func (h *traceOrderHandler) Finding(finding *govulncheck.Finding) error {
+	f := *finding
+	f.OrderTrace(h.order)
+	return h.Handler.Finding(&f)
+}
+
+// Flush flushes the wrapped handler.
+func (h *traceOrderHandler) Flush() error {
+	return Flush(h.Handler)
+}
+
+// Module passes module to the wrapped handler, if it is a ModuleHandler.
+func (h *traceOrderHandler) Module(module *govulncheck.Module) error {
+	if mh, ok := h.Handler.(govulncheck.ModuleHandler); ok {
+		return mh.Module(module)
+	}
+	return nil
+}
+
+// Build passes build to the wrapped handler, if it is a BuildHandler.
+func (h *traceOrderHandler) Build(build *govulncheck.Build) error {
+	if bh, ok := h.Handler.(govulncheck.BuildHandler); ok {
+		return bh.Build(build)
+	}
+	return nil
+}
+
+// Scope passes scope to the wrapped handler, if it is a ScopeHandler.
+func (h *traceOrderHandler) Scope(scope *govulncheck.Scope) error {
+	if sh, ok := h.Handler.(govulncheck.ScopeHandler); ok {
+		return sh.Scope(scope)
+	}
+	return nil
+}
+
+// Incomplete passes incomplete to the wrapped handler, if it is an
+// IncompleteHandler.
+func (h *traceOrderHandler) Incomplete(incomplete *govulncheck.Incomplete) error {
+	if ih, ok := h.Handler.(govulncheck.IncompleteHandler); ok {
+		return ih.Incomplete(incomplete)
+	}
+	return nil
+}
+
+// Timing passes timing to the wrapped handler, if it is a TimingHandler.
+func (h *traceOrderHandler) Timing(timing *govulncheck.Timing) error {
+	if th, ok := h.Handler.(govulncheck.TimingHandler); ok {
+		return th.Timing(timing)
+	}
+	return nil
+}
+
+// handleJSON is like govulncheck.HandleJSON, but hands to h the traces of
+// findings with the vulnerable symbol first, as the handlers of govulncheck
+// expect, whatever the order in which they were written.
+func handleJSON(r io.Reader, h govulncheck.Handler) error {
+	return govulncheck.HandleJSON(r, &traceOrderHandler{Handler: h, order: govulncheck.TraceSinkFirst})
+}
@@ -0,0 +1,190 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// recordedFindings returns the findings recorded by rec, in order.
+func recordedFindings(rec *govulncheck.Recorder) []*govulncheck.Finding {
+	var findings []*govulncheck.Finding
+	for _, msg := range rec.Messages {
+		if msg.Finding != nil {
+			findings = append(findings, msg.Finding)
+		}
+	}
+	return findings
+}
+
+// tryUpgrades scans the source in dir again as if the module upgrades of
+// the -try-upgrade flag of cfg were applied, and writes a progress message
+// to handler that compares the findings of that scan with findings, the
+// findings of the current scan.
+//
+// The upgrades are applied by go get to a temporary copy of the go.mod
+// file of dir, so the module graph of the proposed scan is the one go get
+// would produce, including the upgrades of other modules that the new
+// versions require. The go.mod file of dir is left untouched.
+func tryUpgrades(ctx context.Context, handler govulncheck.Handler, cfg *config, client *client.Client, dir string, findings []*govulncheck.Finding) error {
+	// Once the -timeout expired, the proposed scan could only be
+	// compared partially.
+	notTried := &govulncheck.Progress{Message: fmt.Sprintf("The -timeout expired before the upgrades to %s could be tried.", strings.Join(cfg.tryUpgrades, ", "))}
+	if timedOut(cfg, ctx.Err()) {
+		return handler.Progress(notTried)
+	}
+	modfile, cleanup, err := upgradedModfile(ctx, cfg, dir)
+	if err != nil {
+		if timedOut(cfg, ctx.Err()) {
+			return handler.Progress(notTried)
+		}
+		return fmt.Errorf("govulncheck: %v", err)
+	}
+	defer cleanup()
+
+	// The proposed scan has no side effects: it neither writes files
+	// nor reports downloads, and its messages are only recorded.
+	proposed := *cfg
+	proposed.modfile = modfile
+	proposed.tryUpgrades = nil
+	proposed.accept = false
+	proposed.reachabilitySnapshot = ""
+	proposed.reachabilityDiff = ""
+	proposed.surface = false
+	proposed.timing = false
+	proposed.verify = false
+	proposed.entryFunctions = ""
+	proposed.binaryFile = ""
+	proposed.AllEntryFunctions = false
+	proposed.download = nil
+	rec := &govulncheck.Recorder{}
+	if err := runSource(ctx, rec, &proposed, client, dir); err != nil {
+		if timedOut(cfg, ctx.Err()) {
+			return handler.Progress(notTried)
+		}
+		return err
+	}
+	for _, msg := range rec.Messages {
+		if msg.Incomplete != nil && isTimeout(msg.Incomplete) {
+			return handler.Progress(notTried)
+		}
+	}
+	msg := fmt.Sprintf("Upgrading to %s would %s.", strings.Join(cfg.tryUpgrades, ", "), describeUpgrade(findings, recordedFindings(rec)))
+	return handler.Progress(&govulncheck.Progress{Message: msg})
+}
+
+// upgradedModfile returns the path of a temporary go.mod file, along with
+// its go.sum file, where the upgrades of cfg are applied to the go.mod file
+// of the module in dir, and a function that removes the temporary files.
+func upgradedModfile(ctx context.Context, cfg *config, dir string) (_ string, cleanup func(), err error) {
+	tmp, err := os.MkdirTemp("", "govulncheck-upgrade")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(tmp) }
+	defer func() {
+		if err != nil {
+			cleanup()
+		}
+	}()
+	cmd := exec.CommandContext(ctx, "go", "env", "GOMOD")
+	cmd.Dir = dir
+	cmd.Env = cfg.env
+	out, err := cmd.Output()
+	gomod := strings.TrimSpace(string(out))
+	if err != nil || gomod == "" || gomod == os.DevNull {
+		return "", nil, fmt.Errorf("simulating upgrades: %v", errNoGoMod)
+	}
+	for _, name := range []string{"go.mod", "go.sum"} {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(gomod), name))
+		if err != nil {
+			if name == "go.sum" && os.IsNotExist(err) {
+				continue
+			}
+			return "", nil, fmt.Errorf("simulating upgrades: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(tmp, name), data, 0666); err != nil {
+			return "", nil, fmt.Errorf("simulating upgrades: %v", err)
+		}
+	}
+	modfile := filepath.Join(tmp, "go.mod")
+	args := append([]string{"get", "-modfile=" + modfile}, cfg.tryUpgrades...)
+	cmd = exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = cfg.env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", nil, fmt.Errorf("simulating upgrades: go %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return modfile, cleanup, nil
+}
+
+// describeUpgrade describes how the findings of a scan change from before
+// to after an upgrade, by the vulnerabilities that it fixes, introduces,
+// makes called, or makes only imported, as in "fix GO-2021-0113".
+func describeUpgrade(before, after []*govulncheck.Finding) string {
+	b, a := osvCalls(before), osvCalls(after)
+	var changes []string
+	add := func(verb string, ids []string) {
+		if len(ids) > 0 {
+			changes = append(changes, verb+" "+strings.Join(ids, ", "))
+		}
+	}
+	var fixed, introduced, called, uncalled []string
+	for _, id := range sortedOSVs(b) {
+		isCalled, found := a[id]
+		switch {
+		case !found:
+			fixed = append(fixed, id)
+		case b[id] && !isCalled:
+			uncalled = append(uncalled, id)
+		}
+	}
+	for _, id := range sortedOSVs(a) {
+		wasCalled, found := b[id]
+		switch {
+		case !found:
+			introduced = append(introduced, id)
+		case a[id] && !wasCalled:
+			called = append(called, id)
+		}
+	}
+	add("fix", fixed)
+	add("introduce", introduced)
+	add("make called", called)
+	add("make only imported or required", uncalled)
+	if len(changes) == 0 {
+		return "not change the vulnerabilities found"
+	}
+	return strings.Join(changes, ", and ")
+}
+
+// osvCalls reports, for each OSV ID of findings, whether some of its
+// findings is called.
+func osvCalls(findings []*govulncheck.Finding) map[string]bool {
+	calls := map[string]bool{}
+	for _, f := range findings {
+		calls[f.OSV] = calls[f.OSV] || isCalledFinding(f)
+	}
+	return calls
+}
+
+// sortedOSVs returns the sorted OSV IDs of calls.
+func sortedOSVs(calls map[string]bool) []string {
+	var ids []string
+	for id := range calls {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+func TestDescribeUpgrade(t *testing.T) {
+	called := func(osv string) *govulncheck.Finding {
+		return &govulncheck.Finding{OSV: osv, Trace: []*govulncheck.Frame{{Module: "m", Package: "m/p", Function: "F"}}}
+	}
+	imported := func(osv string) *govulncheck.Finding {
+		return &govulncheck.Finding{OSV: osv, Trace: []*govulncheck.Frame{{Module: "m", Package: "m/p"}}}
+	}
+	for _, test := range []struct {
+		name          string
+		before, after []*govulncheck.Finding
+		want          string
+	}{
+		{
+			name:   "unchanged",
+			before: []*govulncheck.Finding{called("GO-0000-0001"), imported("GO-0000-0001")},
+			after:  []*govulncheck.Finding{called("GO-0000-0001")},
+			want:   "not change the vulnerabilities found",
+		},
+		{
+			name:   "fixed and introduced",
+			before: []*govulncheck.Finding{called("GO-0000-0002"), imported("GO-0000-0001"), called("GO-0000-0003")},
+			after:  []*govulncheck.Finding{called("GO-0000-0003"), imported("GO-0000-0004")},
+			want:   "fix GO-0000-0001, GO-0000-0002, and introduce GO-0000-0004",
+		},
+		{
+			name:   "called",
+			before: []*govulncheck.Finding{imported("GO-0000-0001"), called("GO-0000-0002")},
+			after:  []*govulncheck.Finding{called("GO-0000-0001"), imported("GO-0000-0002")},
+			want:   "make called GO-0000-0001, and make only imported or required GO-0000-0002",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := describeUpgrade(test.before, test.after); got != test.want {
+				t.Errorf("got %q; want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"sort"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// An Upgrade is a module upgrade of an upgrade plan.
+type Upgrade struct {
+	// Module is the path of the module to upgrade.
+	Module string
+
+	// Version is the version to upgrade Module to.
+	Version string
+
+	// OSVs are the IDs of the vulnerabilities resolved by the upgrade,
+	// in sorted order.
+	OSVs []string
+}
+
+// PlanUpgrades returns the smallest set of module upgrades that resolves
+// findings, or only the findings of called vulnerabilities if calledOnly
+// is set.
+//
+// A vulnerability of a module is resolved by upgrading the module to its
+// fixed version or any later one, so a single upgrade per module, to the
+// highest fixed version of its vulnerabilities, resolves all of them. A
+// module that appears at several versions, for instance in findings
+// merged from different scans, is upgraded to that version throughout.
+// Vulnerabilities without a fixed version cannot be resolved by an
+// upgrade and are left out of the plan.
+//
+// The upgrades are sorted by module path.
+func PlanUpgrades(findings []*govulncheck.Finding, calledOnly bool) []*Upgrade {
+	byModule := map[string]*Upgrade{}
+	seen := map[[2]string]bool{}
+	for _, f := range findings {
+		if f.FixedVersion == "" || len(f.Trace) == 0 {
+			continue
+		}
+		if calledOnly && !isCalledFinding(f) {
+			continue
+		}
+		mod := f.Trace[0].Module
+		key := [2]string{mod, f.OSV}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		u := byModule[mod]
+		if u == nil {
+			u = &Upgrade{Module: mod, Version: f.FixedVersion}
+			byModule[mod] = u
+		} else if semver.Compare(u.Version, f.FixedVersion) < 0 {
+			u.Version = f.FixedVersion
+		}
+		u.OSVs = append(u.OSVs, f.OSV)
+	}
+	var upgrades []*Upgrade
+	for _, u := range byModule {
+		sort.Strings(u.OSVs)
+		upgrades = append(upgrades, u)
+	}
+	sort.Slice(upgrades, func(i, j int) bool {
+		return upgrades[i].Module < upgrades[j].Module
+	})
+	return upgrades
+}
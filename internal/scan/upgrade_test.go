@@ -0,0 +1,56 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+func TestPlanUpgrades(t *testing.T) {
+	finding := func(osv, mod, version, fixed, fn string) *govulncheck.Finding {
+		return &govulncheck.Finding{
+			OSV:          osv,
+			FixedVersion: fixed,
+			Trace:        []*govulncheck.Frame{{Module: mod, Version: version, Function: fn}},
+		}
+	}
+	findings := []*govulncheck.Finding{
+		finding("GO-0000-0001", "golang.org/amod", "v1.0.0", "v1.0.4", "A"),
+		finding("GO-0000-0001", "golang.org/amod", "v1.0.0", "v1.0.4", "B"),
+		// One upgrade resolves both vulnerabilities of the module,
+		// even when it is found at another version.
+		finding("GO-0000-0002", "golang.org/amod", "v1.1.0", "v1.2.0", ""),
+		finding("GO-0000-0003", "golang.org/bmod", "v0.1.0", "v0.3.0", ""),
+		// Vulnerabilities without a fix are not planned.
+		finding("GO-0000-0004", "golang.org/cmod", "v2.0.0", "", "C"),
+	}
+
+	for _, test := range []struct {
+		calledOnly bool
+		want       []*Upgrade
+	}{
+		{
+			calledOnly: false,
+			want: []*Upgrade{
+				{Module: "golang.org/amod", Version: "v1.2.0", OSVs: []string{"GO-0000-0001", "GO-0000-0002"}},
+				{Module: "golang.org/bmod", Version: "v0.3.0", OSVs: []string{"GO-0000-0003"}},
+			},
+		},
+		{
+			calledOnly: true,
+			want: []*Upgrade{
+				{Module: "golang.org/amod", Version: "v1.0.4", OSVs: []string{"GO-0000-0001"}},
+			},
+		},
+	} {
+		got := PlanUpgrades(findings, test.calledOnly)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("calledOnly=%t: mismatch (-want, +got):\n%s", test.calledOnly, diff)
+		}
+	}
+}
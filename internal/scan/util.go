@@ -6,7 +6,10 @@ package scan
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
+	"golang.org/x/mod/module"
 	"golang.org/x/vuln/internal"
 	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/osv"
@@ -59,12 +62,113 @@ func latestFixed(modulePath string, as []osv.Affected) string {
 	return v
 }
 
-func fixedVersion(modulePath string, affected []osv.Affected) string {
+// fixedVersion returns the version of modulePath that fixes the
+// vulnerability with the affected ranges, if any, and the status of
+// the fix. The status is unknown if no affected range of modulePath has
+// a semver range, since the fix cannot be determined then.
+func fixedVersion(modulePath string, affected []osv.Affected) (string, govulncheck.FixStatus) {
+	known := false
+	for _, a := range affected {
+		if modulePath != a.Module.Path {
+			continue
+		}
+		for _, r := range a.Ranges {
+			if r.Type == osv.RangeTypeSemver {
+				known = true
+			}
+		}
+	}
+	if !known {
+		return "", govulncheck.FixStatusUnknown
+	}
 	fixed := latestFixed(modulePath, affected)
-	if fixed != "" {
-		fixed = "v" + fixed
+	if fixed == "" {
+		return "", govulncheck.FixStatusNotFixed
+	}
+	return "v" + fixed, govulncheck.FixStatusFixed
+}
+
+// ownership returns the ownership of modulePath given patterns, the
+// comma-separated list of glob patterns of internal module paths, which
+// match module path prefixes as for the GOPRIVATE environment variable.
+// It returns "" if there are no patterns.
+func ownership(patterns, modulePath string) govulncheck.Ownership {
+	if patterns == "" {
+		return ""
+	}
+	if module.MatchPrefixPatterns(patterns, modulePath) {
+		return govulncheck.OwnershipInternal
+	}
+	return govulncheck.OwnershipExternal
+}
+
+// assumedCalled reports whether the vulnerabilities imported from
+// modulePath are treated as called given patterns, the comma-separated
+// list of glob patterns of the -assume-called flag.
+func assumedCalled(patterns, modulePath string) bool {
+	return patterns != "" && module.MatchPrefixPatterns(patterns, modulePath)
+}
+
+// affectedRange returns the range of affected entries for modulePath
+// that contains version, or nil if there is no such range.
+func affectedRange(modulePath, version string, affected []osv.Affected) *govulncheck.AffectedRange {
+	if version == "" {
+		return nil
+	}
+	for _, a := range affected {
+		if modulePath != a.Module.Path {
+			continue
+		}
+		introduced, fixed, found := isem.AffectedRange(a.Ranges, version)
+		if !found {
+			continue
+		}
+		r := &govulncheck.AffectedRange{}
+		if introduced != "0" {
+			r.Introduced = "v" + introduced
+		}
+		if fixed != "" {
+			r.Fixed = "v" + fixed
+		}
+		return r
+	}
+	return nil
+}
+
+// affectedGoVersions returns the Go versions among versions, as in
+// "go1.21.5", whose standard library is affected by entry.
+func affectedGoVersions(entry *osv.Entry, versions []string) []string {
+	var affected []string
+	for _, v := range versions {
+		sv := isem.GoTagToSemver(v)
+		for _, a := range entry.Affected {
+			if a.Module.Path == internal.GoStdModulePath && isem.Affects(a.Ranges, sv) {
+				affected = append(affected, v)
+				break
+			}
+		}
+	}
+	return affected
+}
+
+// cweIDs returns the sorted, distinct CWE IDs of entry, as in "CWE-22",
+// or nil if entry has none.
+func cweIDs(entry *osv.Entry) []string {
+	if entry == nil || entry.DatabaseSpecific == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var ids []string
+	for _, id := range entry.DatabaseSpecific.CWEIDs {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
 	}
-	return fixed
+	sort.Strings(ids)
+	return ids
 }
 
 func moduleVersionString(modulePath, version string) string {
@@ -5,8 +5,10 @@
 package scan
 
 import (
+	"reflect"
 	"testing"
 
+	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/osv"
 )
 
@@ -180,3 +182,83 @@ func TestLatestFixed(t *testing.T) {
 		})
 	}
 }
+
+func TestFixedVersion(t *testing.T) {
+	const module = "example.com/module"
+	affected := func(typ osv.RangeType, events ...osv.RangeEvent) []osv.Affected {
+		return []osv.Affected{{
+			Module: osv.Module{Path: module},
+			Ranges: []osv.Range{{Type: typ, Events: events}},
+		}}
+	}
+	for _, test := range []struct {
+		name       string
+		in         []osv.Affected
+		wantFixed  string
+		wantStatus govulncheck.FixStatus
+	}{
+		{
+			name:       "fixed",
+			in:         affected(osv.RangeTypeSemver, osv.RangeEvent{Introduced: "0"}, osv.RangeEvent{Fixed: "1.2.3"}),
+			wantFixed:  "v1.2.3",
+			wantStatus: govulncheck.FixStatusFixed,
+		},
+		{
+			name:       "not fixed",
+			in:         affected(osv.RangeTypeSemver, osv.RangeEvent{Introduced: "0"}),
+			wantStatus: govulncheck.FixStatusNotFixed,
+		},
+		{
+			name:       "no semver",
+			in:         affected(osv.RangeType("GIT"), osv.RangeEvent{Introduced: "0"}, osv.RangeEvent{Fixed: "1.2.3"}),
+			wantStatus: govulncheck.FixStatusUnknown,
+		},
+		{
+			name:       "other module",
+			in:         []osv.Affected{{Module: osv.Module{Path: "example.com/other"}}},
+			wantStatus: govulncheck.FixStatusUnknown,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fixed, status := fixedVersion(module, test.in)
+			if fixed != test.wantFixed || status != test.wantStatus {
+				t.Errorf("got %q, %q, want %q, %q", fixed, status, test.wantFixed, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	for _, test := range []struct {
+		patterns, module string
+		want             govulncheck.Ownership
+	}{
+		{"", "example.com/corp/mod", ""},
+		{"example.com/corp", "example.com/corp/mod", govulncheck.OwnershipInternal},
+		{"example.com/corp", "example.com/corporate", govulncheck.OwnershipExternal},
+		{"*.corp.example.com,github.com/corp/*", "git.corp.example.com/mod", govulncheck.OwnershipInternal},
+		{"*.corp.example.com,github.com/corp/*", "github.com/corp/mod/v2", govulncheck.OwnershipInternal},
+		{"*.corp.example.com,github.com/corp/*", "github.com/other/mod", govulncheck.OwnershipExternal},
+		{"example.com/corp", "stdlib", govulncheck.OwnershipExternal},
+	} {
+		if got := ownership(test.patterns, test.module); got != test.want {
+			t.Errorf("ownership(%q, %q) = %q, want %q", test.patterns, test.module, got, test.want)
+		}
+	}
+}
+
+func TestCWEIDs(t *testing.T) {
+	for _, test := range []struct {
+		entry *osv.Entry
+		want  []string
+	}{
+		{nil, nil},
+		{&osv.Entry{}, nil},
+		{&osv.Entry{DatabaseSpecific: &osv.DatabaseSpecific{URL: "https://pkg.go.dev/vuln/GO-0000-0001"}}, nil},
+		{&osv.Entry{DatabaseSpecific: &osv.DatabaseSpecific{CWEIDs: []string{"CWE-79", "cwe-22", " CWE-79"}}}, []string{"CWE-22", "CWE-79"}},
+	} {
+		if got := cweIDs(test.entry); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("cweIDs(%v) = %v, want %v", test.entry, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// verifyTrace checks trace, the trace of a called finding of vv from its
+// vulnerable symbol to its entry point, against the call graph, for the
+// -verify flag. It returns why the trace cannot be corroborated, or "" if
+// it can.
+//
+// The check is independent of the search and the filtering of the call
+// stacks the trace was built from: starting at the call sink of vv, it
+// looks up each caller of the trace among the call sites of its callee,
+// by symbol and by the position of the call, and it requires the last
+// caller to be one of entries, the entry functions of the call graph.
+func verifyTrace(vv *vulncheck.Vuln, entries map[*vulncheck.FuncNode]bool, trace []*govulncheck.Frame) string {
+	if vv.CallSink == nil {
+		return fmt.Sprintf("%s is not in the call graph", vv.Symbol)
+	}
+	if len(trace) == 0 {
+		return "the trace is empty"
+	}
+	sink := trace[0]
+	if sym := sink.Symbol(); !matchesFrame(sink, vv.CallSink) || sym != vv.ImportSink.PkgPath+"."+vv.Symbol {
+		return fmt.Sprintf("the trace ends at %s instead of %s.%s", sym, vv.ImportSink.PkgPath, vv.Symbol)
+	}
+	fn := vv.CallSink
+	for _, fr := range trace[1:] {
+		var caller *vulncheck.FuncNode
+		for _, cs := range fn.CallSites {
+			if matchesFrame(fr, cs.Parent) && samePosition(fr.Position, cs) {
+				caller = cs.Parent
+				break
+			}
+		}
+		if caller == nil {
+			return fmt.Sprintf("the call graph has no call of %s by %s%s", fn, fr.Symbol(), atPosition(fr.Position))
+		}
+		fn = caller
+	}
+	if !entries[fn] {
+		return fmt.Sprintf("the trace starts at %s, which is not an entry point", fn)
+	}
+	return ""
+}
+
+// matchesFrame reports whether fr is a frame of fn.
+func matchesFrame(fr *govulncheck.Frame, fn *vulncheck.FuncNode) bool {
+	if fn.Package == nil {
+		return fr.Package == "" && fr.Receiver == "" && fr.Function == fn.Name
+	}
+	return fr.Package == fn.Package.PkgPath && fr.Receiver == fn.Receiver() && fr.Function == fn.Name
+}
+
+// samePosition reports whether pos, the position of a frame, is the
+// position of the call cs. Only lines and columns are compared, as file
+// names may be relative.
+func samePosition(pos *govulncheck.Position, cs *vulncheck.CallSite) bool {
+	if pos == nil || cs.Pos == nil {
+		return pos == nil && cs.Pos == nil
+	}
+	return pos.Line == cs.Pos.Line && pos.Column == cs.Pos.Column
+}
+
+// atPosition returns " at line:col" for pos, or "" if pos is nil.
+func atPosition(pos *govulncheck.Position) string {
+	if pos == nil {
+		return ""
+	}
+	return fmt.Sprintf(" at %d:%d", pos.Line, pos.Column)
+}
+
+// verifySummary returns the message summarizing the verification of n
+// called findings, of which failed could not be corroborated.
+func verifySummary(n, failed int) string {
+	msg := fmt.Sprintf("Verified the traces of %d called %s against the call graph", n, choose(n == 1, "finding", "findings"))
+	if failed == 0 {
+		return msg + "."
+	}
+	return fmt.Sprintf("%s: %d could not be corroborated.", msg, failed)
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+func TestVerifyTrace(t *testing.T) {
+	mod := &packages.Module{Path: "golang.org/main"}
+	pkg := &packages.Package{PkgPath: "golang.org/main", Module: mod}
+	vpkg := &packages.Package{PkgPath: "golang.org/vmod/vuln", Module: &packages.Module{Path: "golang.org/vmod", Version: "v1.0.0"}}
+	main := &vulncheck.FuncNode{Name: "main", Package: pkg}
+	worker := &vulncheck.FuncNode{Name: "worker", Package: pkg}
+	vuln := &vulncheck.FuncNode{Name: "V", Package: vpkg}
+	workerCall := &vulncheck.CallSite{Parent: main, Name: "worker", Pos: &token.Position{Filename: "main.go", Line: 3, Column: 2}, Resolved: true}
+	vulnCall := &vulncheck.CallSite{Parent: worker, Name: "V", Pos: &token.Position{Filename: "main.go", Line: 7, Column: 2}, Resolved: true}
+	worker.CallSites = []*vulncheck.CallSite{workerCall}
+	vuln.CallSites = []*vulncheck.CallSite{vulnCall}
+	vv := &vulncheck.Vuln{OSV: &osv.Entry{ID: "GO-0000-0001"}, Symbol: "V", CallSink: vuln, ImportSink: vpkg}
+	entries := map[*vulncheck.FuncNode]bool{main: true}
+
+	stack := vulncheck.CallStack{
+		{Function: main, Call: workerCall},
+		{Function: worker, Call: vulnCall},
+		{Function: vuln},
+	}
+	trace := func(edit func([]*govulncheck.Frame) []*govulncheck.Frame) []*govulncheck.Frame {
+		return edit(tracefromEntries(stack, ""))
+	}
+	for _, test := range []struct {
+		name  string
+		trace []*govulncheck.Frame
+		want  string
+	}{
+		{
+			name:  "corroborated",
+			trace: trace(func(fs []*govulncheck.Frame) []*govulncheck.Frame { return fs }),
+		},
+		{
+			name: "wrong position",
+			trace: trace(func(fs []*govulncheck.Frame) []*govulncheck.Frame {
+				fs[1].Position.Line = 8
+				return fs
+			}),
+			want: "the call graph has no call of golang.org/vmod/vuln.V by golang.org/main.worker at 8:2",
+		},
+		{
+			name: "missing frame",
+			trace: trace(func(fs []*govulncheck.Frame) []*govulncheck.Frame {
+				return []*govulncheck.Frame{fs[0], fs[2]}
+			}),
+			want: "the call graph has no call of golang.org/vmod/vuln.V by golang.org/main.main at 3:2",
+		},
+		{
+			name: "not an entry point",
+			trace: trace(func(fs []*govulncheck.Frame) []*govulncheck.Frame {
+				return fs[:2]
+			}),
+			want: "the trace starts at golang.org/main.worker, which is not an entry point",
+		},
+		{
+			name: "wrong sink",
+			trace: trace(func(fs []*govulncheck.Frame) []*govulncheck.Frame {
+				fs[0].Function = "W"
+				return fs
+			}),
+			want: "the trace ends at golang.org/vmod/vuln.W instead of golang.org/vmod/vuln.V",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := verifyTrace(vv, entries, test.trace); got != test.want {
+				t.Errorf("got %q; want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestVerifySummary(t *testing.T) {
+	for _, test := range []struct {
+		n, failed int
+		want      string
+	}{
+		{1, 0, "Verified the traces of 1 called finding against the call graph."},
+		{3, 1, "Verified the traces of 3 called findings against the call graph: 1 could not be corroborated."},
+	} {
+		if got := verifySummary(test.n, test.failed); got != test.want {
+			t.Errorf("verifySummary(%d, %d) = %q; want %q", test.n, test.failed, got, test.want)
+		}
+	}
+}
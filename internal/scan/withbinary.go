@@ -0,0 +1,188 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// compiledSymbols are the vulnerable symbols compiled into the binary of
+// the -binary flag, which is built from the scanned source. A source scan
+// only reports the vulnerable symbols it calls as called if they are also
+// in the binary, so that code the linker left out, which never runs, is
+// not reported, while call stacks are still found in the source.
+type compiledSymbols struct {
+	// file is the binary, as named by the -binary flag.
+	file string
+
+	// symbols is the set of the vulnerable symbols in the binary, as in
+	// "pkg.Func" or "pkg.T.Method", and vulns their vulnerabilities.
+	symbols map[string]bool
+	vulns   []*vulncheck.Vuln
+
+	// called is the set of the vulnerable symbols that are called in the
+	// source and compiled into the binary, and dropped the vulnerabilities
+	// and symbols that are called in the source but not compiled into the
+	// binary, as in "GO-2021-0113 in pkg.Func".
+	called  map[string]bool
+	dropped []string
+}
+
+// newCompiledSymbols returns the vulnerable symbols of bvr, the result of
+// the binary scan of file.
+func newCompiledSymbols(file string, bvr *vulncheck.Result) *compiledSymbols {
+	cs := &compiledSymbols{file: file, symbols: map[string]bool{}, vulns: bvr.Vulns, called: map[string]bool{}}
+	for _, vv := range bvr.Vulns {
+		cs.symbols[vulnSymbol(vv)] = true
+	}
+	return cs
+}
+
+// vulnSymbol returns the vulnerable symbol of vv, as in "pkg.T.Method".
+func vulnSymbol(vv *vulncheck.Vuln) string {
+	return vv.ImportSink.PkgPath + "." + vv.Symbol
+}
+
+// filter returns stacks, the call stacks of vv in the source, if its
+// symbol is compiled into the binary, or none otherwise, in which case vv
+// is reported as imported.
+func (cs *compiledSymbols) filter(vv *vulncheck.Vuln, stacks []vulncheck.CallStack) []vulncheck.CallStack {
+	if len(stacks) == 0 {
+		return stacks
+	}
+	sym := vulnSymbol(vv)
+	if !cs.symbols[sym] {
+		cs.dropped = append(cs.dropped, fmt.Sprintf("%s in %s", vv.OSV.ID, sym))
+		return nil
+	}
+	cs.called[sym] = true
+	return stacks
+}
+
+// messages returns the progress messages that describe how the source and
+// binary scans differ, once all the call stacks have been filtered: the
+// symbols called in the source that are not compiled into the binary, and
+// those compiled into the binary that are not called in the source, such
+// as methods that the linker keeps or functions called through reflection.
+func (cs *compiledSymbols) messages() []string {
+	var msgs []string
+	if len(cs.dropped) > 0 {
+		sort.Strings(cs.dropped)
+		msgs = append(msgs, fmt.Sprintf("The following vulnerable symbols are called in the source but are not compiled into binary %s, so they are reported as imported:\n  %s",
+			cs.file, strings.Join(cs.dropped, "\n  ")))
+	}
+	seen := map[string]bool{}
+	var uncalled []string
+	for _, vv := range cs.vulns {
+		s := fmt.Sprintf("%s in %s", vv.OSV.ID, vulnSymbol(vv))
+		if !cs.called[vulnSymbol(vv)] && !seen[s] {
+			seen[s] = true
+			uncalled = append(uncalled, s)
+		}
+	}
+	if len(uncalled) > 0 {
+		sort.Strings(uncalled)
+		msgs = append(msgs, fmt.Sprintf("Warning: the following vulnerable symbols are compiled into binary %s but are not called in the source. The linker keeps some functions that are never called, such as methods, but they may also be called through reflection:\n  %s",
+			cs.file, strings.Join(uncalled, "\n  ")))
+	}
+	return msgs
+}
+
+// scanBinarySymbols scans the binary named by the -binary flag of cfg for
+// the vulnerable symbols compiled into it, and warns if it was not built
+// from pkgs, the packages loaded from the source. It returns nil if the
+// flag is not set.
+func scanBinarySymbols(ctx context.Context, handler govulncheck.Handler, cfg *config, client *client.Client, pkgs []*packages.Package) (*compiledSymbols, error) {
+	if cfg.binaryFile == "" {
+		return nil, nil
+	}
+	exe, err := os.Open(absPath(cfg.binaryFile, filepath.FromSlash(cfg.dir)))
+	if err != nil {
+		return nil, fmt.Errorf("govulncheck: %v", err)
+	}
+	defer exe.Close()
+	bvr, err := binary(ctx, exe, &cfg.Config, client)
+	if err != nil {
+		return nil, fmt.Errorf("govulncheck: %v", err)
+	}
+	if err := cfg.download.finish(); err != nil {
+		return nil, err
+	}
+	if bvr.Stripped {
+		return nil, fmt.Errorf("govulncheck: binary %s has no symbol table, so the symbols compiled into it are unknown", cfg.binaryFile)
+	}
+	goos, goarch := goPlatform(cfg)
+	if diffs := buildDifferences(bvr.BuildInfo, pkgs, goos, goarch); len(diffs) > 0 {
+		msg := fmt.Sprintf("Warning: binary %s may not be built from the scanned code, so the symbols compiled into it may differ:\n  %s", cfg.binaryFile, strings.Join(diffs, "\n  "))
+		if err := handler.Progress(&govulncheck.Progress{Message: msg}); err != nil {
+			return nil, err
+		}
+	}
+	return newCompiledSymbols(cfg.binaryFile, bvr), nil
+}
+
+// buildDifferences describes how the build of the binary with build
+// information bi differs from pkgs, loaded for goos and goarch: a
+// different main module or platform, and the modules of pkgs at other
+// versions in the binary. Modules of pkgs that are not in the binary are
+// not reported, as the linker leaves out the packages that are not used.
+func buildDifferences(bi *debug.BuildInfo, pkgs []*packages.Package, goos, goarch string) []string {
+	if bi == nil {
+		return nil
+	}
+	var diffs []string
+	mains := map[string]bool{}
+	versions := map[string]string{}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		m := pkg.Module
+		switch {
+		case m == nil:
+		case m.Main:
+			mains[m.Path] = true
+		case m.Replace != nil:
+			versions[m.Path] = m.Replace.Version
+		default:
+			versions[m.Path] = m.Version
+		}
+	})
+	if bi.Main.Path != "" && !mains[bi.Main.Path] {
+		diffs = append(diffs, fmt.Sprintf("the main module of the binary is %s", bi.Main.Path))
+	}
+	binGOOS, binGOARCH := buildSetting(bi, "GOOS"), buildSetting(bi, "GOARCH")
+	if binGOOS != "" && goos != "" && (binGOOS != goos || binGOARCH != goarch) {
+		diffs = append(diffs, fmt.Sprintf("the binary is built for %s/%s, but the code is loaded for %s/%s", binGOOS, binGOARCH, goos, goarch))
+	}
+	for _, dep := range bi.Deps {
+		v := dep.Version
+		if dep.Replace != nil {
+			v = dep.Replace.Version
+		}
+		if src, ok := versions[dep.Path]; ok && src != v {
+			diffs = append(diffs, fmt.Sprintf("the binary is built with %s@%s, but the code with %s@%s", dep.Path, v, dep.Path, src))
+		}
+	}
+	return diffs
+}
+
+// buildSetting returns the value of the build setting key of bi, or "".
+func buildSetting(bi *debug.BuildInfo, key string) string {
+	for _, s := range bi.Settings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+func TestCompiledSymbols(t *testing.T) {
+	vuln := &packages.Package{PkgPath: "golang.org/vmod/vuln"}
+	get := &vulncheck.Vuln{OSV: &osv.Entry{ID: "GO-0000-0001"}, Symbol: "Client.Get", ImportSink: vuln}
+	parse := &vulncheck.Vuln{OSV: &osv.Entry{ID: "GO-0000-0001"}, Symbol: "Parse", ImportSink: vuln}
+	decode := &vulncheck.Vuln{OSV: &osv.Entry{ID: "GO-0000-0002"}, Symbol: "Decode", ImportSink: vuln}
+	cs := newCompiledSymbols("app", &vulncheck.Result{Vulns: []*vulncheck.Vuln{
+		{OSV: get.OSV, Symbol: get.Symbol, ImportSink: vuln},
+		{OSV: decode.OSV, Symbol: decode.Symbol, ImportSink: vuln},
+	}})
+
+	stacks := []vulncheck.CallStack{{{Function: &vulncheck.FuncNode{Name: "main"}}}}
+	if got := cs.filter(get, stacks); len(got) != 1 {
+		t.Errorf("Client.Get is compiled into the binary, but got %d call stacks; want 1", len(got))
+	}
+	if got := cs.filter(parse, stacks); len(got) != 0 {
+		t.Errorf("Parse is not compiled into the binary, but got %d call stacks; want 0", len(got))
+	}
+	// Symbols that are not called are not reported as dropped.
+	if got := cs.filter(decode, nil); len(got) != 0 {
+		t.Errorf("Decode is not called, but got %d call stacks; want 0", len(got))
+	}
+
+	want := []string{
+		"The following vulnerable symbols are called in the source but are not compiled into binary app, so they are reported as imported:\n" +
+			"  GO-0000-0001 in golang.org/vmod/vuln.Parse",
+		"Warning: the following vulnerable symbols are compiled into binary app but are not called in the source. " +
+			"The linker keeps some functions that are never called, such as methods, but they may also be called through reflection:\n" +
+			"  GO-0000-0002 in golang.org/vmod/vuln.Decode",
+	}
+	if diff := cmp.Diff(want, cs.messages()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBuildDifferences(t *testing.T) {
+	mainMod := &packages.Module{Path: "golang.org/main", Main: true}
+	dep := &packages.Package{PkgPath: "golang.org/dep", Module: &packages.Module{Path: "golang.org/dep", Version: "v1.2.0"}}
+	fork := &packages.Package{PkgPath: "golang.org/fork", Module: &packages.Module{
+		Path: "golang.org/fork", Version: "v1.0.0", Replace: &packages.Module{Path: "golang.org/myfork", Version: "v1.0.1"},
+	}}
+	pkgs := []*packages.Package{{PkgPath: "golang.org/main", Module: mainMod,
+		Imports: map[string]*packages.Package{"golang.org/dep": dep, "golang.org/fork": fork}}}
+
+	bi := &debug.BuildInfo{
+		Main: debug.Module{Path: "golang.org/main"},
+		Deps: []*debug.Module{
+			{Path: "golang.org/dep", Version: "v1.2.0"},
+			{Path: "golang.org/fork", Version: "v1.0.0", Replace: &debug.Module{Path: "golang.org/myfork", Version: "v1.0.1"}},
+			// Modules not imported by the loaded packages are ignored.
+			{Path: "golang.org/other", Version: "v0.1.0"},
+		},
+		Settings: []debug.BuildSetting{{Key: "GOOS", Value: "linux"}, {Key: "GOARCH", Value: "amd64"}},
+	}
+	if got := buildDifferences(bi, pkgs, "linux", "amd64"); len(got) != 0 {
+		t.Errorf("got differences %q; want none", got)
+	}
+
+	bi.Main.Path = "golang.org/other/cmd"
+	bi.Deps[0].Version = "v1.1.0"
+	want := []string{
+		"the main module of the binary is golang.org/other/cmd",
+		"the binary is built for linux/amd64, but the code is loaded for darwin/arm64",
+		"the binary is built with golang.org/dep@v1.1.0, but the code with golang.org/dep@v1.2.0",
+	}
+	if diff := cmp.Diff(want, buildDifferences(bi, pkgs, "darwin", "arm64")); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
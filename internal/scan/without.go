@@ -0,0 +1,56 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// applyWithoutCalls writes a progress message to handler that tells which
+// vulnerabilities of vr would no longer be called if the functions of the
+// packages matching the -without-calls flag of cfg made no calls, as if
+// the code stopped using those packages. It does nothing if the flag is
+// not set.
+//
+// The call stacks are searched again in the call graph of vr without the
+// outgoing calls of the matching packages, so the findings of the scan
+// are not changed.
+func applyWithoutCalls(handler govulncheck.Handler, cfg *config, vr *vulncheck.Result) error {
+	if cfg.withoutCalls == "" {
+		return nil
+	}
+	before := calledOSVs(vulncheck.CallStacks(vr))
+	after := calledOSVs(vulncheck.CallStacksWithFilter(vr, func(caller, callee *vulncheck.FuncNode) bool {
+		return caller.Package == nil || !module.MatchPrefixPatterns(cfg.withoutCalls, caller.Package.PkgPath)
+	}))
+	var uncalled []string
+	for _, id := range sortedOSVs(before) {
+		if !after[id] {
+			uncalled = append(uncalled, id)
+		}
+	}
+	msg := fmt.Sprintf("Without the calls of the packages matching %s, the called vulnerabilities would not change.", cfg.withoutCalls)
+	if len(uncalled) > 0 {
+		msg = fmt.Sprintf("Without the calls of the packages matching %s, %s would no longer be called.", cfg.withoutCalls, strings.Join(uncalled, ", "))
+	}
+	return handler.Progress(&govulncheck.Progress{Message: msg})
+}
+
+// calledOSVs returns the set of OSV IDs of the vulnerabilities that have
+// some call stacks in stacks.
+func calledOSVs(stacks map[*vulncheck.Vuln][]vulncheck.CallStack) map[string]bool {
+	called := map[string]bool{}
+	for vv, cs := range stacks {
+		if len(cs) > 0 {
+			called[vv.OSV.ID] = true
+		}
+	}
+	return called
+}
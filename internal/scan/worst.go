@@ -0,0 +1,153 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// worstFindings returns the most severe finding of each module of
+// findings, in the order the modules first appear in findings.
+//
+// Findings are ranked by the severity of their OSV entry in osvs, then
+// called findings are ranked over imported ones, and then findings with
+// shorter traces over longer ones. Among equally ranked findings, the
+// first one is returned.
+func worstFindings(findings []*govulncheck.Finding, osvs map[string]*osv.Entry) []*govulncheck.Finding {
+	var modules []string
+	worst := map[string]*govulncheck.Finding{}
+	scores := map[string]float64{}
+	for _, f := range findings {
+		if _, ok := scores[f.OSV]; !ok {
+			scores[f.OSV] = severityScore(osvs[f.OSV])
+		}
+		mod := f.Trace[0].Module
+		w, ok := worst[mod]
+		if !ok {
+			modules = append(modules, mod)
+		}
+		if !ok || worse(f, w, scores) {
+			worst[mod] = f
+		}
+	}
+	var reduced []*govulncheck.Finding
+	for _, mod := range modules {
+		reduced = append(reduced, worst[mod])
+	}
+	return reduced
+}
+
+// worse reports whether finding f is ranked over finding g.
+func worse(f, g *govulncheck.Finding, scores map[string]float64) bool {
+	if scores[f.OSV] != scores[g.OSV] {
+		return scores[f.OSV] > scores[g.OSV]
+	}
+	if fc, gc := isCalledFinding(f), isCalledFinding(g); fc != gc {
+		return fc
+	}
+	return len(f.Trace) < len(g.Trace)
+}
+
+// severityScore returns the highest CVSS v3 base score of the
+// severities of entry, or 0 if none can be computed.
+func severityScore(entry *osv.Entry) float64 {
+	_, score := cvss3Severity(entry)
+	return score
+}
+
+// cvss3Severity returns the CVSS v3 vector of the severities of entry
+// with the highest base score, and that score, or "" and 0 if none can
+// be computed.
+func cvss3Severity(entry *osv.Entry) (vector string, score float64) {
+	if entry == nil {
+		return "", 0
+	}
+	for _, s := range entry.Severity {
+		if s.Type != osv.SeverityTypeCVSSV3 {
+			continue
+		}
+		if base, err := cvss3BaseScore(s.Score); err == nil && (vector == "" || base > score) {
+			vector, score = s.Score, base
+		}
+	}
+	return vector, score
+}
+
+// cvss3Weights are the weights of the values of the base metrics of
+// CVSS v3. The weights of the privileges required when the scope is
+// changed are under the metric "PR:C".
+var cvss3Weights = map[string]map[string]float64{
+	"AV":   {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC":   {"L": 0.77, "H": 0.44},
+	"PR":   {"N": 0.85, "L": 0.62, "H": 0.27},
+	"PR:C": {"N": 0.85, "L": 0.68, "H": 0.5},
+	"UI":   {"N": 0.85, "R": 0.62},
+	"C":    {"H": 0.56, "L": 0.22, "N": 0},
+	"I":    {"H": 0.56, "L": 0.22, "N": 0},
+	"A":    {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3BaseScore returns the base score of the CVSS v3 vector string
+// vector, such as "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H". See
+// https://www.first.org/cvss/v3.1/specification-document#7-1-Base-Metrics-Equations.
+func cvss3BaseScore(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, fmt.Errorf("%q is not a CVSS v3 vector", vector)
+	}
+	metrics := map[string]string{}
+	for _, p := range parts[1:] {
+		name, value, ok := strings.Cut(p, ":")
+		if !ok {
+			return 0, fmt.Errorf("malformed metric %q in CVSS vector %q", p, vector)
+		}
+		metrics[name] = value
+	}
+	changed := metrics["S"] == "C"
+	if !changed && metrics["S"] != "U" {
+		return 0, fmt.Errorf("missing scope in CVSS vector %q", vector)
+	}
+	w := map[string]float64{}
+	for _, name := range []string{"AV", "AC", "PR", "UI", "C", "I", "A"} {
+		weights := cvss3Weights[name]
+		if name == "PR" && changed {
+			weights = cvss3Weights["PR:C"]
+		}
+		v, ok := weights[metrics[name]]
+		if !ok {
+			return 0, fmt.Errorf("missing or invalid metric %s in CVSS vector %q", name, vector)
+		}
+		w[name] = v
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// roundUp returns the smallest number with one decimal place that is
+// equal to or higher than x, as defined by CVSS v3.1.
+func roundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestCVSS3BaseScore(t *testing.T) {
+	for _, test := range []struct {
+		vector string
+		want   float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1},
+		{"CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0},
+	} {
+		got, err := cvss3BaseScore(test.vector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("cvss3BaseScore(%q) = %v, want %v", test.vector, got, test.want)
+		}
+	}
+	for _, vector := range []string{
+		"",
+		"CVSS:2.0/AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/C:H/I:H/A:H",
+		"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.1/AV",
+	} {
+		if _, err := cvss3BaseScore(vector); err == nil {
+			t.Errorf("cvss3BaseScore(%q): got no error", vector)
+		}
+	}
+}
+
+func TestWorstFindings(t *testing.T) {
+	osvs := map[string]*osv.Entry{
+		"LOW":  {ID: "LOW", Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"}}},
+		"HIGH": {ID: "HIGH", Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+		"NONE": {ID: "NONE"},
+	}
+	finding := func(id, mod string, called bool, depth int) *govulncheck.Finding {
+		f := &govulncheck.Finding{OSV: id, Trace: []*govulncheck.Frame{{Module: mod}}}
+		if called {
+			f.Trace[0].Function = "F"
+			for i := 1; i < depth; i++ {
+				f.Trace = append(f.Trace, &govulncheck.Frame{Module: "main", Function: "G"})
+			}
+		}
+		return f
+	}
+	var (
+		aLow        = finding("LOW", "a", true, 2)
+		aHigh       = finding("HIGH", "a", false, 1)
+		bImported   = finding("NONE", "b", false, 1)
+		bCalledLong = finding("NONE", "b", true, 3)
+		bCalled     = finding("NONE", "b", true, 2)
+		cOnly       = finding("LOW", "c", true, 2)
+	)
+	findings := []*govulncheck.Finding{bImported, aLow, bCalledLong, cOnly, aHigh, bCalled}
+	got := worstFindings(findings, osvs)
+	want := []*govulncheck.Finding{bCalled, aHigh, cOnly}
+	if len(got) != len(want) {
+		t.Fatalf("got %d findings, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("#%d: got %s in %s with %d frames, want %s in %s with %d frames", i,
+				got[i].OSV, got[i].Trace[0].Module, len(got[i].Trace),
+				want[i].OSV, want[i].Trace[0].Module, len(want[i].Trace))
+		}
+	}
+}
+
+func TestModuleSummariesWorstSeverity(t *testing.T) {
+	const (
+		low  = "CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"
+		high = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+	)
+	osvs := map[string]*osv.Entry{
+		"LOW":  {ID: "LOW", Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: low}}},
+		"HIGH": {ID: "HIGH", Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: high}}},
+		"NONE": {ID: "NONE"},
+	}
+	finding := func(id, mod, fixed string) *govulncheck.Finding {
+		return &govulncheck.Finding{OSV: id, FixedVersion: fixed, Trace: []*govulncheck.Frame{{Module: mod, Version: "v1.0.0"}}}
+	}
+	findings := []*govulncheck.Finding{
+		finding("LOW", "a", "v1.1.0"),
+		finding("HIGH", "a", ""),
+		finding("NONE", "b", "v1.2.0"),
+		finding("LOW", "c", "v1.3.0"),
+		finding("NONE", "c", ""),
+	}
+	want := map[string]string{"a": high, "b": "", "c": low}
+	mods := moduleSummaries(findings, osvs)
+	if len(mods) != len(want) {
+		t.Fatalf("got %d modules, want %d", len(mods), len(want))
+	}
+	for _, m := range mods {
+		if m.WorstSeverity != want[m.Path] {
+			t.Errorf("%s: got worst severity %q, want %q", m.Path, m.WorstSeverity, want[m.Path])
+		}
+	}
+}
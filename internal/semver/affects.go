@@ -43,11 +43,38 @@ func Affects(a []osv.Range, v string) bool {
 //   - no-fix is not an event, as opposed to being an
 //     event where Introduced="" and Fixed=""
 func containsSemver(ar osv.Range, v string) bool {
+	_, _, affected := matchSemver(ar, v)
+	return affected
+}
+
+// AffectedRange returns the introduced and fixed versions delimiting
+// the semver range in ranges that contains version v. Introduced is
+// "0" if the range begins at the beginning of time, and fixed is ""
+// if the range has no fix. The returned versions have no "v" prefix.
+//
+// The last return value is false if v is not contained in any semver
+// range. Ranges of other types, such as GIT, are never matched since
+// module versions cannot be compared against them.
+func AffectedRange(ranges []osv.Range, v string) (introduced, fixed string, found bool) {
+	for _, r := range ranges {
+		if introduced, fixed, affected := matchSemver(r, v); affected {
+			return introduced, fixed, true
+		}
+	}
+	return "", "", false
+}
+
+// matchSemver checks if semver version v is in the range encoded
+// by ar and, if so, returns the introduced and fixed events
+// delimiting the affected interval containing v.
+//
+// The assumptions of containsSemver apply.
+func matchSemver(ar osv.Range, v string) (introduced, fixed string, affected bool) {
 	if ar.Type != osv.RangeTypeSemver {
-		return false
+		return "", "", false
 	}
 	if len(ar.Events) == 0 {
-		return true
+		return "0", "", true
 	}
 
 	// Strip and then add the semver prefix so we can support bare versions,
@@ -80,14 +107,21 @@ func containsSemver(ar osv.Range, v string) bool {
 		return Less(v1, v2)
 	})
 
-	var affected bool
 	for _, e := range ar.Events {
 		if !affected && e.Introduced != "" {
 			affected = e.Introduced == "0" || !Less(v, e.Introduced)
+			if affected {
+				introduced = e.Introduced
+			}
 		} else if affected && e.Fixed != "" {
-			affected = Less(v, e.Fixed)
+			if Less(v, e.Fixed) {
+				return introduced, e.Fixed, true
+			}
+			affected = false
 		}
 	}
-
-	return affected
+	if !affected {
+		return "", "", false
+	}
+	return introduced, "", true
 }
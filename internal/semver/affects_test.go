@@ -135,3 +135,71 @@ func TestAffectsSemver(t *testing.T) {
 		}
 	}
 }
+
+func TestAffectedRange(t *testing.T) {
+	gitRange := osv.Range{Type: osv.RangeType("GIT"), Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "a1b2c3d"}}}
+	cases := []struct {
+		name           string
+		ranges         []osv.Range
+		version        string
+		wantIntroduced string
+		wantFixed      string
+		wantFound      bool
+	}{
+		{
+			name:           "semver beginning of time",
+			ranges:         []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "2.0.0"}}}},
+			version:        "v1.0.0",
+			wantIntroduced: "0",
+			wantFixed:      "2.0.0",
+			wantFound:      true,
+		},
+		{
+			name: "semver second interval",
+			ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{
+				{Introduced: "0"}, {Fixed: "1.2.0"}, {Introduced: "1.5.0"}, {Fixed: "1.5.3"},
+			}}},
+			version:        "v1.5.1",
+			wantIntroduced: "1.5.0",
+			wantFixed:      "1.5.3",
+			wantFound:      true,
+		},
+		{
+			name:           "semver no fix",
+			ranges:         []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "1.0.0"}}}},
+			version:        "v1.1.0",
+			wantIntroduced: "1.0.0",
+			wantFound:      true,
+		},
+		{
+			name:    "semver not in range",
+			ranges:  []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.2.0"}}}},
+			version: "v1.2.0",
+		},
+		{
+			name:    "git only",
+			ranges:  []osv.Range{gitRange},
+			version: "v1.0.0",
+		},
+		{
+			name: "git and semver",
+			ranges: []osv.Range{
+				gitRange,
+				{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "0.9.0"}, {Fixed: "1.0.1"}}},
+			},
+			version:        "v1.0.0",
+			wantIntroduced: "0.9.0",
+			wantFixed:      "1.0.1",
+			wantFound:      true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			introduced, fixed, found := AffectedRange(c.ranges, c.version)
+			if introduced != c.wantIntroduced || fixed != c.wantFixed || found != c.wantFound {
+				t.Errorf("AffectedRange(%s) = (%q, %q, %t); want (%q, %q, %t)", c.version,
+					introduced, fixed, found, c.wantIntroduced, c.wantFixed, c.wantFound)
+			}
+		})
+	}
+}
@@ -16,10 +16,15 @@ import (
 //
 // For use in tests.
 type MockHandler struct {
-	ConfigMessages   []*govulncheck.Config
-	ProgressMessages []*govulncheck.Progress
-	OSVMessages      []*osv.Entry
-	FindingMessages  []*govulncheck.Finding
+	ConfigMessages     []*govulncheck.Config
+	ProgressMessages   []*govulncheck.Progress
+	OSVMessages        []*osv.Entry
+	FindingMessages    []*govulncheck.Finding
+	ModuleMessages     []*govulncheck.Module
+	BuildMessages      []*govulncheck.Build
+	ScopeMessages      []*govulncheck.Scope
+	IncompleteMessages []*govulncheck.Incomplete
+	TimingMessages     []*govulncheck.Timing
 }
 
 func NewMockHandler() *MockHandler {
@@ -46,6 +51,31 @@ func (h *MockHandler) Finding(finding *govulncheck.Finding) error {
 	return nil
 }
 
+func (h *MockHandler) Module(module *govulncheck.Module) error {
+	h.ModuleMessages = append(h.ModuleMessages, module)
+	return nil
+}
+
+func (h *MockHandler) Build(build *govulncheck.Build) error {
+	h.BuildMessages = append(h.BuildMessages, build)
+	return nil
+}
+
+func (h *MockHandler) Scope(scope *govulncheck.Scope) error {
+	h.ScopeMessages = append(h.ScopeMessages, scope)
+	return nil
+}
+
+func (h *MockHandler) Incomplete(incomplete *govulncheck.Incomplete) error {
+	h.IncompleteMessages = append(h.IncompleteMessages, incomplete)
+	return nil
+}
+
+func (h *MockHandler) Timing(timing *govulncheck.Timing) error {
+	h.TimingMessages = append(h.TimingMessages, timing)
+	return nil
+}
+
 func (h *MockHandler) Sort() {
 	sort.Slice(h.FindingMessages, func(i, j int) bool {
 		if h.FindingMessages[i].OSV > h.FindingMessages[j].OSV {
@@ -85,6 +115,13 @@ func (h *MockHandler) Write(to govulncheck.Handler) error {
 			return err
 		}
 	}
+	if bh, ok := to.(govulncheck.BuildHandler); ok {
+		for _, build := range h.BuildMessages {
+			if err := bh.Build(build); err != nil {
+				return err
+			}
+		}
+	}
 	seen := map[string]bool{}
 	for _, finding := range h.FindingMessages {
 		if !seen[finding.OSV] {
@@ -109,5 +146,33 @@ func (h *MockHandler) Write(to govulncheck.Handler) error {
 			}
 		}
 	}
+	if mh, ok := to.(govulncheck.ModuleHandler); ok {
+		for _, module := range h.ModuleMessages {
+			if err := mh.Module(module); err != nil {
+				return err
+			}
+		}
+	}
+	if sh, ok := to.(govulncheck.ScopeHandler); ok {
+		for _, scope := range h.ScopeMessages {
+			if err := sh.Scope(scope); err != nil {
+				return err
+			}
+		}
+	}
+	if ih, ok := to.(govulncheck.IncompleteHandler); ok {
+		for _, incomplete := range h.IncompleteMessages {
+			if err := ih.Incomplete(incomplete); err != nil {
+				return err
+			}
+		}
+	}
+	if th, ok := to.(govulncheck.TimingHandler); ok {
+		for _, timing := range h.TimingMessages {
+			if err := th.Timing(timing); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
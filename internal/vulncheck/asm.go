@@ -0,0 +1,162 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+)
+
+// asmInstrRegexp matches assembly instructions that define or branch
+// to a symbol of the package being assembled, such as
+//
+//	TEXT ·encrypt(SB),NOSPLIT,$0
+//	CALL ·expandKey<ABIInternal>(SB)
+//
+// The first group is the instruction and the second is the symbol name.
+var asmInstrRegexp = regexp.MustCompile(`^\s*(TEXT|CALL|JMP|BL|B)\s+·([A-Za-z_][A-Za-z0-9_]*)(?:<[A-Za-z0-9]*>)?\(SB\)`)
+
+// asmCalls returns the calls made from assembly in pkgs and their
+// dependencies, keyed by package path. For each package, it maps the
+// name of each function implemented in assembly to the names of the
+// functions of the package it calls or jumps to.
+//
+// Only symbols local to the package are considered. Calls through
+// assembly functions that have no Go declaration are followed, so the
+// callees include the functions they transitively lead to.
+func asmCalls(pkgs []*packages.Package) map[string]map[string][]string {
+	calls := make(map[string]map[string][]string)
+	seen := make(map[*packages.Package]bool)
+	var visit func(*packages.Package)
+	visit = func(pkg *packages.Package) {
+		if seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		for _, imp := range pkg.Imports {
+			visit(imp)
+		}
+		if c := pkgAsmCalls(pkg); len(c) > 0 {
+			calls[pkg.PkgPath] = c
+		}
+	}
+	for _, pkg := range pkgs {
+		visit(pkg)
+	}
+	return calls
+}
+
+// pkgAsmCalls returns the calls made from the assembly files of pkg,
+// as described by asmCalls. Files that cannot be read are skipped.
+func pkgAsmCalls(pkg *packages.Package) map[string][]string {
+	calls := make(map[string][]string)
+	for _, file := range pkg.OtherFiles {
+		if !strings.HasSuffix(file, ".s") {
+			continue
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		var text string // function being defined
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			m := asmInstrRegexp.FindStringSubmatch(s.Text())
+			if m == nil {
+				continue
+			}
+			if m[1] == "TEXT" {
+				text = m[2]
+				if _, ok := calls[text]; !ok {
+					calls[text] = nil
+				}
+			} else if text != "" {
+				calls[text] = append(calls[text], m[2])
+			}
+		}
+		f.Close()
+	}
+	return calls
+}
+
+// asmCallees returns the functions of pkg called from the assembly
+// implementation of the function named name, according to calls.
+// Assembly functions without a Go declaration are not returned, but
+// the functions they call are.
+func asmCallees(pkg *ssa.Package, name string, calls map[string][]string) []*ssa.Function {
+	var callees []*ssa.Function
+	seen := map[string]bool{name: true}
+	var visit func(string)
+	visit = func(name string) {
+		for _, c := range calls[name] {
+			if seen[c] {
+				continue
+			}
+			seen[c] = true
+			if f := pkg.Func(c); f != nil {
+				callees = append(callees, f)
+			} else {
+				visit(c)
+			}
+		}
+	}
+	visit(name)
+	return callees
+}
+
+// asmEntries returns the functions called from assembly according to
+// calls. Such calls are not seen by the ssa based call graph
+// algorithms, so the functions are treated as entries to make sure they
+// are part of the call graph.
+func asmEntries(prog *ssa.Program, calls map[string]map[string][]string) []*ssa.Function {
+	var entries []*ssa.Function
+	for path, pkgCalls := range calls {
+		pkg := prog.ImportedPackage(path)
+		if pkg == nil {
+			continue
+		}
+		for name := range pkgCalls {
+			if pkg.Func(name) == nil {
+				continue
+			}
+			entries = append(entries, asmCallees(pkg, name, pkgCalls)...)
+		}
+	}
+	return entries
+}
+
+// asmCallEdges adds edges to cg for calls made from assembly according
+// to calls. Each function declared in Go and implemented in assembly
+// gets an edge to every Go function its implementation calls. The
+// added edges have no call site.
+func asmCallEdges(cg *callgraph.Graph, prog *ssa.Program, calls map[string]map[string][]string) {
+	for path, pkgCalls := range calls {
+		pkg := prog.ImportedPackage(path)
+		if pkg == nil {
+			continue
+		}
+		for name := range pkgCalls {
+			fn := pkg.Func(name)
+			if fn == nil {
+				continue
+			}
+			caller := cg.Nodes[fn]
+			if caller == nil {
+				continue
+			}
+			for _, f := range asmCallees(pkg, name, pkgCalls) {
+				if callee := cg.Nodes[f]; callee != nil {
+					callgraph.AddEdge(caller, nil, callee)
+				}
+			}
+		}
+	}
+}
@@ -9,7 +9,9 @@ package vulncheck
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"go/token"
 	"io"
 	"runtime/debug"
 
@@ -22,8 +24,12 @@ import (
 
 // Binary detects presence of vulnerable symbols in exe.
 // The Calls, Imports, and Requires fields on Result will be empty.
+//
+// If cfg.EntrySymbols are set, the vulnerabilities whose symbols are not
+// reached from them through the direct calls of exe are marked as
+// Unreached.
 func Binary(ctx context.Context, exe io.ReaderAt, cfg *govulncheck.Config, client *client.Client) (_ *Result, err error) {
-	mods, packageSymbols, bi, err := buildinfo.ExtractPackagesAndSymbols(exe)
+	mods, packageSymbols, positions, bi, err := buildinfo.ExtractPackagesSymbolsAndPositions(exe)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse provided binary: %v", err)
 	}
@@ -36,7 +42,7 @@ func Binary(ctx context.Context, exe io.ReaderAt, cfg *govulncheck.Config, clien
 	if err != nil {
 		return nil, err
 	}
-	modVulns := moduleVulnerabilities(mv)
+	modVulns := moduleVulnerabilities(mv).only(cfg.OSVs)
 
 	goos := findSetting("GOOS", bi)
 	goarch := findSetting("GOARCH", bi)
@@ -44,13 +50,19 @@ func Binary(ctx context.Context, exe io.ReaderAt, cfg *govulncheck.Config, clien
 		fmt.Printf("warning: failed to extract build system specification GOOS: %s GOARCH: %s\n", goos, goarch)
 	}
 
-	modVulns = modVulns.filter(goos, goarch)
-	result := &Result{}
+	all := modVulns.filter("", "", nil)
+	modVulns = modVulns.filter(goos, goarch, nil)
+	result := &Result{BuildInfo: bi, NotApplicable: notApplicable(all, modVulns)}
 
 	if packageSymbols == nil {
-		// The binary exe is stripped. We currently cannot detect inlined
-		// symbols for stripped binaries (see #57764), so we report
-		// vulnerabilities at the go.mod-level precision.
+		// The binary exe is stripped, or is a core dump without a
+		// symbol table. We currently cannot detect inlined symbols for
+		// stripped binaries (see #57764), so we report vulnerabilities
+		// at the go.mod-level precision.
+		if len(cfg.EntrySymbols) > 0 {
+			return nil, errors.New("cannot scope the scan to entry symbols: the binary has no symbol table")
+		}
+		result.Stripped = true
 		addRequiresOnlyVulns(result, graph, modVulns)
 	} else {
 		for pkg, symbols := range packageSymbols {
@@ -60,10 +72,31 @@ func Binary(ctx context.Context, exe io.ReaderAt, cfg *govulncheck.Config, clien
 				addSymbolVulns(result, graph, pkg, symbols, modVulns)
 			}
 		}
+		addPositions(result, positions)
+		if len(cfg.EntrySymbols) > 0 && cfg.ScanLevel.WantSymbols() {
+			if err := addReached(result, exe, cfg.EntrySymbols); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return result, nil
 }
 
+// addReached marks the vulnerabilities of result whose symbols are not
+// reached from the entry symbols of exe as Unreached, and records the
+// entry symbols that are not in exe.
+func addReached(result *Result, exe io.ReaderAt, entries []string) error {
+	reached, missing, err := buildinfo.ExtractReachedSymbols(exe, entries)
+	if err != nil {
+		return fmt.Errorf("cannot scope the scan to entry symbols: %v", err)
+	}
+	result.MissingEntrySymbols = missing
+	for _, v := range result.Vulns {
+		v.Unreached = !reached[v.ImportSink.PkgPath][v.Symbol]
+	}
+	return nil
+}
+
 // addImportsOnlyVulns adds Vuln entries to result in imports only mode, i.e., for each vulnerable symbol
 // of pkg.
 func addImportsOnlyVulns(result *Result, graph *PackageGraph, pkg string, symbols []string, modVulns moduleVulnerabilities) {
@@ -143,6 +176,19 @@ func addRequiresOnlyVulns(result *Result, graph *PackageGraph, modVulns moduleVu
 	}
 }
 
+// addPositions sets the binary positions of the vulnerabilities in
+// result from positions, keyed by package and symbol.
+func addPositions(result *Result, positions map[string]map[string]*buildinfo.SymbolPosition) {
+	for _, v := range result.Vulns {
+		pos := positions[v.ImportSink.PkgPath][v.Symbol]
+		if pos == nil || pos.Filename == "" {
+			continue
+		}
+		v.BinaryPos = &token.Position{Filename: pos.Filename, Line: pos.Line}
+		v.Inlined = pos.Inlined
+	}
+}
+
 func addVuln(result *Result, graph *PackageGraph, osv *osv.Entry, symbol string, pkgPath string) {
 	result.Vulns = append(result.Vulns, &Vuln{
 		OSV:        osv,
@@ -14,6 +14,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"sort"
 	"testing"
 
@@ -22,11 +24,22 @@ import (
 	"golang.org/x/vuln/internal/semver"
 	"golang.org/x/vuln/internal/testenv"
 	"golang.org/x/vuln/internal/vulncheck/internal/buildinfo"
+	"golang.org/x/vuln/internal/vulncheck/internal/gosym"
 )
 
+// skipUnsupportedGo skips t if the binaries built by the tests, which use
+// the Go version running them, cannot be analyzed.
+func skipUnsupportedGo(t *testing.T) {
+	t.Helper()
+	if gosym.FuncSymName(runtime.Version()) == "" {
+		t.Skipf("binaries built with %s are not supported", runtime.Version())
+	}
+}
+
 // TODO: we build binary programatically, so what if the underlying tool chain changes?
 func TestBinary(t *testing.T) {
 	testenv.NeedsGoBuild(t)
+	skipUnsupportedGo(t)
 
 	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
 		{
@@ -158,6 +171,105 @@ func TestBinary(t *testing.T) {
 	}
 
 	compareVulns(t, wantVulns, res)
+
+}
+
+// TestBinaryEntrySymbols tests scoping the symbols of a binary to the
+// symbols reached from its entry symbols.
+func TestBinaryEntrySymbols(t *testing.T) {
+	testenv.NeedsGoBuild(t)
+	skipUnsupportedGo(t)
+
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"main.go": `
+			package main
+
+			import (
+				"archive/zip"
+				"os"
+
+				"golang.org/amod/avuln"
+			)
+
+			func main() {
+				if len(os.Args) > 1 {
+					serve()
+				} else {
+					other()
+				}
+			}
+
+			//go:noinline
+			func serve() {
+				avuln.VulnData{}.Vuln1()
+			}
+
+			//go:noinline
+			func other() {
+				_, err := zip.OpenReader("file.zip")
+				print(err)
+			}
+			`,
+			}},
+		{
+			Name: "golang.org/amod@v1.1.3",
+			Files: map[string]interface{}{"avuln/avuln.go": `
+			package avuln
+
+			type VulnData struct {}
+
+			func (v VulnData) Vuln1() {
+				print("vuln1")
+			}
+			`},
+		},
+	})
+	defer e.Cleanup()
+
+	cmd := exec.Command("go", "build", "-o", "entry")
+	cmd.Dir = e.Config.Dir
+	cmd.Env = e.Config.Env
+	out, err := cmd.CombinedOutput()
+	if err != nil || len(out) > 0 {
+		t.Fatalf("failed to build the binary %v %v", err, string(out))
+	}
+
+	bin, err := os.Open(filepath.Join(e.Config.Dir, "entry"))
+	if err != nil {
+		t.Fatalf("failed to access the binary %v", err)
+	}
+	defer bin.Close()
+
+	c, err := newTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &govulncheck.Config{ScanLevel: "symbol", EntrySymbols: []string{"main.serve", "main.missing"}}
+	res, err := Binary(context.Background(), bin, cfg, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantVulns := []*testVuln{
+		{Symbol: "VulnData.Vuln1", PkgPath: "golang.org/amod/avuln", ModPath: "golang.org/amod"},
+	}
+	if getGoVersion(bin) != "" {
+		wantVulns = append(wantVulns, &testVuln{Symbol: "OpenReader", PkgPath: "archive/zip", ModPath: "stdlib"})
+	}
+	compareVulns(t, wantVulns, res)
+	for _, v := range res.Vulns {
+		// Only main.serve reaches avuln.VulnData.Vuln1.
+		if got, want := v.Unreached, v.Symbol != "VulnData.Vuln1"; got != want {
+			t.Errorf("%s: got Unreached %t, want %t", v.Symbol, got, want)
+		}
+	}
+	if got, want := res.MissingEntrySymbols, []string{"main.missing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got missing entry symbols %v, want %v", got, want)
+	}
 }
 
 func getGoVersion(exe io.ReaderAt) string {
@@ -173,6 +285,7 @@ func getGoVersion(exe io.ReaderAt) string {
 // expectations are set to fail once it gets addressed.
 func Test58509(t *testing.T) {
 	testenv.NeedsGoBuild(t)
+	skipUnsupportedGo(t)
 
 	vulnLib := `package bvuln
 
@@ -0,0 +1,235 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// cgoCalls returns the identifiers mentioned by the C functions of pkgs
+// and their dependencies, keyed by package path. For each package, it
+// maps the name of each C function defined in the preambles of its Go
+// files or in its C files to the identifiers of the body of the function,
+// which include the functions it calls.
+func cgoCalls(pkgs []*packages.Package) map[string]map[string][]string {
+	calls := make(map[string]map[string][]string)
+	seen := make(map[*packages.Package]bool)
+	var visit func(*packages.Package)
+	visit = func(pkg *packages.Package) {
+		if seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		for _, imp := range pkg.Imports {
+			visit(imp)
+		}
+		if c := pkgCgoCalls(pkg); len(c) > 0 {
+			calls[pkg.PkgPath] = c
+		}
+	}
+	for _, pkg := range pkgs {
+		visit(pkg)
+	}
+	return calls
+}
+
+// pkgCgoCalls returns the identifiers mentioned by the C functions of
+// pkg, as described by cgoCalls. Files that cannot be read or parsed are
+// skipped.
+func pkgCgoCalls(pkg *packages.Package) map[string][]string {
+	if !usesCgo(pkg) {
+		return nil
+	}
+	calls := make(map[string][]string)
+	fset := token.NewFileSet()
+	for _, file := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, d := range f.Decls {
+			d, ok := d.(*ast.GenDecl)
+			if !ok || d.Tok != token.IMPORT {
+				continue
+			}
+			for _, spec := range d.Specs {
+				spec := spec.(*ast.ImportSpec)
+				if path, err := strconv.Unquote(spec.Path.Value); err != nil || path != "C" {
+					continue
+				}
+				// The preamble is the comment of the import, or of
+				// its declaration if it is not parenthesized.
+				doc := spec.Doc
+				if doc == nil && !d.Lparen.IsValid() {
+					doc = d.Doc
+				}
+				if doc != nil {
+					addCFunctions(calls, doc.Text())
+				}
+			}
+		}
+	}
+	for _, file := range pkg.OtherFiles {
+		if !strings.HasSuffix(file, ".c") && !strings.HasSuffix(file, ".h") {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		addCFunctions(calls, string(src))
+	}
+	return calls
+}
+
+// usesCgo reports whether pkg was processed by cgo, which compiles the
+// Go files it generates for the package in addition to its own.
+func usesCgo(pkg *packages.Package) bool {
+	return len(pkg.CompiledGoFiles) > len(pkg.GoFiles)
+}
+
+// addCFunctions adds to calls the functions defined in the C source src,
+// each mapped to the identifiers of its body. The source is not parsed,
+// but tokenized: a function definition is a top-level identifier followed
+// by a parenthesized list and a brace-enclosed body. Comments, literals,
+// and preprocessor directives are skipped, so calls made in macros are
+// missed.
+func addCFunctions(calls map[string][]string, src string) {
+	var (
+		depth  int    // brace depth
+		parens int    // parenthesis depth at brace depth 0
+		last   string // last top-level identifier
+		name   string // candidate name of the function being declared
+		closed bool   // whether the last top-level token closed the parameters
+		body   string // function being defined, if depth > 0
+	)
+	lineStart := true
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			lineStart = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+			continue
+		case lineStart && c == '#':
+			// Skip the directive, along with its continuation lines.
+			for i < len(src) && src[i] != '\n' {
+				if src[i] == '\\' && i+1 < len(src) && src[i+1] == '\n' {
+					i++
+				}
+				i++
+			}
+			continue
+		}
+		lineStart = false
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			if j := strings.Index(src[i+2:], "*/"); j >= 0 {
+				i += j + 4
+			} else {
+				i = len(src)
+			}
+		case c == '"' || c == '\'':
+			for i++; i < len(src) && src[i] != c && src[i] != '\n'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+			i++
+		case isCIdentStart(c):
+			j := i + 1
+			for j < len(src) && (isCIdentStart(src[j]) || '0' <= src[j] && src[j] <= '9') {
+				j++
+			}
+			id := src[i:j]
+			i = j
+			if depth > 0 {
+				if body != "" {
+					calls[body] = append(calls[body], id)
+				}
+			} else if parens == 0 {
+				last, closed = id, false
+			}
+		default:
+			i++
+			if depth > 0 {
+				switch c {
+				case '{':
+					depth++
+				case '}':
+					if depth--; depth == 0 {
+						body = ""
+					}
+				}
+				continue
+			}
+			switch c {
+			case '(':
+				if parens == 0 {
+					name = last
+				}
+				parens++
+			case ')':
+				if parens > 0 {
+					if parens--; parens == 0 {
+						closed = true
+						continue
+					}
+				}
+			case '{':
+				depth = 1
+				if closed && name != "" {
+					body = name
+					if _, ok := calls[body]; !ok {
+						calls[body] = nil
+					}
+				}
+			case ';', '}':
+				name = ""
+			}
+			closed = false
+		}
+	}
+}
+
+// isCIdentStart reports whether c may start a C identifier.
+func isCIdentStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+}
+
+// cgoCallees returns the names of the identifiers that the C function
+// named name transitively mentions, according to calls, and whether
+// name is defined in calls.
+func cgoCallees(name string, calls map[string][]string) (map[string]bool, bool) {
+	if _, ok := calls[name]; !ok {
+		return nil, false
+	}
+	seen := map[string]bool{name: true}
+	var visit func(string)
+	visit = func(name string) {
+		for _, c := range calls[name] {
+			if !seen[c] {
+				seen[c] = true
+				visit(c)
+			}
+		}
+	}
+	visit(name)
+	return seen, true
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"go/constant"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// deleteDeadEdges deletes the edges of cg whose call sites are in blocks
+// that can never execute, such as the body of "if debug" where debug is
+// a constant false, so that vulnerable symbols that are only called from
+// such blocks are not reported as called.
+func deleteDeadEdges(cg *callgraph.Graph) {
+	live := make(map[*ssa.Function]map[*ssa.BasicBlock]bool)
+	isDead := func(e *callgraph.Edge) bool {
+		if e.Site == nil || e.Site.Block() == nil {
+			return false
+		}
+		b := e.Site.Block()
+		l, ok := live[b.Parent()]
+		if !ok {
+			l = liveBlocks(b.Parent())
+			live[b.Parent()] = l
+		}
+		return l != nil && !l[b]
+	}
+	for _, n := range cg.Nodes {
+		n.Out = liveEdges(n.Out, isDead)
+		n.In = liveEdges(n.In, isDead)
+	}
+}
+
+// liveEdges returns the edges that are not dead, reusing the storage of
+// edges.
+func liveEdges(edges []*callgraph.Edge, isDead func(*callgraph.Edge) bool) []*callgraph.Edge {
+	live := edges[:0]
+	for _, e := range edges {
+		if !isDead(e) {
+			live = append(live, e)
+		}
+	}
+	return live
+}
+
+// liveBlocks returns the blocks of f that may execute, or nil if all of
+// them may. Unlike a compiler, ssa keeps the branches of conditions that
+// are constant, which the type checker folds from constant expressions,
+// so the blocks that are only reached through the branches that are not
+// taken never execute.
+//
+// Conditions on the platform, such as runtime.GOOS == "windows", are
+// constant for the platform the packages were loaded for, which is also
+// the one vulnerabilities are matched against.
+func liveBlocks(f *ssa.Function) map[*ssa.BasicBlock]bool {
+	if !hasConstantBranch(f) {
+		return nil
+	}
+	live := make(map[*ssa.BasicBlock]bool)
+	var visit func(*ssa.BasicBlock)
+	visit = func(b *ssa.BasicBlock) {
+		if b == nil || live[b] {
+			return
+		}
+		live[b] = true
+		succs := b.Succs
+		if c, ok := constantBranch(b); ok {
+			// The successors of an If are its true and false branches.
+			if constant.BoolVal(c.Value) {
+				succs = succs[:1]
+			} else {
+				succs = succs[1:]
+			}
+		}
+		for _, s := range succs {
+			visit(s)
+		}
+	}
+	if len(f.Blocks) > 0 {
+		visit(f.Blocks[0])
+	}
+	visit(f.Recover)
+	return live
+}
+
+// hasConstantBranch reports whether some block of f ends with a branch
+// on a constant condition.
+func hasConstantBranch(f *ssa.Function) bool {
+	for _, b := range f.Blocks {
+		if _, ok := constantBranch(b); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// constantBranch returns the condition of the If that ends b, if it is a
+// constant boolean.
+func constantBranch(b *ssa.BasicBlock) (*ssa.Const, bool) {
+	if len(b.Instrs) == 0 {
+		return nil, false
+	}
+	i, ok := b.Instrs[len(b.Instrs)-1].(*ssa.If)
+	if !ok {
+		return nil, false
+	}
+	c, ok := i.Cond.(*ssa.Const)
+	if !ok || c.Value == nil || c.Value.Kind() != constant.Bool {
+		return nil, false
+	}
+	return c, true
+}
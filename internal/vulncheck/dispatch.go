@@ -0,0 +1,110 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// A callEdge identifies an edge of a call graph by its call site and its
+// callee.
+type callEdge struct {
+	site   ssa.CallInstruction
+	callee *ssa.Function
+}
+
+// storedFuncs returns the functions that the functions of fns store in
+// dispatch tables, the maps, slices, and arrays of function values, such
+// as the handler registries of web frameworks, sorted by name.
+func storedFuncs(fns map[*ssa.Function]bool) []*ssa.Function {
+	seen := make(map[*ssa.Function]bool)
+	var stored []*ssa.Function
+	add := func(v ssa.Value) {
+		if f, ok := v.(*ssa.Function); ok && f.Synthetic == "" && !seen[f] {
+			seen[f] = true
+			stored = append(stored, f)
+		}
+	}
+	for f := range fns {
+		for _, b := range f.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.MapUpdate:
+					add(instr.Value)
+				case *ssa.Store:
+					if _, ok := instr.Addr.(*ssa.IndexAddr); ok {
+						add(instr.Val)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].String() < stored[j].String() })
+	return stored
+}
+
+// dispatchTableEdges adds edges to cg for calls through dispatch tables,
+// which the call graph analysis misses when the tables are accessed in
+// ways it cannot follow, such as through reflection. Each dynamic call
+// of a function value in cg is assumed to call every function of the same
+// signature that a function of cg stores in a dispatch table. This
+// over-approximates the calls, so dispatchTableEdges returns the added
+// edges, which were not already in cg, for them to be labeled as assumed.
+// The calls made by the standard library are left out, as they would
+// mostly add calls from the runtime to every stored function.
+func dispatchTableEdges(cg *callgraph.Graph) map[callEdge]bool {
+	fns := make(map[*ssa.Function]bool)
+	for f := range cg.Nodes {
+		if f != nil {
+			fns[f] = true
+		}
+	}
+	stored := storedFuncs(fns)
+	added := make(map[callEdge]bool)
+	if len(stored) == 0 {
+		return added
+	}
+	for f, n := range cg.Nodes {
+		if f == nil || f.Pkg == nil || isStdPackage(f.Pkg.Pkg.Path()) {
+			continue
+		}
+		existing := make(map[callEdge]bool)
+		for _, e := range n.Out {
+			existing[callEdge{e.Site, e.Callee.Func}] = true
+		}
+		live := liveBlocks(f)
+		for _, b := range f.Blocks {
+			if live != nil && !live[b] {
+				continue
+			}
+			for _, instr := range b.Instrs {
+				call, ok := instr.(ssa.CallInstruction)
+				if !ok || !isFuncValueCall(call) {
+					continue
+				}
+				for _, s := range stored {
+					e := callEdge{call, s}
+					if existing[e] || added[e] || !types.Identical(call.Common().Signature(), s.Signature) {
+						continue
+					}
+					added[e] = true
+					callgraph.AddEdge(n, call, cg.CreateNode(s))
+				}
+			}
+		}
+	}
+	return added
+}
+
+// isFuncValueCall reports whether call is a dynamic call of a function
+// value, rather than a static call or a call of an interface method.
+func isFuncValueCall(call ssa.CallInstruction) bool {
+	c := call.Common()
+	return !c.IsInvoke() && c.StaticCallee() == nil
+}
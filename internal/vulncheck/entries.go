@@ -5,8 +5,11 @@
 package vulncheck
 
 import (
+	"fmt"
+	"go/types"
 	"strings"
 
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 )
 
@@ -39,6 +42,106 @@ func entryPoints(topPackages []*ssa.Package) []*ssa.Function {
 	return entries
 }
 
+// filterEntries returns the packages of topPackages accepted by filter,
+// or all of them if filter is nil.
+func filterEntries(topPackages []*ssa.Package, filter EntryFilter) []*ssa.Package {
+	if filter == nil {
+		return topPackages
+	}
+	var entries []*ssa.Package
+	for _, pkg := range topPackages {
+		if filter(pkg.Pkg.Path()) {
+			entries = append(entries, pkg)
+		}
+	}
+	return entries
+}
+
+// An EntryFunction identifies a function or method that is an entry point
+// of source analysis by the path of its package and its name in the format
+// of vulnerability databases, such as "F" for function F, or "T.M" for
+// method M of type T or *T.
+type EntryFunction struct {
+	PkgPath string
+	Symbol  string
+}
+
+func (e EntryFunction) String() string {
+	return e.PkgPath + "." + e.Symbol
+}
+
+// checkEntryFunctions returns an error if some of entries is not a
+// top-level function, or a method of a top-level type, of pkgs.
+func checkEntryFunctions(pkgs []*packages.Package, entries []EntryFunction) error {
+	byPath := make(map[string]*types.Package)
+	for _, p := range pkgs {
+		byPath[p.PkgPath] = p.Types
+	}
+	for _, e := range entries {
+		pkg := byPath[e.PkgPath]
+		if pkg == nil {
+			return fmt.Errorf("entry function %s: package %s is not among the analyzed packages", e, e.PkgPath)
+		}
+		if !hasFunc(pkg, e.Symbol) {
+			return fmt.Errorf("entry function %s: no function or method %s in package %s", e, e.Symbol, e.PkgPath)
+		}
+	}
+	return nil
+}
+
+// hasFunc reports whether symbol names a top-level function of pkg, or a
+// method of a top-level type of pkg.
+func hasFunc(pkg *types.Package, symbol string) bool {
+	name, method, isMethod := strings.Cut(symbol, ".")
+	obj := pkg.Scope().Lookup(name)
+	if !isMethod {
+		_, ok := obj.(*types.Func)
+		return ok
+	}
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return false
+	}
+	return types.NewMethodSet(types.NewPointer(tn.Type())).Lookup(pkg, method) != nil
+}
+
+// entryFunctions returns the functions of topPackages identified by
+// entries, which are checked by checkEntryFunctions.
+func entryFunctions(topPackages []*ssa.Package, entries []EntryFunction) ([]*ssa.Function, error) {
+	byPath := make(map[string]*ssa.Package)
+	for _, p := range topPackages {
+		byPath[p.Pkg.Path()] = p
+	}
+	var funcs []*ssa.Function
+	for _, e := range entries {
+		f := lookupFunc(byPath[e.PkgPath], e.Symbol)
+		if f == nil {
+			return nil, fmt.Errorf("entry function %s: no function for %s", e, e.Symbol)
+		}
+		funcs = append(funcs, f)
+	}
+	return funcs, nil
+}
+
+// lookupFunc returns the function of pkg named symbol as by hasFunc, or
+// nil if there is none.
+func lookupFunc(pkg *ssa.Package, symbol string) *ssa.Function {
+	if pkg == nil {
+		return nil
+	}
+	name, method, isMethod := strings.Cut(symbol, ".")
+	if !isMethod {
+		f, _ := pkg.Members[name].(*ssa.Function)
+		return f
+	}
+	for _, f := range memberFuncs(pkg.Members[name], pkg.Prog) {
+		if f.Name() == method {
+			return f
+		}
+	}
+	return nil
+}
+
 func isEntry(f *ssa.Function) bool {
 	// it should be safe to ignore checking that the signature of the "init" function
 	// is valid, since it is synthetic
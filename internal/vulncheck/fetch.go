@@ -6,21 +6,35 @@ package vulncheck
 
 import (
 	"context"
+	"fmt"
 
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/osv"
+	isem "golang.org/x/vuln/internal/semver"
 )
 
 // FetchVulnerabilities fetches vulnerabilities that affect the supplied modules.
+//
+// For a module replaced by a module with a different path, such as a fork or
+// a local directory, the vulnerabilities of both the replacement and the
+// original module are fetched. The latter are reported as possibly mitigated
+// by the replacement.
 func FetchVulnerabilities(ctx context.Context, c *client.Client, modules []*packages.Module) ([]*ModVulns, error) {
-	mreqs := make([]*client.ModuleRequest, len(modules))
+	var (
+		mreqs []*client.ModuleRequest
+		mods  []int // index in modules of each request
+	)
 	for i, mod := range modules {
 		modPath := mod.Path
 		if mod.Replace != nil {
 			modPath = mod.Replace.Path
 		}
-		mreqs[i] = &client.ModuleRequest{
-			Path: modPath,
+		mreqs = append(mreqs, &client.ModuleRequest{Path: modPath})
+		mods = append(mods, i)
+		if IsPathReplaced(mod) {
+			mreqs = append(mreqs, &client.ModuleRequest{Path: mod.Path})
+			mods = append(mods, i)
 		}
 	}
 	resps, err := c.ByModules(ctx, mreqs)
@@ -28,14 +42,74 @@ func FetchVulnerabilities(ctx context.Context, c *client.Client, modules []*pack
 		return nil, err
 	}
 	var mv []*ModVulns
+	byModule := map[int]*ModVulns{}
 	for i, resp := range resps {
 		if len(resp.Entries) == 0 {
 			continue
 		}
-		mv = append(mv, &ModVulns{
-			Module: modules[i],
+		if m := byModule[mods[i]]; m != nil {
+			m.Vulns = append(m.Vulns, resp.Entries...)
+			continue
+		}
+		m := &ModVulns{
+			Module: modules[mods[i]],
 			Vulns:  resp.Entries,
-		})
+		}
+		byModule[mods[i]] = m
+		mv = append(mv, m)
 	}
 	return mv, nil
 }
+
+// IsPathReplaced reports whether mod is replaced by a module with a
+// different path, such as a fork or a local directory.
+func IsPathReplaced(mod *packages.Module) bool {
+	return mod.Replace != nil && mod.Replace.Path != mod.Path
+}
+
+// SymbolVulns is a vulnerability of a module along with the symbols that
+// it affects in the packages of the module.
+type SymbolVulns struct {
+	OSV *osv.Entry
+
+	// Packages are the packages of the module affected by OSV, as listed
+	// in its ecosystem-specific data, in the order of the entry. A
+	// package without symbols is affected as a whole, and so is the
+	// module if there are no packages.
+	Packages []osv.Package
+}
+
+// FetchVulnerableSymbols returns the vulnerabilities of the module with
+// path modulePath at version, which must be a semantic version such as
+// "v1.2.3", along with the symbols that they affect, as recorded in the
+// database. No code is analyzed. If version is empty, the vulnerabilities
+// of all the versions of the module are returned. Vulnerabilities are
+// sorted by ID.
+func FetchVulnerableSymbols(ctx context.Context, c *client.Client, modulePath, version string) ([]*SymbolVulns, error) {
+	if version != "" && !isem.Valid(version) {
+		return nil, fmt.Errorf("version %s is not valid semver", version)
+	}
+	resps, err := c.ByModules(ctx, []*client.ModuleRequest{{Path: modulePath, Version: version}})
+	if err != nil {
+		return nil, err
+	}
+	var svs []*SymbolVulns
+	for _, entry := range resps[0].Entries {
+		sv := &SymbolVulns{OSV: entry}
+		affected := false
+		for _, a := range entry.Affected {
+			if a.Module.Path != modulePath {
+				continue
+			}
+			if version != "" && !isem.Affects(a.Ranges, version) {
+				continue
+			}
+			affected = true
+			sv.Packages = append(sv.Packages, a.EcosystemSpecific.Packages...)
+		}
+		if affected {
+			svs = append(svs, sv)
+		}
+	}
+	return svs, nil
+}
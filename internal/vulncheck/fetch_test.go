@@ -49,8 +49,69 @@ func TestFetchVulnerabilities(t *testing.T) {
 			Module: &packages.Module{Path: "example.mod/c", Replace: &packages.Module{Path: "example.mod/d", Version: "v1.0.0"}, Version: "v2.0.0"},
 			Vulns:  []*osv.Entry{c},
 		},
+		{
+			// Vulnerabilities of the original module are fetched for a
+			// module replaced by a local directory.
+			Module: &packages.Module{Path: "example.mod/e", Replace: &packages.Module{Path: "../local/example.mod/d", Version: "v1.0.1"}, Version: "v2.1.0"},
+			Vulns:  []*osv.Entry{d},
+		},
 	}
 	if diff := cmp.Diff(got, want); diff != "" {
 		t.Fatalf("mismatch (-want, +got):\n%s", diff)
 	}
 }
+
+func TestFetchVulnerableSymbols(t *testing.T) {
+	a := &osv.Entry{ID: "a", Affected: []osv.Affected{{
+		Module: osv.Module{Path: "example.mod/a"},
+		Ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.2.0"}}}},
+		EcosystemSpecific: osv.EcosystemSpecific{Packages: []osv.Package{
+			{Path: "example.mod/a/p", Symbols: []string{"F", "T.M"}},
+			{Path: "example.mod/a/q"},
+		}},
+	}}}
+	b := &osv.Entry{ID: "b", Affected: []osv.Affected{{
+		Module: osv.Module{Path: "example.mod/a"},
+		Ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "1.1.0"}, {Fixed: "1.3.0"}}}},
+		EcosystemSpecific: osv.EcosystemSpecific{Packages: []osv.Package{
+			{Path: "example.mod/a/p", Symbols: []string{"G"}},
+		}},
+	}, {
+		Module: osv.Module{Path: "example.mod/other"},
+		EcosystemSpecific: osv.EcosystemSpecific{Packages: []osv.Package{
+			{Path: "example.mod/other/p", Symbols: []string{"H"}},
+		}},
+	}}}
+
+	mc, err := client.NewInMemoryClient([]*osv.Entry{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		version string
+		want    []*vulncheck.SymbolVulns
+	}{
+		{"v1.0.0", []*vulncheck.SymbolVulns{{OSV: a, Packages: a.Affected[0].EcosystemSpecific.Packages}}},
+		{"v1.1.0", []*vulncheck.SymbolVulns{
+			{OSV: a, Packages: a.Affected[0].EcosystemSpecific.Packages},
+			{OSV: b, Packages: b.Affected[0].EcosystemSpecific.Packages},
+		}},
+		{"v1.2.0", []*vulncheck.SymbolVulns{{OSV: b, Packages: b.Affected[0].EcosystemSpecific.Packages}}},
+		{"v1.3.0", nil},
+	} {
+		t.Run(test.version, func(t *testing.T) {
+			got, err := vulncheck.FetchVulnerableSymbols(context.Background(), mc, "example.mod/a", test.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := vulncheck.FetchVulnerableSymbols(context.Background(), mc, "example.mod/a", "1.x"); err == nil {
+		t.Error("got no error for invalid version")
+	}
+}
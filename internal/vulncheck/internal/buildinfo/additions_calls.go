@@ -0,0 +1,239 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package buildinfo
+
+// This file adds to buildinfo the recovery of the direct calls between
+// the functions of a binary, from their machine code.
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/vuln/internal/vulncheck/internal/gosym"
+)
+
+// ExtractReachedSymbols returns the symbols of bin that are reached from
+// the functions named entries, keyed by package and then by symbol as in
+// ExtractPackagesSymbolsAndPositions, along with the entries that are not
+// functions of bin. Entries are named as in the symbol table, such as
+// "main.run" or "example.com/cmd.(*Server).Serve", or as vulnerable
+// symbols are, such as "example.com/cmd.Server.Serve".
+//
+// A function is reached if it is an entry, if a reached function calls
+// or jumps to it directly, if it was inlined into a reached function, or
+// if it is a closure of a reached function. Calls through interfaces,
+// function values, and reflection cannot be recovered from the machine
+// code, so the functions that are only reached through them are not
+// reported, except for closures.
+//
+// Direct calls are decoded for the 386, amd64, arm, and arm64
+// architectures. On 386 and amd64, whose instructions have variable
+// lengths, any call or jump opcode followed by the offset of the entry
+// of a function is taken as a call to it, which may rarely match bytes
+// that are not instructions.
+func ExtractReachedSymbols(bin io.ReaderAt, entries []string) (map[string]map[string]bool, []string, error) {
+	ft, err := loadFuncTable(bin)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ft.tab == nil {
+		return nil, nil, errors.New("the binary has no symbol table")
+	}
+	var goarch string
+	for _, s := range ft.bi.Settings {
+		if s.Key == "GOARCH" {
+			goarch = s.Value
+		}
+	}
+	decode := callDecoders[goarch]
+	if decode == nil {
+		return nil, nil, fmt.Errorf("the calls of binaries built for GOARCH %q cannot be decoded", goarch)
+	}
+
+	funcs := make(map[string]*gosym.Func)
+	names := make(map[pkgSymbol][]*gosym.Func)
+	byEntry := make(map[uint64]*gosym.Func)
+	for i := range ft.tab.Funcs {
+		f := &ft.tab.Funcs[i]
+		if f.Func == nil {
+			continue
+		}
+		pkg, sym, err := parseName(f.Sym)
+		if err != nil {
+			return nil, nil, err
+		}
+		funcs[f.Name] = f
+		ps := pkgSymbol{pkg, sym}
+		names[ps] = append(names[ps], f)
+		byEntry[f.Entry] = f
+	}
+	closures := make(map[*gosym.Func][]*gosym.Func)
+	for _, f := range funcs {
+		if p := closureParent(f.Name, funcs); p != nil {
+			closures[p] = append(closures[p], f)
+		}
+	}
+
+	var queue []*gosym.Func
+	visited := make(map[*gosym.Func]bool)
+	visit := func(f *gosym.Func) {
+		if !visited[f] {
+			visited[f] = true
+			queue = append(queue, f)
+		}
+	}
+	var missing []string
+	for _, e := range entries {
+		pkg, sym, err := parseName(&gosym.Sym{Name: e})
+		if err != nil {
+			return nil, nil, err
+		}
+		fs := names[pkgSymbol{pkg, sym}]
+		if len(fs) == 0 {
+			missing = append(missing, e)
+		}
+		for _, f := range fs {
+			visit(f)
+		}
+	}
+
+	reached := make(map[string]map[string]bool)
+	reach := func(pkg, sym string) {
+		if reached[pkg] == nil {
+			reached[pkg] = make(map[string]bool)
+		}
+		reached[pkg][sym] = true
+	}
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		pkg, sym, _ := parseName(f.Sym)
+		reach(pkg, sym)
+
+		// The function table is based at the offset of the text in the
+		// file, so the entries of functions are their offsets in bin.
+		code := make([]byte, f.End-f.Entry)
+		if _, err := bin.ReadAt(code, int64(f.Entry)); err != nil {
+			return nil, nil, fmt.Errorf("reading the code of %s: %v", f.Name, err)
+		}
+		for _, target := range decode(code, f.Entry) {
+			if g := byEntry[target]; g != nil {
+				visit(g)
+			}
+		}
+		for _, g := range closures[f] {
+			visit(g)
+		}
+		it, err := ft.lineTab.InlineTree(f, ft.value, ft.base, ft.r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("InlineTree: %v", err)
+		}
+		for _, ic := range it {
+			pkg, sym, err := parseName(&gosym.Sym{Name: ic.Name})
+			if err != nil {
+				return nil, nil, err
+			}
+			reach(pkg, sym)
+		}
+	}
+	return reached, missing, nil
+}
+
+// closureParent returns the function of funcs that the closure named name
+// is defined in, as in "main.run" for "main.run.func1.2", or nil if name
+// is not the name of a closure. Deferred and go statements are compiled
+// to closures named "gowrap" and "deferwrap".
+func closureParent(name string, funcs map[string]*gosym.Func) *gosym.Func {
+	for i := strings.LastIndex(name, "."); i > 0; i = strings.LastIndex(name[:i], ".") {
+		if !isClosureName(name[i+1:], "func", "gowrap", "deferwrap") {
+			return nil
+		}
+		if f := funcs[name[:i]]; f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// isClosureName reports whether the first element of name is a number, or
+// a number prefixed by one of prefixes, as in "func1" or "2".
+func isClosureName(name string, prefixes ...string) bool {
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(name, p) {
+			name = name[len(p):]
+			break
+		}
+	}
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// callDecoders decode, for each supported GOARCH, the targets of the
+// direct calls and jumps of code, the machine code of a function whose
+// entry is at address pc.
+var callDecoders = map[string]func(code []byte, pc uint64) []uint64{
+	"386":   decodeX86Calls,
+	"amd64": decodeX86Calls,
+	"arm":   decodeARMCalls,
+	"arm64": decodeARM64Calls,
+}
+
+// decodeX86Calls decodes the CALL rel32 and JMP rel32 instructions of code.
+func decodeX86Calls(code []byte, pc uint64) []uint64 {
+	var targets []uint64
+	for i := 0; i+5 <= len(code); i++ {
+		if code[i] != 0xe8 && code[i] != 0xe9 {
+			continue
+		}
+		rel := int32(binary.LittleEndian.Uint32(code[i+1:]))
+		targets = append(targets, pc+uint64(i+5)+uint64(int64(rel)))
+	}
+	return targets
+}
+
+// decodeARMCalls decodes the B and BL instructions of code, whose offsets
+// are relative to the address of the instruction plus 8.
+func decodeARMCalls(code []byte, pc uint64) []uint64 {
+	var targets []uint64
+	for i := 0; i+4 <= len(code); i += 4 {
+		w := binary.LittleEndian.Uint32(code[i:])
+		if w&0x0e000000 != 0x0a000000 || w>>28 == 0xf {
+			continue
+		}
+		off := int64(int32(w<<8)>>8) * 4
+		targets = append(targets, uint64(int64(pc)+int64(i)+8+off))
+	}
+	return targets
+}
+
+// decodeARM64Calls decodes the B and BL instructions of code.
+func decodeARM64Calls(code []byte, pc uint64) []uint64 {
+	var targets []uint64
+	for i := 0; i+4 <= len(code); i += 4 {
+		w := binary.LittleEndian.Uint32(code[i:])
+		if op := w >> 26; op != 0x05 && op != 0x25 {
+			continue
+		}
+		off := int64(int32(w<<6)>>6) * 4
+		targets = append(targets, uint64(int64(pc)+int64(i)+off))
+	}
+	return targets
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package buildinfo
+
+// This file adds to buildinfo the functionality for reading the build
+// information of a Go program from an ELF core dump of its process.
+
+import (
+	"bytes"
+	"debug/buildinfo"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime/debug"
+)
+
+// buildInfoMagic is the magic prefix of the build information blob
+// of Go executables, which is aligned to buildInfoAlign bytes in memory.
+var buildInfoMagic = []byte("\xff Go buildinf:")
+
+const (
+	buildInfoAlign      = 16
+	buildInfoHeaderSize = 32
+
+	// coreChunkSize is the size of the chunks of memory segments that
+	// are searched for the build information blob at once, and
+	// maxBuildInfoSize a bound on the size of the blob.
+	coreChunkSize    = 1 << 20
+	maxBuildInfoSize = 1 << 20
+)
+
+// readBuildInfo reads the build information of bin, which is either a
+// Go executable or an ELF core dump of a process running one.
+func readBuildInfo(bin io.ReaderAt) (*debug.BuildInfo, error) {
+	if f, err := elf.NewFile(bin); err == nil && f.Type == elf.ET_CORE {
+		return readCoreBuildInfo(f)
+	}
+	return buildinfo.Read(bin)
+}
+
+// readCoreBuildInfo reads the build information of the program of the
+// core dump f by searching the dumped writable memory segments for the
+// build information blob, which the linker places in the data segment.
+//
+// Segments that were not dumped, or were only partially dumped, are
+// searched as far as their contents are available. Only the format of
+// the blob used since Go 1.18, which holds the strings inline, is
+// supported.
+func readCoreBuildInfo(f *elf.File) (*debug.BuildInfo, error) {
+	for _, p := range f.Progs {
+		if p.Type != elf.PT_LOAD || p.Flags&elf.PF_W == 0 || p.Filesz == 0 {
+			continue
+		}
+		if bi := findBuildInfo(p); bi != nil {
+			return bi, nil
+		}
+	}
+	return nil, errors.New("no Go build information found in core dump")
+}
+
+// findBuildInfo returns the build information found in the memory
+// segment p, or nil if there is none.
+func findBuildInfo(p *elf.Prog) *debug.BuildInfo {
+	buf := make([]byte, coreChunkSize+len(buildInfoMagic))
+	for off := int64(0); off < int64(p.Filesz); off += coreChunkSize {
+		n, err := p.ReadAt(buf, off)
+		if n == 0 && err != nil {
+			// The rest of the segment is not available.
+			return nil
+		}
+		chunk := buf[:n]
+		for i := 0; i+len(buildInfoMagic) <= len(chunk); i += buildInfoAlign {
+			if !bytes.HasPrefix(chunk[i:], buildInfoMagic) {
+				continue
+			}
+			blob := make([]byte, maxBuildInfoSize)
+			m, _ := p.ReadAt(blob, off+int64(i))
+			if bi := decodeBuildInfo(blob[:m]); bi != nil {
+				return bi
+			}
+		}
+	}
+	return nil
+}
+
+// decodeBuildInfo decodes the build information blob data, as written
+// by the linker since Go 1.18. It returns nil if data is not such a blob
+// or is truncated.
+func decodeBuildInfo(data []byte) *debug.BuildInfo {
+	if len(data) < buildInfoHeaderSize || !bytes.HasPrefix(data, buildInfoMagic) {
+		return nil
+	}
+	const flagsVersionInl = 0x2
+	if data[len(buildInfoMagic)+1]&flagsVersionInl == 0 {
+		// Strings are referenced by pointers, as before Go 1.18.
+		return nil
+	}
+	vers, rest, ok := decodeString(data[buildInfoHeaderSize:])
+	if !ok {
+		return nil
+	}
+	mod, _, ok := decodeString(rest)
+	if !ok {
+		return nil
+	}
+	// The module information is wrapped in 16-byte sentinels.
+	if len(mod) < 33 || mod[len(mod)-17] != '\n' {
+		return nil
+	}
+	bi, err := debug.ParseBuildInfo(string(mod[16 : len(mod)-16]))
+	if err != nil {
+		return nil
+	}
+	bi.GoVersion = string(vers)
+	return bi
+}
+
+// decodeString decodes a string prefixed by its length as a uvarint from
+// the start of data, and returns it along with the rest of data.
+func decodeString(data []byte) (s, rest []byte, ok bool) {
+	n, k := binary.Uvarint(data)
+	if k <= 0 || n > uint64(len(data)-k) {
+		return nil, nil, false
+	}
+	return data[k : k+int(n)], data[k+int(n):], true
+}
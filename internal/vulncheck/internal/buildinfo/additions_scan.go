@@ -11,7 +11,6 @@ package buildinfo
 // and cmd/go/internal/version/exe.go.
 
 import (
-	"debug/buildinfo"
 	"errors"
 	"fmt"
 	"io"
@@ -41,91 +40,163 @@ func debugModulesToPackagesModules(debugModules []*debug.Module) []*packages.Mod
 	return packagesModules
 }
 
+// A SymbolPosition is the source position of a function in a binary, as
+// recorded in its line table.
+type SymbolPosition struct {
+	Filename string
+	Line     int
+
+	// Inlined is set if the function only appears inlined into other
+	// functions. Filename and Line are then the position of a call to
+	// the function, before it was inlined, rather than of the code of
+	// the function inside its caller.
+	Inlined bool
+}
+
 // ExtractPackagesAndSymbols extracts symbols, packages, modules from
 // bin as well as bin's metadata.
 //
 // If the symbol table is not available, such as in the case of stripped
 // binaries, returns module and binary info but without the symbol info.
 func ExtractPackagesAndSymbols(bin io.ReaderAt) ([]*packages.Module, map[string][]string, *debug.BuildInfo, error) {
-	bi, err := buildinfo.Read(bin)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	funcSymName := gosym.FuncSymName(bi.GoVersion)
-	if funcSymName == "" {
-		return nil, nil, nil, fmt.Errorf("binary built using unsupported Go version: %q", bi.GoVersion)
-	}
+	mods, packageSymbols, _, bi, err := ExtractPackagesSymbolsAndPositions(bin)
+	return mods, packageSymbols, bi, err
+}
 
-	x, err := openExe(bin)
+// ExtractPackagesSymbolsAndPositions is like ExtractPackagesAndSymbols,
+// but also returns the position of each symbol, keyed by package and
+// then by symbol.
+//
+// The position of a function that is compiled on its own is the
+// position of its entry. The position of a function that only appears
+// inlined is the position of one of the calls to it, preferring the
+// logical source location of the call to the location of the inlined
+// code, which is in the caller.
+func ExtractPackagesSymbolsAndPositions(bin io.ReaderAt) ([]*packages.Module, map[string][]string, map[string]map[string]*SymbolPosition, *debug.BuildInfo, error) {
+	ft, err := loadFuncTable(bin)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
-
-	value, base, r, err := x.SymbolInfo(funcSymName)
-	if err != nil {
-		if errors.Is(err, ErrNoSymbols) {
-			// bin is stripped, so return just module info and metadata.
-			return debugModulesToPackagesModules(bi.Deps), nil, bi, nil
-		}
-		return nil, nil, nil, fmt.Errorf("reading %v: %v", funcSymName, err)
+	bi, tab, lineTab := ft.bi, ft.tab, ft.lineTab
+	if tab == nil {
+		// bin is stripped, so return just module info and metadata.
+		return debugModulesToPackagesModules(bi.Deps), nil, nil, bi, nil
 	}
 
-	pclntab, textOffset := x.PCLNTab()
-	if pclntab == nil {
-		// TODO(https://go.dev/issue/59731): if we have build information, but
-		// not PCLN table, we should be able to fall back to much higher
-		// granularity vulnerability checking.
-		return nil, nil, nil, errors.New("unable to load the PCLN table")
-	}
-	lineTab := gosym.NewLineTable(pclntab, textOffset)
-	if lineTab == nil {
-		return nil, nil, nil, errors.New("invalid line table")
-	}
-	tab, err := gosym.NewTable(nil, lineTab)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	type pkgSymbol struct {
-		pkg string
-		sym string
-	}
-	pkgSyms := make(map[pkgSymbol]bool)
+	pkgSyms := make(map[pkgSymbol]*SymbolPosition)
 	for _, f := range tab.Funcs {
 		if f.Func == nil {
 			continue
 		}
 		pkgName, symName, err := parseName(f.Func.Sym)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, nil, err
+		}
+		ps := pkgSymbol{pkgName, symName}
+		if pos := pkgSyms[ps]; pos == nil || pos.Inlined {
+			file, line, _ := tab.PCToLine(f.Entry)
+			pkgSyms[ps] = &SymbolPosition{Filename: file, Line: line}
 		}
-		pkgSyms[pkgSymbol{pkgName, symName}] = true
 
 		// Collect symbols that were inlined in f.
-		it, err := lineTab.InlineTree(&f, value, base, r)
+		it, err := lineTab.InlineTree(&f, ft.value, ft.base, ft.r)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("InlineTree: %v", err)
+			return nil, nil, nil, nil, fmt.Errorf("InlineTree: %v", err)
 		}
 		for _, ic := range it {
 			pkgName, symName, err := parseName(&gosym.Sym{Name: ic.Name})
 			if err != nil {
-				return nil, nil, nil, err
+				return nil, nil, nil, nil, err
+			}
+			ps := pkgSymbol{pkgName, symName}
+			if pkgSyms[ps] == nil {
+				// The instruction at ParentPC has the position of the
+				// call site, in the function the call was inlined into.
+				file, line, _ := tab.PCToLine(f.Entry + uint64(ic.ParentPC))
+				pkgSyms[ps] = &SymbolPosition{Filename: file, Line: line, Inlined: true}
 			}
-			pkgSyms[pkgSymbol{pkgName, symName}] = true
 		}
 	}
 
 	packageSymbols := make(map[string][]string)
-	for p := range pkgSyms {
+	positions := make(map[string]map[string]*SymbolPosition)
+	for p, pos := range pkgSyms {
 		packageSymbols[p.pkg] = append(packageSymbols[p.pkg], p.sym)
+		if positions[p.pkg] == nil {
+			positions[p.pkg] = make(map[string]*SymbolPosition)
+		}
+		positions[p.pkg][p.sym] = pos
 	}
 	// Sort symbols per pkg for deterministic results.
 	for _, syms := range packageSymbols {
 		sort.Strings(syms)
 	}
 
-	return debugModulesToPackagesModules(bi.Deps), packageSymbols, bi, nil
+	return debugModulesToPackagesModules(bi.Deps), packageSymbols, positions, bi, nil
+}
+
+// A funcTable is the function table of a binary, read from its PCLN table.
+type funcTable struct {
+	bi *debug.BuildInfo
+
+	// tab and lineTab are the function and line tables of the binary,
+	// which are nil if it is stripped.
+	tab     *gosym.Table
+	lineTab *gosym.LineTable
+
+	// value, base, and r locate the function symbol table of the binary,
+	// from which the inline trees of its functions are read.
+	value, base uint64
+	r           io.ReaderAt
+}
+
+// loadFuncTable reads the build information and function table of bin.
+func loadFuncTable(bin io.ReaderAt) (*funcTable, error) {
+	bi, err := readBuildInfo(bin)
+	if err != nil {
+		return nil, err
+	}
+
+	funcSymName := gosym.FuncSymName(bi.GoVersion)
+	if funcSymName == "" {
+		return nil, fmt.Errorf("binary built using unsupported Go version: %q", bi.GoVersion)
+	}
+
+	x, err := openExe(bin)
+	if err != nil {
+		return nil, err
+	}
+
+	value, base, r, err := x.SymbolInfo(funcSymName)
+	if err != nil {
+		if errors.Is(err, ErrNoSymbols) {
+			return &funcTable{bi: bi}, nil
+		}
+		return nil, fmt.Errorf("reading %v: %v", funcSymName, err)
+	}
+
+	pclntab, textOffset := x.PCLNTab()
+	if pclntab == nil {
+		// TODO(https://go.dev/issue/59731): if we have build information, but
+		// not PCLN table, we should be able to fall back to much higher
+		// granularity vulnerability checking.
+		return nil, errors.New("unable to load the PCLN table")
+	}
+	lineTab := gosym.NewLineTable(pclntab, textOffset)
+	if lineTab == nil {
+		return nil, errors.New("invalid line table")
+	}
+	tab, err := gosym.NewTable(nil, lineTab)
+	if err != nil {
+		return nil, err
+	}
+	return &funcTable{bi: bi, tab: tab, lineTab: lineTab, value: value, base: base, r: r}, nil
+}
+
+// A pkgSymbol is a symbol of a package, as in "T.Method".
+type pkgSymbol struct {
+	pkg string
+	sym string
 }
 
 func parseName(s *gosym.Sym) (pkg, sym string, err error) {
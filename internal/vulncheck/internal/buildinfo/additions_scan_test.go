@@ -8,14 +8,32 @@
 package buildinfo
 
 import (
+	"bytes"
+	"debug/buildinfo"
+	"debug/elf"
+	"encoding/binary"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/vuln/internal/test"
+	"golang.org/x/vuln/internal/vulncheck/internal/gosym"
 )
 
+// skipUnsupportedGo skips t if the binaries built by the tests, which use
+// the Go version running them, cannot be analyzed.
+func skipUnsupportedGo(t *testing.T) {
+	t.Helper()
+	if gosym.FuncSymName(runtime.Version()) == "" {
+		t.Skipf("binaries built with %s are not supported", runtime.Version())
+	}
+}
+
 func TestExtractPackagesAndSymbols(t *testing.T) {
+	skipUnsupportedGo(t)
+
 	unsupported := map[string]bool{
 		"darwin/386": true,
 		"darwin/arm": true,
@@ -54,9 +72,76 @@ func TestExtractPackagesAndSymbols(t *testing.T) {
 	}
 }
 
+// TestInlinedPositions checks that a function that was inlined into its
+// caller is reported at the position of the call, and marked as inlined.
+func TestInlinedPositions(t *testing.T) {
+	skipUnsupportedGo(t)
+	binary, done := test.GoBuild(t, "testdata/inlined", "", false)
+	defer done()
+
+	f, err := os.Open(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	_, _, positions, _, err := ExtractPackagesSymbolsAndPositions(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := positions["main"]["add"]
+	if got == nil {
+		t.Fatalf("no position for main.add; got %v", positions["main"])
+	}
+	if filepath.Base(got.Filename) != "main.go" || got.Line != 6 || !got.Inlined {
+		t.Errorf("main.add: got %+v, want inlined at main.go:6", got)
+	}
+	if got := positions["main"]["main"]; got == nil || got.Inlined {
+		t.Errorf("main.main: got %+v, want a position that is not inlined", got)
+	}
+}
+
+// TestExtractReachedSymbols checks that the symbols reached from an entry
+// are found through direct calls, closures, and inlining, on each of the
+// architectures whose calls are decoded.
+func TestExtractReachedSymbols(t *testing.T) {
+	skipUnsupportedGo(t)
+
+	for _, goarch := range []string{"amd64", "386", "arm", "arm64"} {
+		goarch := goarch
+		t.Run(goarch, func(t *testing.T) {
+			binary, done := test.GoBuild(t, "testdata/reached", "", false, "GOOS", "linux", "GOARCH", goarch)
+			defer done()
+
+			f, err := os.Open(binary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			reached, missing, err := ExtractReachedSymbols(f, []string{"main.serve", "main.missing"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, sym := range []string{"serve", "helper", "inClosure", "add"} {
+				if !reached["main"][sym] {
+					t.Errorf("main.%s is not reached from main.serve", sym)
+				}
+			}
+			for _, sym := range []string{"main", "other"} {
+				if reached["main"][sym] {
+					t.Errorf("main.%s is reached from main.serve", sym)
+				}
+			}
+			if want := []string{"main.missing"}; !cmp.Equal(missing, want) {
+				t.Errorf("got missing entries %q, want %q", missing, want)
+			}
+		})
+	}
+}
+
 // TestStrippedBinary checks support for stripped binaries.
 // Currently, just checks that there is no symbol table.
 func TestStrippedBinary(t *testing.T) {
+	skipUnsupportedGo(t)
 	binary, done := test.GoBuild(t, "testdata", "", true, "GOOS", "linux", "GOARCH", "amd64")
 	defer done()
 
@@ -73,3 +158,72 @@ func TestStrippedBinary(t *testing.T) {
 		t.Errorf("want empty symbol table; got %v", syms)
 	}
 }
+
+// TestCoreDump checks that the build information of a program is read
+// from a core dump of its process, where it is only found in memory
+// segments.
+func TestCoreDump(t *testing.T) {
+	binary, done := test.GoBuild(t, "testdata", "", false, "GOOS", "linux", "GOARCH", "amd64")
+	defer done()
+
+	want, err := buildinfo.ReadFile(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exe, err := elf.Open(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exe.Close()
+	sec := exe.Section(".go.buildinfo")
+	if sec == nil {
+		t.Fatal("no .go.buildinfo section")
+	}
+	data, err := sec.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Place the build information in the middle of a data segment.
+	segment := append(make([]byte, 4096), data...)
+	bi, err := readBuildInfo(bytes.NewReader(coreDump(t, segment)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bi.GoVersion != want.GoVersion || bi.Path != want.Path || bi.Main != want.Main {
+		t.Errorf("got %s %s %v, want %s %s %v", bi.GoVersion, bi.Path, bi.Main, want.GoVersion, want.Path, want.Main)
+	}
+}
+
+// coreDump returns a little-endian ELF64 core file with a single
+// writable memory segment holding segment.
+func coreDump(t *testing.T, segment []byte) []byte {
+	const headerSize, progSize = 64, 56
+	var buf bytes.Buffer
+	hdr := elf.Header64{
+		Type:      uint16(elf.ET_CORE),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     headerSize,
+		Ehsize:    headerSize,
+		Phentsize: progSize,
+		Phnum:     1,
+	}
+	copy(hdr.Ident[:], elf.ELFMAG)
+	hdr.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	hdr.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	hdr.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	prog := elf.Prog64{
+		Type:   uint32(elf.PT_LOAD),
+		Flags:  uint32(elf.PF_R | elf.PF_W),
+		Off:    headerSize + progSize,
+		Vaddr:  0xc000000000,
+		Filesz: uint64(len(segment)),
+		Memsz:  uint64(len(segment)),
+	}
+	for _, v := range []any{hdr, prog, segment} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
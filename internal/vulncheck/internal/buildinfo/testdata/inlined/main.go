@@ -0,0 +1,13 @@
+package main
+
+import "os"
+
+func main() {
+	println(add(len(os.Args), 1)) // add is inlined here
+}
+
+// add is small enough to be inlined into main, and has no other
+// callers, so the binary only contains its inlined copy.
+func add(a, b int) int {
+	return a + b
+}
@@ -0,0 +1,39 @@
+package main
+
+import "os"
+
+func main() {
+	if len(os.Args) > 1 {
+		serve()
+	} else {
+		other()
+	}
+}
+
+// serve is the entry of the test, which reaches helper through a direct
+// call, inClosure through a closure, and add through inlining.
+//
+//go:noinline
+func serve() {
+	helper()
+	done := make(chan bool)
+	go func() {
+		inClosure()
+		done <- true
+	}()
+	<-done
+	println(add(len(os.Args), 1))
+}
+
+//go:noinline
+func helper() { println("helper") }
+
+//go:noinline
+func inClosure() { println("closure") }
+
+//go:noinline
+func other() { println("other") }
+
+func add(a, b int) int {
+	return a + b
+}
@@ -32,6 +32,9 @@ func TestFuncSymName(t *testing.T) {
 }
 
 func TestInlineTree(t *testing.T) {
+	if FuncSymName(runtime.Version()) == "" {
+		t.Skipf("binaries built with %s are not supported", runtime.Version())
+	}
 	pclinetestBinary, cleanup := dotest(t)
 	defer cleanup()
 
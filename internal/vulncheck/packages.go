@@ -61,11 +61,19 @@ func (g *PackageGraph) GetModule(path string) *packages.Module {
 }
 
 // AddPackages adds the packages and the full graph of imported packages.
-// It will ignore packages that have duplicate paths to ones the graph already holds.
+// It will ignore packages that have duplicate paths to ones the graph already holds,
+// but still add the packages they import.
 func (g *PackageGraph) AddPackages(pkgs ...*packages.Package) {
 	for _, pkg := range pkgs {
-		if _, found := g.packages[pkg.PkgPath]; found {
+		if p, found := g.packages[pkg.PkgPath]; found {
 			//TODO: check duplicates are okay?
+			if p != pkg {
+				// pkg is a variant of p, such as the test variant
+				// of a package, which may import more packages.
+				for _, child := range pkg.Imports {
+					g.AddPackages(child)
+				}
+			}
 			continue
 		}
 		g.packages[pkg.PkgPath] = pkg
@@ -118,12 +126,13 @@ func (g *PackageGraph) GetPackage(path string) *packages.Package {
 // See golang.org/x/tools/go/packages.Load for details of how it works.
 func (g *PackageGraph) LoadPackages(cfg *packages.Config, tags []string, patterns []string) ([]*packages.Package, error) {
 	if len(tags) > 0 {
-		cfg.BuildFlags = []string{fmt.Sprintf("-tags=%s", strings.Join(tags, ","))}
+		cfg.BuildFlags = append(cfg.BuildFlags, fmt.Sprintf("-tags=%s", strings.Join(tags, ",")))
 	}
 	cfg.Mode |=
 		packages.NeedDeps |
 			packages.NeedImports |
 			packages.NeedModule |
+			packages.NeedFiles |
 			packages.NeedSyntax |
 			packages.NeedTypes |
 			packages.NeedTypesInfo |
@@ -0,0 +1,107 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"os"
+	"sort"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/osv"
+	isem "golang.org/x/vuln/internal/semver"
+)
+
+// A Requirement is a module required by the go.mod file of a main module
+// at a version lower than the one selected for the build, which is raised
+// by the requirements of other modules. The vulnerabilities of a
+// module are matched against the selected version only.
+type Requirement struct {
+	// Module is the required module, at the version selected for the build.
+	Module *packages.Module
+
+	// Version is the version required by the go.mod file.
+	Version string
+
+	// Patched are the vulnerabilities that affect Version but not the
+	// selected version of Module, so they are not reported.
+	Patched []*osv.Entry
+}
+
+// requirements returns the requirements of mods, the modules of the
+// build, that go.mod files of main modules require at versions lower than
+// the selected ones, along with the vulnerabilities among mv that are
+// patched by the selected versions. Replaced modules are not considered,
+// as their versions are not selected by requirements.
+func requirements(mods []*packages.Module, mv []*ModVulns) []*Requirement {
+	required := requiredVersions(mods)
+	vulns := map[*packages.Module][]*osv.Entry{}
+	for _, m := range mv {
+		vulns[m.Module] = append(vulns[m.Module], m.Vulns...)
+	}
+	var reqs []*Requirement
+	for _, mod := range mods {
+		version, ok := required[mod.Path]
+		if !ok || mod.Main || mod.Replace != nil || !isem.Less(version, mod.Version) {
+			continue
+		}
+		reqs = append(reqs, &Requirement{
+			Module:  mod,
+			Version: version,
+			Patched: patched(mod.Path, version, mod.Version, vulns[mod]),
+		})
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Module.Path < reqs[j].Module.Path })
+	return reqs
+}
+
+// requiredVersions returns the versions of the modules required by the
+// go.mod files of the main modules among mods, keyed by module path. Of
+// the versions required by several main modules of a workspace, the
+// highest one is used. go.mod files that cannot be read are skipped.
+func requiredVersions(mods []*packages.Module) map[string]string {
+	required := map[string]string{}
+	for _, mod := range mods {
+		if !mod.Main || mod.GoMod == "" {
+			continue
+		}
+		data, err := os.ReadFile(mod.GoMod)
+		if err != nil {
+			continue
+		}
+		f, err := modfile.ParseLax(mod.GoMod, data, nil)
+		if err != nil {
+			continue
+		}
+		for _, r := range f.Require {
+			if v, ok := required[r.Mod.Path]; !ok || isem.Less(v, r.Mod.Version) {
+				required[r.Mod.Path] = r.Mod.Version
+			}
+		}
+	}
+	return required
+}
+
+// patched returns the entries of entries that affect the module at path
+// at version required, but not at version selected.
+func patched(path, required, selected string, entries []*osv.Entry) []*osv.Entry {
+	var ps []*osv.Entry
+	for _, e := range entries {
+		if affectsModule(e, path, required) && !affectsModule(e, path, selected) {
+			ps = append(ps, e)
+		}
+	}
+	return ps
+}
+
+// affectsModule reports whether e affects the module at path at version.
+func affectsModule(e *osv.Entry, path, version string) bool {
+	for _, a := range e.Affected {
+		if a.Module.Path == path && isem.Affects(a.Ranges, version) {
+			return true
+		}
+	}
+	return false
+}
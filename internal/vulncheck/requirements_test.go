@@ -0,0 +1,62 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestRequirements(t *testing.T) {
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(gomod, []byte(`module golang.org/entry
+
+require (
+	golang.org/amod v1.0.0
+	golang.org/bmod v1.1.0
+	golang.org/cmod v1.0.0
+	golang.org/dmod v1.0.0
+)
+
+replace golang.org/cmod => ../cmod
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	main := &packages.Module{Path: "golang.org/entry", Main: true, GoMod: gomod}
+	amod := &packages.Module{Path: "golang.org/amod", Version: "v1.2.0"}
+	bmod := &packages.Module{Path: "golang.org/bmod", Version: "v1.1.0"}
+	cmod := &packages.Module{Path: "golang.org/cmod", Version: "v1.3.0", Replace: &packages.Module{Path: "../cmod"}}
+	dmod := &packages.Module{Path: "golang.org/dmod", Version: "v1.5.0"}
+
+	affected := func(id, path, introduced, fixed string) *osv.Entry {
+		return &osv.Entry{ID: id, Affected: []osv.Affected{{
+			Module: osv.Module{Path: path},
+			Ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{
+				{Introduced: introduced}, {Fixed: fixed},
+			}}},
+		}}}
+	}
+	patchedA := affected("A1", "golang.org/amod", "0", "1.1.0")
+	stillA := affected("A2", "golang.org/amod", "0", "1.3.0")
+	mv := []*ModVulns{
+		{Module: amod, Vulns: []*osv.Entry{patchedA, stillA}},
+		{Module: cmod, Vulns: []*osv.Entry{affected("C1", "golang.org/cmod", "0", "1.1.0")}},
+	}
+
+	got := requirements([]*packages.Module{dmod, main, cmod, bmod, amod}, mv)
+	want := []*Requirement{
+		// A2 still affects the selected version, so it is not patched.
+		{Module: amod, Version: "v1.0.0", Patched: []*osv.Entry{patchedA}},
+		{Module: dmod, Version: "v1.0.0"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
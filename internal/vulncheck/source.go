@@ -8,14 +8,18 @@ import (
 	"context"
 	"fmt"
 	"go/token"
+	"sort"
 	"sync"
+	"time"
 
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
+	"golang.org/x/vuln/internal"
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/semver"
 )
 
 // Source detects vulnerabilities in packages. The result will contain:
@@ -27,7 +31,53 @@ import (
 // some known vulnerabilities.
 //
 // 3) A CallGraph leading to the use of a known vulnerable function or method.
-func Source(ctx context.Context, pkgs []*packages.Package, cfg *govulncheck.Config, client *client.Client, graph *PackageGraph) (_ *Result, err error) {
+//
+// If ctx is done once the vulnerabilities are fetched but before the call
+// graph is built, the result only has 1) and 2), and Result.Cancelled is
+// set.
+func Source(ctx context.Context, pkgs []*packages.Package, cfg *govulncheck.Config, client *client.Client, graph *PackageGraph) (*Result, error) {
+	return SourceWithEntryFilter(ctx, pkgs, cfg, client, graph, nil)
+}
+
+// An EntryFilter reports whether the top-level package with path pkgPath
+// is an entry point candidate of the analysis.
+type EntryFilter func(pkgPath string) bool
+
+// SourceWithEntryFilter is like Source, but only the packages of pkgs
+// accepted by filter, if it is not nil, are entry points: they alone
+// seed Result.EntryPackages and Result.EntryFunctions. The other packages
+// of pkgs are still analyzed as dependencies of the entry points, so
+// vulnerabilities are only reported if they are reachable from the
+// entry points.
+func SourceWithEntryFilter(ctx context.Context, pkgs []*packages.Package, cfg *govulncheck.Config, client *client.Client, graph *PackageGraph, filter EntryFilter) (*Result, error) {
+	return source(ctx, pkgs, cfg, client, graph, filter, nil)
+}
+
+// SourceWithEntryFunctions is like Source, but the entry points of the
+// analysis are exactly the functions identified by entries, instead of
+// the exported functions and main functions of pkgs. Result.EntryFunctions
+// are then these functions, so that call stacks are only rooted there, and
+// Result.EntryPackages are their packages. It returns an error if some of
+// entries is not a function or method of a package of pkgs.
+func SourceWithEntryFunctions(ctx context.Context, pkgs []*packages.Package, cfg *govulncheck.Config, client *client.Client, graph *PackageGraph, entries []EntryFunction) (*Result, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entry functions")
+	}
+	if err := checkEntryFunctions(pkgs, entries); err != nil {
+		return nil, err
+	}
+	entryPkgs := make(map[string]bool)
+	for _, e := range entries {
+		entryPkgs[e.PkgPath] = true
+	}
+	filter := func(pkgPath string) bool { return entryPkgs[pkgPath] }
+	return source(ctx, pkgs, cfg, client, graph, filter, entries)
+}
+
+// source implements SourceWithEntryFilter and SourceWithEntryFunctions.
+// If entryFuncs is not nil, they are the entry points instead of those
+// of the packages accepted by filter.
+func source(ctx context.Context, pkgs []*packages.Package, cfg *govulncheck.Config, client *client.Client, graph *PackageGraph, filter EntryFilter, entryFuncs []EntryFunction) (_ *Result, err error) {
 	// buildSSA builds a whole program that assumes all packages use the same FileSet.
 	// Check all packages in pkgs are using the same FileSet.
 	// TODO(https://go.dev/issue/59729): take FileSet out of Package and
@@ -49,45 +99,135 @@ func Source(ctx context.Context, pkgs []*packages.Package, cfg *govulncheck.Conf
 
 	// If we are building the callgraph, build ssa and the callgraph in parallel
 	// with fetching vulnerabilities. If the vulns set is empty, return without
-	// waiting for SSA construction or callgraph to finish.
+	// waiting for SSA construction or callgraph to finish. A sequential scan
+	// builds them first instead.
 	var (
-		wg       sync.WaitGroup // guards entries, cg, and buildErr
-		entries  []*ssa.Function
-		cg       *callgraph.Graph
-		buildErr error
+		wg         sync.WaitGroup // guards entries, cg, assumed, buildDiags, buildErr, and buildTime
+		entries    []*ssa.Function
+		cg         *callgraph.Graph
+		assumed    map[callEdge]bool
+		buildDiags []*Diagnostic
+		buildErr   error
+		buildTime  time.Duration
 	)
 	if cfg.ScanLevel.WantSymbols() {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			prog, ssaPkgs := buildSSA(pkgs, fset)
-			entries = entryPoints(ssaPkgs)
-			cg, buildErr = callGraph(ctx, prog, entries)
-		}()
+		build := func() {
+			start := time.Now()
+			defer func() { buildTime = time.Since(start) }()
+			defer func() {
+				// A panic would otherwise end the whole process
+				// from this goroutine with an opaque stack trace.
+				if r := recover(); r != nil {
+					buildErr = fmt.Errorf("building call graph: %v", r)
+				}
+			}()
+			prog, ssaPkgs, diags := buildSSA(pkgs, fset, cfg.Sequential)
+			buildDiags = diags
+			if entryFuncs != nil {
+				entries, buildErr = entryFunctions(ssaPkgs, entryFuncs)
+				if buildErr != nil {
+					return
+				}
+			} else {
+				entries = entryPoints(filterEntries(ssaPkgs, filter))
+			}
+			cg, assumed, buildErr = callGraph(ctx, prog, entries, asmCalls(pkgs), cgoCalls(pkgs), cfg.DispatchTables)
+		}
+		if cfg.Sequential {
+			build()
+		} else {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				build()
+			}()
+		}
 	}
 
 	mods := extractModules(pkgs)
+	var skipped []*packages.Module
+	if cfg.ModuleDepth > 0 {
+		within := modulesWithinDepth(pkgs, mods, cfg.ModuleDepth)
+		keep := map[*packages.Module]bool{}
+		for _, m := range within {
+			keep[m] = true
+		}
+		for _, m := range mods {
+			if !keep[m] {
+				skipped = append(skipped, m)
+			}
+		}
+		mods = within
+	}
+	start := time.Now()
 	mv, err := FetchVulnerabilities(ctx, client, mods)
 	if err != nil {
 		return nil, err
 	}
-	modVulns := moduleVulnerabilities(mv)
-	modVulns = modVulns.filter("", "")
-	result := &Result{}
-
-	vulnPkgModSlice(pkgs, modVulns, result)
+	modVulns := moduleVulnerabilities(mv).only(cfg.OSVs)
+	result := &Result{
+		SkippedModules: skipped,
+		Requirements:   requirements(mods, modVulns),
+	}
+	var stdVersions []string
+	for _, v := range cfg.GoVersions {
+		stdVersions = append(stdVersions, semver.GoTagToSemver(v))
+	}
+	all := modVulns.filter("", "", stdVersions)
+	modVulns = modVulns.filter(cfg.GOOS, cfg.GOARCH, stdVersions)
+	result.NotApplicable = notApplicable(all, modVulns)
+	result.Timings.Fetch = time.Since(start)
+
+	start = time.Now()
+	vulnPkgModSlice(pkgs, modVulns, result, filter)
+	if cfg.Sequential {
+		// Process the vulnerabilities in a reproducible order,
+		// independent of the order in which packages are visited.
+		sort.SliceStable(result.Vulns, func(i, j int) bool { return result.Vulns[i].OSV.ID < result.Vulns[j].OSV.ID })
+	}
+	result.Timings.Match = time.Since(start)
 	// Return result immediately if not in symbol mode or
-	// if there are no vulnerable packages.
-	if !cfg.ScanLevel.WantSymbols() || len(result.EntryPackages) == 0 {
+	// if there are no vulnerable packages, unless all the
+	// entry functions are to be reported.
+	if !cfg.ScanLevel.WantSymbols() || len(result.EntryPackages) == 0 && !cfg.AllEntryFunctions {
 		return result, nil
 	}
 
-	wg.Wait() // wait for build to finish
+	// Wait for build to finish, unless ctx is done first. The vulnerable
+	// packages are then still reported as imported.
+	built := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(built)
+	}()
+	select {
+	case <-built:
+	case <-ctx.Done():
+		result.Cancelled = true
+		return result, nil
+	}
 	if buildErr != nil {
-		return nil, err
+		if ctx.Err() != nil {
+			result.Cancelled = true
+			return result, nil
+		}
+		return nil, buildErr
+	}
+	result.Diagnostics = buildDiags
+	result.Timings.CallGraph = buildTime
+	if cfg.AllEntryFunctions {
+		nodes := make(map[*ssa.Function]*FuncNode)
+		for _, e := range entries {
+			result.AllEntryFunctions = append(result.AllEntryFunctions, createNode(nodes, e, graph))
+		}
+		if len(result.EntryPackages) == 0 {
+			return result, nil
+		}
 	}
 
-	vulnCallGraphSlice(entries, modVulns, cg, result, graph)
+	start = time.Now()
+	vulnCallGraphSlice(entries, modVulns, cg, assumed, result, graph)
+	result.Timings.Match += time.Since(start)
 
 	return result, nil
 }
@@ -95,13 +235,16 @@ func Source(ctx context.Context, pkgs []*packages.Package, cfg *govulncheck.Conf
 // vulnPkgModSlice computes the slice of pkgs imports and requires graph
 // leading to imports/requires of vulnerable packages/modules in modVulns
 // and stores the computed slices to result.
-func vulnPkgModSlice(pkgs []*packages.Package, modVulns moduleVulnerabilities, result *Result) {
+func vulnPkgModSlice(pkgs []*packages.Package, modVulns moduleVulnerabilities, result *Result, filter EntryFilter) {
 	// analyzedPkgs contains information on packages analyzed thus far.
 	// If a package is mapped to false, this means it has been visited
 	// but it does not lead to a vulnerable imports. Otherwise, a
 	// visited package is mapped to true.
 	analyzedPkgs := make(map[*packages.Package]bool)
 	for _, pkg := range pkgs {
+		if filter != nil && !filter(pkg.PkgPath) {
+			continue
+		}
 		// Top level packages that lead to vulnerable imports are
 		// stored as result.EntryPackages graph entry points.
 		if vulnerable := vulnImportSlice(pkg, modVulns, result, analyzedPkgs); vulnerable {
@@ -167,8 +310,10 @@ func vulnImportSlice(pkg *packages.Package, modVulns moduleVulnerabilities, resu
 
 // vulnCallGraphSlice checks if known vulnerabilities are transitively reachable from sources
 // via call graph cg. If so, populates result.Calls graph with this reachability information.
-func vulnCallGraphSlice(sources []*ssa.Function, modVulns moduleVulnerabilities, cg *callgraph.Graph, result *Result, graph *PackageGraph) {
-	sinksWithVulns := vulnFuncs(cg, modVulns)
+// The assumed edges of cg are labeled as such in result.
+func vulnCallGraphSlice(sources []*ssa.Function, modVulns moduleVulnerabilities, cg *callgraph.Graph, assumed map[callEdge]bool, result *Result, graph *PackageGraph) {
+	namer := newSymbolNamer()
+	sinksWithVulns := vulnFuncs(cg, modVulns, namer)
 
 	// Compute call graph backwards reachable
 	// from vulnerable functions and methods.
@@ -198,7 +343,7 @@ func vulnCallGraphSlice(sources []*ssa.Function, modVulns moduleVulnerabilities,
 
 	// Transform the resulting call graph slice into
 	// vulncheck representation and store it to result.
-	vulnCallGraph(filteredSources, filteredSinks, result, graph)
+	vulnCallGraph(cg, assumed, filteredSources, filteredSinks, result, graph, namer)
 }
 
 // callGraphSlice computes a slice of callgraph beginning at starts
@@ -241,8 +386,11 @@ func callGraphSlice(starts []*callgraph.Node, forward bool) *callgraph.Graph {
 }
 
 // vulnCallGraph creates vulnerability call graph from sources -> sinks reachability info.
-func vulnCallGraph(sources []*callgraph.Node, sinks map[*callgraph.Node][]*osv.Entry, result *Result, graph *PackageGraph) {
+// The candidate callees of unresolved call sites are those of cg, the full
+// call graph, whose assumed edges are labeled as such.
+func vulnCallGraph(cg *callgraph.Graph, assumed map[callEdge]bool, sources []*callgraph.Node, sinks map[*callgraph.Node][]*osv.Entry, result *Result, graph *PackageGraph, namer *symbolNamer) {
 	nodes := make(map[*ssa.Function]*FuncNode)
+	candidates := make(map[ssa.CallInstruction][]*FuncNode)
 
 	// First create entries and sinks and store relevant information.
 	for _, s := range sources {
@@ -251,13 +399,15 @@ func vulnCallGraph(sources []*callgraph.Node, sinks map[*callgraph.Node][]*osv.E
 	}
 
 	for s, vulns := range sinks {
-		f := s.Func
 		funNode := createNode(nodes, s.Func, graph)
 
 		// Populate CallSink field for each detected vuln symbol.
+		symbols := namer.names(s.Func)
 		for _, osv := range vulns {
 			if vulnMatchesPackage(osv, funNode.Package.PkgPath) {
-				addCallSinkForVuln(funNode, osv, dbFuncName(f), funNode.Package.PkgPath, result)
+				for _, symbol := range symbols {
+					addCallSinkForVuln(funNode, osv, symbol, funNode.Package.PkgPath, result)
+				}
 			}
 		}
 	}
@@ -277,10 +427,27 @@ func vulnCallGraph(sources []*callgraph.Node, sinks map[*callgraph.Node][]*osv.E
 			call := edge.Site
 			cs := &CallSite{
 				Parent:   nCaller,
-				Name:     call.Common().Value.Name(),
-				RecvType: callRecvType(call),
 				Resolved: resolved(call),
-				Pos:      instrPosition(call),
+			}
+			if call != nil {
+				cs.Name = call.Common().Value.Name()
+				cs.RecvType = callRecvType(call)
+				cs.Pos = instrPosition(call)
+				if assumed[callEdge{call, edge.Callee.Func}] {
+					// The assumed calls of C functions are callbacks
+					// into Go, and the others go through dispatch tables.
+					if isCgoCall(call) {
+						cs.Callback = true
+					} else {
+						cs.Assumed = true
+					}
+				}
+				if !cs.Resolved {
+					cs.Candidates = callCandidates(cg, call, nodes, candidates, graph)
+				}
+			} else {
+				// Calls made from assembly have no call site.
+				cs.Name = edge.Callee.Func.Name()
 			}
 			nCallee.CallSites = append(nCallee.CallSites, cs)
 
@@ -293,11 +460,45 @@ func vulnCallGraph(sources []*callgraph.Node, sinks map[*callgraph.Node][]*osv.E
 	}
 }
 
+// callCandidates returns the callees of call in cg, which are cached in
+// candidates.
+func callCandidates(cg *callgraph.Graph, call ssa.CallInstruction, nodes map[*ssa.Function]*FuncNode, candidates map[ssa.CallInstruction][]*FuncNode, graph *PackageGraph) []*FuncNode {
+	if fns, ok := candidates[call]; ok {
+		return fns
+	}
+	var fns []*FuncNode
+	if n := cg.Nodes[call.Parent()]; n != nil {
+		seen := make(map[*ssa.Function]bool)
+		for _, edge := range n.Out {
+			if edge.Site == call && !seen[edge.Callee.Func] {
+				seen[edge.Callee.Func] = true
+				fns = append(fns, createNode(nodes, edge.Callee.Func, graph))
+			}
+		}
+	}
+	candidates[call] = fns
+	return fns
+}
+
 // vulnFuncs returns vulnerability information for vulnerable functions in cg.
-func vulnFuncs(cg *callgraph.Graph, modVulns moduleVulnerabilities) map[*callgraph.Node][]*osv.Entry {
+// A method is also vulnerable if a vulnerability lists a method promoted
+// from it to another type of its package.
+func vulnFuncs(cg *callgraph.Graph, modVulns moduleVulnerabilities, namer *symbolNamer) map[*callgraph.Node][]*osv.Entry {
 	m := make(map[*callgraph.Node][]*osv.Entry)
 	for f, n := range cg.Nodes {
-		vulns := modVulns.vulnsForSymbol(pkgPath(f), dbFuncName(f))
+		if modVulns.vulnsForPackage(pkgPath(f)) == nil {
+			continue
+		}
+		var vulns []*osv.Entry
+		seen := make(map[*osv.Entry]bool)
+		for _, symbol := range namer.names(f) {
+			for _, v := range modVulns.vulnsForSymbol(pkgPath(f), symbol) {
+				if !seen[v] {
+					seen[v] = true
+					vulns = append(vulns, v)
+				}
+			}
+		}
 		if len(vulns) > 0 {
 			m[n] = vulns
 		}
@@ -371,3 +572,61 @@ func extractModules(pkgs []*packages.Package) []*packages.Module {
 	}
 	return modules
 }
+
+// modulesWithinDepth returns the modules in mods that are at most depth
+// module dependencies away from the modules of pkgs. The distance of a
+// module is the smallest number of imports crossing a module boundary
+// along an import chain from pkgs to a package of the module. The
+// standard library is always included.
+func modulesWithinDepth(pkgs []*packages.Package, mods []*packages.Module, depth int) []*packages.Module {
+	// Compute package distances with a 0-1 breadth-first search, where
+	// imports within a module have weight 0 and imports of a package in
+	// another module have weight 1.
+	dist := map[*packages.Package]int{}
+	var queue []*packages.Package
+	for _, p := range pkgs {
+		dist[p] = 0
+		queue = append(queue, p)
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, imp := range p.Imports {
+			d := dist[p]
+			if !sameModule(p.Module, imp.Module) {
+				d++
+			}
+			if old, ok := dist[imp]; ok && old <= d {
+				continue
+			}
+			dist[imp] = d
+			if d == dist[p] {
+				queue = append([]*packages.Package{imp}, queue...)
+			} else {
+				queue = append(queue, imp)
+			}
+		}
+	}
+
+	keep := map[*packages.Module]bool{}
+	for p, d := range dist {
+		if p.Module != nil && d <= depth {
+			keep[p.Module] = true
+		}
+	}
+	var result []*packages.Module
+	for _, m := range mods {
+		if keep[m] || m.Path == internal.GoStdModulePath {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// sameModule reports whether m1 and m2 are the same module.
+func sameModule(m1, m2 *packages.Module) bool {
+	if m1 == nil || m2 == nil {
+		return m1 == m2
+	}
+	return m1.Path == m2.Path
+}
@@ -6,11 +6,16 @@ package vulncheck
 
 import (
 	"context"
+	"os/exec"
 	"path"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/packages/packagestest"
+	"golang.org/x/vuln/internal"
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/osv"
@@ -531,3 +536,897 @@ func TestIssue57174(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestModulesWithinDepth(t *testing.T) {
+	// Import graph:
+	//
+	//	main/a -> main/b -> direct/p -> deep/q -> deeper/r
+	//	             \-> fmt          \-> direct/s
+	//
+	// direct/s is in module direct, so module direct is reached
+	// again through deep/q, but its distance remains 1.
+	mainMod := &packages.Module{Path: "main", Main: true}
+	direct := &packages.Module{Path: "direct"}
+	deep := &packages.Module{Path: "deep"}
+	deeper := &packages.Module{Path: "deeper"}
+	stdlib := &packages.Module{Path: internal.GoStdModulePath}
+
+	pkg := func(path string, m *packages.Module, imports ...*packages.Package) *packages.Package {
+		p := &packages.Package{PkgPath: path, Module: m, Imports: map[string]*packages.Package{}}
+		for _, imp := range imports {
+			p.Imports[imp.PkgPath] = imp
+		}
+		return p
+	}
+	r := pkg("deeper/r", deeper)
+	s := pkg("direct/s", direct)
+	q := pkg("deep/q", deep, r, s)
+	p := pkg("direct/p", direct, q)
+	fmtPkg := pkg("fmt", stdlib)
+	b := pkg("main/b", mainMod, p, fmtPkg)
+	a := pkg("main/a", mainMod, b)
+
+	mods := []*packages.Module{mainMod, direct, deep, deeper, stdlib}
+	for _, test := range []struct {
+		depth int
+		want  []string
+	}{
+		{0, []string{"main", "stdlib"}},
+		{1, []string{"main", "direct", "stdlib"}},
+		{2, []string{"main", "direct", "deep", "stdlib"}},
+		{3, []string{"main", "direct", "deep", "deeper", "stdlib"}},
+	} {
+		var got []string
+		for _, m := range modulesWithinDepth([]*packages.Package{a}, mods, test.depth) {
+			got = append(got, m.Path)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("depth %d: got %v; want %v", test.depth, got, test.want)
+		}
+	}
+}
+
+func TestCgoCallback(t *testing.T) {
+	if out, err := exec.Command("go", "env", "CGO_ENABLED").Output(); err != nil || strings.TrimSpace(string(out)) != "1" {
+		t.Skip("cgo is not enabled")
+	}
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			/*
+			#include <stdlib.h>
+
+			extern void goVuln(void);
+			extern void goOther(void);
+
+			static void callback(void) { goVuln(); }
+			static void helper(void) {
+				// goOther();
+			}
+			*/
+			import "C"
+
+			import "golang.org/bmod/bvuln"
+
+			func X() {
+				C.callback()
+			}
+
+			func Y() {
+				C.helper()
+			}
+
+			func Z() {
+				C.abs(1)
+			}
+
+			//export goVuln
+			func goVuln() {
+				bvuln.Vuln()
+			}
+
+			//export goOther
+			func goOther() {
+				bvuln.Vuln()
+			}
+			`,
+			},
+		},
+		{
+			Name: "golang.org/bmod@v0.5.0",
+			Files: map[string]interface{}{"bvuln/bvuln.go": `
+			package bvuln
+
+			func Vuln() {}
+			`},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &govulncheck.Config{ScanLevel: "symbol"}
+	result, err := Source(context.Background(), pkgs, cfg, c, graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The calls of the vulnerable symbol from the Go functions
+	// exported to C are reachable through the calls of the C functions
+	// that call them back. C.helper calls back neither, and C.abs, which
+	// is not defined in the package, is assumed to call back both.
+	wantCalls := map[string][]string{
+		"golang.org/entry/x.X":       {"golang.org/entry/x.goVuln"},
+		"golang.org/entry/x.Z":       {"golang.org/entry/x.goOther", "golang.org/entry/x.goVuln"},
+		"golang.org/entry/x.goOther": {"golang.org/bmod/bvuln.Vuln"},
+		"golang.org/entry/x.goVuln":  {"golang.org/bmod/bvuln.Vuln"},
+	}
+	if callStrMap := callGraphToStrMap(result); !reflect.DeepEqual(wantCalls, callStrMap) {
+		t.Errorf("want %v call graph; got %v", wantCalls, callStrMap)
+	}
+	for _, v := range result.Vulns {
+		for _, export := range v.CallSink.CallSites {
+			for _, cs := range export.Parent.CallSites {
+				if want := cs.Parent.Name == "Z"; cs.Callback != want {
+					t.Errorf("call of %s from %s: got Callback %t, want %t", export.Parent.Name, cs.Parent.Name, cs.Callback, want)
+				}
+			}
+		}
+	}
+}
+
+// TestGoAndDefer checks that vulnerable symbols called by go and defer
+// statements, directly or through functions and closures they launch, are
+// reachable through resolved call sites.
+func TestGoAndDefer(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			import "golang.org/bmod/bvuln"
+
+			func X() {
+				go bvuln.Vuln()
+			}
+
+			func W() {
+				defer bvuln.Vuln()
+			}
+
+			func Y() {
+				go worker()
+			}
+
+			func worker() {
+				bvuln.Vuln()
+			}
+
+			func Z() {
+				defer func() {
+					bvuln.Vuln()
+				}()
+			}
+			`,
+			},
+		},
+		{
+			Name: "golang.org/bmod@v0.5.0",
+			Files: map[string]interface{}{"bvuln/bvuln.go": `
+			package bvuln
+
+			func Vuln() {}
+			`},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &govulncheck.Config{ScanLevel: "symbol"}
+	result, err := Source(context.Background(), pkgs, cfg, c, graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCalls := map[string][]string{
+		"golang.org/entry/x.W":      {"golang.org/bmod/bvuln.Vuln"},
+		"golang.org/entry/x.X":      {"golang.org/bmod/bvuln.Vuln"},
+		"golang.org/entry/x.Y":      {"golang.org/entry/x.worker"},
+		"golang.org/entry/x.Z":      {"golang.org/entry/x.Z$1"},
+		"golang.org/entry/x.Z$1":    {"golang.org/bmod/bvuln.Vuln"},
+		"golang.org/entry/x.worker": {"golang.org/bmod/bvuln.Vuln"},
+	}
+	if callStrMap := callGraphToStrMap(result); !reflect.DeepEqual(wantCalls, callStrMap) {
+		t.Errorf("want %v call graph; got %v", wantCalls, callStrMap)
+	}
+	for _, v := range result.Vulns {
+		var visit func(*FuncNode)
+		seen := map[*FuncNode]bool{}
+		visit = func(f *FuncNode) {
+			if seen[f] {
+				return
+			}
+			seen[f] = true
+			for _, cs := range f.CallSites {
+				if !cs.Resolved {
+					t.Errorf("call of %s in %s is not resolved", f, cs.Parent)
+				}
+				visit(cs.Parent)
+			}
+		}
+		visit(v.CallSink)
+	}
+}
+
+// TestDeadCode checks that vulnerable symbols that are only called in
+// blocks guarded by constant false conditions are not reachable, while
+// calls guarded by constant true conditions are.
+func TestDeadCode(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			import "golang.org/bmod/bvuln"
+
+			const debug = false
+
+			func X(b bool) {
+				if debug {
+					bvuln.Vuln()
+				}
+				if debug && b {
+					trace()
+				}
+				switch {
+				case debug:
+					bvuln.Vuln()
+				}
+			}
+
+			func trace() {
+				bvuln.Vuln()
+			}
+
+			func Y() {
+				if !debug {
+					bvuln.Vuln()
+				}
+			}
+			`,
+			},
+		},
+		{
+			Name: "golang.org/bmod@v0.5.0",
+			Files: map[string]interface{}{"bvuln/bvuln.go": `
+			package bvuln
+
+			func Vuln() {}
+			`},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &govulncheck.Config{ScanLevel: "symbol"}
+	result, err := Source(context.Background(), pkgs, cfg, c, graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCalls := map[string][]string{
+		"golang.org/entry/x.Y": {"golang.org/bmod/bvuln.Vuln"},
+	}
+	if callStrMap := callGraphToStrMap(result); !reflect.DeepEqual(wantCalls, callStrMap) {
+		t.Errorf("want %v call graph; got %v", wantCalls, callStrMap)
+	}
+}
+
+// TestAllEntryFunctions checks that, with Config.AllEntryFunctions, all
+// the entry functions are reported even if no vulnerable package is
+// imported.
+func TestAllEntryFunctions(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			type T struct{}
+
+			func (T) M() {}
+
+			func X() {}
+
+			func helper() {}
+			`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &govulncheck.Config{ScanLevel: "symbol", AllEntryFunctions: true}
+	result, err := Source(context.Background(), pkgs, cfg, c, graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, fn := range result.AllEntryFunctions {
+		got = append(got, fn.String())
+	}
+	sort.Strings(got)
+	want := []string{"golang.org/entry/x.T.M", "golang.org/entry/x.X", "golang.org/entry/x.init"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("got entry functions %v; want %v", got, want)
+	}
+	if len(result.EntryFunctions) != 0 {
+		t.Errorf("got %d entry functions reaching vulnerabilities; want none", len(result.EntryFunctions))
+	}
+}
+
+// TestDispatchTables checks that, with Config.DispatchTables, a vulnerable
+// function stored in a map is assumed to be called by a dynamic call of the
+// same signature, here through reflection, and that the call is labeled as
+// assumed.
+func TestDispatchTables(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			import (
+				"reflect"
+
+				"golang.org/bmod/bvuln"
+			)
+
+			var handlers = map[string]func(){}
+
+			func register() {
+				handlers["vuln"] = bvuln.Vuln
+			}
+
+			func X(name string) {
+				register()
+				h := reflect.ValueOf(handlers).MapIndex(reflect.ValueOf(name))
+				h.Interface().(func())()
+			}
+			`,
+			},
+		},
+		{
+			Name: "golang.org/bmod@v0.5.0",
+			Files: map[string]interface{}{"bvuln/bvuln.go": `
+			package bvuln
+
+			func Vuln() {}
+			`},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dispatch := range []bool{false, true} {
+		cfg := &govulncheck.Config{ScanLevel: "symbol", DispatchTables: dispatch}
+		result, err := Source(context.Background(), pkgs, cfg, c, graph)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sink *FuncNode
+		for _, v := range result.Vulns {
+			if v.Symbol == "Vuln" {
+				sink = v.CallSink
+			}
+		}
+		if !dispatch {
+			if sink != nil {
+				t.Errorf("Vuln is reached without -dispatch-tables")
+			}
+			continue
+		}
+		if sink == nil {
+			t.Fatalf("Vuln is not reached with -dispatch-tables")
+		}
+		var callers []string
+		for _, cs := range sink.CallSites {
+			callers = append(callers, cs.Parent.String())
+			if !cs.Assumed {
+				t.Errorf("call of Vuln from %s is not assumed", cs.Parent)
+			}
+		}
+		if want := []string{"golang.org/entry/x.X"}; !reflect.DeepEqual(callers, want) {
+			t.Errorf("want Vuln called from %v; got %v", want, callers)
+		}
+	}
+}
+
+// TestAssembly checks that vulnerable functions implemented in assembly
+// are reached through their Go declarations, and that Go functions
+// called from assembly are reached through the assembly functions.
+func TestAssembly(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			import "golang.org/bmod/bvuln"
+
+			func X() {
+				bvuln.Asm()
+			}
+
+			var f = bvuln.Vuln
+
+			func Y() {
+				f()
+			}
+			`,
+			},
+		},
+		{
+			Name: "golang.org/bmod@v0.5.0",
+			Files: map[string]interface{}{
+				"bvuln/bvuln.go": `
+			package bvuln
+
+			// Vuln is implemented in assembly.
+			func Vuln()
+
+			// Asm is implemented in assembly.
+			func Asm()
+
+			func vuln() {}
+			`,
+				"bvuln/bvuln.s": `
+			#include "textflag.h"
+
+			TEXT ·Asm(SB),NOSPLIT,$0-0
+				JMP asmHelper<>(SB)
+
+			TEXT asmHelper<>(SB),NOSPLIT,$0-0
+				CALL ·Vuln(SB)
+				RET
+
+			TEXT ·Vuln(SB),NOSPLIT,$0-0
+				CALL ·vuln<ABIInternal>(SB)
+				RET
+			`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &govulncheck.Config{ScanLevel: "symbol"}
+	result, err := Source(context.Background(), pkgs, cfg, c, graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCalls := map[string][]string{
+		"golang.org/entry/x.X":      {"golang.org/bmod/bvuln.Asm"},
+		"golang.org/entry/x.Y":      {"golang.org/bmod/bvuln.Vuln"},
+		"golang.org/bmod/bvuln.Asm": {"golang.org/bmod/bvuln.Vuln"},
+	}
+	if callStrMap := callGraphToStrMap(result); !reflect.DeepEqual(wantCalls, callStrMap) {
+		t.Errorf("want %v call graph; got %v", wantCalls, callStrMap)
+	}
+}
+
+// TestEntryFilter checks that only the packages accepted by the entry
+// filter are entry points, and that vulnerabilities of the other packages
+// are only reported if they are reachable from the entry points.
+func TestEntryFilter(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			import "golang.org/bmod/bvuln"
+
+			func X() {
+				bvuln.Vuln()
+			}
+
+			func Safe() {}
+			`,
+				"y/y.go": `
+			package y
+
+			import "golang.org/entry/x"
+
+			func Y() {
+				x.Safe()
+			}
+			`,
+			},
+		},
+		{
+			Name: "golang.org/bmod@v0.5.0",
+			Files: map[string]interface{}{"bvuln/bvuln.go": `
+			package bvuln
+
+			func Vuln() {}
+			`},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x"), path.Join(e.Temp(), "entry/y")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name        string
+		filter      EntryFilter
+		wantEntries []string
+		wantCalled  bool
+	}{
+		{"all", nil, []string{"golang.org/entry/x.X"}, true},
+		{"x", func(p string) bool { return p == "golang.org/entry/x" }, []string{"golang.org/entry/x.X"}, true},
+		// y imports the vulnerable package through x, but only calls
+		// a function of x that does not lead to the vulnerable symbol.
+		{"y", func(p string) bool { return p == "golang.org/entry/y" }, nil, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &govulncheck.Config{ScanLevel: "symbol"}
+			result, err := SourceWithEntryFilter(context.Background(), pkgs, cfg, c, graph, test.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotEntries []string
+			for _, f := range result.EntryFunctions {
+				gotEntries = append(gotEntries, f.String())
+			}
+			sort.Strings(gotEntries)
+			if !reflect.DeepEqual(gotEntries, test.wantEntries) {
+				t.Errorf("got entry functions %v; want %v", gotEntries, test.wantEntries)
+			}
+			gotCalled := false
+			for _, v := range result.Vulns {
+				if v.Symbol == "Vuln" && v.CallSink != nil {
+					gotCalled = true
+				}
+			}
+			if gotCalled != test.wantCalled {
+				t.Errorf("got bvuln.Vuln called %t; want %t", gotCalled, test.wantCalled)
+			}
+		})
+	}
+}
+
+func TestEntryFunctions(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			import "golang.org/bmod/bvuln"
+
+			func handleRequest() {
+				bvuln.Vuln()
+			}
+
+			func Safe() {}
+
+			type S struct{}
+
+			func (*S) Serve() {
+				handleRequest()
+			}
+			`,
+			},
+		},
+		{
+			Name: "golang.org/bmod@v0.5.0",
+			Files: map[string]interface{}{"bvuln/bvuln.go": `
+			package bvuln
+
+			func Vuln() {}
+			`},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newTestClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name        string
+		entries     []EntryFunction
+		wantEntries []string
+		wantCalled  bool
+		wantErr     string
+	}{
+		{"unexported", []EntryFunction{{"golang.org/entry/x", "handleRequest"}}, []string{"golang.org/entry/x.handleRequest"}, true, ""},
+		{"method", []EntryFunction{{"golang.org/entry/x", "S.Serve"}}, []string{"*golang.org/entry/x.S.Serve"}, true, ""},
+		{"safe", []EntryFunction{{"golang.org/entry/x", "Safe"}}, nil, false, ""},
+		{"no function", []EntryFunction{{"golang.org/entry/x", "Missing"}}, nil, false,
+			"entry function golang.org/entry/x.Missing: no function or method Missing in package golang.org/entry/x"},
+		{"no package", []EntryFunction{{"golang.org/bmod/bvuln", "Vuln"}}, nil, false,
+			"entry function golang.org/bmod/bvuln.Vuln: package golang.org/bmod/bvuln is not among the analyzed packages"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &govulncheck.Config{ScanLevel: "symbol"}
+			result, err := SourceWithEntryFunctions(context.Background(), pkgs, cfg, c, graph, test.entries)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("got error %v; want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotEntries []string
+			for _, f := range result.EntryFunctions {
+				gotEntries = append(gotEntries, f.String())
+			}
+			if !reflect.DeepEqual(gotEntries, test.wantEntries) {
+				t.Errorf("got entry functions %v; want %v", gotEntries, test.wantEntries)
+			}
+			gotCalled := false
+			for _, v := range result.Vulns {
+				if v.Symbol == "Vuln" && v.CallSink != nil {
+					gotCalled = true
+				}
+			}
+			if gotCalled != test.wantCalled {
+				t.Errorf("got bvuln.Vuln called %t; want %t", gotCalled, test.wantCalled)
+			}
+		})
+	}
+}
+
+// TestPromotedMethods checks that calls of vulnerable methods promoted
+// from embedded types, at one or more levels of embedding, are attributed
+// to the vulnerable methods.
+func TestPromotedMethods(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			import "golang.org/vmod/vuln"
+
+			type Outer struct{ vuln.Data }
+
+			type Mid struct{ *vuln.Data }
+
+			type Outer2 struct{ Mid }
+
+			type Ier interface{ I() }
+
+			func X() {
+				Outer{}.V()
+			}
+
+			func Y() {
+				o := Outer2{Mid{&vuln.Data{}}}
+				o.P()
+			}
+
+			func Z() {
+				var i Ier = Outer{}
+				i.I()
+			}
+
+			func W() {
+				var i Ier = &Outer2{}
+				i.I()
+			}
+
+			func MV() {
+				f := Outer{}.V
+				f()
+			}
+
+			func ME() {
+				f := (*Outer2).P
+				f(&Outer2{})
+			}
+
+			type Outer3 struct{ Ier }
+
+			func EI() {
+				o := Outer3{vuln.Data{}}
+				o.I()
+			}
+
+			type G[T any] struct {
+				vuln.Data
+				t T
+			}
+
+			func GE() {
+				var g G[int]
+				g.V()
+			}
+
+			func PO() {
+				vuln.Public{}.M()
+			}
+
+			func PP() {
+				var p vuln.Public2
+				p.N()
+			}
+			`,
+			},
+		},
+		{
+			Name: "golang.org/vmod@v1.2.3",
+			Files: map[string]interface{}{"vuln/vuln.go": `
+			package vuln
+
+			type Data struct{}
+
+			func (Data) V() {}
+
+			func (*Data) P() {}
+
+			func (Data) I() {}
+
+			type inner struct{}
+
+			func (inner) M() {}
+
+			type Public struct{ inner }
+
+			type inner2 struct{ *inner3 }
+
+			type inner3 struct{}
+
+			func (*inner3) N() {}
+
+			type Public2 struct{ inner2 }
+			`},
+		},
+	})
+	defer e.Cleanup()
+
+	c, err := client.NewInMemoryClient([]*osv.Entry{{
+		ID: "V",
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: "golang.org/vmod"},
+			Ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "1.2.0"}}}},
+			EcosystemSpecific: osv.EcosystemSpecific{Packages: []osv.Package{{
+				Path:    "golang.org/vmod/vuln",
+				Symbols: []string{"Data.V", "Data.P", "Data.I", "Public.M", "Public2.N"},
+			}}},
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &govulncheck.Config{ScanLevel: "symbol"}
+	result, err := Source(context.Background(), pkgs, cfg, c, graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCalls := map[string][]string{
+		"golang.org/entry/x.X":  {"golang.org/vmod/vuln.Data.V"},
+		"golang.org/entry/x.Y":  {"*golang.org/vmod/vuln.Data.P"},
+		"golang.org/entry/x.Z":  {"golang.org/vmod/vuln.Data.I"},
+		"golang.org/entry/x.W":  {"golang.org/vmod/vuln.Data.I"},
+		"golang.org/entry/x.MV": {"golang.org/vmod/vuln.Data.V"},
+		"golang.org/entry/x.ME": {"*golang.org/vmod/vuln.Data.P"},
+		"golang.org/entry/x.EI": {"golang.org/vmod/vuln.Data.I"},
+		"golang.org/entry/x.GE": {"golang.org/vmod/vuln.Data.V"},
+		"golang.org/entry/x.PO": {"golang.org/vmod/vuln.inner.M"},
+		"golang.org/entry/x.PP": {"*golang.org/vmod/vuln.inner3.N"},
+	}
+	if callStrMap := callGraphToStrMap(result); !reflect.DeepEqual(wantCalls, callStrMap) {
+		t.Errorf("want %v call graph; got %v", wantCalls, callStrMap)
+	}
+
+	// Symbols promoted within the vulnerable package are called
+	// through the methods they are promoted from.
+	for _, v := range result.Vulns {
+		if v.CallSink == nil {
+			t.Errorf("vulnerable symbol %s is not called", v.Symbol)
+		}
+	}
+}
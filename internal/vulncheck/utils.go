@@ -7,9 +7,12 @@ package vulncheck
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"go/token"
 	"go/types"
+	"sort"
 	"strings"
+	"sync"
 
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/callgraph/cha"
@@ -25,7 +28,14 @@ import (
 // buildSSA creates an ssa representation for pkgs. Returns
 // the ssa program encapsulating the packages and top level
 // ssa packages corresponding to pkgs.
-func buildSSA(pkgs []*packages.Package, fset *token.FileSet) (*ssa.Program, []*ssa.Package) {
+//
+// Packages whose ssa construction panics, for instance because
+// they are malformed, are reported as diagnostics and the rest
+// of the program is still built.
+//
+// If sequential is set, the packages are built one at a time,
+// in the order of their paths.
+func buildSSA(pkgs []*packages.Package, fset *token.FileSet, sequential bool) (*ssa.Program, []*ssa.Package, []*Diagnostic) {
 	// TODO(https://go.dev/issue/57221): what about entry functions that are generics?
 	prog := ssa.NewProgram(fset, ssa.InstantiateGenerics)
 
@@ -54,43 +64,204 @@ func buildSSA(pkgs []*packages.Package, fset *token.FileSet) (*ssa.Program, []*s
 			ssaPkgs = append(ssaPkgs, sp)
 		}
 	}
-	prog.Build()
-	return prog, ssaPkgs
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex // guards diags
+		diags []*Diagnostic
+	)
+	if sequential {
+		all := prog.AllPackages()
+		sort.Slice(all, func(i, j int) bool { return all[i].Pkg.Path() < all[j].Pkg.Path() })
+		for _, p := range all {
+			if d := buildPackage(p); d != nil {
+				diags = append(diags, d)
+			}
+		}
+		return prog, ssaPkgs, diags
+	}
+	// Build the packages in parallel, like prog.Build does.
+	for _, p := range prog.AllPackages() {
+		wg.Add(1)
+		go func(p *ssa.Package) {
+			defer wg.Done()
+			if d := buildPackage(p); d != nil {
+				mu.Lock()
+				diags = append(diags, d)
+				mu.Unlock()
+			}
+		}(p)
+	}
+	wg.Wait()
+	sort.Slice(diags, func(i, j int) bool { return diags[i].PkgPath < diags[j].PkgPath })
+	return prog, ssaPkgs, diags
+}
+
+// buildPackage builds the ssa code of p. A panic while building is
+// recovered and returned as a diagnostic naming the package.
+func buildPackage(p *ssa.Package) (d *Diagnostic) {
+	defer func() {
+		if r := recover(); r != nil {
+			d = &Diagnostic{PkgPath: p.Pkg.Path(), Message: fmt.Sprintf("building ssa: %v", r)}
+		}
+	}()
+	p.Build()
+	return nil
 }
 
 // callGraph builds a call graph of prog based on VTA analysis.
-func callGraph(ctx context.Context, prog *ssa.Program, entries []*ssa.Function) (*callgraph.Graph, error) {
+// The calls made from assembly, as returned by asmCalls, and the calls
+// back into Go from C, according to cgo as returned by cgoCalls, are
+// added to the resulting graph. If dispatch is set, the calls through
+// dispatch tables are also added. The callbacks from C functions of
+// unknown source and the calls through dispatch tables are returned as
+// assumed edges.
+func callGraph(ctx context.Context, prog *ssa.Program, entries []*ssa.Function, asm, cgo map[string]map[string][]string, dispatch bool) (*callgraph.Graph, map[callEdge]bool, error) {
 	entrySlice := make(map[*ssa.Function]bool)
 	for _, e := range entries {
 		entrySlice[e] = true
 	}
+	// Go functions exported to C can be called back from any C
+	// function of their package, which static analysis cannot see.
+	// Keep them in the slice so that cgoCallbackEdges can connect
+	// them once the call graph is built.
+	allFuncs := ssautil.AllFunctions(prog)
+	for f := range allFuncs {
+		if isCgoExport(f) {
+			entrySlice[f] = true
+		}
+	}
+	// Likewise, calls from assembly into Go are not visible in ssa.
+	for _, f := range asmEntries(prog, asm) {
+		entrySlice[f] = true
+	}
 
 	if err := ctx.Err(); err != nil { // cancelled?
-		return nil, err
+		return nil, nil, err
 	}
 	initial := cha.CallGraph(prog)
-	allFuncs := ssautil.AllFunctions(prog)
+	deleteDeadEdges(initial)
 
 	fslice := forwardSlice(entrySlice, initial)
 	// Keep only actually linked functions.
 	pruneSet(fslice, allFuncs)
 
 	if err := ctx.Err(); err != nil { // cancelled?
-		return nil, err
+		return nil, nil, err
 	}
 	vtaCg := vta.CallGraph(fslice, initial)
+	deleteDeadEdges(vtaCg)
 
 	// Repeat the process once more, this time using
 	// the produced VTA call graph as the base graph.
 	fslice = forwardSlice(entrySlice, vtaCg)
 	pruneSet(fslice, allFuncs)
+	if dispatch {
+		// Likewise, the functions stored in dispatch tables may only be
+		// called in ways the analysis cannot follow. Keep them in the
+		// slice so that dispatchTableEdges can connect them.
+		for _, f := range storedFuncs(fslice) {
+			entrySlice[f] = true
+		}
+		fslice = forwardSlice(entrySlice, vtaCg)
+		pruneSet(fslice, allFuncs)
+	}
 
 	if err := ctx.Err(); err != nil { // cancelled?
-		return nil, err
+		return nil, nil, err
 	}
 	cg := vta.CallGraph(fslice, vtaCg)
+	deleteDeadEdges(cg)
 	cg.DeleteSyntheticNodes()
-	return cg, nil
+	assumed := cgoCallbackEdges(cg, cgo)
+	asmCallEdges(cg, prog, asm)
+	if dispatch {
+		for e := range dispatchTableEdges(cg) {
+			assumed[e] = true
+		}
+	}
+	return cg, assumed, nil
+}
+
+// isCgoExport reports whether f is a wrapper generated by cgo for
+// a Go function exported to C with an //export directive.
+func isCgoExport(f *ssa.Function) bool {
+	return f.Synthetic == "" && strings.HasPrefix(f.Name(), "_cgoexp_")
+}
+
+// isCgoCall reports whether call is a call of a C function.
+func isCgoCall(call ssa.CallInstruction) bool {
+	f := call.Common().StaticCallee()
+	return f != nil && strings.HasPrefix(f.Name(), "_Cfunc_")
+}
+
+// cgoCallbackEdges adds edges to cg for calls from Go into C that may
+// call back into Go, according to calls, as returned by cgoCalls. The C
+// side of a package is opaque to the call graph analysis, so each call of
+// a C function defined in the package is assumed to call the Go functions
+// exported to C that the function transitively mentions, which include
+// the ones it calls and the ones whose address it passes. A C function
+// that is not defined in the package, such as a function of a library, is
+// assumed to call every Go function that the package exports to C, which
+// over-approximates the calls, so cgoCallbackEdges returns these edges
+// for them to be labeled as assumed callbacks. The added edges use the
+// call site of the C function and lead directly to the exported Go
+// functions, skipping the wrappers generated by cgo.
+func cgoCallbackEdges(cg *callgraph.Graph, calls map[string]map[string][]string) map[callEdge]bool {
+	exports := make(map[*ssa.Package][]*callgraph.Node)
+	for f, n := range cg.Nodes {
+		if f == nil || !isCgoExport(f) {
+			continue
+		}
+		for _, e := range n.Out {
+			exports[f.Pkg] = append(exports[f.Pkg], e.Callee)
+		}
+	}
+	assumed := make(map[callEdge]bool)
+	if len(exports) == 0 {
+		return assumed
+	}
+	type cfunc struct {
+		node *callgraph.Node
+		name string
+	}
+	var cfuncs []cfunc
+	for f, n := range cg.Nodes {
+		if f == nil || !strings.HasPrefix(f.Name(), "_Cfunc_") || len(exports[f.Pkg]) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(f.Name(), "_Cfunc_")
+		if cgoHelpers[name] {
+			// The helpers of cgo convert values and do not call back.
+			continue
+		}
+		cfuncs = append(cfuncs, cfunc{n, name})
+	}
+	for _, c := range cfuncs {
+		pkg := c.node.Func.Pkg
+		mentioned, defined := cgoCallees(c.name, calls[pkg.Pkg.Path()])
+		for _, e := range c.node.In {
+			for _, callee := range exports[pkg] {
+				if defined && !mentioned[callee.Func.Name()] {
+					continue
+				}
+				callgraph.AddEdge(e.Caller, e.Site, callee)
+				if !defined && e.Site != nil {
+					assumed[callEdge{e.Site, callee.Func}] = true
+				}
+			}
+		}
+	}
+	return assumed
+}
+
+// cgoHelpers are the functions that cgo provides to convert between Go
+// and C values, which are called like C functions.
+var cgoHelpers = map[string]bool{
+	"CString":   true,
+	"CBytes":    true,
+	"GoString":  true,
+	"GoStringN": true,
+	"GoBytes":   true,
 }
 
 // dbTypeFormat formats the name of t according how types
@@ -165,6 +336,59 @@ func dbTypesFuncName(f *types.Func) string {
 	return dbTypeFormat(sig.Recv().Type()) + "." + f.Name()
 }
 
+// symbolNamer computes the names of functions as used in vulnerability
+// databases. A method promoted through embedding to another type of its
+// package can also be listed under the name of that type, such as
+// Public.M for a method M of an unexported type embedded in Public.
+type symbolNamer struct {
+	promoted map[*types.Package]map[*types.Func][]string
+}
+
+func newSymbolNamer() *symbolNamer {
+	return &symbolNamer{promoted: make(map[*types.Package]map[*types.Func][]string)}
+}
+
+// names returns dbFuncName(f) followed by the names of the methods
+// promoted from f to other named types of its package, if f is a method.
+func (n *symbolNamer) names(f *ssa.Function) []string {
+	names := []string{dbFuncName(f)}
+	obj, ok := f.Object().(*types.Func)
+	if !ok || obj.Pkg() == nil || f.Signature.Recv() == nil {
+		return names
+	}
+	promoted, ok := n.promoted[obj.Pkg()]
+	if !ok {
+		promoted = promotedMethods(obj.Pkg())
+		n.promoted[obj.Pkg()] = promoted
+	}
+	return append(names, promoted[obj]...)
+}
+
+// promotedMethods maps the methods promoted through embedded fields to
+// the named types of pkg to the names of the promoted methods, in the
+// format of dbFuncName.
+func promotedMethods(pkg *types.Package) map[*types.Func][]string {
+	promoted := make(map[*types.Func][]string)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		ms := types.NewMethodSet(types.NewPointer(tn.Type()))
+		for i := 0; i < ms.Len(); i++ {
+			sel := ms.At(i)
+			if len(sel.Index()) < 2 {
+				continue // declared by tn itself
+			}
+			if f, ok := sel.Obj().(*types.Func); ok {
+				promoted[f] = append(promoted[f], tn.Name()+"."+f.Name())
+			}
+		}
+	}
+	return promoted
+}
+
 // memberFuncs returns functions associated with the `member`:
 // 1) `member` itself if `member` is a function
 // 2) `member` methods if `member` is a type
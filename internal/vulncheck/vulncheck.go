@@ -7,11 +7,13 @@ package vulncheck
 import (
 	"fmt"
 	"go/token"
+	"runtime/debug"
 	"strings"
 	"time"
 
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/osv"
 	"golang.org/x/vuln/internal/semver"
 )
@@ -23,6 +25,11 @@ type Result struct {
 	// EntryFunctions are a subset of Functions representing vulncheck entry points.
 	EntryFunctions []*FuncNode
 
+	// AllEntryFunctions are all the entry points of the call graph, in
+	// order, including those that do not reach vulnerable symbols. It is
+	// only set in source mode with cfg.AllEntryFunctions.
+	AllEntryFunctions []*FuncNode
+
 	// EntryPackages are a subset of Packages representing packages of vulncheck entry points.
 	EntryPackages []*packages.Package
 
@@ -31,6 +38,82 @@ type Result struct {
 	// or whose packages are imported in Imports, or whose modules are required in
 	// Requires, have an entry in Vulns.
 	Vulns []*Vuln
+
+	// Diagnostics describes the problems analyzing specific packages
+	// that the analysis recovered from. Code that could not be analyzed
+	// is missing from the graphs, so vulnerabilities reachable through
+	// it may not be reported.
+	Diagnostics []*Diagnostic
+
+	// SkippedModules are the modules of the build that were not scanned
+	// because they are further away from the main module than
+	// cfg.ModuleDepth, in source mode.
+	SkippedModules []*packages.Module
+
+	// Requirements are the modules that go.mod files of main modules
+	// require at versions lower than the ones selected for the build,
+	// in source mode.
+	Requirements []*Requirement
+
+	// NotApplicable are the vulnerabilities of the scanned module versions
+	// that only affect other platforms than the GOOS and GOARCH of the
+	// scanned code, and are thus not analyzed further.
+	NotApplicable []*osv.Entry
+
+	// BuildInfo is the build information of the binary in binary mode.
+	// It is nil in source mode.
+	BuildInfo *debug.BuildInfo
+
+	// Stripped is set in binary mode if the binary has no symbol table,
+	// in which case Vulns are all the vulnerabilities of the modules of
+	// the binary instead of those of the symbols it contains.
+	Stripped bool
+
+	// MissingEntrySymbols are the cfg.EntrySymbols that are not functions
+	// of the binary, in binary mode. They reach no symbol.
+	MissingEntrySymbols []string
+
+	// Cancelled is set in source mode if the context was done before the
+	// call graph was built, in which case Vulns only tells which
+	// vulnerable packages are imported, and no symbol is reachable.
+	Cancelled bool
+
+	// Timings are the durations of the phases of the analysis, in
+	// source mode.
+	Timings Timings
+}
+
+// Timings are the durations of the phases of a source analysis.
+type Timings struct {
+	// Fetch is the time spent fetching vulnerabilities.
+	Fetch time.Duration
+
+	// CallGraph is the time spent building the SSA program and the call
+	// graph, concurrently with fetching vulnerabilities. It is 0 if no
+	// call graph was built.
+	CallGraph time.Duration
+
+	// Match is the time spent matching vulnerabilities against the
+	// imports and the call graph.
+	Match time.Duration
+
+	// CallStacks are the times spent searching for call stacks by
+	// StreamCallStacks, summed per vulnerable package.
+	CallStacks map[string]time.Duration
+}
+
+// Diagnostic describes a problem analyzing a package that did not stop
+// the analysis.
+type Diagnostic struct {
+	// PkgPath is the import path of the package.
+	PkgPath string
+
+	// Message describes the problem.
+	Message string
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.PkgPath, d.Message)
 }
 
 // Vuln provides information on how a vulnerability is affecting user code by
@@ -60,6 +143,19 @@ type Vuln struct {
 	// When analyzing binaries or PkgPath is not imported, ImportSink will be
 	// unavailable and set to 0.
 	ImportSink *packages.Package
+
+	// BinaryPos is the position of Symbol in the line table of the binary
+	// under analysis, if known. It is only set when analyzing binaries.
+	//
+	// If Inlined is set, Symbol only appears in the binary inlined into
+	// its callers, and BinaryPos is the logical source position of
+	// one of the calls to Symbol rather than the position of Symbol.
+	BinaryPos *token.Position
+	Inlined   bool
+
+	// Unreached is set when analyzing binaries with cfg.EntrySymbols if
+	// Symbol is in the binary, but is not reached from any of them.
+	Unreached bool
 }
 
 // A FuncNode describes a function in the call graph.
@@ -93,6 +189,48 @@ func (fn *FuncNode) Receiver() string {
 	return strings.Replace(fn.RecvType, fmt.Sprintf("%s.", fn.Package.PkgPath), "", 1)
 }
 
+// IsInit reports whether fn is a package initializer. A source init
+// function, or anonymous functions used in inits, are named "init#x" by
+// ssa, where x is a positive integer. Implicit inits are named "init".
+func (fn *FuncNode) IsInit() bool {
+	return fn.Name == "init" || strings.HasPrefix(fn.Name, "init#")
+}
+
+// Position returns the position of fn, or nil if it is unknown. Implicit
+// init functions have no position in the source, so the position of the
+// package statement of their package is returned instead.
+func (fn *FuncNode) Position() *token.Position {
+	if fn.Pos != nil && fn.Pos.IsValid() {
+		return fn.Pos
+	}
+	if fn.IsInit() && fn.Package != nil && len(fn.Package.Syntax) > 0 {
+		// Pick the first file since it is as good as any.
+		pos := fn.Package.Fset.Position(fn.Package.Syntax[0].Package)
+		return &pos
+	}
+	return nil
+}
+
+// Location returns fn and its position in the form
+// "pkg.Recv.Func at file:line:col". The position is omitted if it is
+// unknown. See govulncheck.Frame.Location.
+func (fn *FuncNode) Location() string {
+	fr := &govulncheck.Frame{Function: fn.Name}
+	if fn.Package != nil {
+		fr.Package = fn.Package.PkgPath
+		fr.Receiver = fn.Receiver()
+	}
+	if pos := fn.Position(); pos != nil {
+		fr.Position = &govulncheck.Position{
+			Filename: pos.Filename,
+			Offset:   pos.Offset,
+			Line:     pos.Line,
+			Column:   pos.Column,
+		}
+	}
+	return fr.Location()
+}
+
 // A CallSite describes a function call.
 type CallSite struct {
 	// Parent is the enclosing function where the call is made.
@@ -109,6 +247,22 @@ type CallSite struct {
 
 	// Resolved indicates if the called function can be statically resolved.
 	Resolved bool
+
+	// Candidates are the functions that the call may dispatch to, as
+	// determined by the call graph analysis, if the call cannot be
+	// statically resolved. They include the functions that do not lead
+	// to vulnerable symbols.
+	Candidates []*FuncNode
+
+	// Assumed indicates if the call is only assumed to be made, as the
+	// call of a function value that may come from a dispatch table. See
+	// Config.DispatchTables.
+	Assumed bool
+
+	// Callback indicates if the call is of a C function that is only
+	// assumed to call back into the callee, a Go function exported to C,
+	// as the C function is not defined in the package of the call.
+	Callback bool
 }
 
 // moduleVulnerabilities is an internal structure for
@@ -122,15 +276,27 @@ type ModVulns struct {
 	Vulns  []*osv.Entry
 }
 
-func (mv moduleVulnerabilities) filter(os, arch string) moduleVulnerabilities {
+// filter returns the vulnerabilities of mv that affect the versions of
+// their modules on the platform of os and arch, if not empty. If
+// stdVersions is not empty, the standard library is matched against
+// these semver versions instead of its version, and its vulnerabilities
+// are kept if they affect any of them.
+func (mv moduleVulnerabilities) filter(os, arch string, stdVersions []string) moduleVulnerabilities {
 	now := time.Now()
 	var filteredMod moduleVulnerabilities
 	for _, mod := range mv {
 		module := mod.Module
+		// Vulnerabilities of a module replaced by a different path are
+		// those of the original module, so they are checked against
+		// the version of the original module.
 		modVersion := module.Version
-		if module.Replace != nil {
+		if module.Replace != nil && !IsPathReplaced(module) {
 			modVersion = module.Replace.Version
 		}
+		versions := []string{modVersion}
+		if module.Path == internal.GoStdModulePath && len(stdVersions) > 0 {
+			versions = stdVersions
+		}
 		// TODO(https://golang.org/issues/49264): if modVersion == "", try vcs?
 		var filteredVulns []*osv.Entry
 		for _, v := range mod.Vulns {
@@ -154,12 +320,7 @@ func (mv moduleVulnerabilities) filter(os, arch string) moduleVulnerabilities {
 				// A module version is affected if
 				//  - it is included in one of the affected version ranges
 				//  - and module version is not ""
-				if modVersion == "" {
-					// Module version of "" means the module version is not available,
-					// and so we don't want to spam users with potential false alarms.
-					continue
-				}
-				if !semver.Affects(a.Ranges, modVersion) {
+				if !affectsAny(a.Ranges, versions) {
 					continue
 				}
 				var filteredImports []osv.Package
@@ -191,6 +352,80 @@ func (mv moduleVulnerabilities) filter(os, arch string) moduleVulnerabilities {
 	return filteredMod
 }
 
+// affectsAny reports whether the ranges affect any of versions. A module
+// version of "" means the module version is not available, and is not
+// considered affected so as not to spam users with potential false alarms.
+func affectsAny(ranges []osv.Range, versions []string) bool {
+	for _, v := range versions {
+		if v != "" && semver.Affects(ranges, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// notApplicable returns the vulnerabilities of all, the vulnerabilities
+// of mv filtered for all platforms, that are not in applicable, the
+// vulnerabilities of mv filtered for the platform of the scanned code.
+func notApplicable(all, applicable moduleVulnerabilities) []*osv.Entry {
+	kept := map[string]bool{}
+	for _, mod := range applicable {
+		for _, v := range mod.Vulns {
+			kept[v.ID] = true
+		}
+	}
+	var entries []*osv.Entry
+	for _, mod := range all {
+		for _, v := range mod.Vulns {
+			if !kept[v.ID] {
+				kept[v.ID] = true
+				entries = append(entries, v)
+			}
+		}
+	}
+	return entries
+}
+
+// only returns the vulnerabilities of mv that match ids, see MatchesOSVs.
+// It returns mv if ids is empty.
+func (mv moduleVulnerabilities) only(ids []string) moduleVulnerabilities {
+	if len(ids) == 0 {
+		return mv
+	}
+	var filteredMod moduleVulnerabilities
+	for _, mod := range mv {
+		var filteredVulns []*osv.Entry
+		for _, v := range mod.Vulns {
+			if MatchesOSVs(v, ids) {
+				filteredVulns = append(filteredVulns, v)
+			}
+		}
+		if len(filteredVulns) > 0 {
+			filteredMod = append(filteredMod, &ModVulns{
+				Module: mod.Module,
+				Vulns:  filteredVulns,
+			})
+		}
+	}
+	return filteredMod
+}
+
+// MatchesOSVs reports whether the ID or one of the aliases of entry is
+// in ids.
+func MatchesOSVs(entry *osv.Entry, ids []string) bool {
+	for _, id := range ids {
+		if id == entry.ID {
+			return true
+		}
+		for _, a := range entry.Aliases {
+			if id == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func matchesPlatform(os, arch string, e osv.Package) bool {
 	return matchesPlatformComponent(os, e.GOOS) &&
 		matchesPlatformComponent(arch, e.GOARCH)
@@ -233,9 +468,10 @@ func (mv moduleVulnerabilities) vulnsForPackage(importPath string) []*osv.Entry
 		return nil
 	}
 
+	replacedPath := importPath
 	if mostSpecificMod.Module.Replace != nil {
 		// standard libraries do not have a module nor replace module
-		importPath = fmt.Sprintf("%s%s", mostSpecificMod.Module.Replace.Path, strings.TrimPrefix(importPath, mostSpecificMod.Module.Path))
+		replacedPath = fmt.Sprintf("%s%s", mostSpecificMod.Module.Replace.Path, strings.TrimPrefix(importPath, mostSpecificMod.Module.Path))
 	}
 	vulns := mostSpecificMod.Vulns
 	packageVulns := []*osv.Entry{}
@@ -243,7 +479,9 @@ Vuln:
 	for _, v := range vulns {
 		for _, a := range v.Affected {
 			for _, p := range a.EcosystemSpecific.Packages {
-				if p.Path == importPath {
+				// Vulnerabilities of the original module of a replaced
+				// module refer to packages by their original path.
+				if p.Path == replacedPath || (p.Path == importPath && a.Module.Path == mostSpecificMod.Module.Path) {
 					packageVulns = append(packageVulns, v)
 					continue Vuln
 				}
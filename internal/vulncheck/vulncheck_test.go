@@ -5,6 +5,7 @@
 package vulncheck
 
 import (
+	"go/token"
 	"path"
 	"reflect"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/packages/packagestest"
+	"golang.org/x/vuln/internal"
 	"golang.org/x/vuln/internal/osv"
 )
 
@@ -205,10 +207,57 @@ func TestFilterVulns(t *testing.T) {
 		},
 	}
 
-	filtered := mv.filter("linux", "amd64")
+	filtered := mv.filter("linux", "amd64", nil)
 	if diff := diffModuleVulnerabilities(expected, filtered); diff != "" {
 		t.Fatalf("Filter returned unexpected results (-want,+got):\n%s", diff)
 	}
+
+	// The vulnerabilities only filtered out by the platform are not
+	// applicable.
+	var ids []string
+	for _, e := range notApplicable(mv.filter("", "", nil), filtered) {
+		ids = append(ids, e.ID)
+	}
+	if want := []string{"d", "e", "h"}; !cmp.Equal(want, ids) {
+		t.Errorf("notApplicable = %v; want %v", ids, want)
+	}
+}
+
+func TestFilterVulnsGoVersions(t *testing.T) {
+	mv := moduleVulnerabilities{
+		{
+			Module: &packages.Module{Path: internal.GoStdModulePath, Version: "v1.22.0"},
+			Vulns: []*osv.Entry{
+				{ID: "a", Affected: []osv.Affected{{
+					Module: osv.Module{Path: internal.GoStdModulePath},
+					Ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.21.5"}}}},
+				}}},
+				{ID: "b", Affected: []osv.Affected{{
+					Module: osv.Module{Path: internal.GoStdModulePath},
+					Ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "1.22.0"}, {Fixed: "1.22.1"}}}},
+				}}},
+				{ID: "c", Affected: []osv.Affected{{
+					Module: osv.Module{Path: internal.GoStdModulePath},
+					Ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.19.0"}}}},
+				}}},
+			},
+		},
+	}
+	ids := func(mv moduleVulnerabilities) []string {
+		var ids []string
+		for _, v := range mv[0].Vulns {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+	// Without versions, the standard library is matched against its version.
+	if got, want := ids(mv.filter("", "", nil)), []string{"b"}; !cmp.Equal(want, got) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	// Otherwise, a vulnerability is kept if it affects any of the versions.
+	if got, want := ids(mv.filter("", "", []string{"v1.20.3", "v1.22.2"})), []string{"a"}; !cmp.Equal(want, got) {
+		t.Errorf("got %v; want %v", got, want)
+	}
 }
 
 func diffModuleVulnerabilities(a, b moduleVulnerabilities) string {
@@ -523,3 +572,52 @@ func TestReceiver(t *testing.T) {
 		})
 	}
 }
+
+func TestFuncNodeLocation(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			type T struct{}
+
+			func (*T) M() {}
+			`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	pkgs, err := graph.LoadPackages(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := pkgs[0]
+	file := pkg.Fset.Position(pkg.Syntax[0].Package).Filename
+
+	for _, test := range []struct {
+		fn   *FuncNode
+		want string
+	}{
+		{
+			fn:   &FuncNode{Name: "M", RecvType: "*golang.org/entry/x.T", Package: pkg, Pos: &token.Position{Filename: file, Line: 6, Column: 14}},
+			want: "golang.org/entry/x.T.M at " + file + ":6:14",
+		},
+		{
+			// Implicit inits are at the package statement.
+			fn:   &FuncNode{Name: "init", Package: pkg, Pos: &token.Position{}},
+			want: "golang.org/entry/x.init at " + file + ":2:4",
+		},
+		{
+			fn:   &FuncNode{Name: "F", Package: pkg},
+			want: "golang.org/entry/x.F",
+		},
+	} {
+		if got := test.fn.Location(); got != test.want {
+			t.Errorf("Location() = %q; want %q", got, test.want)
+		}
+	}
+}
@@ -6,11 +6,13 @@ package vulncheck
 
 import (
 	"container/list"
+	"context"
 	"fmt"
 	"go/token"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 // CallStack is a call stack starting with a client
@@ -26,6 +28,11 @@ type StackEntry struct {
 	// Call is the call site inducing the next stack frame.
 	// nil when the frame represents the last frame in the stack.
 	Call *CallSite
+
+	// InlinedAt is the logical source position of a call to Function
+	// that the compiler inlined, for frames of binaries that only
+	// contain Function inlined into its callers. It is nil otherwise.
+	InlinedAt *token.Position
 }
 
 // CallStacks returns representative call stacks for each
@@ -40,32 +47,141 @@ type StackEntry struct {
 // each function is visited at most once to avoid potential
 // exponential explosion. Hence, not all call stacks are analyzed.
 func CallStacks(res *Result) map[*Vuln][]CallStack {
-	var (
-		wg sync.WaitGroup
-		mu sync.Mutex
-	)
+	return CallStacksWithEdges(res, nil)
+}
+
+// An EdgeFunc is called for an edge of the call graph, from caller
+// to callee, when the edge is explored by the call stack search.
+type EdgeFunc func(caller, callee *FuncNode)
+
+// CallStacksWithEdges is like CallStacks, but also calls onEdge, if it
+// is not nil, each time the search extends a call chain by a call from
+// caller to callee. Calls to onEdge are serialized but, since the
+// vulnerabilities are searched concurrently, edges explored for
+// different vulnerabilities are interleaved.
+func CallStacksWithEdges(res *Result, onEdge EdgeFunc) map[*Vuln][]CallStack {
 	stacksPerVuln := make(map[*Vuln][]CallStack)
-	for _, vuln := range res.Vulns {
-		vuln := vuln
-		wg.Add(1)
-		go func() {
-			cs := callStacks(vuln.CallSink, res)
-			// sort call stacks by the estimated value to the user
-			sort.SliceStable(cs, func(i int, j int) bool { return stackLess(cs[i], cs[j]) })
-			mu.Lock()
-			stacksPerVuln[vuln] = cs
-			mu.Unlock()
-			wg.Done()
-		}()
+	StreamCallStacks(context.Background(), res, onEdge, func(vuln *Vuln, cs []CallStack) error {
+		stacksPerVuln[vuln] = cs
+		return nil
+	})
+	return stacksPerVuln
+}
+
+// StreamCallStacks is like CallStacksWithEdges, but instead of returning
+// the call stacks, it calls onVuln with the call stacks of each
+// vulnerability of res.Vulns, in order, as soon as they and the call
+// stacks of the vulnerabilities before it are computed. Calls to onVuln
+// are serialized. If onVuln returns an error, it is not called again
+// and StreamCallStacks returns the error once the search is complete.
+// If ctx is done first, onVuln is not called again either, and
+// StreamCallStacks returns the error, or ctx.Err(), without waiting for
+// the searches in progress.
+func StreamCallStacks(ctx context.Context, res *Result, onEdge EdgeFunc, onVuln func(*Vuln, []CallStack) error) error {
+	return streamCallStacks(ctx, res, onEdge, onVuln, false)
+}
+
+// StreamCallStacksSequentially is like StreamCallStacks, but searches the
+// call stacks of one vulnerability at a time, in the order of res.Vulns,
+// on the calling goroutine. The call stacks are the same, but onEdge is
+// called in a reproducible order, and the search times are not skewed by
+// concurrent searches.
+func StreamCallStacksSequentially(ctx context.Context, res *Result, onEdge EdgeFunc, onVuln func(*Vuln, []CallStack) error) error {
+	return streamCallStacks(ctx, res, onEdge, onVuln, true)
+}
+
+// streamCallStacks implements StreamCallStacks and
+// StreamCallStacksSequentially.
+func streamCallStacks(ctx context.Context, res *Result, onEdge EdgeFunc, onVuln func(*Vuln, []CallStack) error, sequential bool) error {
+	if onEdge != nil && !sequential {
+		var edgeMu sync.Mutex
+		f := onEdge
+		onEdge = func(caller, callee *FuncNode) {
+			edgeMu.Lock()
+			defer edgeMu.Unlock()
+			f(caller, callee)
+		}
+	}
+	type search struct {
+		stacks   []CallStack
+		duration time.Duration
+	}
+	find := func(vuln *Vuln) search {
+		start := time.Now()
+		cs := callStacks(vuln.CallSink, res, onEdge, nil)
+		// sort call stacks by the estimated value to the user
+		sort.SliceStable(cs, func(i int, j int) bool { return stackLess(cs[i], cs[j]) })
+		return search{cs, time.Since(start)}
+	}
+	var results []chan search
+	if !sequential {
+		results = make([]chan search, len(res.Vulns))
+		for i, vuln := range res.Vulns {
+			vuln := vuln
+			results[i] = make(chan search, 1)
+			go func(result chan<- search) {
+				result <- find(vuln)
+			}(results[i])
+		}
 	}
 
-	wg.Wait()
+	var err error
+	for i, vuln := range res.Vulns {
+		var s search
+		if sequential {
+			if ctx.Err() == nil {
+				s = find(vuln)
+			}
+		} else {
+			select {
+			case s = <-results[i]:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			if err == nil {
+				err = ctx.Err()
+			}
+			return err
+		}
+		if vuln.CallSink != nil && vuln.ImportSink != nil {
+			if res.Timings.CallStacks == nil {
+				res.Timings.CallStacks = make(map[string]time.Duration)
+			}
+			res.Timings.CallStacks[vuln.ImportSink.PkgPath] += s.duration
+		}
+		if err == nil {
+			err = onVuln(vuln, s.stacks)
+		}
+	}
+	return err
+}
+
+// An EdgeFilter reports whether the call stack search follows the edge
+// of the call graph from caller to callee.
+type EdgeFilter func(caller, callee *FuncNode) bool
+
+// CallStacksWithFilter is like CallStacks, but the search only follows
+// the edges of the call graph for which keep returns true, as if the
+// other calls did not exist. Vulnerabilities that are only reached
+// through such calls have no call stacks.
+func CallStacksWithFilter(res *Result, keep EdgeFilter) map[*Vuln][]CallStack {
+	stacksPerVuln := make(map[*Vuln][]CallStack)
+	for _, vuln := range res.Vulns {
+		cs := callStacks(vuln.CallSink, res, nil, keep)
+		sort.SliceStable(cs, func(i int, j int) bool { return stackLess(cs[i], cs[j]) })
+		stacksPerVuln[vuln] = cs
+	}
 	return stacksPerVuln
 }
 
 // callStacks finds representative call stacks
 // for vulnerable symbol identified with vulnSinkID.
-func callStacks(vulnSink *FuncNode, res *Result) []CallStack {
+// If onEdge is not nil, it is called for each
+// call chain link pushed during the search.
+// If keep is not nil, the search only follows
+// the call chain links for which it returns true.
+func callStacks(vulnSink *FuncNode, res *Result, onEdge EdgeFunc, keep EdgeFilter) []CallStack {
 	if vulnSink == nil {
 		return nil
 	}
@@ -95,7 +211,13 @@ func callStacks(vulnSink *FuncNode, res *Result) []CallStack {
 		// Pick a single call site for each function in determinstic order.
 		// A single call site is sufficient as we visit a function only once.
 		for _, cs := range callsites(f.CallSites, seen) {
+			if keep != nil && !keep(cs.Parent, f) {
+				continue
+			}
 			nStack := &callChain{f: cs.Parent, call: cs, child: c}
+			if onEdge != nil {
+				onEdge(cs.Parent, f)
+			}
 			if entries[cs.Parent] {
 				stacks = append(stacks, nStack.CallStack())
 			}
@@ -174,11 +296,12 @@ func isStdPackage(pkg string) bool {
 	return !strings.Contains(pkg, ".")
 }
 
-// confidence computes an approximate measure of whether the stack
-// is realizeable in practice. Currently, it equals the number of call
-// sites in stack that go through standard libraries. Such call stacks
-// have been experimentally shown to often result in false positives.
-func confidence(stack CallStack) int {
+// Confidence computes an approximate measure of whether the stack
+// is realizeable in practice, where lower is more likely. Currently, it
+// equals the number of functions of stack, including the vulnerable
+// one, that are in standard libraries. Such call stacks have been
+// experimentally shown to often result in false positives.
+func Confidence(stack CallStack) int {
 	c := 0
 	for _, e := range stack {
 		if e.Function.Package != nil && isStdPackage(e.Function.Package.PkgPath) {
@@ -195,7 +318,7 @@ func confidence(stack CallStack) int {
 // 1) their estimated level of confidence in being a real call stack,
 // 2) their length, and 3) the number of dynamic call sites in the stack.
 func stackLess(s1, s2 CallStack) bool {
-	if c1, c2 := confidence(s1), confidence(s2); c1 != c2 {
+	if c1, c2 := Confidence(s1), Confidence(s2); c1 != c2 {
 		return c1 < c2
 	}
 
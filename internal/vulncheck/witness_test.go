@@ -5,7 +5,12 @@
 package vulncheck
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -62,3 +67,189 @@ func TestCallStacks(t *testing.T) {
 		t.Errorf("want %v; got %v", want, got)
 	}
 }
+
+func TestCallStacksWithEdges(t *testing.T) {
+	// Call graph structure for the test program
+	//    entry1      entry2
+	//      |           |
+	//    interm1       |
+	//           \     /
+	//          interm2
+	//            |
+	//          vuln1
+	e1 := &FuncNode{Name: "entry1"}
+	e2 := &FuncNode{Name: "entry2"}
+	i1 := &FuncNode{Name: "interm1", CallSites: []*CallSite{{Parent: e1, Resolved: true}}}
+	i2 := &FuncNode{Name: "interm2", CallSites: []*CallSite{{Parent: e2, Resolved: true}, {Parent: i1, Resolved: true}}}
+	v1 := &FuncNode{Name: "vuln1", CallSites: []*CallSite{{Parent: i2, Resolved: true}}}
+	res := &Result{
+		EntryFunctions: []*FuncNode{e1, e2},
+		Vulns:          []*Vuln{{CallSink: v1, Symbol: "vuln1"}},
+	}
+
+	var got []string
+	CallStacksWithEdges(res, func(caller, callee *FuncNode) {
+		got = append(got, caller.Name+"->"+callee.Name)
+	})
+	// Call sites are explored in no particular order.
+	sort.Strings(got)
+	want := []string{"entry1->interm1", "entry2->interm2", "interm1->interm2", "interm2->vuln1"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+}
+
+func TestStreamCallStacks(t *testing.T) {
+	// Call graph structure for the test program
+	//    entry1      entry2
+	//      |           |
+	//    vuln1       vuln2
+	e1 := &FuncNode{Name: "entry1"}
+	e2 := &FuncNode{Name: "entry2"}
+	v1 := &FuncNode{Name: "vuln1", CallSites: []*CallSite{{Parent: e1, Resolved: true}}}
+	v2 := &FuncNode{Name: "vuln2", CallSites: []*CallSite{{Parent: e2, Resolved: true}}}
+	res := &Result{
+		EntryFunctions: []*FuncNode{e1, e2},
+		Vulns: []*Vuln{
+			{CallSink: v2, Symbol: "vuln2", ImportSink: &packages.Package{PkgPath: "p2"}},
+			{Symbol: "imported", ImportSink: &packages.Package{PkgPath: "p3"}},
+			{CallSink: v1, Symbol: "vuln1", ImportSink: &packages.Package{PkgPath: "p1"}},
+		},
+	}
+
+	var got []string
+	err := StreamCallStacks(context.Background(), res, nil, func(v *Vuln, stacks []CallStack) error {
+		got = append(got, fmt.Sprintf("%s:%d", v.Symbol, len(stacks)))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Vulnerabilities are streamed in the order of res.Vulns.
+	want := []string{"vuln2:1", "imported:0", "vuln1:1"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+	// The search times are recorded for the packages of called symbols.
+	var pkgs []string
+	for pkg := range res.Timings.CallStacks {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	if want := []string{"p1", "p2"}; !reflect.DeepEqual(want, pkgs) {
+		t.Errorf("want search times for %v; got %v", want, pkgs)
+	}
+
+	errStop := errors.New("stop")
+	got = nil
+	err = StreamCallStacks(context.Background(), res, nil, func(v *Vuln, stacks []CallStack) error {
+		got = append(got, v.Symbol)
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("got error %v; want %v", err, errStop)
+	}
+	if want := []string{"vuln2"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+
+	// Once the context is done, no vulnerability is streamed any more.
+	ctx, cancel := context.WithCancel(context.Background())
+	got = nil
+	err = StreamCallStacksSequentially(ctx, res, nil, func(v *Vuln, stacks []CallStack) error {
+		got = append(got, v.Symbol)
+		cancel()
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("got error %v; want %v", err, context.Canceled)
+	}
+	if want := []string{"vuln2"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+}
+
+func TestStreamCallStacksSequentially(t *testing.T) {
+	// Call graph structure for the test program
+	//    entry1      entry2
+	//      |           |
+	//    interm1       |
+	//           \     /
+	//          interm2
+	//         /       \
+	//      vuln1     vuln2
+	// Callers are explored in the order of their positions.
+	pos := func(line int) *token.Position { return &token.Position{Filename: "f.go", Line: line} }
+	e1 := &FuncNode{Name: "entry1", Pos: pos(1)}
+	e2 := &FuncNode{Name: "entry2", Pos: pos(2)}
+	i1 := &FuncNode{Name: "interm1", Pos: pos(3), CallSites: []*CallSite{{Parent: e1, Resolved: true}}}
+	i2 := &FuncNode{Name: "interm2", Pos: pos(4), CallSites: []*CallSite{{Parent: e2, Resolved: true}, {Parent: i1, Resolved: true}}}
+	v1 := &FuncNode{Name: "vuln1", CallSites: []*CallSite{{Parent: i2, Resolved: true}}}
+	v2 := &FuncNode{Name: "vuln2", CallSites: []*CallSite{{Parent: i2, Resolved: true}}}
+	res := &Result{
+		EntryFunctions: []*FuncNode{e1, e2},
+		Vulns:          []*Vuln{{CallSink: v2, Symbol: "vuln2"}, {CallSink: v1, Symbol: "vuln1"}},
+	}
+
+	var edges []string
+	got := make(map[*Vuln][]CallStack)
+	err := StreamCallStacksSequentially(context.Background(), res, func(caller, callee *FuncNode) {
+		edges = append(edges, caller.Name+"->"+callee.Name)
+	}, func(v *Vuln, stacks []CallStack) error {
+		got[v] = stacks
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The call stacks are those of the concurrent search.
+	if want := stacksToString(CallStacks(res)); !reflect.DeepEqual(want, stacksToString(got)) {
+		t.Errorf("want %v; got %v", want, stacksToString(got))
+	}
+	// Edges are explored one vulnerability at a time, in order.
+	wantEdges := []string{
+		"interm2->vuln2", "entry2->interm2", "interm1->interm2", "entry1->interm1",
+		"interm2->vuln1", "entry2->interm2", "interm1->interm2", "entry1->interm1",
+	}
+	if !reflect.DeepEqual(wantEdges, edges) {
+		t.Errorf("want edges %v; got %v", wantEdges, edges)
+	}
+}
+
+func TestCallStacksWithFilter(t *testing.T) {
+	// Call graph structure for the test program
+	//    entry1      entry2
+	//      |           |
+	//    interm1       |
+	//      |           |
+	//    vuln1       vuln2
+	//      |
+	//    vuln3
+	e1 := &FuncNode{Name: "entry1"}
+	e2 := &FuncNode{Name: "entry2"}
+	i1 := &FuncNode{Name: "interm1", CallSites: []*CallSite{{Parent: e1, Resolved: true}}}
+	v1 := &FuncNode{Name: "vuln1", CallSites: []*CallSite{{Parent: i1, Resolved: true}}}
+	v2 := &FuncNode{Name: "vuln2", CallSites: []*CallSite{{Parent: e2, Resolved: true}}}
+	v3 := &FuncNode{Name: "vuln3", CallSites: []*CallSite{{Parent: v1, Resolved: true}}}
+	res := &Result{
+		EntryFunctions: []*FuncNode{e1, e2},
+		Vulns: []*Vuln{
+			{CallSink: v1, Symbol: "vuln1"},
+			{CallSink: v2, Symbol: "vuln2"},
+			{CallSink: v3, Symbol: "vuln3"},
+		},
+	}
+
+	// Pruning the calls of interm1 leaves only vuln2 reachable.
+	stacks := CallStacksWithFilter(res, func(caller, callee *FuncNode) bool {
+		return caller != i1
+	})
+	want := map[string][]string{
+		"vuln1": nil,
+		"vuln2": {"entry2->vuln2"},
+		"vuln3": nil,
+	}
+	if got := stacksToString(stacks); !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+}
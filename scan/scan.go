@@ -10,7 +10,9 @@ import (
 	"io"
 	"os"
 
+	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/scan"
+	"golang.org/x/vuln/internal/vulncheck"
 )
 
 // Cmd represents an external govulncheck command being prepared or run,
@@ -36,10 +38,29 @@ type Cmd struct {
 	//
 	Env []string
 
-	ctx  context.Context
-	args []string
-	done chan struct{}
-	err  error
+	// Transformers are applied, in order, to the findings of the scan
+	// before they are written to Stdout. Each transformer receives the
+	// findings returned by the previous one. If a transformer returns an
+	// error, the scan stops and Wait returns that error.
+	Transformers []govulncheck.Transformer
+
+	// OnCallEdge, if not nil, is called in source mode for each call
+	// graph edge, from caller to callee, explored while searching for
+	// call stacks that reach vulnerable symbols. Calls are serialized.
+	OnCallEdge func(caller, callee *vulncheck.FuncNode)
+
+	// PositionMapper, if not nil, is called in source mode for each
+	// source position of the findings before they are written to Stdout.
+	// It returns the position to report, such as with a Filename mapped
+	// from a build sandbox to a repository checkout, or false to omit the
+	// position.
+	PositionMapper govulncheck.PositionMapper
+
+	ctx     context.Context
+	args    []string
+	handler govulncheck.Handler
+	done    chan struct{}
+	err     error
 }
 
 // Command returns the Cmd struct to execute govulncheck with the given
@@ -95,5 +116,11 @@ func (c *Cmd) scan() error {
 	if err := c.ctx.Err(); err != nil {
 		return err
 	}
-	return scan.RunGovulncheck(c.ctx, c.Env, c.Stdin, c.Stdout, c.Stderr, c.args)
+	hooks := scan.Hooks{
+		Transformers:   c.Transformers,
+		OnCallEdge:     c.OnCallEdge,
+		PositionMapper: c.PositionMapper,
+		Handler:        c.handler,
+	}
+	return scan.RunGovulncheck(c.ctx, c.Env, c.Stdin, c.Stdout, c.Stderr, c.args, hooks)
 }
@@ -0,0 +1,90 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// Result is a finding of a streamed scan, along with the OSV entry of its
+// vulnerability, or the error that ended the scan.
+type Result struct {
+	Finding *govulncheck.Finding
+	OSV     *osv.Entry
+
+	// Err is the error that ended the scan. If it is set, the other
+	// fields are nil and the result is the last one of the scan.
+	Err error
+}
+
+// Stream starts the specified command like Start, but instead of writing
+// the output of the scan to Stdout, it sends the findings of the scan to
+// the returned channel as they are found, and closes the channel when the
+// scan is done. Output flags such as -json and -format are ignored, as
+// are progress messages. In convert mode, the findings are read from
+// Stdin.
+//
+// The scan runs in the background until all of its findings are received
+// or the context passed to Command is done. Wait may be called once the
+// channel is closed, and returns the error of the last result, if any.
+// Unlike the output of govulncheck, findings of called vulnerabilities are
+// not errors.
+func (c *Cmd) Stream() (<-chan Result, error) {
+	if c.done != nil {
+		return nil, errors.New("vuln: already started")
+	}
+	ch := make(chan Result)
+	c.handler = &streamHandler{ctx: c.ctx, ch: ch, osvs: map[string]*osv.Entry{}}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		defer close(ch)
+		if err := c.Wait(); err != nil {
+			select {
+			case ch <- Result{Err: err}:
+			case <-c.ctx.Done():
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// streamHandler is a handler that sends findings to a channel.
+type streamHandler struct {
+	ctx  context.Context
+	ch   chan<- Result
+	osvs map[string]*osv.Entry
+}
+
+// Config does nothing, as only findings are streamed.
+func (h *streamHandler) Config(config *govulncheck.Config) error {
+	return nil
+}
+
+// Progress does nothing, as only findings are streamed.
+func (h *streamHandler) Progress(progress *govulncheck.Progress) error {
+	return nil
+}
+
+// OSV records entry, which precedes the findings of its vulnerability.
+func (h *streamHandler) OSV(entry *osv.Entry) error {
+	h.osvs[entry.ID] = entry
+	return nil
+}
+
+// Finding sends finding to the channel, unless the scan is canceled.
+func (h *streamHandler) Finding(finding *govulncheck.Finding) error {
+	select {
+	case h.ch <- Result{Finding: finding, OSV: h.osvs[finding.OSV]}:
+		return nil
+	case <-h.ctx.Done():
+		return h.ctx.Err()
+	}
+}
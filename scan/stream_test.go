@@ -0,0 +1,68 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "cmd", "govulncheck", "testdata", "convert_input.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cmd := Command(context.Background(), "-mode=convert")
+	cmd.Stdin = f
+	results, err := cmd.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := 0
+	for r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if r.OSV == nil || r.OSV.ID != r.Finding.OSV {
+			t.Errorf("finding of %s has OSV entry %v", r.Finding.OSV, r.OSV)
+		}
+		findings++
+	}
+	if findings == 0 {
+		t.Error("got no findings")
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+	if _, err := cmd.Stream(); err == nil {
+		t.Error("want error when streaming a started command; got nil")
+	}
+}
+
+func TestStreamCanceled(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "cmd", "govulncheck", "testdata", "convert_input.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := Command(ctx, "-mode=convert")
+	cmd.Stdin = f
+	results, err := cmd.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stop after the first finding, without draining the channel.
+	<-results
+	cancel()
+	if err := cmd.Wait(); err != context.Canceled {
+		t.Errorf("Wait() = %v; want %v", err, context.Canceled)
+	}
+}